@@ -0,0 +1,159 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// AdjustBrightness changes the brightness of the image by percent, from -100
+// (black) to 100 (white)
+// i.e :
+// imgr.AdjustBrightness(10)
+func (i *Imager) AdjustBrightness(percent float64) *Imager {
+	if !i.checkRange("brightness", percent, -100, 100) {
+		return i
+	}
+
+	return i.apply("AdjustBrightness", func(img image.Image) image.Image {
+		return imaging.AdjustBrightness(img, percent)
+	})
+}
+
+// AdjustContrast changes the contrast of the image by percent, from -100
+// (solid gray) to 100
+// i.e :
+// imgr.AdjustContrast(20)
+func (i *Imager) AdjustContrast(percent float64) *Imager {
+	if !i.checkRange("contrast", percent, -100, 100) {
+		return i
+	}
+
+	return i.apply("AdjustContrast", func(img image.Image) image.Image {
+		return imaging.AdjustContrast(img, percent)
+	})
+}
+
+// AdjustSaturation changes the saturation of the image by percent, from -100
+// (grayscale) to 100 (saturation doubled)
+// i.e :
+// imgr.AdjustSaturation(25)
+func (i *Imager) AdjustSaturation(percent float64) *Imager {
+	if !i.checkRange("saturation", percent, -100, 100) {
+		return i
+	}
+
+	return i.apply("AdjustSaturation", func(img image.Image) image.Image {
+		return imaging.AdjustSaturation(img, percent)
+	})
+}
+
+// AdjustHue rotates the hue of the image by degrees, from -180 to 180
+// i.e :
+// imgr.AdjustHue(90)
+func (i *Imager) AdjustHue(degrees float64) *Imager {
+	if !i.checkRange("hue", degrees, -180, 180) {
+		return i
+	}
+
+	shift := degrees / 360
+	return i.apply("AdjustHue", func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			h, s, l := rgbToHSL(c.R, c.G, c.B)
+			h = math.Mod(h+shift+1, 1)
+			r, g, b := hslToRGB(h, s, l)
+			return color.NRGBA{r, g, b, c.A}
+		})
+	})
+}
+
+// AdjustGamma applies a gamma correction to the image, gamma ranges from 0.1
+// to 10. Below 1 darkens the image and above 1 lightens it
+// i.e :
+// imgr.AdjustGamma(1.2)
+func (i *Imager) AdjustGamma(gamma float64) *Imager {
+	if !i.checkRange("gamma", gamma, 0.1, 10) {
+		return i
+	}
+
+	return i.apply("AdjustGamma", func(img image.Image) image.Image {
+		return imaging.AdjustGamma(img, gamma)
+	})
+}
+
+// checkRange records ErrInvalidArgument when value is outside [low, high]
+func (i *Imager) checkRange(name string, value, low, high float64) bool {
+	if value >= low && value <= high {
+		return true
+	}
+
+	i.setErr(fmt.Errorf("%w: %s %v out of [%v, %v]", ErrInvalidArgument, name, value, low, high))
+	return false
+}
+
+// rgbToHSL converts a color to hue, saturation and lightness, all in [0, 1]
+func rgbToHSL(r, g, b uint8) (h, s, l float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	high := math.Max(rf, math.Max(gf, bf))
+	low := math.Min(rf, math.Min(gf, bf))
+	l = (high + low) / 2
+
+	delta := high - low
+	if delta == 0 {
+		return 0, 0, l
+	}
+
+	if l < 0.5 {
+		s = delta / (high + low)
+	} else {
+		s = delta / (2 - high - low)
+	}
+
+	switch high {
+	case rf:
+		h = (gf - bf) / delta
+		if gf < bf {
+			h += 6
+		}
+	case gf:
+		h = (bf-rf)/delta + 2
+	default:
+		h = (rf-gf)/delta + 4
+	}
+
+	return h / 6, s, l
+}
+
+// hslToRGB converts hue, saturation and lightness in [0, 1] to a color
+func hslToRGB(h, s, l float64) (r, g, b uint8) {
+	if s == 0 {
+		v := clampUint8(l * 255)
+		return v, v, v
+	}
+
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+
+	channel := func(t float64) uint8 {
+		t = math.Mod(t+1, 1)
+		switch {
+		case t < 1.0/6:
+			return clampUint8((p + (q-p)*6*t) * 255)
+		case t < 1.0/2:
+			return clampUint8(q * 255)
+		case t < 2.0/3:
+			return clampUint8((p + (q-p)*(2.0/3-t)*6) * 255)
+		}
+		return clampUint8(p * 255)
+	}
+
+	return channel(h + 1.0/3), channel(h), channel(h - 1.0/3)
+}
@@ -0,0 +1,76 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// createColorImage returns a 10x10 image filled with c
+func createColorImage(c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+// pixel returns the 8-bit color of the pixel at x, y
+func pixel(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestAdjust(t *testing.T) {
+	gray := color.NRGBA{100, 100, 100, 255}
+	tests := []struct {
+		name  string
+		op    func(*Imager) *Imager
+		check func(c color.NRGBA) bool
+	}{
+		{"brightness", func(i *Imager) *Imager { return i.AdjustBrightness(20) }, func(c color.NRGBA) bool { return c.R > 140 }},
+		{"contrast", func(i *Imager) *Imager { return i.AdjustContrast(50) }, func(c color.NRGBA) bool { return c.R < 100 }},
+		{"gamma", func(i *Imager) *Imager { return i.AdjustGamma(2) }, func(c color.NRGBA) bool { return c.R > 100 }},
+	}
+
+	for _, tt := range tests {
+		imgr, _ := NewImager(createColorImage(gray))
+		if c := pixel(tt.op(imgr).Image, 5, 5); !tt.check(c) {
+			t.Fatalf("%s: unexpected color %v", tt.name, c)
+		}
+	}
+}
+
+func TestAdjustSaturationAndHue(t *testing.T) {
+	imgr, _ := NewImager(createColorImage(color.NRGBA{200, 50, 50, 255}))
+	if c := pixel(imgr.AdjustSaturation(-100).Image, 5, 5); c.R != c.G || c.G != c.B {
+		t.Fatalf("AdjustSaturation(-100) did not produce gray: %v", c)
+	}
+
+	imgr, _ = NewImager(createColorImage(color.NRGBA{255, 0, 0, 128}))
+	if c := pixel(imgr.AdjustHue(120).Image, 5, 5); c != (color.NRGBA{0, 255, 0, 128}) {
+		t.Fatalf("AdjustHue(120) did not turn red into green: %v", c)
+	}
+	if c := pixel(imgr.AdjustHue(-120).Image, 5, 5); c != (color.NRGBA{255, 0, 0, 128}) {
+		t.Fatalf("AdjustHue(-120) did not turn green back into red: %v", c)
+	}
+}
+
+func TestAdjustRange(t *testing.T) {
+	imgr, _ := NewImager(createColorImage(color.White))
+	imgr.AdjustBrightness(150)
+	if !errors.Is(imgr.Err(), ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", imgr.Err())
+	}
+
+	imgr, _ = NewImager(createColorImage(color.White))
+	if imgr.AdjustGamma(0).Err() == nil {
+		t.Fatalf("AdjustGamma accepted a zero gamma")
+	}
+	if c := pixel(imgr.Image, 5, 5); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("an invalid adjustment changed the image: %v", c)
+	}
+}
@@ -0,0 +1,79 @@
+package imager
+
+import (
+	"image"
+	"math"
+)
+
+// Anchor is a position within the image
+type Anchor int
+
+const (
+	AnchorTopLeft Anchor = iota
+	AnchorTop
+	AnchorTopRight
+	AnchorLeft
+	AnchorCenter
+	AnchorRight
+	AnchorBottomLeft
+	AnchorBottom
+	AnchorBottomRight
+)
+
+// point returns the top left corner of a size area placed at the anchor
+// within bounds, margin pixels away from the edges
+func (a Anchor) point(bounds image.Rectangle, size image.Point, margin int) image.Point {
+	pt := bounds.Min.Add(image.Pt(margin, margin))
+	free := bounds.Size().Sub(size).Sub(image.Pt(2*margin, 2*margin))
+
+	switch a {
+	case AnchorTop, AnchorCenter, AnchorBottom:
+		pt.X += free.X / 2
+	case AnchorTopRight, AnchorRight, AnchorBottomRight:
+		pt.X += free.X
+	}
+
+	switch a {
+	case AnchorLeft, AnchorCenter, AnchorRight:
+		pt.Y += free.Y / 2
+	case AnchorBottomLeft, AnchorBottom, AnchorBottomRight:
+		pt.Y += free.Y
+	}
+
+	return pt
+}
+
+// Gravity is the part of the image kept by the crops, either an Anchor, such
+// as AnchorTop for north, or a FocalPoint
+type Gravity interface {
+	// place returns the top left corner of the kept size area within bounds
+	place(bounds image.Rectangle, size image.Point) image.Point
+}
+
+func (a Anchor) place(bounds image.Rectangle, size image.Point) image.Point {
+	return a.point(bounds, size, 0)
+}
+
+// focalPoint is the Gravity returned by FocalPoint
+type focalPoint struct {
+	x, y float64
+}
+
+// FocalPoint returns a Gravity keeping the area centered on the point at x, y,
+// fractions of the width and height from 0 to 1. The area is moved back within
+// the image when the point is near the edges
+// i.e :
+// imgr.CropAnchor(400, 400, imager.FocalPoint(0.3, 0.25))
+func FocalPoint(x, y float64) Gravity {
+	return focalPoint{x: math.Max(0, math.Min(1, x)), y: math.Max(0, math.Min(1, y))}
+}
+
+func (f focalPoint) place(bounds image.Rectangle, size image.Point) image.Point {
+	x := int(f.x*float64(bounds.Dx())+0.5) - size.X/2
+	y := int(f.y*float64(bounds.Dy())+0.5) - size.Y/2
+
+	return bounds.Min.Add(image.Pt(
+		max(0, min(x, bounds.Dx()-size.X)),
+		max(0, min(y, bounds.Dy()-size.Y)),
+	))
+}
@@ -0,0 +1,285 @@
+package imager
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+// Animation holds the frames of an animated image. Every frame covers the
+// whole canvas, the partial frames of the source are composed on load.
+// Frames are replaced rather than modified by the operations
+type Animation struct {
+	// Frames of the animation, all of the same size
+	Frames []image.Image
+
+	// Delays holds the delay of each frame in 100ths of a second
+	Delays []int
+
+	// LoopCount has the meaning of gif.GIF.LoopCount, 0 loops forever
+	LoopCount int
+}
+
+// AnimationFrame is a frame of an animation, see Frames
+type AnimationFrame struct {
+	// Imager holds the still image of the frame
+	Imager *Imager
+
+	// Start is the time the frame is shown at since the beginning of the
+	// animation, for Delay
+	Start, Delay time.Duration
+}
+
+// Frames returns the frames of the animation as still images with their
+// timing, a single frame for still images. The frames share the pixels and
+// metadata of the imager, like Fork
+// i.e :
+//
+//	for idx, frame := range imgr.Frames() {
+//		err := frame.Imager.Save(fmt.Sprintf("frame-%d.png", idx))
+//	}
+func (i *Imager) Frames() []AnimationFrame {
+	if i.Animation == nil {
+		return []AnimationFrame{{Imager: i.frameImager(i.Image)}}
+	}
+
+	frames := make([]AnimationFrame, len(i.Animation.Frames))
+	var start time.Duration
+	for idx, frame := range i.Animation.Frames {
+		frames[idx] = AnimationFrame{Imager: i.frameImager(frame), Start: start, Delay: i.frameDelay(idx)}
+		start += frames[idx].Delay
+	}
+
+	return frames
+}
+
+// FrameAt returns the frame at index of the animation as a still image, see
+// Frames. Still images only have the frame 0
+// i.e :
+// still, err := imgr.FrameAt(0)
+func (i *Imager) FrameAt(index int) (*Imager, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+
+	count := 1
+	if i.Animation != nil {
+		count = len(i.Animation.Frames)
+	}
+	if index < 0 || index >= count {
+		return nil, fmt.Errorf("%w: frame %d of %d", ErrInvalidArgument, index, count)
+	}
+	if i.Animation == nil {
+		return i.frameImager(i.Image), nil
+	}
+
+	return i.frameImager(i.Animation.Frames[index]), nil
+}
+
+// FrameAtTime returns the frame shown at t since the beginning of the
+// animation as a still image, the last frame once the animation is over,
+// see Frames
+// i.e :
+// preview, err := imgr.FrameAtTime(1500 * time.Millisecond)
+func (i *Imager) FrameAtTime(t time.Duration) (*Imager, error) {
+	if t < 0 {
+		return nil, fmt.Errorf("%w: frame at %v", ErrInvalidArgument, t)
+	}
+	if i.Animation == nil {
+		return i.FrameAt(0)
+	}
+
+	index := len(i.Animation.Frames) - 1
+	var end time.Duration
+	for idx := range i.Animation.Frames {
+		end += i.frameDelay(idx)
+		if t < end {
+			index = idx
+			break
+		}
+	}
+
+	return i.FrameAt(index)
+}
+
+// frameDelay returns the delay of the frame at index of the animation
+func (i *Imager) frameDelay(index int) time.Duration {
+	if index >= len(i.Animation.Delays) {
+		return 0
+	}
+
+	return time.Duration(i.Animation.Delays[index]) * 10 * time.Millisecond
+}
+
+// frameImager returns a still imager of frame sharing the metadata of i
+func (i *Imager) frameImager(frame image.Image) *Imager {
+	still := *i
+	still.history = nil
+	still.Animation, still.originalAnimation = nil, nil
+	still.Image, still.original = frame, frame
+
+	return &still
+}
+
+// apply replaces the image, and every frame of the animation, by the result
+// of op, name being the method reported to the Observer unless an outer one
+// is running, see operation. Nothing is done once an error is recorded, the
+// image would not be the one expected by the rest of the chain
+func (i *Imager) apply(name string, op func(image.Image) image.Image) *Imager {
+	if i.err != nil {
+		return i
+	}
+	if obs := currentObserver(); obs != nil {
+		frames := 1
+		if i.Animation != nil {
+			frames = len(i.Animation.Frames)
+		}
+		if i.operationName != "" {
+			name = i.operationName
+		}
+		defer observeOperation(obs, name, time.Now(), frames)
+	}
+	if i.Animation == nil {
+		i.Image = op(i.Image)
+		return i
+	}
+
+	anim := *i.Animation
+	anim.Frames = make([]image.Image, len(i.Animation.Frames))
+	for idx, frame := range i.Animation.Frames {
+		anim.Frames[idx] = op(frame)
+	}
+
+	i.Animation = &anim
+	i.Image = anim.Frames[0]
+
+	return i
+}
+
+// loopPlays returns the number of times an animation of loopCount is
+// played, as stored by APNG and WebP, 0 playing it forever
+func loopPlays(loopCount int) int {
+	switch {
+	case loopCount < 0:
+		return 1
+	case loopCount > 0:
+		return min(loopCount+1, 1<<16-1)
+	}
+
+	return 0
+}
+
+// loopCountFromPlays returns the LoopCount of an animation played plays
+// times, the inverse of loopPlays
+func loopCountFromPlays(plays int) int {
+	switch {
+	case plays == 1:
+		return -1
+	case plays > 1:
+		return plays - 1
+	}
+
+	return 0
+}
+
+// decodeGIFAnimation returns the composed frames of an animated GIF, nil
+// when data holds a single frame
+func decodeGIFAnimation(data []byte) *Animation {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil || len(g.Image) < 2 {
+		return nil
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	for _, frame := range g.Image {
+		bounds = bounds.Union(frame.Bounds())
+	}
+
+	anim := &Animation{Delays: make([]int, len(g.Image)), LoopCount: g.LoopCount}
+	canvas := image.NewNRGBA(bounds)
+	for idx, frame := range g.Image {
+		var disposal byte
+		if idx < len(g.Disposal) {
+			disposal = g.Disposal[idx]
+		}
+		if idx < len(g.Delay) {
+			anim.Delays[idx] = g.Delay[idx]
+		}
+
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = imaging.Clone(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		anim.Frames = append(anim.Frames, imaging.Clone(canvas))
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			draw.Draw(canvas, canvas.Bounds(), previous, image.Point{}, draw.Src)
+		}
+	}
+
+	return anim
+}
+
+// encodeGIFAnimation writes anim to w, each frame gets its own palette of at
+// most numColors colors
+func encodeGIFAnimation(w io.Writer, anim *Animation, numColors int, dither bool) error {
+	g := &gif.GIF{LoopCount: anim.LoopCount}
+	for idx, frame := range anim.Frames {
+		paletted := gifFrame(frame, numColors, dither)
+
+		delay := 0
+		if idx < len(anim.Delays) {
+			delay = anim.Delays[idx]
+		}
+
+		g.Image = append(g.Image, paletted)
+		g.Delay = append(g.Delay, delay)
+		// Frames cover the whole canvas, clear it so transparency shows through
+		g.Disposal = append(g.Disposal, gif.DisposalBackground)
+	}
+
+	return gif.EncodeAll(w, g)
+}
+
+// gifFrame returns img reduced to its median cut palette, dithered with
+// Floyd-Steinberg error diffusion when dither is set
+func gifFrame(img image.Image, numColors int, dither bool) *image.Paletted {
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(bounds, gifPalette(img, numColors))
+
+	var drawer draw.Drawer = draw.Src
+	if dither {
+		drawer = draw.FloydSteinberg
+	}
+	drawer.Draw(paletted, bounds, img, bounds.Min)
+
+	return paletted
+}
+
+// gifPalette builds the palette of a GIF frame, fully transparent pixels
+// share a single transparent entry
+func gifPalette(img image.Image, numColors int) color.Palette {
+	palette := medianCut(img, numColors)
+	for idx, c := range palette {
+		if nrgba := c.(color.NRGBA); nrgba.A < 0x80 {
+			palette[idx] = color.NRGBA{}
+		} else {
+			nrgba.A = 0xff
+			palette[idx] = nrgba
+		}
+	}
+
+	return palette
+}
@@ -0,0 +1,159 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+// createTestGIF returns a 3 frame animated GIF, the second frame only covers
+// the top left quarter of the canvas
+func createTestGIF(t *testing.T) []byte {
+	palette := color.Palette{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}}
+
+	full := func(idx uint8) *image.Paletted {
+		frame := image.NewPaletted(image.Rect(0, 0, 40, 40), palette)
+		for p := range frame.Pix {
+			frame.Pix[p] = idx
+		}
+		return frame
+	}
+	partial := image.NewPaletted(image.Rect(0, 0, 20, 20), palette)
+	for p := range partial.Pix {
+		partial.Pix[p] = 1
+	}
+
+	g := &gif.GIF{
+		Image:     []*image.Paletted{full(0), partial, full(2)},
+		Delay:     []int{10, 20, 30},
+		LoopCount: 3,
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gif.EncodeAll(buf, g); err != nil {
+		t.Fatalf("failed to encode test gif: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestAnimatedGIF(t *testing.T) {
+	imgr, err := NewImagerFromBytes(createTestGIF(t))
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+
+	if imgr.Animation == nil || len(imgr.Animation.Frames) != 3 {
+		t.Fatalf("NewImagerFromBytes did not load the animation frames")
+	}
+
+	// The partial frame is composed over the first one
+	second := imgr.Animation.Frames[1]
+	if got := color.NRGBAModel.Convert(second.At(5, 5)); got != (color.NRGBA{0, 255, 0, 255}) {
+		t.Fatalf("second frame has color %v inside the partial frame", got)
+	}
+	if got := color.NRGBAModel.Convert(second.At(30, 30)); got != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("second frame has color %v outside the partial frame", got)
+	}
+
+	imgr.Resize(20, 20, MD_STRETCH).Rotate(90)
+
+	data, err := imgr.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode the animated gif: %v", err)
+	}
+
+	if len(g.Image) != 3 {
+		t.Fatalf("Bytes wrote %d frames, want 3", len(g.Image))
+	}
+	if g.LoopCount != 3 || g.Delay[0] != 10 || g.Delay[1] != 20 || g.Delay[2] != 30 {
+		t.Fatalf("Bytes did not preserve the timing: loop %d delays %v", g.LoopCount, g.Delay)
+	}
+
+	for idx, want := range []color.NRGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}} {
+		frame := g.Image[idx]
+		if frame.Bounds().Dx() != 20 || frame.Bounds().Dy() != 20 {
+			t.Fatalf("frame %d has bounds %v, want 20x20", idx, frame.Bounds())
+		}
+		// Rotated by 90 degrees counter-clockwise, the top left quarter moves to the bottom left
+		if got := color.NRGBAModel.Convert(frame.At(2, 17)); got != want {
+			t.Fatalf("frame %d has color %v, want %v", idx, got, want)
+		}
+	}
+}
+
+func TestAnimatedGIFReset(t *testing.T) {
+	imgr, _ := NewImagerFromReader(bytes.NewReader(createTestGIF(t)))
+	if imgr.Animation == nil {
+		t.Fatalf("NewImagerFromReader did not load the animation frames")
+	}
+
+	imgr.Resize(10, 10, MD_STRETCH).Reset()
+
+	if imgr.Animation.Frames[2].Bounds().Dx() != 40 || imgr.Image.Bounds().Dx() != 40 {
+		t.Fatalf("Reset did not restore the animation frames")
+	}
+}
+
+func TestFrames(t *testing.T) {
+	imgr, err := NewImagerFromBytes(createTestGIF(t))
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+
+	frames := imgr.Frames()
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(frames))
+	}
+	if frames[2].Start != 300*time.Millisecond || frames[2].Delay != 300*time.Millisecond {
+		t.Fatalf("unexpected timing of the last frame: %v %v", frames[2].Start, frames[2].Delay)
+	}
+	for _, frame := range frames {
+		if frame.Imager.Animation != nil || frame.Imager.ImageType != IMGIF {
+			t.Fatalf("the frames are not still GIF images")
+		}
+	}
+
+	for _, tt := range []struct {
+		at       time.Duration
+		expected color.NRGBA
+	}{
+		{0, color.NRGBA{255, 0, 0, 255}},
+		{150 * time.Millisecond, color.NRGBA{0, 255, 0, 255}},
+		{350 * time.Millisecond, color.NRGBA{0, 0, 255, 255}},
+		{time.Minute, color.NRGBA{0, 0, 255, 255}},
+	} {
+		still, err := imgr.FrameAtTime(tt.at)
+		if err != nil {
+			t.Fatalf("FrameAtTime returned an error: %v", err)
+		}
+		if c := color.NRGBAModel.Convert(still.Image.At(5, 5)); c != tt.expected {
+			t.Fatalf("frame at %v: got %v, want %v", tt.at, c, tt.expected)
+		}
+	}
+
+	// Editing a frame leaves the animation untouched
+	still, _ := imgr.FrameAt(1)
+	still.Resize(10, 10, MD_STRETCH)
+	if imgr.Animation.Frames[1].Bounds().Dx() != 40 {
+		t.Fatalf("resizing a frame modified the animation")
+	}
+
+	if _, err := imgr.FrameAt(3); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+
+	single, _ := NewImager(createTestImage())
+	if frames := single.Frames(); len(frames) != 1 || frames[0].Imager.Image != single.Image {
+		t.Fatalf("a still image does not have a single frame")
+	}
+}
@@ -0,0 +1,248 @@
+package imager
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/disintegration/imaging"
+)
+
+// The APNG frame control operations
+const (
+	apngDisposeNone       = 0
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+	apngBlendSource       = 0
+	apngBlendOver         = 1
+)
+
+// pngChunks calls fn with the type and data of each chunk of the PNG stream
+// data until fn returns false or the stream ends
+func pngChunks(data []byte, fn func(kind string, chunk []byte) bool) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return
+	}
+
+	for pos := len(pngSignature); pos+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		if length < 0 || pos+12+length > len(data) {
+			return
+		}
+		if !fn(string(data[pos+4:pos+8]), data[pos+8:pos+8+length]) {
+			return
+		}
+		pos += 12 + length
+	}
+}
+
+// isAPNG reports whether the beginning of a PNG stream announces an
+// animation, the acTL chunk coming before the image data
+func isAPNG(header []byte) bool {
+	animated := false
+	pngChunks(header, func(kind string, chunk []byte) bool {
+		animated = kind == "acTL"
+		return !animated && kind != "IDAT"
+	})
+
+	return animated
+}
+
+// apngFrame is a frame of an APNG stream, its fcTL chunk followed by its
+// image data
+type apngFrame struct {
+	fctl []byte
+	data [][]byte
+}
+
+// decodeAPNG returns the composed frames of an animated PNG, nil when data
+// isn't one or holds a single frame
+func decodeAPNG(data []byte) *Animation {
+	var ihdr []byte
+	var plays int
+	var shared [][]byte
+	var frames []*apngFrame
+	var current *apngFrame
+	pngChunks(data, func(kind string, chunk []byte) bool {
+		switch kind {
+		case "IHDR":
+			ihdr = chunk
+		case "acTL":
+			if len(chunk) >= 8 {
+				plays = int(binary.BigEndian.Uint32(chunk[4:]))
+			}
+		case "PLTE", "tRNS":
+			// Needed to decode every frame
+			shared = append(shared, pngChunk(kind, chunk))
+		case "fcTL":
+			if len(chunk) >= 26 {
+				current = &apngFrame{fctl: chunk}
+				frames = append(frames, current)
+			}
+		case "IDAT":
+			// Without an fcTL first, the default image isn't part of the
+			// animation
+			if current != nil {
+				current.data = append(current.data, chunk)
+			}
+		case "fdAT":
+			if current != nil && len(chunk) > 4 {
+				current.data = append(current.data, chunk[4:])
+			}
+		}
+		return kind != "IEND"
+	})
+	if len(ihdr) < 13 || len(frames) < 2 {
+		return nil
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, int(binary.BigEndian.Uint32(ihdr)), int(binary.BigEndian.Uint32(ihdr[4:]))))
+	anim := &Animation{LoopCount: loopCountFromPlays(plays)}
+	for _, frame := range frames {
+		fctl := frame.fctl
+		width, height := binary.BigEndian.Uint32(fctl[4:]), binary.BigEndian.Uint32(fctl[8:])
+		x, y := int(binary.BigEndian.Uint32(fctl[12:])), int(binary.BigEndian.Uint32(fctl[16:]))
+		delayNum, delayDen := int(binary.BigEndian.Uint16(fctl[20:])), int(binary.BigEndian.Uint16(fctl[22:]))
+		dispose, blend := fctl[24], fctl[25]
+
+		// Each frame is decoded as a PNG of its own sharing the header
+		frameIHDR := append(binary.BigEndian.AppendUint32(nil, width), binary.BigEndian.AppendUint32(nil, height)...)
+		stream := append([]byte(pngSignature), pngChunk("IHDR", append(frameIHDR, ihdr[8:13]...))...)
+		for _, chunk := range shared {
+			stream = append(stream, chunk...)
+		}
+		stream = append(stream, pngChunk("IDAT", bytes.Join(frame.data, nil))...)
+		stream = append(stream, pngChunk("IEND", nil)...)
+		img, err := png.Decode(bytes.NewReader(stream))
+		if err != nil {
+			return nil
+		}
+
+		var previous *image.NRGBA
+		if dispose == apngDisposePrevious {
+			previous = imaging.Clone(canvas)
+		}
+
+		rect := img.Bounds().Sub(img.Bounds().Min).Add(image.Pt(x, y))
+		op := draw.Src
+		if blend == apngBlendOver {
+			op = draw.Over
+		}
+		draw.Draw(canvas, rect, img, img.Bounds().Min, op)
+		anim.Frames = append(anim.Frames, imaging.Clone(canvas))
+
+		// The delays are kept in 100ths of a second, a zero denominator
+		// meaning 100
+		if delayDen == 0 {
+			delayDen = 100
+		}
+		anim.Delays = append(anim.Delays, (delayNum*100+delayDen/2)/delayDen)
+
+		switch dispose {
+		case apngDisposeBackground:
+			draw.Draw(canvas, rect, image.Transparent, image.Point{}, draw.Src)
+		case apngDisposePrevious:
+			draw.Draw(canvas, canvas.Bounds(), previous, image.Point{}, draw.Src)
+		}
+	}
+
+	return anim
+}
+
+// encodeAPNG writes anim to w as an animated PNG. Like encodeWebPAnimation,
+// the frames after the first only hold the area differing from the frame
+// before and replace it
+func encodeAPNG(w io.Writer, anim *Animation, opts EncodeOptions) error {
+	frames := make([]*image.NRGBA, len(anim.Frames))
+	alpha := false
+	for idx, img := range anim.Frames {
+		frames[idx] = imaging.Clone(img)
+		alpha = alpha || !frames[idx].Opaque()
+	}
+
+	colorType, bpp := byte(2), 3
+	if alpha {
+		colorType, bpp = 6, 4
+	}
+	bounds := frames[0].Rect
+	ihdr := binary.BigEndian.AppendUint32(nil, uint32(bounds.Dx()))
+	ihdr = binary.BigEndian.AppendUint32(ihdr, uint32(bounds.Dy()))
+	ihdr = append(ihdr, 8, colorType, 0, 0, 0)
+
+	actl := binary.BigEndian.AppendUint32(nil, uint32(len(frames)))
+	actl = binary.BigEndian.AppendUint32(actl, uint32(loopPlays(anim.LoopCount)))
+
+	out := append([]byte(pngSignature), pngChunk("IHDR", ihdr)...)
+	out = append(out, pngChunk("acTL", actl)...)
+
+	seq := uint32(0)
+	for idx, frame := range frames {
+		rect := frame.Rect
+		if idx > 0 {
+			rect = changedRect(frames[idx-1], frame)
+		}
+
+		delay := 0
+		if idx < len(anim.Delays) {
+			delay = anim.Delays[idx]
+		}
+
+		fctl := binary.BigEndian.AppendUint32(nil, seq)
+		fctl = binary.BigEndian.AppendUint32(fctl, uint32(rect.Dx()))
+		fctl = binary.BigEndian.AppendUint32(fctl, uint32(rect.Dy()))
+		fctl = binary.BigEndian.AppendUint32(fctl, uint32(rect.Min.X))
+		fctl = binary.BigEndian.AppendUint32(fctl, uint32(rect.Min.Y))
+		fctl = binary.BigEndian.AppendUint16(fctl, uint16(min(delay, 1<<16-1)))
+		fctl = binary.BigEndian.AppendUint16(fctl, 100)
+		fctl = append(fctl, apngDisposeNone, apngBlendSource)
+		out = append(out, pngChunk("fcTL", fctl)...)
+		seq++
+
+		data, err := pngFrameData(frame, rect, bpp, opts.PNGCompression)
+		if err != nil {
+			return err
+		}
+		if idx == 0 {
+			out = append(out, pngChunk("IDAT", data)...)
+		} else {
+			out = append(out, pngChunk("fdAT", append(binary.BigEndian.AppendUint32(nil, seq), data...))...)
+			seq++
+		}
+	}
+	out = append(out, pngChunk("IEND", nil)...)
+
+	_, err := w.Write(out)
+	return err
+}
+
+// pngFrameData returns the compressed image data of the rect area of frame,
+// as 8-bit RGB or RGBA pixels depending on bpp
+func pngFrameData(frame *image.NRGBA, rect image.Rectangle, bpp int, level png.CompressionLevel) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	zw, err := zlib.NewWriterLevel(buf, zlibLevel(level))
+	if err != nil {
+		return nil, err
+	}
+
+	row, prev := make([]byte, rect.Dx()*bpp), make([]byte, rect.Dx()*bpp)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		pix := frame.Pix[y*frame.Stride+rect.Min.X*4 : y*frame.Stride+rect.Max.X*4]
+		for x := 0; x < rect.Dx(); x++ {
+			copy(row[x*bpp:x*bpp+bpp], pix[x*4:])
+		}
+		if _, err := zw.Write(filterRow(row, prev, bpp)); err != nil {
+			return nil, err
+		}
+		prev, row = row, prev
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
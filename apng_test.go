@@ -0,0 +1,57 @@
+package imager
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestAPNG(t *testing.T) {
+	imgr, _ := NewImagerFromBytes(createTestGIF(t))
+	anim := imgr.Animation
+
+	data, err := imgr.ConvertTo(IMPNG).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if !isAPNG(data) {
+		t.Fatalf("Bytes did not write an animated png")
+	}
+
+	// Decoders without APNG support show the first frame
+	still, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode the png: %v", err)
+	}
+	assertSamePixels(t, still, anim.Frames[0])
+
+	fromBytes, err := NewImagerFromBytes(data)
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+	fromReader, err := NewImagerFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewImagerFromReader returned an error: %v", err)
+	}
+
+	for _, loaded := range []*Imager{fromBytes, fromReader} {
+		got := loaded.Animation
+		if got == nil || len(got.Frames) != 3 {
+			t.Fatalf("the animation frames were not loaded")
+		}
+		if got.LoopCount != anim.LoopCount || got.Delays[0] != 10 || got.Delays[1] != 20 || got.Delays[2] != 30 {
+			t.Fatalf("the timing was not preserved: loop %d delays %v", got.LoopCount, got.Delays)
+		}
+		for idx, frame := range got.Frames {
+			assertSamePixels(t, frame, anim.Frames[idx])
+		}
+	}
+}
+
+func TestLoopPlays(t *testing.T) {
+	for _, loopCount := range []int{-1, 0, 1, 5} {
+		if got := loopCountFromPlays(loopPlays(loopCount)); got != loopCount {
+			t.Errorf("loop count %d round trips to %d", loopCount, got)
+		}
+	}
+}
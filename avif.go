@@ -0,0 +1,45 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"slices"
+)
+
+// Defaults of the AVIF encode options
+const (
+	defaultAVIFQuality = 60
+	defaultAVIFSpeed   = 6
+)
+
+// errAVIFEncoder is returned when encoding AVIF images without an AVIF
+// encoder registered
+var errAVIFEncoder = fmt.Errorf("%w: AVIF images need the imageravif package", ErrUnsupportedFormat)
+
+// isAVIF reports whether data starts like an AVIF image, still or animated.
+// The major brand can be a generic one, such as mif1, with avif or avis
+// among the compatible brands
+func isAVIF(data []byte) bool {
+	avif := func(brand string) bool { return brand == "avif" || brand == "avis" }
+	return avif(ftypBrand(data)) || slices.ContainsFunc(ftypCompatible(data), avif)
+}
+
+// encodeAVIF writes img to w as AVIF with the encoder registered by
+// RegisterFormat, such as the one of the imageravif package, the AVIF
+// options of opts set to their defaults when out of range
+func encodeAVIF(w io.Writer, img image.Image, opts EncodeOptions) error {
+	encode := registeredEncoder(IMAVIF)
+	if encode == nil {
+		return errAVIFEncoder
+	}
+
+	if opts.AVIFQuality <= 0 || opts.AVIFQuality > 100 {
+		opts.AVIFQuality = defaultAVIFQuality
+	}
+	if opts.AVIFSpeed <= 0 || opts.AVIFSpeed > 10 {
+		opts.AVIFSpeed = defaultAVIFSpeed
+	}
+
+	return encode(w, img, opts)
+}
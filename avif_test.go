@@ -0,0 +1,71 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAVIFWithoutCodec(t *testing.T) {
+	data := append([]byte{0, 0, 0, 28}, "ftypavif\x00\x00\x00\x00avifmif1miaf"...)
+	if !isAVIF(data) || isHEIF(data) {
+		t.Fatalf("isAVIF did not recognize an AVIF header")
+	}
+	if _, err := NewImagerFromBytes(data); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("NewImagerFromBytes returned %v, expected ErrUnknownFormat", err)
+	}
+
+	// A generic major brand, the AVIF one is among the compatible brands
+	generic := append([]byte{0, 0, 0, 24}, "ftypmif1\x00\x00\x00\x00mif1avif"...)
+	if !isAVIF(generic) || isHEIF(generic) {
+		t.Fatalf("isAVIF did not recognize an AVIF header with the mif1 major brand")
+	}
+	if _, err := NewImagerFromBytes(generic); err == nil || !strings.Contains(err.Error(), "imageravif package") {
+		t.Fatalf("NewImagerFromBytes returned %v, expected the AVIF error", err)
+	}
+
+	imgr, _ := NewImager(createTestImage())
+	if err := imgr.Encode(new(bytes.Buffer), IMAVIF); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Encode returned %v, expected ErrUnsupportedFormat", err)
+	}
+	if err := imgr.Save(filepath.Join(t.TempDir(), "image.avif")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Save returned %v, expected ErrUnsupportedFormat", err)
+	}
+	if err := imgr.Fork().ConvertTo(IMAVIF).Err(); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("ConvertTo returned %v, expected ErrUnsupportedFormat", err)
+	}
+
+	// The fallback format is used instead
+	buf := new(bytes.Buffer)
+	if err := imgr.Encode(buf, IMAVIF, EncodeOptions{FallbackFormat: IMPNG}); err != nil {
+		t.Fatalf("Encode with a fallback format returned %v", err)
+	}
+	if out, err := NewImagerFromBytes(buf.Bytes()); err != nil || out.ImageType != IMPNG {
+		t.Fatalf("Encode did not use the fallback format: %v", err)
+	}
+}
+
+func TestAVIFRegisteredEncoder(t *testing.T) {
+	var got EncodeOptions
+	RegisterFormat(IMAVIF, "", nil, func(w io.Writer, img image.Image, opts EncodeOptions) error {
+		got = opts
+		_, err := w.Write([]byte("avif"))
+		return err
+	})
+	t.Cleanup(func() { RegisterFormat(IMAVIF, "", nil, nil) })
+
+	imgr, _ := NewImager(createTestImage())
+	if err := imgr.Encode(new(bytes.Buffer), IMAVIF); err != nil {
+		t.Fatalf("Encode returned %v", err)
+	}
+	if got.AVIFQuality != defaultAVIFQuality || got.AVIFSpeed != defaultAVIFSpeed {
+		t.Errorf("expected the default AVIF options, got %+v", got)
+	}
+	if format := imgr.BestFormat("image/avif,image/webp", BestFormatOptions{}); format != IMAVIF {
+		t.Errorf("expected BestFormat to pick AVIF, got %q", format)
+	}
+}
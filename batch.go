@@ -0,0 +1,165 @@
+package imager
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// BatchResize resizes every file in paths and saves the result to outputDir
+// keeping the base filename. The files are processed by a pool of workers,
+// workers <= 0 uses one worker per CPU. The returned slice holds the error
+// of each file, indexed to match paths, while the error is only set when the
+// batch could not be started at all
+// i.e :
+// errs, err := imager.BatchResize(paths, 800, 600, imager.MD_FIT, "thumbs", 4)
+func BatchResize(paths []string, width, height int, mode ResizeMode, outputDir string, workers int) ([]error, error) {
+	pipeline := NewPipeline().Resize(width, height, mode)
+	results, err := BatchProcess(context.Background(), paths, pipeline, BatchOptions{Workers: workers, OutputDir: outputDir})
+	if results == nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(results))
+	for idx, result := range results {
+		errs[idx] = result.Err
+	}
+
+	return errs, nil
+}
+
+// BatchOptions holds the options used by BatchProcess
+type BatchOptions struct {
+	// Workers is the number of files processed at once, zero uses one worker
+	// per CPU
+	Workers int
+
+	// FS, when set, is where the inputs are read from instead of the disk
+	FS fs.FS
+
+	// OutputDir is the directory the results are saved to, keeping the base
+	// filename of the inputs
+	OutputDir string
+
+	// Format replaces the extension of the results, such as webp, so they are
+	// converted. Empty keeps the input extension
+	Format string
+
+	// Encode holds the options used to save the results
+	Encode EncodeOptions
+
+	// Progress, when set, is called after each file with its result and the
+	// number of files done so far. Calls are not concurrent
+	Progress func(result BatchResult, done, total int)
+}
+
+// BatchResult is the outcome of a file processed by BatchProcess
+type BatchResult struct {
+	Input  string
+	Output string
+	Err    error
+}
+
+// BatchProcess runs pipeline on every input file and saves the results to
+// opts.OutputDir, using a pool of opts.Workers workers. The results are
+// indexed to match inputs, each one holding the error of its file. When ctx
+// is cancelled the pending files are not processed, their result holds the
+// context error which is returned too. The error is also set when the batch
+// could not be started at all
+// i.e :
+// results, err := imager.BatchProcess(ctx, paths, pipeline, imager.BatchOptions{OutputDir: "thumbs", Workers: 8})
+// results, err := imager.BatchProcess(ctx, names, pipeline, imager.BatchOptions{FS: os.DirFS("photos"), OutputDir: "out", Format: "webp"})
+func BatchProcess(ctx context.Context, inputs []string, pipeline *Pipeline, opts BatchOptions) ([]BatchResult, error) {
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(opts.OutputDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]BatchResult, len(inputs))
+	jobs := make(chan int)
+
+	var mu sync.Mutex
+	done := 0
+	report := func(idx int) {
+		if opts.Progress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		opts.Progress(results[idx], done, len(inputs))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = processFile(ctx, inputs[idx], pipeline, opts)
+				report(idx)
+			}
+		}()
+	}
+
+	next := 0
+	for ; next < len(inputs); next++ {
+		select {
+		case jobs <- next:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Files never started
+	for idx := next; idx < len(inputs); idx++ {
+		results[idx] = BatchResult{Input: inputs[idx], Err: ctx.Err()}
+		report(idx)
+	}
+
+	return results, ctx.Err()
+}
+
+// processFile loads, transforms and saves a single file for BatchProcess
+func processFile(ctx context.Context, input string, pipeline *Pipeline, opts BatchOptions) BatchResult {
+	name := filepath.Base(input)
+	if opts.Format != "" {
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + "." + strings.TrimPrefix(opts.Format, ".")
+	}
+	result := BatchResult{Input: input, Output: filepath.Join(opts.OutputDir, name)}
+
+	if result.Err = ctx.Err(); result.Err != nil {
+		return result
+	}
+
+	var imgr *Imager
+	if opts.FS != nil {
+		imgr, result.Err = NewImagerFromFS(opts.FS, input)
+	} else {
+		imgr, result.Err = NewImagerFromFile(input)
+	}
+	if result.Err != nil {
+		return result
+	}
+
+	if result.Err = pipeline.Run(imgr); result.Err != nil {
+		return result
+	}
+
+	result.Err = imgr.Save(result.Output, opts.Encode)
+	return result
+}
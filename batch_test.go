@@ -0,0 +1,128 @@
+package imager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBatchResize(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	var paths []string
+	for _, name := range []string{"a.png", "b.png", "c.png", "d.png", "e.png"} {
+		location := filepath.Join(inputDir, name)
+		file, err := os.Create(location)
+		if err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		if err := png.Encode(file, createTestImage()); err != nil {
+			t.Fatalf("failed to encode test image: %v", err)
+		}
+		file.Close()
+		paths = append(paths, location)
+	}
+	paths = append(paths, filepath.Join(inputDir, "missing.png"))
+
+	errs, err := BatchResize(paths, 40, 20, MD_STRETCH, outputDir, 3)
+	if err != nil {
+		t.Fatalf("BatchResize returned an error: %v", err)
+	}
+
+	if len(errs) != len(paths) {
+		t.Fatalf("BatchResize returned %d errors for %d paths", len(errs), len(paths))
+	}
+
+	for idx, location := range paths[:5] {
+		if errs[idx] != nil {
+			t.Fatalf("BatchResize failed for %s: %v", location, errs[idx])
+		}
+
+		imgr, err := NewImagerFromFile(filepath.Join(outputDir, filepath.Base(location)))
+		if err != nil {
+			t.Fatalf("failed to load output: %v", err)
+		}
+
+		if imgr.Image.Bounds().Dx() != 40 || imgr.Image.Bounds().Dy() != 20 {
+			t.Fatalf("BatchResize output has unexpected dimensions: got %v", imgr.Image.Bounds())
+		}
+	}
+
+	if errs[5] == nil {
+		t.Fatalf("BatchResize did not report an error for a missing file")
+	}
+}
+
+func TestBatchProcess(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	fsys := fstest.MapFS{}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, createTestImage()); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	names := []string{"a.png", "b.png", "c.png", "missing.png"}
+	for _, name := range names[:3] {
+		fsys[name] = &fstest.MapFile{Data: buf.Bytes()}
+	}
+
+	var calls []int
+	opts := BatchOptions{
+		Workers:   2,
+		FS:        fsys,
+		OutputDir: outputDir,
+		Format:    "jpg",
+		Progress: func(result BatchResult, done, total int) {
+			if total != len(names) {
+				t.Errorf("unexpected total %d", total)
+			}
+			calls = append(calls, done)
+		},
+	}
+
+	results, err := BatchProcess(context.Background(), names, NewPipeline().Resize(10, 10, MD_STRETCH), opts)
+	if err != nil {
+		t.Fatalf("BatchProcess returned an error: %v", err)
+	}
+	if len(calls) != len(names) || calls[len(calls)-1] != len(names) {
+		t.Fatalf("unexpected progress calls: %v", calls)
+	}
+
+	for _, result := range results[:3] {
+		if result.Err != nil {
+			t.Fatalf("%s failed: %v", result.Input, result.Err)
+		}
+		imgr, err := NewImagerFromFile(result.Output)
+		if err != nil || imgr.ImageType != IMJPEG || imgr.Image.Bounds().Dx() != 10 {
+			t.Fatalf("unexpected output %s: %v", result.Output, err)
+		}
+	}
+	if results[3].Err == nil {
+		t.Fatalf("BatchProcess did not report an error for a missing file")
+	}
+}
+
+func TestBatchProcessCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := BatchProcess(ctx, []string{"a.png", "b.png"}, NewPipeline(), BatchOptions{OutputDir: t.TempDir()})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	for _, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Fatalf("%s: expected context.Canceled, got %v", result.Input, result.Err)
+		}
+	}
+
+	if _, err := BatchProcess(context.Background(), nil, NewPipeline().Add(Op{Name: "explode"}), BatchOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mamur-rezeki/imager"
+)
+
+// fixtures are the encoded fixtures by name
+var fixtures = []struct {
+	name string
+	data func() []byte
+}{
+	{"jpeg", LargeJPEG},
+	{"png", TransparentPNG},
+	{"gif", AnimatedGIF},
+}
+
+// load decodes a fixture, the benchmarks work on forks of it
+func load(b testing.TB, data []byte) *imager.Imager {
+	imgr, err := imager.NewImagerFromBytes(data)
+	if err != nil {
+		b.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	return imgr
+}
+
+func TestFixtures(t *testing.T) {
+	sizes := map[string][2]int{
+		"jpeg": {PhotoWidth, PhotoHeight},
+		"png":  {LogoWidth, LogoHeight},
+		"gif":  {AnimationWidth, AnimationHeight},
+	}
+
+	for _, fixture := range fixtures {
+		imgr := load(t, fixture.data())
+		if imgr.ImageType != fixture.name {
+			t.Errorf("%s: decoded as %q", fixture.name, imgr.ImageType)
+		}
+		if b := imgr.Image.Bounds(); b.Dx() != sizes[fixture.name][0] || b.Dy() != sizes[fixture.name][1] {
+			t.Errorf("%s: unexpected size %v", fixture.name, b)
+		}
+	}
+
+	if imgr := load(t, AnimatedGIF()); imgr.Animation == nil || len(imgr.Animation.Frames) != AnimationFrames {
+		t.Errorf("expected %d frames", AnimationFrames)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for _, fixture := range fixtures {
+		data := fixture.data()
+		b.Run(fixture.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for n := 0; n < b.N; n++ {
+				if _, err := imager.NewImagerFromBytes(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkResize(b *testing.B) {
+	photo := load(b, LargeJPEG())
+	modes := []struct {
+		name string
+		mode imager.ResizeMode
+	}{
+		{"fit", imager.MD_FIT},
+		{"crop", imager.MD_CROP},
+		{"scale", imager.MD_SCALE},
+		{"stretch", imager.MD_STRETCH},
+		{"smart", imager.MD_SMART},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				if err := photo.Fork().Resize(800, 800, mode.mode).Err(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	// The resamplings also run on a pool of workers, zero uses GOMAXPROCS
+	for _, mode := range modes {
+		if mode.mode == imager.MD_CROP || mode.mode == imager.MD_SMART {
+			continue
+		}
+		for _, workers := range []int{1, 0} {
+			b.Run(fmt.Sprintf("%s/workers=%d", mode.name, workers), func(b *testing.B) {
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					if err := photo.Fork().Resize(800, 800, mode.mode, imager.WithWorkers(workers)).Err(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+
+	animation := load(b, AnimatedGIF())
+	b.Run("gif", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			if err := animation.Fork().Resize(200, 0, imager.MD_SCALE).Err(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEncode(b *testing.B) {
+	// The photo is encoded at a web size, full size encodes are dominated by
+	// the codecs themselves
+	photo := load(b, LargeJPEG()).Resize(1600, 0, imager.MD_SCALE)
+	logo := load(b, TransparentPNG())
+	animation := load(b, AnimatedGIF())
+
+	cases := []struct {
+		name   string
+		imgr   *imager.Imager
+		format string
+	}{
+		{"jpeg", photo, imager.IMJPEG},
+		{"webp", photo, imager.IMWEBP},
+		{"png", logo, imager.IMPNG},
+		{"png-palette", logo, imager.IMPNG},
+		{"gif", animation, imager.IMGIF},
+	}
+
+	for _, c := range cases {
+		opts := imager.EncodeOptions{JPEGQuality: 85, PNGAutoPalette: c.name == "png-palette"}
+		imgr := c.imgr.Fork()
+		imgr.ImageType = c.format
+
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				if _, err := imgr.Bytes(opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		// AppendBytes reuses the pooled buffers and the destination
+		b.Run(c.name+"/append", func(b *testing.B) {
+			b.ReportAllocs()
+			var buf []byte
+			for n := 0; n < b.N; n++ {
+				var err error
+				if buf, err = imgr.AppendBytes(buf[:0], opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	pipelines := []struct {
+		name     string
+		data     []byte
+		pipeline *imager.Pipeline
+		format   string
+	}{
+		{
+			"thumbnail",
+			LargeJPEG(),
+			imager.NewPipeline().AutoOrient().Resize(400, 400, imager.MD_CROP).Sharpen(0.5),
+			imager.IMJPEG,
+		},
+		{
+			"web",
+			LargeJPEG(),
+			imager.NewPipeline().Resize(1600, 0, imager.MD_SCALE).AdjustContrast(10).AdjustSaturation(10),
+			imager.IMWEBP,
+		},
+		{
+			"logo",
+			TransparentPNG(),
+			imager.NewPipeline().Resize(300, 0, imager.MD_SCALE),
+			imager.IMPNG,
+		},
+		{
+			"animation",
+			AnimatedGIF(),
+			imager.NewPipeline().Resize(200, 0, imager.MD_SCALE).Grayscale(),
+			imager.IMGIF,
+		},
+	}
+
+	// From the encoded source to the encoded result, as served over HTTP
+	for _, p := range pipelines {
+		b.Run(p.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				imgr, err := imager.NewImagerFromBytes(p.data)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := p.pipeline.Run(imgr); err != nil {
+					b.Fatal(err)
+				}
+				imgr.ImageType = p.format
+				if _, err := imgr.Bytes(imager.EncodeOptions{JPEGQuality: 85}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
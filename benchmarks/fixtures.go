@@ -0,0 +1,130 @@
+// Package benchmarks measures the decoding, resizing, encoding and pipelines
+// of imager on realistic images: a large JPEG photo, a transparent PNG and
+// an animated GIF. The fixtures are generated, always the same, so results
+// compare across commits. Compare two revisions with benchstat:
+//
+//	go test -run '^$' -bench . -benchmem -count 10 ./benchmarks > old.txt
+//	git checkout feature
+//	go test -run '^$' -bench . -benchmem -count 10 ./benchmarks > new.txt
+//	benchstat old.txt new.txt
+package benchmarks
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Sizes of the fixtures
+const (
+	PhotoWidth  = 4000
+	PhotoHeight = 3000
+
+	LogoWidth  = 1200
+	LogoHeight = 800
+
+	AnimationWidth  = 400
+	AnimationHeight = 300
+	AnimationFrames = 20
+)
+
+// LargeJPEG returns a 12 megapixels JPEG photo, smooth gradients with
+// sensor-like noise and sharp edges, encoded at quality 90
+// i.e :
+// data := benchmarks.LargeJPEG()
+var LargeJPEG = sync.OnceValue(func() []byte {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, PhotoWidth, PhotoHeight))
+	for y := 0; y < PhotoHeight; y++ {
+		for x := 0; x < PhotoWidth; x++ {
+			// A sky above a horizon, with a sun disc
+			fy := float64(y) / PhotoHeight
+			r, g, b := 90+100*fy, 140+60*fy, 230-80*fy
+			if y > PhotoHeight*3/5 {
+				r, g, b = 70+40*fy, 110-30*fy, 40
+			}
+			if math.Hypot(float64(x-PhotoWidth*2/3), float64(y-PhotoHeight/4)) < 300 {
+				r, g, b = 255, 230, 160
+			}
+
+			noise := rng.NormFloat64() * 6
+			img.Pix[img.PixOffset(x, y)+0] = clamp(r + noise)
+			img.Pix[img.PixOffset(x, y)+1] = clamp(g + noise)
+			img.Pix[img.PixOffset(x, y)+2] = clamp(b + noise)
+			img.Pix[img.PixOffset(x, y)+3] = 255
+		}
+	}
+
+	return encode(func(buf *bytes.Buffer) error { return jpeg.Encode(buf, img, &jpeg.Options{Quality: 90}) })
+})
+
+// TransparentPNG returns a logo-like PNG: flat colored shapes with
+// antialiased edges over a transparent background
+// i.e :
+// data := benchmarks.TransparentPNG()
+var TransparentPNG = sync.OnceValue(func() []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, LogoWidth, LogoHeight))
+	cx, cy := float64(LogoWidth)/2, float64(LogoHeight)/2
+	for y := 0; y < LogoHeight; y++ {
+		for x := 0; x < LogoWidth; x++ {
+			// A ring, its alpha fading over the last pixel of its edges
+			d := math.Hypot(float64(x)-cx, float64(y)-cy)
+			alpha := math.Min(1, math.Max(0, math.Min(d-200, 350-d)))
+			if alpha == 0 {
+				continue
+			}
+
+			c := color.NRGBA{uint8(x * 255 / LogoWidth), 80, uint8(y * 255 / LogoHeight), uint8(alpha * 255)}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return encode(func(buf *bytes.Buffer) error { return png.Encode(buf, img) })
+})
+
+// AnimatedGIF returns a looping GIF of a ball moving over a gradient
+// i.e :
+// data := benchmarks.AnimatedGIF()
+var AnimatedGIF = sync.OnceValue(func() []byte {
+	anim := &gif.GIF{}
+	for frame := 0; frame < AnimationFrames; frame++ {
+		img := image.NewPaletted(image.Rect(0, 0, AnimationWidth, AnimationHeight), palette.Plan9)
+		bx := float64(frame*AnimationWidth) / AnimationFrames
+		for y := 0; y < AnimationHeight; y++ {
+			for x := 0; x < AnimationWidth; x++ {
+				c := color.RGBA{uint8(x * 255 / AnimationWidth), uint8(y * 255 / AnimationHeight), 128, 255}
+				if math.Hypot(float64(x)-bx, float64(y-AnimationHeight/2)) < 40 {
+					c = color.RGBA{255, 255, 255, 255}
+				}
+				img.Set(x, y, c)
+			}
+		}
+
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, 5)
+	}
+
+	return encode(func(buf *bytes.Buffer) error { return gif.EncodeAll(buf, anim) })
+})
+
+// encode returns the data written by fn, the fixtures always encode
+func encode(fn func(buf *bytes.Buffer) error) []byte {
+	buf := new(bytes.Buffer)
+	if err := fn(buf); err != nil {
+		panic(err)
+	}
+
+	return buf.Bytes()
+}
+
+// clamp converts v to a channel value
+func clamp(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
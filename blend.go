@@ -0,0 +1,105 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// BlendMode is how Composite mixes the colors of a layer with the image
+type BlendMode int
+
+const (
+	// BlendNormal draws the layer over the image
+	BlendNormal BlendMode = iota
+
+	// BlendMultiply darkens, white is neutral
+	BlendMultiply
+
+	// BlendScreen lightens, black is neutral
+	BlendScreen
+
+	// BlendOverlay multiplies the dark areas and screens the light ones,
+	// increasing the contrast
+	BlendOverlay
+
+	// BlendDarken keeps the darker of the two colors
+	BlendDarken
+
+	// BlendLighten keeps the lighter of the two colors
+	BlendLighten
+)
+
+// blendFuncs maps the blend modes to their channel blending function, both
+// values and the result in [0, 1]
+var blendFuncs = map[BlendMode]func(backdrop, source float64) float64{
+	BlendNormal:   func(_, s float64) float64 { return s },
+	BlendMultiply: func(b, s float64) float64 { return b * s },
+	BlendScreen:   func(b, s float64) float64 { return b + s - b*s },
+	BlendOverlay: func(b, s float64) float64 {
+		if b <= 0.5 {
+			return 2 * b * s
+		}
+		return 1 - 2*(1-b)*(1-s)
+	},
+	BlendDarken:  func(b, s float64) float64 { return min(b, s) },
+	BlendLighten: func(b, s float64) float64 { return max(b, s) },
+}
+
+// Composite draws layer over the image with its top left corner at x, y,
+// mixing the colors according to mode. opacity ranges from 0 to 1 and
+// multiplies the alpha of the layer. A nil layer records ErrInvalidArgument
+// i.e :
+// imgr.Composite(logo, 20, 20, imager.BlendNormal, 0.8)
+// imgr.Composite(texture, 0, 0, imager.BlendMultiply, 1)
+func (i *Imager) Composite(layer image.Image, x, y int, mode BlendMode, opacity float64) *Imager {
+	if layer == nil {
+		i.setErr(fmt.Errorf("%w: nil layer", ErrInvalidArgument))
+		return i
+	}
+	blend, ok := blendFuncs[mode]
+	if !ok {
+		i.setErr(fmt.Errorf("%w: blend mode %d", ErrInvalidArgument, mode))
+		return i
+	}
+	if !i.checkRange("opacity", opacity, 0, 1) {
+		return i
+	}
+
+	src := imaging.Clone(layer)
+	return i.apply("Composite", func(img image.Image) image.Image {
+		dst := imaging.Clone(img)
+		area := dst.Rect.Intersect(src.Rect.Add(image.Pt(x, y)))
+
+		for py := area.Min.Y; py < area.Max.Y; py++ {
+			for px := area.Min.X; px < area.Max.X; px++ {
+				d := dst.PixOffset(px, py)
+				s := src.PixOffset(px-x, py-y)
+				compositePixel(dst.Pix[d:d+4], src.Pix[s:s+4], opacity, blend)
+			}
+		}
+
+		return dst
+	})
+}
+
+// compositePixel blends the non-premultiplied source pixel over dst, as
+// specified by the W3C compositing and blending recommendation
+func compositePixel(dst, src []uint8, opacity float64, blend func(b, s float64) float64) {
+	as := float64(src[3]) / 255 * opacity
+	if as == 0 {
+		return
+	}
+	ab := float64(dst[3]) / 255
+	ao := as + ab*(1-as)
+
+	for ch := 0; ch < 3; ch++ {
+		cb, cs := float64(dst[ch])/255, float64(src[ch])/255
+		// The blended color only applies where the backdrop is opaque
+		mixed := (1-ab)*cs + ab*blend(cb, cs)
+		co := (as*mixed + ab*(1-as)*cb) / ao
+		dst[ch] = uint8(co*255 + 0.5)
+	}
+	dst[3] = uint8(ao*255 + 0.5)
+}
@@ -0,0 +1,66 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestComposite(t *testing.T) {
+	gray := color.NRGBA{128, 128, 128, 255}
+
+	tests := []struct {
+		mode    BlendMode
+		opacity float64
+		want    color.NRGBA
+	}{
+		{BlendNormal, 1, color.NRGBA{128, 128, 128, 255}},
+		{BlendNormal, 0.5, color.NRGBA{192, 64, 64, 255}},
+		{BlendMultiply, 1, color.NRGBA{128, 0, 0, 255}},
+		{BlendScreen, 1, color.NRGBA{255, 128, 128, 255}},
+		{BlendOverlay, 1, color.NRGBA{255, 0, 0, 255}},
+		{BlendDarken, 1, color.NRGBA{128, 0, 0, 255}},
+		{BlendLighten, 1, color.NRGBA{255, 128, 128, 255}},
+	}
+
+	for _, tt := range tests {
+		imgr, _ := NewImager(createTestImage())
+		imgr.Composite(createColorImage(gray), 20, 30, tt.mode, tt.opacity)
+
+		if c := pixel(imgr.Image, 25, 35); !closeColor(c, tt.want, 1) {
+			t.Fatalf("Composite mode %d opacity %v returned %v, want %v", tt.mode, tt.opacity, c, tt.want)
+		}
+		if c := pixel(imgr.Image, 19, 35); c != (color.NRGBA{255, 0, 0, 255}) {
+			t.Fatalf("Composite mode %d changed a pixel outside the layer: %v", tt.mode, c)
+		}
+	}
+}
+
+func TestCompositeTransparent(t *testing.T) {
+	imgr, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 10, 10)))
+	imgr.Composite(createColorImage(color.NRGBA{0, 0, 255, 255}), 5, 5, BlendMultiply, 1)
+
+	// Over a transparent backdrop the layer is drawn as is
+	if c := pixel(imgr.Image, 7, 7); c != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("Composite returned %v over a transparent pixel", c)
+	}
+
+	if err := imgr.Composite(imgr.Image, 0, 0, BlendMode(42), 1).Err(); err == nil {
+		t.Fatalf("Composite did not record an error for an unknown mode")
+	}
+}
+
+// closeColor reports whether the channels of a and b differ by at most
+// tolerance
+func closeColor(a, b color.NRGBA, tolerance int) bool {
+	return absInt(int(a.R)-int(b.R)) <= tolerance && absInt(int(a.G)-int(b.G)) <= tolerance &&
+		absInt(int(a.B)-int(b.B)) <= tolerance && absInt(int(a.A)-int(b.A)) <= tolerance
+}
+
+func TestCompositeNilLayer(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	if err := imgr.Composite(nil, 0, 0, BlendNormal, 1).Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for a nil layer, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package imager
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache keeps the encoded results of transformations by key, see CacheKey,
+// so identical requests are not processed again. Implementations must be
+// safe for concurrent use
+type Cache interface {
+	// Get returns the data stored at key, ok is false when there is none
+	Get(ctx context.Context, key string) (data []byte, ok bool)
+
+	// Put stores data at key, a failure only costs a later miss
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// CacheKey returns the key of the result of pipeline run on the encoded
+// source and encoded as format with opts. It hashes the source data, so an
+// updated image gets new keys
+// i.e :
+// key := imager.CacheKey(data, pipeline, imager.IMWEBP, imager.EncodeOptions{})
+func CacheKey(source []byte, pipeline *Pipeline, format string, opts EncodeOptions) string {
+	h := sha256.New()
+	h.Write(source)
+
+	// The options are plain values, they always marshal
+	spec, _ := json.Marshal(struct {
+		Pipeline *Pipeline
+		Format   string
+		Options  EncodeOptions
+	}{pipeline, format, opts})
+	h.Write(spec)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RunCached returns the result of the pipeline run on the encoded source and
+// encoded as format, the source format when empty. The result is read from
+// cache when found, and stored there otherwise
+// i.e :
+// data, err := pipeline.RunCached(ctx, cache, source, imager.IMJPEG, imager.EncodeOptions{JPEGQuality: 80})
+func (p *Pipeline) RunCached(ctx context.Context, cache Cache, source []byte, format string, opts EncodeOptions) ([]byte, error) {
+	key := CacheKey(source, p, format, opts)
+	if data, ok := cache.Get(ctx, key); ok {
+		return data, nil
+	}
+
+	imgr, err := NewImagerFromBytes(source)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Run(imgr); err != nil {
+		return nil, err
+	}
+	if format != "" {
+		imgr.ImageType = format
+	}
+	data, err := imgr.Bytes(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Put(ctx, key, data)
+	return data, nil
+}
+
+// MemoryCache returns a Cache keeping up to maxBytes of results in memory,
+// the least recently used ones being evicted first
+// i.e :
+// cache := imager.MemoryCache(256 << 20)
+func MemoryCache(maxBytes int64) Cache {
+	return &memoryCache{maxBytes: maxBytes, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// memoryCache is the Cache returned by MemoryCache, order holds the entries
+// from the most to the least recently used
+type memoryCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// memoryEntry is an element of memoryCache.order
+type memoryEntry struct {
+	key  string
+	data []byte
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*memoryEntry).data, true
+}
+
+func (c *memoryCache) Put(ctx context.Context, key string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if int64(len(data)) > c.maxBytes {
+		return nil
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, data: data})
+	c.size += int64(len(data))
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// remove drops the entry of elem
+func (c *memoryCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*memoryEntry)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.data))
+}
+
+// DirCache returns a Cache keeping the results as files below the root
+// directory. Nothing is evicted, old files are to be removed by the likes of
+// a cron job, which the cache copes with at any time
+// i.e :
+// cache := imager.DirCache("/var/cache/imager")
+func DirCache(root string) Cache {
+	return dirCache(root)
+}
+
+// dirCache is the Cache returned by DirCache
+type dirCache string
+
+func (d dirCache) path(key string) string {
+	// Keys are hashes, spread over subdirectories by their first characters
+	if len(key) < 3 {
+		return filepath.Join(string(d), filepath.Base(key))
+	}
+
+	return filepath.Join(string(d), key[:2], filepath.Base(key[2:]))
+}
+
+func (d dirCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	data, err := os.ReadFile(d.path(key))
+	return data, err == nil
+}
+
+func (d dirCache) Put(ctx context.Context, key string, data []byte) error {
+	location := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(location), 0o755); err != nil {
+		return err
+	}
+
+	// Written aside then renamed, so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(location), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), location)
+}
@@ -0,0 +1,76 @@
+package imager
+
+import (
+	"context"
+	"testing"
+)
+
+// countingCache counts the hits of the cache it wraps
+type countingCache struct {
+	Cache
+	hits int
+}
+
+func (c *countingCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	data, ok := c.Cache.Get(ctx, key)
+	if ok {
+		c.hits++
+	}
+	return data, ok
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := MemoryCache(10)
+
+	cache.Put(ctx, "a", []byte("aaaa"))
+	cache.Put(ctx, "b", []byte("bbbb"))
+	cache.Get(ctx, "a")
+	// Evicts b, the least recently used
+	cache.Put(ctx, "c", []byte("cccc"))
+
+	if _, ok := cache.Get(ctx, "b"); ok {
+		t.Fatalf("the least recently used entry was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(ctx, key); !ok {
+			t.Fatalf("entry %s was evicted", key)
+		}
+	}
+
+	cache.Put(ctx, "d", make([]byte, 11))
+	if _, ok := cache.Get(ctx, "d"); ok {
+		t.Fatalf("an entry larger than the cache was kept")
+	}
+}
+
+func TestRunCached(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMPNG
+	source, _ := imgr.Bytes()
+	pipeline := NewPipeline().Resize(50, 50, MD_STRETCH)
+
+	for name, cache := range map[string]Cache{"memory": MemoryCache(1 << 20), "dir": DirCache(t.TempDir())} {
+		counting := &countingCache{Cache: cache}
+
+		first, err := pipeline.RunCached(context.Background(), counting, source, IMJPEG, EncodeOptions{})
+		if err != nil {
+			t.Fatalf("%s: RunCached returned an error: %v", name, err)
+		}
+		second, _ := pipeline.RunCached(context.Background(), counting, source, IMJPEG, EncodeOptions{})
+		if counting.hits != 1 || string(first) != string(second) {
+			t.Fatalf("%s: the second run was not read from the cache", name)
+		}
+
+		if format, _ := DetectFormat(first); format != IMJPEG {
+			t.Fatalf("%s: RunCached encoded %s, want jpeg", name, format)
+		}
+		if _, err := pipeline.RunCached(context.Background(), counting, source, IMJPEG, EncodeOptions{JPEGQuality: 50}); err != nil || counting.hits != 1 {
+			t.Fatalf("%s: other options were read from the cache", name)
+		}
+	}
+
+	if CacheKey(source, pipeline, IMJPEG, EncodeOptions{}) == CacheKey(source[:len(source)-1], pipeline, IMJPEG, EncodeOptions{}) {
+		t.Fatalf("CacheKey ignores the source")
+	}
+}
@@ -0,0 +1,251 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// Pad extends the canvas by the given number of pixels on each side, filled
+// with bg. A nil bg is transparent
+// i.e :
+// imgr.Pad(10, 20, 10, 20, color.White)
+func (i *Imager) Pad(top, right, bottom, left int, bg color.Color) *Imager {
+	if top < 0 || right < 0 || bottom < 0 || left < 0 {
+		i.setErr(fmt.Errorf("%w: negative padding %d %d %d %d", ErrInvalidArgument, top, right, bottom, left))
+		return i
+	}
+
+	size := i.Image.Bounds().Size()
+	i.padFocal(size, image.Pt(size.X+left+right, size.Y+top+bottom), image.Pt(left, top))
+	return i.apply("Pad", func(img image.Image) image.Image {
+		size := img.Bounds().Size()
+		return placeOnCanvas(img, size.X+left+right, size.Y+top+bottom, image.Pt(left, top), bg)
+	})
+}
+
+// PadTo places the image on a width x height canvas filled with bg, at the
+// given anchor, to letterbox a fitted image onto an exact size. The parts of
+// an image larger than the canvas are cut off. A nil bg is transparent
+// i.e :
+// imgr.Resize(1200, 630, imager.MD_FIT).PadTo(1200, 630, imager.AnchorCenter, color.Black)
+func (i *Imager) PadTo(width, height int, anchor Anchor, bg color.Color) *Imager {
+	if width <= 0 || height <= 0 {
+		i.setErr(fmt.Errorf("%w: canvas size %dx%d", ErrInvalidArgument, width, height))
+		return i
+	}
+
+	size := i.Image.Bounds().Size()
+	i.padFocal(size, image.Pt(width, height), anchor.point(image.Rect(0, 0, width, height), size, 0))
+	return i.apply("PadTo", func(img image.Image) image.Image {
+		origin := anchor.point(image.Rect(0, 0, width, height), img.Bounds().Size(), 0)
+		return placeOnCanvas(img, width, height, origin, bg)
+	})
+}
+
+// defaultBackground is the background of the transparent images encoded to
+// formats without alpha
+var defaultBackground color.Color = color.White
+
+// Flatten draws the image over an opaque bg, removing the transparency. A
+// nil bg is white
+// i.e :
+// imgr.Flatten(color.White)
+func (i *Imager) Flatten(bg color.Color) *Imager {
+	if bg == nil {
+		bg = defaultBackground
+	}
+
+	return i.apply("Flatten", func(img image.Image) image.Image {
+		return flatten(img, bg)
+	})
+}
+
+// flatten returns img drawn over an opaque bg
+func flatten(img image.Image, bg color.Color) *image.NRGBA {
+	bounds := img.Bounds()
+	return flattenInto(image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy())), img, bg)
+}
+
+// flattenInto draws img over an opaque bg into dst, of the size of img
+func flattenInto(dst *image.NRGBA, img image.Image, bg color.Color) *image.NRGBA {
+	bounds := img.Bounds()
+	r, g, b, _ := bg.RGBA()
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.RGBA64{uint16(r), uint16(g), uint16(b), 0xFFFF}), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// isOpaque reports whether img has no transparent pixel
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	return false
+}
+
+// placeOnCanvas draws img at origin over a width x height canvas filled
+// with bg
+func placeOnCanvas(img image.Image, width, height int, origin image.Point, bg color.Color) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if bg != nil {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	}
+
+	bounds := img.Bounds()
+	draw.Draw(dst, bounds.Sub(bounds.Min).Add(origin), img, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// Stroke is a band of solid color drawn by Frame
+type Stroke struct {
+	Width int
+	Color color.Color
+}
+
+// FrameOptions holds the strokes drawn by Frame
+type FrameOptions struct {
+	// Outer strokes extend the canvas, from the image edge outwards
+	Outer []Stroke
+
+	// Inner strokes are drawn over the image, from its edge inwards
+	Inner []Stroke
+}
+
+// Border extends the canvas by width pixels on each side, filled with c
+// i.e :
+// imgr.Border(4, color.White)
+func (i *Imager) Border(width int, c color.Color) *Imager {
+	defer i.operation("Border")()
+
+	return i.Frame(FrameOptions{Outer: []Stroke{{Width: width, Color: c}}})
+}
+
+// Frame draws strokes around and along the edges of the image, such as a
+// thin dark line inside a wide white mat
+// i.e :
+// imgr.Frame(imager.FrameOptions{Outer: []imager.Stroke{{Width: 2, Color: color.Black}, {Width: 20, Color: color.White}}})
+// imgr.Frame(imager.FrameOptions{Inner: []imager.Stroke{{Width: 1, Color: color.Gray{200}}}})
+func (i *Imager) Frame(opts FrameOptions) *Imager {
+	for _, strokes := range [][]Stroke{opts.Outer, opts.Inner} {
+		for _, stroke := range strokes {
+			if stroke.Width < 0 {
+				i.setErr(fmt.Errorf("%w: negative stroke width %d", ErrInvalidArgument, stroke.Width))
+				return i
+			}
+		}
+	}
+
+	outer := 0
+	for _, stroke := range opts.Outer {
+		outer += stroke.Width
+	}
+
+	size := i.Image.Bounds().Size()
+	i.padFocal(size, image.Pt(size.X+2*outer, size.Y+2*outer), image.Pt(outer, outer))
+	return i.apply("Frame", func(img image.Image) image.Image {
+		size := img.Bounds().Size()
+		dst := placeOnCanvas(img, size.X+2*outer, size.Y+2*outer, image.Pt(outer, outer), nil)
+
+		// Each stroke is a ring between rect and rect inset by its width
+		drawRing := func(rect image.Rectangle, stroke Stroke) image.Rectangle {
+			inner := rect.Inset(stroke.Width)
+			if stroke.Color != nil {
+				src := image.NewUniform(stroke.Color)
+				for _, band := range []image.Rectangle{
+					image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, inner.Min.Y),
+					image.Rect(rect.Min.X, inner.Max.Y, rect.Max.X, rect.Max.Y),
+					image.Rect(rect.Min.X, inner.Min.Y, inner.Min.X, inner.Max.Y),
+					image.Rect(inner.Max.X, inner.Min.Y, rect.Max.X, inner.Max.Y),
+				} {
+					draw.Draw(dst, band, src, image.Point{}, draw.Src)
+				}
+			}
+			return inner
+		}
+
+		// Outer strokes are listed from the image outwards, drawn inwards
+		rect := dst.Bounds()
+		for k := len(opts.Outer) - 1; k >= 0; k-- {
+			rect = drawRing(rect, opts.Outer[k])
+		}
+		for _, stroke := range opts.Inner {
+			rect = drawRing(rect, stroke)
+		}
+
+		return dst
+	})
+}
+
+// DropShadow extends the canvas and draws a shadow of the image shape below
+// it, offset by offsetX and offsetY and blurred with a gaussian blur of
+// blurSigma, such as to lift a product cut-out from a white background. The
+// shadow has the color c, nil being black, and opacity ranges from 0 to 1
+// i.e :
+// imgr.DropShadow(8, 8, 6, color.Black, 0.5)
+// imgr.DropShadow(0, 12, 10, nil, 0.35).Flatten(color.White)
+func (i *Imager) DropShadow(offsetX, offsetY int, blurSigma float64, c color.Color, opacity float64) *Imager {
+	if !i.checkRange("opacity", opacity, 0, 1) {
+		return i
+	}
+	if blurSigma < 0 {
+		i.setErr(fmt.Errorf("%w: negative shadow blur %v", ErrInvalidArgument, blurSigma))
+		return i
+	}
+	if c == nil {
+		c = color.Black
+	}
+
+	size := i.Image.Bounds().Size()
+	canvas, origin := shadowCanvas(image.Rectangle{Max: size}, image.Pt(offsetX, offsetY), blurSigma)
+	i.padFocal(size, canvas.Size(), origin)
+	return i.apply("DropShadow", func(img image.Image) image.Image {
+		return dropShadow(img, image.Pt(offsetX, offsetY), blurSigma, c, opacity)
+	})
+}
+
+// shadowCanvas returns the canvas fitting an image of rect and its shadow,
+// and the origin of the image on the canvas
+func shadowCanvas(rect image.Rectangle, offset image.Point, sigma float64) (image.Rectangle, image.Point) {
+	// The blur spreads the shadow by about three sigmas
+	margin := int(math.Ceil(3 * sigma))
+	canvas := rect.Union(rect.Add(offset).Inset(-margin))
+
+	return canvas, rect.Min.Sub(canvas.Min)
+}
+
+// dropShadow returns img over its shadow, on a canvas fitting both
+func dropShadow(img image.Image, offset image.Point, sigma float64, c color.Color, opacity float64) *image.NRGBA {
+	src := imaging.Clone(img)
+	canvas, origin := shadowCanvas(src.Rect, offset, sigma)
+
+	// The silhouette is the alpha channel of the image in the shadow color
+	shade := color.NRGBAModel.Convert(c).(color.NRGBA)
+	alpha := float64(shade.A) / 255 * opacity
+	shadow := image.NewNRGBA(image.Rect(0, 0, canvas.Dx(), canvas.Dy()))
+	at := origin.Add(offset)
+	for y := 0; y < src.Rect.Dy(); y++ {
+		for x := 0; x < src.Rect.Dx(); x++ {
+			a := src.Pix[y*src.Stride+x*4+3]
+			if a == 0 {
+				continue
+			}
+			off := (at.Y+y)*shadow.Stride + (at.X+x)*4
+			shadow.Pix[off], shadow.Pix[off+1], shadow.Pix[off+2] = shade.R, shade.G, shade.B
+			shadow.Pix[off+3] = uint8(float64(a)*alpha + 0.5)
+		}
+	}
+	if sigma > 0 {
+		shadow = imaging.Blur(shadow, sigma)
+	}
+
+	draw.Draw(shadow, src.Rect.Add(origin), src, image.Point{}, draw.Over)
+	return shadow
+}
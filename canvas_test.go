@@ -0,0 +1,175 @@
+package imager
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"testing"
+)
+
+func TestPad(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.Pad(10, 20, 30, 40, color.White)
+
+	if bounds := imgr.Image.Bounds(); bounds.Dx() != 160 || bounds.Dy() != 140 {
+		t.Fatalf("Pad returned unexpected bounds: %v", bounds)
+	}
+	if c := pixel(imgr.Image, 39, 10); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("Pad did not fill the left padding: %v", c)
+	}
+	if c := pixel(imgr.Image, 40, 10); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("Pad moved the image: %v", c)
+	}
+
+	if err := imgr.Pad(-1, 0, 0, 0, nil).Err(); err == nil {
+		t.Fatalf("Pad did not record an error for a negative padding")
+	}
+}
+
+func TestPadTo(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.PadTo(200, 120, AnchorCenter, nil)
+
+	if bounds := imgr.Image.Bounds(); bounds.Dx() != 200 || bounds.Dy() != 120 {
+		t.Fatalf("PadTo returned unexpected bounds: %v", bounds)
+	}
+	if c := pixel(imgr.Image, 49, 60); c.A != 0 {
+		t.Fatalf("PadTo did not leave the canvas transparent: %v", c)
+	}
+	if c := pixel(imgr.Image, 50, 10); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("PadTo did not center the image: %v", c)
+	}
+
+	// A larger image is cut off
+	imgr, _ = NewImager(createTestImage())
+	imgr.PadTo(50, 50, AnchorBottomRight, color.Black)
+	if bounds := imgr.Image.Bounds(); bounds != image.Rect(0, 0, 50, 50) {
+		t.Fatalf("PadTo returned unexpected bounds: %v", bounds)
+	}
+	if c := pixel(imgr.Image, 0, 0); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("PadTo did not cover the canvas: %v", c)
+	}
+}
+
+func TestBorder(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.Border(5, color.White)
+
+	if bounds := imgr.Image.Bounds(); bounds.Dx() != 110 || bounds.Dy() != 110 {
+		t.Fatalf("Border returned unexpected bounds: %v", bounds)
+	}
+	if c := pixel(imgr.Image, 4, 50); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("Border did not draw the border: %v", c)
+	}
+	if c := pixel(imgr.Image, 5, 50); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("Border covered the image: %v", c)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.Frame(FrameOptions{
+		Outer: []Stroke{{Width: 2, Color: color.Black}, {Width: 10, Color: color.White}},
+		Inner: []Stroke{{Width: 3, Color: color.NRGBA{0, 0, 255, 255}}},
+	})
+
+	if bounds := imgr.Image.Bounds(); bounds.Dx() != 124 || bounds.Dy() != 124 {
+		t.Fatalf("Frame returned unexpected bounds: %v", bounds)
+	}
+
+	for _, tt := range []struct {
+		x    int
+		want color.NRGBA
+	}{
+		{0, color.NRGBA{255, 255, 255, 255}},
+		{9, color.NRGBA{255, 255, 255, 255}},
+		{10, color.NRGBA{0, 0, 0, 255}},
+		{11, color.NRGBA{0, 0, 0, 255}},
+		{12, color.NRGBA{0, 0, 255, 255}},
+		{14, color.NRGBA{0, 0, 255, 255}},
+		{15, color.NRGBA{255, 0, 0, 255}},
+	} {
+		if c := pixel(imgr.Image, tt.x, 62); c != tt.want {
+			t.Fatalf("Frame drew %v at x=%d, want %v", c, tt.x, tt.want)
+		}
+	}
+
+	if err := imgr.Frame(FrameOptions{Inner: []Stroke{{Width: -1}}}).Err(); err == nil {
+		t.Fatalf("Frame did not record an error for a negative width")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(0, 0, color.NRGBA{0, 0, 255, 128})
+
+	imgr, _ := NewImager(img)
+	imgr.Flatten(nil)
+
+	if c := pixel(imgr.Image, 5, 5); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("Flatten did not fill the transparent pixels with white: %v", c)
+	}
+	if c := pixel(imgr.Image, 0, 0); c.A != 255 || c.B != 255 || c.R < 120 || c.R > 135 {
+		t.Fatalf("Flatten did not blend the translucent pixel: %v", c)
+	}
+}
+
+func TestEncodeJPEGBackground(t *testing.T) {
+	imgr, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 16, 16)))
+
+	decode := func(opts ...EncodeOptions) color.NRGBA {
+		buf := new(bytes.Buffer)
+		if err := imgr.Encode(buf, IMJPEG, opts...); err != nil {
+			t.Fatalf("Encode returned an error: %v", err)
+		}
+		img, err := jpeg.Decode(buf)
+		if err != nil {
+			t.Fatalf("failed to decode the JPEG: %v", err)
+		}
+		return pixel(img, 8, 8)
+	}
+
+	if c := decode(); c.R < 250 || c.G < 250 || c.B < 250 {
+		t.Fatalf("transparent image was not encoded over white: %v", c)
+	}
+
+	imgr.Background = color.Black
+	if c := decode(); c.R > 5 || c.G > 5 || c.B > 5 {
+		t.Fatalf("transparent image was not encoded over Background: %v", c)
+	}
+
+	if c := decode(EncodeOptions{Background: color.NRGBA{255, 0, 0, 255}}); c.R < 240 || c.G > 15 {
+		t.Fatalf("transparent image was not encoded over the options background: %v", c)
+	}
+}
+
+func TestDropShadow(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(img, image.Rect(10, 10, 30, 30), image.NewUniform(color.NRGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+
+	imgr, _ := NewImager(img)
+	imgr.DropShadow(5, 8, 2, color.Black, 0.5)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("DropShadow returned an error: %v", err)
+	}
+
+	// The blur margin of 6 pixels extends the canvas on the left too
+	if bounds := imgr.Image.Bounds(); bounds.Dx() != 52 || bounds.Dy() != 54 {
+		t.Fatalf("DropShadow returned unexpected bounds: %v", bounds)
+	}
+	if c := pixel(imgr.Image, 20, 20); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("DropShadow did not keep the image on top: %v", c)
+	}
+	if c := pixel(imgr.Image, 32, 35); c.R != 0 || c.A < 100 || c.A > 140 {
+		t.Fatalf("DropShadow did not draw a half transparent black shadow: %v", c)
+	}
+	if c := pixel(imgr.Image, 0, 0); c.A != 0 {
+		t.Fatalf("DropShadow did not leave the canvas transparent: %v", c)
+	}
+
+	if err := imgr.DropShadow(0, 0, 1, nil, 2).Err(); err == nil {
+		t.Fatalf("DropShadow did not record an error for an opacity out of range")
+	}
+}
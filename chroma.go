@@ -0,0 +1,75 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// ReplaceColor replaces the pixels close to target by replacement, keeping
+// their alpha. tolerance ranges from 0, only target itself, to 1, every
+// color, and is the distance between the colors relative to the one between
+// black and white. Nil colors record ErrInvalidArgument
+// i.e :
+// imgr.ReplaceColor(color.White, color.RGBA{250, 245, 235, 255}, 0.05)
+func (i *Imager) ReplaceColor(target, replacement color.Color, tolerance float64) *Imager {
+	if target == nil || replacement == nil {
+		i.setErr(fmt.Errorf("%w: nil color", ErrInvalidArgument))
+		return i
+	}
+	if !i.checkRange("tolerance", tolerance, 0, 1) {
+		return i
+	}
+	t := color.NRGBAModel.Convert(target).(color.NRGBA)
+	r := color.NRGBAModel.Convert(replacement).(color.NRGBA)
+
+	return i.apply("ReplaceColor", func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			if rgbDistance(c, t) > tolerance {
+				return c
+			}
+			return color.NRGBA{r.R, r.G, r.B, c.A}
+		})
+	})
+}
+
+// KeyOut makes the pixels close to target transparent, such as to knock
+// out the green screen behind a product. tolerance is as with ReplaceColor,
+// the pixels up to half the tolerance further are made partly transparent
+// so the edges stay smooth. A nil target records ErrInvalidArgument
+// i.e :
+// imgr.KeyOut(color.RGBA{0, 177, 64, 255}, 0.2).Save("product.png")
+func (i *Imager) KeyOut(target color.Color, tolerance float64) *Imager {
+	if target == nil {
+		i.setErr(fmt.Errorf("%w: nil target color", ErrInvalidArgument))
+		return i
+	}
+	if !i.checkRange("tolerance", tolerance, 0, 1) {
+		return i
+	}
+	t := color.NRGBAModel.Convert(target).(color.NRGBA)
+	feather := tolerance / 2
+
+	return i.apply("KeyOut", func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			d := rgbDistance(c, t)
+			switch {
+			case d <= tolerance:
+				return color.NRGBA{}
+			case d < tolerance+feather:
+				c.A = clampUint8(float64(c.A) * (d - tolerance) / feather)
+			}
+			return c
+		})
+	})
+}
+
+// rgbDistance returns the euclidean distance between the RGB values of a
+// and b, from 0 to 1
+func rgbDistance(a, b color.NRGBA) float64 {
+	dr, dg, db := float64(a.R)-float64(b.R), float64(a.G)-float64(b.G), float64(a.B)-float64(b.B)
+	return math.Sqrt(dr*dr+dg*dg+db*db) / (255 * math.Sqrt(3))
+}
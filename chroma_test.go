@@ -0,0 +1,80 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// createGreenScreenImage returns a green image with a red square and a
+// slightly darker green pixel in the corner
+func createGreenScreenImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(img, img.Rect, image.NewUniform(color.NRGBA{0, 177, 64, 255}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(10, 10, 30, 30), image.NewUniform(color.NRGBA{200, 30, 30, 255}), image.Point{}, draw.Src)
+	img.SetNRGBA(0, 0, color.NRGBA{10, 160, 70, 255})
+	return img
+}
+
+func TestReplaceColor(t *testing.T) {
+	imgr, _ := NewImager(createGreenScreenImage())
+	imgr.ReplaceColor(color.NRGBA{0, 177, 64, 255}, color.White, 0.1)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("ReplaceColor returned an error: %v", err)
+	}
+
+	if c := pixel(imgr.Image, 0, 0); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("ReplaceColor did not replace a color within the tolerance: %v", c)
+	}
+	if c := pixel(imgr.Image, 20, 20); c != (color.NRGBA{200, 30, 30, 255}) {
+		t.Fatalf("ReplaceColor replaced a distant color: %v", c)
+	}
+
+	imgr, _ = NewImager(createGreenScreenImage())
+	if c := pixel(imgr.ReplaceColor(color.NRGBA{0, 177, 64, 255}, color.White, 0).Image, 0, 0); c != (color.NRGBA{10, 160, 70, 255}) {
+		t.Fatalf("ReplaceColor replaced a color with no tolerance: %v", c)
+	}
+
+	if err := imgr.ReplaceColor(color.White, color.Black, -1).Err(); err == nil {
+		t.Fatalf("ReplaceColor did not record an error for a tolerance out of range")
+	}
+}
+
+func TestKeyOut(t *testing.T) {
+	imgr, _ := NewImager(createGreenScreenImage())
+	imgr.KeyOut(color.NRGBA{0, 177, 64, 255}, 0.1)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("KeyOut returned an error: %v", err)
+	}
+
+	if c := pixel(imgr.Image, 0, 0); c.A != 0 {
+		t.Fatalf("KeyOut did not key out a color within the tolerance: %v", c)
+	}
+	if c := pixel(imgr.Image, 5, 5); c.A != 0 {
+		t.Fatalf("KeyOut did not key out the target: %v", c)
+	}
+	if c := pixel(imgr.Image, 20, 20); c != (color.NRGBA{200, 30, 30, 255}) {
+		t.Fatalf("KeyOut changed a distant color: %v", c)
+	}
+
+	// The corner pixel is at a distance of about 0.054 from the target
+	imgr, _ = NewImager(createGreenScreenImage())
+	if c := pixel(imgr.KeyOut(color.NRGBA{0, 177, 64, 255}, 0.04).Image, 0, 0); c.A == 0 || c.A == 255 {
+		t.Fatalf("KeyOut did not feather a color just past the tolerance: %v", c)
+	}
+}
+
+func TestChromaNilColor(t *testing.T) {
+	for name, op := range map[string]func(i *Imager) *Imager{
+		"KeyOut":                   func(i *Imager) *Imager { return i.KeyOut(nil, 0.1) },
+		"ReplaceColor target":      func(i *Imager) *Imager { return i.ReplaceColor(nil, color.White, 0.1) },
+		"ReplaceColor replacement": func(i *Imager) *Imager { return i.ReplaceColor(color.White, nil, 0.1) },
+	} {
+		imgr, _ := NewImager(createGreenScreenImage())
+		if err := op(imgr).Err(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+}
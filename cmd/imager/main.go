@@ -0,0 +1,247 @@
+// Command imager transforms images from the command line
+//
+//	imager resize -w 800 -h 600 --mode fit in.jpg out.webp
+//	imager crop -w 100 -h 100 -x 10 -y 10 in.png out.png
+//	imager rotate -deg 90 in.jpg out.jpg
+//	imager convert in.png out.webp
+//	imager watermark -text "© ACME" -anchor bottom-right in.jpg out.jpg
+//	imager preset -name avatar -presets presets.json in.jpg out.jpg
+//
+// Several inputs, or glob patterns, are written to the output directory
+// keeping their names, -format changes their extension
+//
+//	imager resize -w 200 -format webp "photos/*.jpg" thumbs/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mamur-rezeki/imager"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "imager:", err)
+		os.Exit(1)
+	}
+}
+
+// errUsage is returned when the command line is invalid, the usage has been
+// printed already
+var errUsage = errors.New("invalid usage")
+
+// command builds the pipeline of a subcommand from its flags
+type command struct {
+	usage string
+	flags func(fs *flag.FlagSet) func() (*imager.Pipeline, error)
+}
+
+// commands holds the subcommands by name
+var commands = map[string]command{
+	"resize": {
+		usage: "resize -w width -h height [-mode fit|crop|scale|stretch|smart]",
+		flags: func(fs *flag.FlagSet) func() (*imager.Pipeline, error) {
+			width := fs.Int("w", 0, "width, zero keeps the aspect ratio")
+			height := fs.Int("h", 0, "height, zero keeps the aspect ratio")
+			mode := fs.String("mode", "fit", "resize mode: fit, crop, scale, stretch or smart")
+			return func() (*imager.Pipeline, error) {
+				m := *mode
+				if *width == 0 || *height == 0 {
+					m = "scale"
+				}
+				return imager.NewPipeline().Add(imager.Op{Name: "resize", Width: *width, Height: *height, Mode: m}), nil
+			}
+		},
+	},
+	"crop": {
+		usage: "crop -w width -h height [-x left -y top]",
+		flags: func(fs *flag.FlagSet) func() (*imager.Pipeline, error) {
+			width := fs.Int("w", 0, "width of the crop")
+			height := fs.Int("h", 0, "height of the crop")
+			x := fs.Int("x", 0, "left edge of the crop")
+			y := fs.Int("y", 0, "top edge of the crop")
+			return func() (*imager.Pipeline, error) {
+				return imager.NewPipeline().Crop(*width, *height, *x, *y), nil
+			}
+		},
+	},
+	"rotate": {
+		usage: "rotate -deg degrees",
+		flags: func(fs *flag.FlagSet) func() (*imager.Pipeline, error) {
+			degrees := fs.Int("deg", 90, "counter-clockwise rotation in degrees")
+			return func() (*imager.Pipeline, error) {
+				return imager.NewPipeline().Rotate(*degrees), nil
+			}
+		},
+	},
+	"convert": {
+		usage: "convert",
+		flags: func(fs *flag.FlagSet) func() (*imager.Pipeline, error) {
+			return func() (*imager.Pipeline, error) {
+				return imager.NewPipeline(), nil
+			}
+		},
+	},
+	"watermark": {
+		usage: "watermark -text text [-anchor bottom-right] [-color #ffffff] [-size 0] [-margin 10]",
+		flags: func(fs *flag.FlagSet) func() (*imager.Pipeline, error) {
+			text := fs.String("text", "", "text of the watermark")
+			anchor := fs.String("anchor", "bottom-right", "position of the watermark")
+			col := fs.String("color", "#ffffff", "hex color of the watermark")
+			size := fs.Float64("size", 0, "height of the text in pixels, zero keeps the font size")
+			margin := fs.Int("margin", 10, "distance to the image edges")
+			return func() (*imager.Pipeline, error) {
+				return imager.NewPipeline().Watermark(*text, *anchor, *col, *size, *margin), nil
+			}
+		},
+	},
+	"preset": {
+		usage: "preset -name name [-presets file.json]",
+		flags: func(fs *flag.FlagSet) func() (*imager.Pipeline, error) {
+			name := fs.String("name", "", "name of the preset")
+			file := fs.String("presets", "", "JSON file of presets, see imager.LoadPresets")
+			return func() (*imager.Pipeline, error) {
+				if *file != "" {
+					data, err := os.ReadFile(*file)
+					if err != nil {
+						return nil, err
+					}
+					if err := imager.LoadPresets(data); err != nil {
+						return nil, err
+					}
+				}
+
+				pipeline, ok := imager.Preset(*name)
+				if !ok {
+					return nil, fmt.Errorf("unknown preset %q", *name)
+				}
+				return pipeline, nil
+			}
+		},
+	},
+}
+
+// run runs the command line args, without the program name
+func run(args []string, stderr io.Writer) error {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return errUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage(stderr)
+		return errUsage
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: imager %s [-quality q] [-format f] input... output\n", cmd.usage)
+		fs.PrintDefaults()
+	}
+	build := cmd.flags(fs)
+	quality := fs.Int("quality", 0, "JPEG quality from 1 to 100, zero uses the default")
+	format := fs.String("format", "", "output extension of batches, the input one by default")
+	if err := fs.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	pipeline, err := build()
+	if err != nil {
+		return err
+	}
+	if err := pipeline.Validate(); err != nil {
+		return err
+	}
+
+	jobs, err := plan(fs.Args()[:fs.NArg()-1], fs.Arg(fs.NArg()-1), *format)
+	if err != nil {
+		return err
+	}
+
+	opts := imager.EncodeOptions{JPEGQuality: *quality}
+	var failed int
+	for _, job := range jobs {
+		if err := process(pipeline, job[0], job[1], opts); err != nil {
+			fmt.Fprintf(stderr, "imager: %s: %v\n", job[0], err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images failed", failed, len(jobs))
+	}
+
+	return nil
+}
+
+// plan returns the input and output path of each image. Several inputs are
+// written to the output directory
+func plan(patterns []string, output, format string) ([][2]string, error) {
+	var inputs []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if matches == nil {
+			return nil, fmt.Errorf("no file matches %s", pattern)
+		}
+		inputs = append(inputs, matches...)
+	}
+
+	info, err := os.Stat(output)
+	isDir := (err == nil && info.IsDir()) || strings.HasSuffix(output, "/")
+	if len(inputs) == 1 && !isDir {
+		return [][2]string{{inputs[0], output}}, nil
+	}
+
+	if err := os.MkdirAll(output, 0o755); err != nil {
+		return nil, err
+	}
+
+	jobs := make([][2]string, len(inputs))
+	for idx, input := range inputs {
+		name := filepath.Base(input)
+		if format != "" {
+			name = strings.TrimSuffix(name, filepath.Ext(name)) + "." + strings.TrimPrefix(format, ".")
+		}
+		jobs[idx] = [2]string{input, filepath.Join(output, name)}
+	}
+
+	return jobs, nil
+}
+
+// process transforms a single image
+func process(pipeline *imager.Pipeline, input, output string, opts imager.EncodeOptions) error {
+	imgr, err := imager.NewImagerFromFile(input, imager.WithAutoOrient())
+	if err != nil {
+		return err
+	}
+
+	if err := pipeline.Run(imgr); err != nil {
+		return err
+	}
+
+	return imgr.Save(output, opts)
+}
+
+// printUsage prints the list of subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: imager command [flags] input... output")
+	fmt.Fprintln(w, "commands:")
+	for _, name := range []string{"resize", "crop", "rotate", "convert", "watermark", "preset"} {
+		fmt.Fprintf(w, "  imager %s\n", commands[name].usage)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mamur-rezeki/imager"
+)
+
+// writeTestImage saves a 100x50 red image to path
+func writeTestImage(t *testing.T, path string) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+
+	imgr, _ := imager.NewImager(img)
+	if err := imgr.Save(path); err != nil {
+		t.Fatalf("failed to write test image: %v", err)
+	}
+}
+
+// bounds returns the size of the image at path
+func bounds(t *testing.T, path string) image.Point {
+	imgr, err := imager.NewImagerFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", path, err)
+	}
+	return imgr.Image.Bounds().Size()
+}
+
+func TestResize(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+	writeTestImage(t, in)
+
+	if err := run([]string{"resize", "-w", "40", "-h", "40", "--mode", "fit", in, out}, io.Discard); err != nil {
+		t.Fatalf("run returned an error: %v", err)
+	}
+	if size := bounds(t, out); size != image.Pt(40, 20) {
+		t.Fatalf("unexpected size %v", size)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestImage(t, filepath.Join(dir, "a.png"))
+	writeTestImage(t, filepath.Join(dir, "b.png"))
+	outDir := filepath.Join(dir, "out")
+
+	if err := run([]string{"rotate", "-deg", "90", "-format", "jpg", filepath.Join(dir, "*.png"), outDir}, io.Discard); err != nil {
+		t.Fatalf("run returned an error: %v", err)
+	}
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if size := bounds(t, filepath.Join(outDir, name)); size != image.Pt(50, 100) {
+			t.Fatalf("%s: unexpected size %v", name, size)
+		}
+	}
+}
+
+func TestPreset(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.png")
+	presets := filepath.Join(dir, "presets.json")
+	writeTestImage(t, in)
+	if err := os.WriteFile(presets, []byte(`{"banner": [{"op": "resize", "width": 60, "height": 20, "mode": "crop"}]}`), 0o644); err != nil {
+		t.Fatalf("failed to write the presets: %v", err)
+	}
+
+	if err := run([]string{"preset", "-name", "banner", "-presets", presets, in, out}, io.Discard); err != nil {
+		t.Fatalf("run returned an error: %v", err)
+	}
+	if size := bounds(t, out); size != image.Pt(60, 20) {
+		t.Fatalf("unexpected size %v", size)
+	}
+
+	if err := run([]string{"preset", "-name", "missing", in, out}, io.Discard); err == nil {
+		t.Fatalf("run accepted an unknown preset")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.png")
+	writeTestImage(t, in)
+
+	tests := [][]string{
+		{},
+		{"explode", in, in},
+		{"resize", "-w", "10"},
+		{"resize", "-nope", in, in},
+	}
+	for _, args := range tests {
+		if err := run(args, io.Discard); !errors.Is(err, errUsage) {
+			t.Fatalf("%v: expected errUsage, got %v", args, err)
+		}
+	}
+
+	if err := run([]string{"watermark", in, filepath.Join(dir, "out.png")}, io.Discard); !errors.Is(err, imager.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument without text, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// ssimWindow is the size of the windows SSIM is computed over, they overlap
+// by half
+const ssimWindow = 8
+
+// Comparison holds the similarity metrics between two images, see Compare
+type Comparison struct {
+	// PSNR is the peak signal-to-noise ratio in dB over the RGB channels,
+	// +Inf for identical images
+	PSNR float64
+
+	// SSIM is the structural similarity of the luminance, from -1 to 1 for
+	// identical images
+	SSIM float64
+
+	// MAE is the mean absolute error over the RGB channels, from 0 to 255
+	MAE float64
+}
+
+// CompareTo returns the peak signal-to-noise ratio in dB between the image and
+// other, computed over the RGB channels. Identical images return +Inf
+// i.e :
+// psnr, err := imgr.CompareTo(other)
+func (i *Imager) CompareTo(other *Imager) (float64, error) {
+	comparison, err := i.Compare(other)
+	if err != nil {
+		return 0, err
+	}
+
+	return comparison.PSNR, nil
+}
+
+// Compare computes the PSNR, SSIM and mean absolute error between the image
+// and other, which must have the same size
+// i.e :
+// cmp, err := imgr.Compare(other)
+// same := cmp.SSIM > 0.98
+func (i *Imager) Compare(other *Imager) (*Comparison, error) {
+	a, b, err := comparePair(i.Image, other.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	var squares, absolutes float64
+	for p := 0; p < len(a.Pix); p += 4 {
+		for ch := 0; ch < 3; ch++ {
+			d := float64(a.Pix[p+ch]) - float64(b.Pix[p+ch])
+			squares += d * d
+			absolutes += math.Abs(d)
+		}
+	}
+
+	samples := float64(len(a.Pix) / 4 * 3)
+	comparison := &Comparison{PSNR: math.Inf(1), SSIM: ssim(a, b)}
+	if samples > 0 {
+		comparison.MAE = absolutes / samples
+	}
+	if squares > 0 {
+		comparison.PSNR = 10 * math.Log10(255*255/(squares/samples))
+	}
+
+	return comparison, nil
+}
+
+// Diff returns an image highlighting in red the pixels differing between the
+// image and other, over a faded copy of the image. The more a pixel differs,
+// the redder it is
+// i.e :
+// diff, err := imgr.Diff(other)
+// err = diff.Save("diff.png")
+func (i *Imager) Diff(other *Imager) (*Imager, error) {
+	a, b, err := comparePair(i.Image, other.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := image.NewNRGBA(a.Rect)
+	for p := 0; p < len(a.Pix); p += 4 {
+		diff := 0
+		for ch := 0; ch < 4; ch++ {
+			diff = max(diff, absInt(int(a.Pix[p+ch])-int(b.Pix[p+ch])))
+		}
+
+		faded := 192 + luma(color.NRGBA{a.Pix[p], a.Pix[p+1], a.Pix[p+2], 255})/4
+		c := [3]float64{float64(faded), float64(faded), float64(faded)}
+		if diff > 0 {
+			// Even the smallest change stands out
+			t := math.Min(1, 0.5+float64(diff)/128)
+			c = [3]float64{c[0] + (255-c[0])*t, c[1] * (1 - t), c[2] * (1 - t)}
+		}
+
+		dst.Pix[p], dst.Pix[p+1], dst.Pix[p+2], dst.Pix[p+3] = uint8(c[0]+0.5), uint8(c[1]+0.5), uint8(c[2]+0.5), 255
+	}
+
+	return NewImager(dst)
+}
+
+// comparePair returns a and b as NRGBA images with the same origin, or
+// ErrSizeMismatch
+func comparePair(a, b image.Image) (*image.NRGBA, *image.NRGBA, error) {
+	ra, rb := a.Bounds(), b.Bounds()
+	if ra.Dx() != rb.Dx() || ra.Dy() != rb.Dy() {
+		return nil, nil, fmt.Errorf("%w: %dx%d and %dx%d", ErrSizeMismatch, ra.Dx(), ra.Dy(), rb.Dx(), rb.Dy())
+	}
+
+	return imaging.Clone(a), imaging.Clone(b), nil
+}
+
+// ssim returns the mean structural similarity of the luminance of a and b,
+// computed over overlapping windows
+func ssim(a, b *image.NRGBA) float64 {
+	w, h := a.Rect.Dx(), a.Rect.Dy()
+	if w == 0 || h == 0 {
+		return 1
+	}
+
+	la, lb := make([]float64, w*h), make([]float64, w*h)
+	for k := range la {
+		p := k * 4
+		la[k] = float64(luma(color.NRGBA{a.Pix[p], a.Pix[p+1], a.Pix[p+2], 255}))
+		lb[k] = float64(luma(color.NRGBA{b.Pix[p], b.Pix[p+1], b.Pix[p+2], 255}))
+	}
+
+	const c1, c2 = (0.01 * 255) * (0.01 * 255), (0.03 * 255) * (0.03 * 255)
+	winW, winH := min(ssimWindow, w), min(ssimWindow, h)
+
+	total, windows := 0.0, 0
+	for y0 := 0; y0+winH <= h; y0 += max(1, winH/2) {
+		for x0 := 0; x0+winW <= w; x0 += max(1, winW/2) {
+			var sumA, sumB, sumAA, sumBB, sumAB float64
+			for y := y0; y < y0+winH; y++ {
+				for x := x0; x < x0+winW; x++ {
+					va, vb := la[y*w+x], lb[y*w+x]
+					sumA, sumB = sumA+va, sumB+vb
+					sumAA, sumBB, sumAB = sumAA+va*va, sumBB+vb*vb, sumAB+va*vb
+				}
+			}
+
+			n := float64(winW * winH)
+			meanA, meanB := sumA/n, sumB/n
+			varA, varB := sumAA/n-meanA*meanA, sumBB/n-meanB*meanB
+			covariance := sumAB/n - meanA*meanB
+
+			total += (2*meanA*meanB + c1) * (2*covariance + c2) / ((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			windows++
+		}
+	}
+
+	return total / float64(windows)
+}
@@ -0,0 +1,97 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func TestCompareTo(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+
+	psnr, err := imgr.CompareTo(imgr)
+	if err != nil {
+		t.Fatalf("CompareTo returned an error: %v", err)
+	}
+	if !math.IsInf(psnr, 1) {
+		t.Fatalf("CompareTo of identical images returned %v, want +Inf", psnr)
+	}
+
+	noisy := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			noisy.Set(x, y, color.RGBA{uint8(255 - (x*y)%20), uint8((x + y) % 20), 0, 255})
+		}
+	}
+	other, _ := NewImager(noisy)
+
+	psnr, err = imgr.CompareTo(other)
+	if err != nil {
+		t.Fatalf("CompareTo returned an error: %v", err)
+	}
+	if math.IsInf(psnr, 0) || psnr <= 0 {
+		t.Fatalf("CompareTo of noisy image returned %v, want a finite positive value", psnr)
+	}
+}
+
+func TestCompareToSizeMismatch(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	other, _ := NewImager(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+
+	if _, err := imgr.CompareTo(other); !errors.Is(err, ErrSizeMismatch) {
+		t.Fatalf("CompareTo returned %v, want %v", err, ErrSizeMismatch)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	imgr, _ := NewImager(createPatternImage(64, 48))
+
+	same, err := imgr.Compare(imgr)
+	if err != nil {
+		t.Fatalf("Compare returned an error: %v", err)
+	}
+	if !math.IsInf(same.PSNR, 1) || math.Abs(same.SSIM-1) > 1e-9 || same.MAE != 0 {
+		t.Fatalf("Compare of identical images returned %+v", *same)
+	}
+
+	blurred, _ := NewImager(createPatternImage(64, 48))
+	blurred.GaussianBlur(1)
+	inverted, _ := NewImager(createPatternImage(64, 48))
+	inverted.Invert()
+
+	near, _ := imgr.Compare(blurred)
+	far, _ := imgr.Compare(inverted)
+	if near.SSIM <= far.SSIM || near.PSNR <= far.PSNR || near.MAE >= far.MAE {
+		t.Fatalf("Compare ranked the inverted image closer: blurred %+v, inverted %+v", *near, *far)
+	}
+	if near.SSIM < 0.8 || far.SSIM > 0.2 {
+		t.Fatalf("Compare returned unexpected SSIM: blurred %v, inverted %v", near.SSIM, far.SSIM)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	changed := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(changed, changed.Bounds(), createTestImage(), image.Point{}, draw.Src)
+	changed.Set(10, 20, color.RGBA{250, 0, 0, 255})
+	other, _ := NewImager(changed)
+
+	diff, err := imgr.Diff(other)
+	if err != nil {
+		t.Fatalf("Diff returned an error: %v", err)
+	}
+
+	if c := pixel(diff.Image, 10, 20); c.R < 200 || c.G > 100 {
+		t.Fatalf("Diff did not highlight the changed pixel: %v", c)
+	}
+	if c := pixel(diff.Image, 50, 50); c.R != c.G || c.G != c.B {
+		t.Fatalf("Diff highlighted an unchanged pixel: %v", c)
+	}
+
+	if _, err := imgr.Diff(&Imager{Image: image.NewNRGBA(image.Rect(0, 0, 5, 5))}); !errors.Is(err, ErrSizeMismatch) {
+		t.Fatalf("Diff returned %v, want %v", err, ErrSizeMismatch)
+	}
+}
@@ -0,0 +1,182 @@
+package imager
+
+import (
+	"context"
+	"image"
+	"io"
+
+	"github.com/disintegration/imaging"
+)
+
+// ResizeCtx is like Resize but stops early when ctx is cancelled, the image
+// is left untouched when the context error is returned. Cancellation is
+// checked between the frames of animations and, when resampling, between
+// the horizontal and the vertical pass. Invalid sizes record and return
+// ErrInvalidArgument, see ResizeWithFilter
+// i.e :
+// imgr, err := imgr.ResizeCtx(ctx, 100, 100, imager.MD_FIT)
+func (i *Imager) ResizeCtx(ctx context.Context, width, height int, mode ResizeMode) (*Imager, error) {
+	defer i.operation("ResizeCtx")()
+
+	if !i.checkResizeSize(width, height, mode) {
+		return i, i.err
+	}
+
+	filter := imaging.Lanczos
+	switch mode {
+	case MD_STRETCH:
+		filter = imaging.NearestNeighbor
+	case MD_FIT, MD_SCALE:
+	default:
+		return i.withContext(ctx, func() {
+			i.Resize(width, height, mode)
+		})
+	}
+
+	return i, i.applyCtx(ctx, "ResizeCtx", func(img image.Image) (image.Image, error) {
+		srcW, srcH := img.Bounds().Dx(), img.Bounds().Dy()
+		dstW, dstH := width, height
+		if mode == MD_FIT {
+			if srcW <= width && srcH <= height {
+				return imaging.Clone(img), nil
+			}
+			dstW, dstH = fitSize(srcW, srcH, width, height)
+		}
+
+		return resizeStaged(ctx, img, dstW, dstH, filter)
+	})
+}
+
+// RotateCtx is like Rotate but stops early when ctx is cancelled, the image
+// is left untouched when the context error is returned
+// i.e :
+// imgr, err := imgr.RotateCtx(ctx, 45)
+func (i *Imager) RotateCtx(ctx context.Context, degrees int) (*Imager, error) {
+	defer i.operation("RotateCtx")()
+
+	return i.withContext(ctx, func() {
+		i.Rotate(degrees)
+	})
+}
+
+// EncodeCtx is like Encode but stops early when ctx is cancelled, returning
+// the context error. Part of the image may have been written to w already
+// i.e :
+// err := imgr.EncodeCtx(r.Context(), w, imager.IMJPEG)
+func (i *Imager) EncodeCtx(ctx context.Context, w io.Writer, format string, opts ...EncodeOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := i.Encode(&ctxWriter{ctx: ctx, w: w}, format, opts...); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// withContext runs op between two cancellation checks and restores the
+// previous image when the context was cancelled while op was running
+func (i *Imager) withContext(ctx context.Context, op func()) (*Imager, error) {
+	if err := ctx.Err(); err != nil {
+		return i, err
+	}
+
+	orig, anim := i.Image, i.Animation
+	op()
+
+	if err := ctx.Err(); err != nil {
+		i.Image, i.Animation = orig, anim
+		return i, err
+	}
+
+	return i, nil
+}
+
+// applyCtx is like apply for operations that can fail, the context is
+// checked before each frame. The image is only replaced when every frame
+// succeeded, the recorded error is returned without running op
+func (i *Imager) applyCtx(ctx context.Context, name string, op func(image.Image) (image.Image, error)) error {
+	if i.err != nil {
+		return i.err
+	}
+
+	frames := []image.Image{i.Image}
+	if i.Animation != nil {
+		frames = i.Animation.Frames
+	}
+
+	results := make([]image.Image, len(frames))
+	for idx, frame := range frames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		if results[idx], err = op(frame); err != nil {
+			return err
+		}
+	}
+
+	idx := 0
+	i.apply(name, func(image.Image) image.Image {
+		idx++
+		return results[idx-1]
+	})
+
+	return nil
+}
+
+// resizeStaged resizes img to width x height, zero keeping the aspect ratio,
+// checking ctx between the horizontal and the vertical pass
+func resizeStaged(ctx context.Context, img image.Image, width, height int, filter imaging.ResampleFilter) (image.Image, error) {
+	srcW, srcH := img.Bounds().Dx(), img.Bounds().Dy()
+	if width == 0 && height == 0 || srcW <= 0 || srcH <= 0 {
+		return &image.NRGBA{}, nil
+	}
+	if width == 0 {
+		width = max(1, int(float64(srcW)*float64(height)/float64(srcH)+0.5))
+	}
+	if height == 0 {
+		height = max(1, int(float64(srcH)*float64(width)/float64(srcW)+0.5))
+	}
+
+	tmp := imaging.Resize(img, width, srcH, filter)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return imaging.Resize(tmp, width, height, filter), nil
+}
+
+// fitSize returns the largest size within maxW x maxH with the aspect ratio
+// of srcW x srcH
+func fitSize(srcW, srcH, maxW, maxH int) (int, int) {
+	if maxW <= 0 || maxH <= 0 {
+		return 0, 0
+	}
+
+	ratio := float64(srcW) / float64(srcH)
+	if float64(maxW)/float64(maxH) > ratio {
+		return max(1, int(float64(maxH)*ratio+0.5)), maxH
+	}
+
+	return maxW, max(1, int(float64(maxW)/ratio+0.5))
+}
+
+// ctxWriter fails the writes once its context is cancelled
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c *ctxWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.w.Write(p)
+}
@@ -0,0 +1,90 @@
+package imager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResizeCtxCancelled(t *testing.T) {
+	img := createTestImage()
+	imgr, _ := NewImager(img)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := imgr.ResizeCtx(ctx, 50, 50, MD_FIT)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ResizeCtx returned %v, want %v", err, context.Canceled)
+	}
+
+	if imgr.Image != img {
+		t.Fatalf("ResizeCtx modified the image of a cancelled context")
+	}
+}
+
+func TestResizeCtx(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+
+	_, err := imgr.ResizeCtx(context.Background(), 50, 50, MD_FIT)
+	if err != nil {
+		t.Fatalf("ResizeCtx returned an error: %v", err)
+	}
+
+	if imgr.Image.Bounds().Dx() != 50 || imgr.Image.Bounds().Dy() != 50 {
+		t.Fatalf("ResizeCtx did not return the expected dimensions: got %v", imgr.Image.Bounds())
+	}
+}
+
+func TestResizeCtxInvalid(t *testing.T) {
+	for _, mode := range []ResizeMode{MD_FIT, MD_SCALE} {
+		imgr, _ := NewImager(createTestImage())
+		if _, err := imgr.ResizeCtx(context.Background(), -10, 50, mode); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("ResizeCtx(%v) returned %v for a negative size", mode, err)
+		}
+		if imgr.Image.Bounds().Dx() != 100 {
+			t.Fatalf("ResizeCtx(%v) changed the image", mode)
+		}
+	}
+
+	// Nothing is resampled after a failed operation
+	imgr, _ := NewImager(createTestImage())
+	imgr.Crop(500, 500, 0, 0)
+	if _, err := imgr.ResizeCtx(context.Background(), 50, 50, MD_SCALE); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("ResizeCtx returned %v after a failed operation", err)
+	}
+	if imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("ResizeCtx resized after a failed operation")
+	}
+}
+
+func TestResizeCtxMatchesResize(t *testing.T) {
+	for _, mode := range []ResizeMode{MD_FIT, MD_SCALE, MD_STRETCH, MD_CROP} {
+		expected, _ := NewImager(createGradientImage())
+		expected.Resize(60, 30, mode)
+
+		imgr, _ := NewImager(createGradientImage())
+		if _, err := imgr.ResizeCtx(context.Background(), 60, 30, mode); err != nil {
+			t.Fatalf("ResizeCtx returned an error: %v", err)
+		}
+
+		assertSamePixels(t, imgr.Image, expected.Image)
+	}
+}
+
+func TestEncodeCtx(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+
+	buf := new(bytes.Buffer)
+	if err := imgr.EncodeCtx(context.Background(), buf, IMPNG); err != nil || buf.Len() == 0 {
+		t.Fatalf("EncodeCtx failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf.Reset()
+	if err := imgr.EncodeCtx(ctx, buf, IMPNG); !errors.Is(err, context.Canceled) || buf.Len() != 0 {
+		t.Fatalf("EncodeCtx returned %v with %d bytes, want %v", err, buf.Len(), context.Canceled)
+	}
+}
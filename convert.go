@@ -0,0 +1,54 @@
+package imager
+
+import (
+	"fmt"
+	"strings"
+)
+
+// convertJPEGQuality is the JPEG quality set by ConvertTo when none is set,
+// lower than the default of 100 which is mostly meant to re-encode JPEG
+// images without further loss
+const convertJPEGQuality = 85
+
+// ConvertTo sets the format the image is encoded to by Bytes, Encode and
+// Save without an extension, one of the IM* constants. Formats without
+// alpha, JPEG and BMP, get the transparent areas flattened over Background
+// and JPEG a quality of 85 unless JPEGQuality is set. Formats other than GIF,
+// WebP and PNG keep the first frame of animations. Formats imager can't
+// encode record ErrUnsupportedFormat
+// i.e :
+// data, err := imgr.ConvertTo(imager.IMWEBP).Bytes()
+// imgr.ConvertTo(imager.IMJPEG).Save("photo")
+func (i *Imager) ConvertTo(format string) *Imager {
+	defer i.operation("ConvertTo")()
+
+	format = strings.ToLower(format)
+	if format == IMJPG {
+		format = IMJPEG
+	}
+	if !encodable(format) {
+		i.setErr(fmt.Errorf("%w: %q", ErrUnsupportedFormat, format))
+		return i
+	}
+	if i.err != nil {
+		return i
+	}
+
+	switch format {
+	case IMGIF, IMWEBP, IMPNG:
+	default:
+		i.Animation = nil
+	}
+	switch format {
+	case IMJPEG, IMBMP:
+		if !isOpaque(i.Image) {
+			i.Flatten(i.Background)
+		}
+	}
+	if format == IMJPEG && i.JPEGQuality <= 0 {
+		i.JPEGQuality = convertJPEGQuality
+	}
+
+	i.ImageType = format
+	return i
+}
@@ -0,0 +1,50 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestConvertTo(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.SetNRGBA(5, 5, color.NRGBA{255, 0, 0, 255})
+
+	for _, format := range []string{IMPNG, IMJPEG, "JPG", IMGIF, IMWEBP, IMTIFF, IMBMP} {
+		imgr, _ := NewImager(img)
+		imgr.ImageType = IMPNG
+		data, err := imgr.ConvertTo(format).Bytes()
+		if err != nil {
+			t.Fatalf("ConvertTo(%q) returned an error: %v", format, err)
+		}
+
+		_, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("ConvertTo(%q) wrote an invalid image: %v", format, err)
+		}
+		if decoded != imgr.ImageType {
+			t.Fatalf("ConvertTo(%q) wrote a %s image", format, decoded)
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	imgr.ConvertTo(IMJPEG)
+	if c := pixel(imgr.Image, 0, 0); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("ConvertTo did not flatten the image for JPEG: %v", c)
+	}
+	if imgr.JPEGQuality != 85 || imgr.ImageType != IMJPEG {
+		t.Fatalf("ConvertTo set a quality of %d and the type %q", imgr.JPEGQuality, imgr.ImageType)
+	}
+
+	imgr, _ = NewImager(img)
+	imgr.ConvertTo(IMPNG)
+	if c := pixel(imgr.Image, 0, 0); c.A != 0 {
+		t.Fatalf("ConvertTo flattened the image for PNG: %v", c)
+	}
+
+	if err := imgr.ConvertTo("heif").Err(); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("ConvertTo recorded %v for HEIF, want ErrUnsupportedFormat", err)
+	}
+}
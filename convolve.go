@@ -0,0 +1,103 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"runtime"
+
+	"github.com/disintegration/imaging"
+)
+
+// ConvolveOptions holds the options used by Convolve
+type ConvolveOptions struct {
+	// Normalize divides the kernel by the sum of its weights, when not zero,
+	// so the brightness of the image is kept
+	Normalize bool
+
+	// Abs takes the absolute value of the results, for edge detection
+	// kernels whose weights sum to zero
+	Abs bool
+
+	// Bias is added to the results, such as 128 to center embossing on gray
+	Bias float64
+}
+
+// Convolve replaces each pixel by the sum of its neighbours weighted by
+// kernel, centered on the pixel. The kernel has an odd number of rows, all
+// of the same odd length. The pixels beyond the edges repeat the edge ones
+// and the alpha is kept
+// i.e :
+// imgr.Convolve([][]float64{{-2, -1, 0}, {-1, 1, 1}, {0, 1, 2}})
+// imgr.Convolve([][]float64{{-1, -1, -1}, {-1, 8, -1}, {-1, -1, -1}}, imager.ConvolveOptions{Abs: true})
+// imgr.Convolve([][]float64{{1, 1, 1, 1, 1}}, imager.ConvolveOptions{Normalize: true})
+func (i *Imager) Convolve(kernel [][]float64, opts ...ConvolveOptions) *Imager {
+	var o ConvolveOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if len(kernel)%2 == 0 || len(kernel[0])%2 == 0 {
+		i.setErr(fmt.Errorf("%w: kernel of %d rows, the number of rows and of weights per row must be odd", ErrInvalidArgument, len(kernel)))
+		return i
+	}
+	sum := 0.0
+	for _, row := range kernel {
+		if len(row) != len(kernel[0]) {
+			i.setErr(fmt.Errorf("%w: kernel rows of %d and %d weights", ErrInvalidArgument, len(kernel[0]), len(row)))
+			return i
+		}
+		for _, w := range row {
+			sum += w
+		}
+	}
+
+	scale := 1.0
+	if o.Normalize && sum != 0 {
+		scale = 1 / sum
+	}
+
+	return i.apply("Convolve", func(img image.Image) image.Image {
+		return convolve(imaging.Clone(img), kernel, scale, o)
+	})
+}
+
+// convolve returns src convolved with kernel scaled by scale
+func convolve(src *image.NRGBA, kernel [][]float64, scale float64, o ConvolveOptions) *image.NRGBA {
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewNRGBA(src.Rect)
+	ry, rx := len(kernel)/2, len(kernel[0])/2
+
+	parallelRows(height, runtime.GOMAXPROCS(0), func(top, bottom int) {
+		for y := top; y < bottom; y++ {
+			for x := 0; x < width; x++ {
+				var r, g, b float64
+				for ky, row := range kernel {
+					sy := min(max(y+ky-ry, 0), height-1)
+					for kx, w := range row {
+						if w == 0 {
+							continue
+						}
+						sx := min(max(x+kx-rx, 0), width-1)
+						s := src.Pix[sy*src.Stride+sx*4:]
+						r += float64(s[0]) * w
+						g += float64(s[1]) * w
+						b += float64(s[2]) * w
+					}
+				}
+
+				d := dst.Pix[y*dst.Stride+x*4:]
+				for ch, v := range [3]float64{r, g, b} {
+					v *= scale
+					if o.Abs {
+						v = math.Abs(v)
+					}
+					d[ch] = clampUint8(v + o.Bias)
+				}
+				d[3] = src.Pix[y*src.Stride+x*4+3]
+			}
+		}
+	})
+
+	return dst
+}
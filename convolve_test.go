@@ -0,0 +1,41 @@
+package imager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvolve(t *testing.T) {
+	identity, _ := NewImager(createEdgeImage())
+	identity.Convolve([][]float64{{0, 0, 0}, {0, 1, 0}, {0, 0, 0}})
+	if gray(identity.Image, 9, 10) != 0 || gray(identity.Image, 10, 10) != 255 {
+		t.Fatalf("the identity kernel changed the image")
+	}
+
+	edges, _ := NewImager(createEdgeImage())
+	edges.Convolve([][]float64{{-1, -1, -1}, {-1, 8, -1}, {-1, -1, -1}}, ConvolveOptions{Abs: true})
+	if gray(edges.Image, 9, 10) != 255 || gray(edges.Image, 10, 10) != 255 || gray(edges.Image, 2, 10) != 0 || gray(edges.Image, 17, 10) != 0 {
+		t.Fatalf("the edge detection missed the edge")
+	}
+
+	blurred, _ := NewImager(createEdgeImage())
+	blurred.Convolve([][]float64{{1, 1, 1, 1, 1}}, ConvolveOptions{Normalize: true})
+	if v := gray(blurred.Image, 9, 10); v != 102 {
+		t.Fatalf("the horizontal box blur gave %d next to the edge", v)
+	}
+	if gray(blurred.Image, 0, 10) != 0 || gray(blurred.Image, 19, 10) != 255 {
+		t.Fatalf("the horizontal box blur changed the flat areas")
+	}
+
+	embossed, _ := NewImager(createEdgeImage())
+	embossed.Convolve([][]float64{{-1, 0, 1}}, ConvolveOptions{Bias: 128})
+	if gray(embossed.Image, 0, 10) != 128 {
+		t.Fatalf("the bias was not added to the flat areas")
+	}
+
+	for _, kernel := range [][][]float64{{{1, 1}}, {{1}, {1}}, {{1, 1, 1}, {1}, {1, 1, 1}}} {
+		if err := identity.Clone().Convolve(kernel).Err(); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("kernel %v: expected ErrInvalidArgument, got %v", kernel, err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// AutoCrop trims the uniform border around the image. The border color is
+// taken from the corner pixels and the crop stops at the first pixel that
+// differs from it by more than tolerance on any channel. A uniform image is
+// left untouched
+// i.e :
+// imgr.AutoCrop(10)
+func (i *Imager) AutoCrop(tolerance uint8) *Imager {
+	bounds := i.Image.Bounds()
+	if bounds.Empty() {
+		return i
+	}
+
+	border := cornerColor(i.Image)
+	isBorder := func(x, y int) bool {
+		return colorDistance(color.NRGBAModel.Convert(i.Image.At(x, y)).(color.NRGBA), border) <= tolerance
+	}
+	rowIsBorder := func(y int) bool {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+	colIsBorder := func(x, minY, maxY int) bool {
+		for y := minY; y < maxY; y++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	top := bounds.Min.Y
+	for top < bounds.Max.Y && rowIsBorder(top) {
+		top++
+	}
+	if top == bounds.Max.Y {
+		// Nothing but border, keep the image as it is
+		return i
+	}
+
+	bottom := bounds.Max.Y
+	for bottom > top && rowIsBorder(bottom-1) {
+		bottom--
+	}
+
+	left := bounds.Min.X
+	for left < bounds.Max.X && colIsBorder(left, top, bottom) {
+		left++
+	}
+
+	right := bounds.Max.X
+	for right > left && colIsBorder(right-1, top, bottom) {
+		right--
+	}
+
+	i.cropFocal(bounds, image.Rect(left, top, right, bottom))
+	return i.apply("AutoCrop", func(img image.Image) image.Image {
+		return imaging.Crop(img, image.Rect(left, top, right, bottom))
+	})
+}
+
+// cornerColor returns the most common color among the four corners of img
+func cornerColor(img image.Image) color.NRGBA {
+	b := img.Bounds()
+	corners := []color.NRGBA{
+		color.NRGBAModel.Convert(img.At(b.Min.X, b.Min.Y)).(color.NRGBA),
+		color.NRGBAModel.Convert(img.At(b.Max.X-1, b.Min.Y)).(color.NRGBA),
+		color.NRGBAModel.Convert(img.At(b.Min.X, b.Max.Y-1)).(color.NRGBA),
+		color.NRGBAModel.Convert(img.At(b.Max.X-1, b.Max.Y-1)).(color.NRGBA),
+	}
+
+	best, bestCount := corners[0], 0
+	for _, c := range corners {
+		count := 0
+		for _, other := range corners {
+			if c == other {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = c, count
+		}
+	}
+
+	return best
+}
+
+// colorDistance returns the largest per channel difference between a and b
+func colorDistance(a, b color.NRGBA) uint8 {
+	diff := func(x, y uint8) uint8 {
+		if x > y {
+			return x - y
+		}
+		return y - x
+	}
+
+	d := diff(a.R, b.R)
+	for _, c := range []uint8{diff(a.G, b.G), diff(a.B, b.B), diff(a.A, b.A)} {
+		if c > d {
+			d = c
+		}
+	}
+
+	return d
+}
+
+// CropAnchor crops a width x height area of the image, the kept part is set
+// by gravity: an Anchor or a FocalPoint. The area is reduced to the image
+// size when larger
+// i.e :
+// imgr.CropAnchor(400, 300, imager.AnchorTop)
+// imgr.CropAnchor(400, 300, imager.AnchorBottomRight)
+// imgr.CropAnchor(400, 300, imager.FocalPoint(0.7, 0.4))
+func (i *Imager) CropAnchor(width, height int, gravity Gravity) *Imager {
+	if width <= 0 || height <= 0 {
+		i.setErr(fmt.Errorf("%w: crop size %dx%d", ErrInvalidArgument, width, height))
+		return i
+	}
+
+	bounds := i.Image.Bounds()
+	size := image.Pt(min(width, bounds.Dx()), min(height, bounds.Dy()))
+	pt := gravity.place(bounds, size)
+
+	i.cropFocal(bounds, image.Rectangle{Min: pt, Max: pt.Add(size)})
+	return i.apply("CropAnchor", func(img image.Image) image.Image {
+		return imaging.Crop(img, image.Rectangle{Min: pt, Max: pt.Add(size)})
+	})
+}
+
+// FitToRatio pads the image with fill to reach the wRatio:hRatio aspect
+// ratio, the image is kept centered (letterboxing). A nil fill is
+// transparent
+// i.e :
+// imgr.FitToRatio(1, 1, color.White)
+// imgr.FitToRatio(16, 9, color.Black)
+func (i *Imager) FitToRatio(wRatio, hRatio int, fill color.Color) *Imager {
+	if wRatio <= 0 || hRatio <= 0 {
+		i.setErr(fmt.Errorf("%w: %d:%d", ErrInvalidRatio, wRatio, hRatio))
+		return i
+	}
+	if fill == nil {
+		fill = color.Transparent
+	}
+
+	size := i.Image.Bounds().Size()
+	width, height := size.X, size.Y
+	if width*hRatio > height*wRatio {
+		height = (width*hRatio + wRatio/2) / wRatio
+	} else {
+		width = (height*wRatio + hRatio/2) / hRatio
+	}
+
+	i.padFocal(size, image.Pt(width, height), image.Pt(width/2-size.X/2, height/2-size.Y/2))
+	return i.apply("FitToRatio", func(img image.Image) image.Image {
+		return imaging.PasteCenter(imaging.New(width, height, fill), img)
+	})
+}
+
+// CropToRatio crops the center of the image to the wRatio:hRatio aspect
+// ratio, or the area around the focal point when set, see SetFocalPoint
+// i.e :
+// imgr.CropToRatio(1, 1)
+// imgr.CropToRatio(16, 9)
+func (i *Imager) CropToRatio(wRatio, hRatio int) *Imager {
+	defer i.operation("CropToRatio")()
+
+	if wRatio <= 0 || hRatio <= 0 {
+		i.setErr(fmt.Errorf("%w: %d:%d", ErrInvalidRatio, wRatio, hRatio))
+		return i
+	}
+
+	return i.cropToAspect(float64(wRatio), float64(hRatio), i.gravity(AnchorCenter))
+}
+
+// CropToAspect crops the largest area of the image with the aspect ratio
+// written as "16:9", "4/3" or "1.91", the kept part is set by gravity. Invalid
+// ratios record ErrInvalidRatio
+// i.e :
+// imgr.CropToAspect("16:9", imager.AnchorCenter)
+// imgr.CropToAspect("9:16", imager.FocalPoint(0.5, 0.3))
+// imgr.CropToAspect("1:1", imager.AnchorTop)
+func (i *Imager) CropToAspect(ratio string, gravity Gravity) *Imager {
+	defer i.operation("CropToAspect")()
+
+	wRatio, hRatio, err := ParseAspect(ratio)
+	if err != nil {
+		i.setErr(err)
+		return i
+	}
+
+	return i.cropToAspect(wRatio, hRatio, gravity)
+}
+
+// ParseAspect parses an aspect ratio written as "16:9", "4/3" or "1.91",
+// returning its width and height parts
+// i.e :
+// w, h, err := imager.ParseAspect("16:9")
+func ParseAspect(ratio string) (float64, float64, error) {
+	wPart, hPart, found := strings.Cut(ratio, ":")
+	if !found {
+		wPart, hPart, found = strings.Cut(ratio, "/")
+	}
+	if !found {
+		hPart = "1"
+	}
+
+	w, errW := strconv.ParseFloat(strings.TrimSpace(wPart), 64)
+	h, errH := strconv.ParseFloat(strings.TrimSpace(hPart), 64)
+	if errW != nil || errH != nil || !(w > 0) || !(h > 0) || math.IsInf(w, 0) || math.IsInf(h, 0) {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidRatio, ratio)
+	}
+
+	return w, h, nil
+}
+
+// cropToAspect crops the largest wRatio:hRatio area placed by gravity
+func (i *Imager) cropToAspect(wRatio, hRatio float64, gravity Gravity) *Imager {
+	width, height := i.Image.Bounds().Dx(), i.Image.Bounds().Dy()
+	if float64(width)*hRatio > float64(height)*wRatio {
+		width = max(1, int(float64(height)*wRatio/hRatio+0.5))
+	} else {
+		height = max(1, int(float64(width)*hRatio/wRatio+0.5))
+	}
+
+	return i.CropAnchor(width, height, gravity)
+}
@@ -0,0 +1,176 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAutoCrop(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 120, 120))
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 120; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	for y := 10; y < 110; y++ {
+		for x := 10; x < 110; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	imgr.AutoCrop(10)
+
+	if imgr.Image.Bounds().Dx() != 100 || imgr.Image.Bounds().Dy() != 100 {
+		t.Fatalf("AutoCrop did not return the expected dimensions: got %v", imgr.Image.Bounds())
+	}
+
+	for _, pt := range []image.Point{{0, 0}, {99, 0}, {0, 99}, {99, 99}, {50, 50}} {
+		r, g, b, _ := imgr.Image.At(pt.X, pt.Y).RGBA()
+		if r>>8 != 255 || g != 0 || b != 0 {
+			t.Fatalf("AutoCrop left a non red pixel at %v", pt)
+		}
+	}
+}
+
+func TestAutoCropUniform(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.AutoCrop(0)
+
+	if imgr.Image.Bounds() != image.Rect(0, 0, 100, 100) {
+		t.Fatalf("AutoCrop cropped a uniform image: got %v", imgr.Image.Bounds())
+	}
+}
+
+func TestFitToRatio(t *testing.T) {
+	imgr, _ := NewImager(image.NewRGBA(image.Rect(0, 0, 100, 50)))
+	imgr.FitToRatio(1, 1, color.White)
+
+	if imgr.Err() != nil {
+		t.Fatalf("FitToRatio recorded an error: %v", imgr.Err())
+	}
+
+	if imgr.Image.Bounds().Dx() != 100 || imgr.Image.Bounds().Dy() != 100 {
+		t.Fatalf("FitToRatio did not return the expected dimensions: got %v", imgr.Image.Bounds())
+	}
+
+	for _, y := range []int{0, 24, 75, 99} {
+		r, g, b, _ := imgr.Image.At(50, y).RGBA()
+		if r>>8 != 255 || g>>8 != 255 || b>>8 != 255 {
+			t.Fatalf("FitToRatio did not add a white bar at row %d", y)
+		}
+	}
+
+	if _, _, _, a := imgr.Image.At(50, 50).RGBA(); a != 0 {
+		t.Fatalf("FitToRatio covered the original image")
+	}
+
+	// A nil fill is transparent
+	imgr, _ = NewImager(createTestImage())
+	imgr.Crop(100, 50, 0, 0).FitToRatio(1, 1, nil)
+	if b := imgr.Image.Bounds(); imgr.Err() != nil || b.Dx() != 100 || b.Dy() != 100 {
+		t.Fatalf("FitToRatio with a nil fill returned %v, %v", b, imgr.Err())
+	}
+	if _, _, _, a := imgr.Image.At(50, 0).RGBA(); a != 0 {
+		t.Fatalf("FitToRatio with a nil fill did not add a transparent bar")
+	}
+}
+
+func TestCropToRatio(t *testing.T) {
+	imgr, _ := NewImager(image.NewRGBA(image.Rect(0, 0, 100, 50)))
+	imgr.CropToRatio(1, 1)
+
+	if imgr.Image.Bounds().Dx() != 50 || imgr.Image.Bounds().Dy() != 50 {
+		t.Fatalf("CropToRatio did not return the expected dimensions: got %v", imgr.Image.Bounds())
+	}
+}
+
+func TestInvalidRatio(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+
+	if err := imgr.FitToRatio(0, 1, color.White).Err(); !errors.Is(err, ErrInvalidRatio) {
+		t.Fatalf("FitToRatio recorded %v, want %v", err, ErrInvalidRatio)
+	}
+
+	imgr, _ = NewImager(createTestImage())
+	if err := imgr.CropToRatio(1, -1).Err(); !errors.Is(err, ErrInvalidRatio) {
+		t.Fatalf("CropToRatio recorded %v, want %v", err, ErrInvalidRatio)
+	}
+}
+
+func TestCropAnchor(t *testing.T) {
+	// The gradient encodes x and y in the red and green channels
+	origin := func(imgr *Imager) image.Point {
+		c := color.RGBAModel.Convert(imgr.Image.At(0, 0)).(color.RGBA)
+		return image.Pt(int(c.R)/2, int(c.G)/2)
+	}
+
+	tests := []struct {
+		name    string
+		gravity Gravity
+		want    image.Point
+	}{
+		{"center", AnchorCenter, image.Pt(40, 40)},
+		{"top right", AnchorTopRight, image.Pt(80, 0)},
+		{"bottom", AnchorBottom, image.Pt(40, 80)},
+		{"focal point", FocalPoint(0.3, 0.4), image.Pt(20, 30)},
+		{"focal point near the edge", FocalPoint(0.95, 0.05), image.Pt(80, 0)},
+	}
+	for _, tt := range tests {
+		imgr, _ := NewImager(createGradientImage())
+		imgr.CropAnchor(20, 20, tt.gravity)
+		if b := imgr.Image.Bounds(); b.Dx() != 20 || b.Dy() != 20 {
+			t.Fatalf("%s: CropAnchor returned %v", tt.name, b)
+		}
+		if got := origin(imgr); got != tt.want {
+			t.Errorf("%s: CropAnchor kept the area at %v, expected %v", tt.name, got, tt.want)
+		}
+	}
+
+	imgr, _ := NewImager(createGradientImage())
+	imgr.Resize(20, 20, MD_CROP, WithGravity(AnchorBottomLeft))
+	if got := origin(imgr); got != image.Pt(0, 80) {
+		t.Fatalf("Resize with WithGravity kept the area at %v", got)
+	}
+
+	if imgr.CropAnchor(0, 10, AnchorTop); !errors.Is(imgr.Err(), ErrInvalidArgument) {
+		t.Fatalf("CropAnchor recorded %v for an empty size", imgr.Err())
+	}
+}
+
+func TestCropToAspect(t *testing.T) {
+	tests := []struct {
+		ratio        string
+		wantW, wantH int
+	}{
+		{"16:9", 100, 56},
+		{"9:16", 56, 100},
+		{"1:1", 100, 100},
+		{"4/3", 100, 75},
+		{"2", 100, 50},
+		{"1.91:1", 100, 52},
+	}
+	for _, tt := range tests {
+		imgr, _ := NewImager(createGradientImage())
+		imgr.CropToAspect(tt.ratio, AnchorTop)
+		if b := imgr.Image.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("CropToAspect(%q) returned %dx%d, expected %dx%d", tt.ratio, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+
+	// The gravity sets the kept part
+	imgr, _ := NewImager(createGradientImage())
+	imgr.CropToAspect("2:1", AnchorBottom)
+	if c := color.RGBAModel.Convert(imgr.Image.At(0, 0)).(color.RGBA); c.G != 100 {
+		t.Fatalf("CropToAspect did not keep the bottom half, first row at y %d", c.G/2)
+	}
+
+	for _, ratio := range []string{"", "16:0", "-1:2", "a:b", "1:2:3"} {
+		imgr, _ := NewImager(createGradientImage())
+		if imgr.CropToAspect(ratio, AnchorCenter); !errors.Is(imgr.Err(), ErrInvalidRatio) {
+			t.Errorf("CropToAspect(%q) recorded %v", ratio, imgr.Err())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// mediaTypes holds the media type of each format
+var mediaTypes = map[string]string{
+	IMJPEG: "image/jpeg",
+	IMJPG:  "image/jpeg",
+	IMPNG:  "image/png",
+	IMGIF:  "image/gif",
+	IMWEBP: "image/webp",
+	IMTIFF: "image/tiff",
+	IMTIF:  "image/tiff",
+	IMBMP:  "image/bmp",
+	IMAVIF: "image/avif",
+}
+
+// NewImagerFromDataURI creates a new Imager from a data URI, such as
+// data:image/png;base64,iVBORw0KGgo... The media type of the URI is ignored,
+// the format is detected from the data
+// i.e :
+// imgr, err := imager.NewImagerFromDataURI(req.Avatar)
+func NewImagerFromDataURI(uri string, opts ...LoadOption) (*Imager, error) {
+	header, payload, ok := strings.Cut(strings.TrimSpace(uri), ",")
+	if !ok || !strings.HasPrefix(strings.ToLower(header), "data:") {
+		return nil, fmt.Errorf("%w: not a data URI", ErrInvalidArgument)
+	}
+
+	var data []byte
+	var err error
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		// Line breaks are common in base64 data
+		payload = strings.Join(strings.Fields(payload), "")
+		if data, err = base64.StdEncoding.DecodeString(payload); err != nil {
+			data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+		}
+	} else {
+		var s string
+		s, err = url.PathUnescape(payload)
+		data = []byte(s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid data URI: %v", ErrInvalidArgument, err)
+	}
+
+	return NewImagerFromBytes(data, opts...)
+}
+
+// DataURI returns the image encoded as format in a base64 data URI, ready to
+// be inlined in HTML or JSON. An empty format uses ImageType
+// i.e :
+// uri, err := imgr.DataURI(imager.IMPNG)
+// uri, err := imgr.Resize(64, 64, imager.MD_FIT).DataURI(imager.IMJPEG, imager.EncodeOptions{JPEGQuality: 60})
+func (i *Imager) DataURI(format string, opts ...EncodeOptions) (string, error) {
+	if format == "" {
+		format = i.ImageType
+	}
+	mediaType, ok := mediaTypes[format]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := i.Encode(buf, format, opts...); err != nil {
+		return "", err
+	}
+
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,41 @@
+package imager
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDataURI(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+
+	uri, err := imgr.DataURI(IMPNG)
+	if err != nil {
+		t.Fatalf("DataURI returned an error: %v", err)
+	}
+	if !strings.HasPrefix(uri, "data:image/png;base64,iVBORw0KGgo") {
+		t.Fatalf("DataURI returned an unexpected URI: %.40s", uri)
+	}
+
+	decoded, err := NewImagerFromDataURI(uri)
+	if err != nil {
+		t.Fatalf("NewImagerFromDataURI returned an error: %v", err)
+	}
+	if decoded.ImageType != IMPNG || decoded.Image.Bounds() != imgr.Image.Bounds() {
+		t.Fatalf("NewImagerFromDataURI loaded a %s of %v", decoded.ImageType, decoded.Image.Bounds())
+	}
+
+	// Without padding and wrapped on several lines
+	payload := strings.TrimRight(strings.TrimPrefix(uri, "data:image/png;base64,"), "=")
+	wrapped := "data:image/png;base64," + payload[:20] + "\n" + payload[20:]
+	if _, err := NewImagerFromDataURI(wrapped); err != nil {
+		t.Fatalf("NewImagerFromDataURI returned an error for wrapped data: %v", err)
+	}
+
+	if _, err := NewImagerFromDataURI("image/png;base64,AAAA"); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("NewImagerFromDataURI returned %v without the data scheme", err)
+	}
+	if _, err := imgr.DataURI("svg"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("DataURI returned %v for an unknown format", err)
+	}
+}
@@ -0,0 +1,59 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// BitDepth returns the number of bits per channel of the image, 16 for the
+// 16-bit PNG and TIFF images and 8 otherwise. 16-bit images are saved as
+// such to PNG and TIFF, but the operations work on 8 bits so the depth
+// drops to 8 after any of them
+// i.e :
+// if imgr.BitDepth() == 16 { imgr.Save("master.tiff") }
+func (i *Imager) BitDepth() int {
+	if is16Bit(i.Image) {
+		return 16
+	}
+
+	return 8
+}
+
+// is16Bit reports whether img stores 16 bits per channel
+func is16Bit(img image.Image) bool {
+	switch img.(type) {
+	case *image.RGBA64, *image.NRGBA64, *image.Gray16, *image.Alpha16:
+		return true
+	}
+
+	return false
+}
+
+// cmykToNRGBA converts a CMYK image, such as a CMYK JPEG from a print
+// workflow, to RGB. image/jpeg already undoes the inversion of the Adobe
+// CMYK and YCCK JPEG images, the conversion itself is the naive one as the
+// CMYK profile is not applied
+func cmykToNRGBA(img *image.CMYK) *image.NRGBA {
+	dst := image.NewNRGBA(img.Rect)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		src := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		out := dst.Pix[y*dst.Stride : y*dst.Stride+img.Rect.Dx()*4]
+		for k := 0; k < len(src); k += 4 {
+			out[k], out[k+1], out[k+2] = color.CMYKToRGB(src[k], src[k+1], src[k+2], src[k+3])
+			out[k+3] = 0xff
+		}
+	}
+
+	return dst
+}
+
+// iccColorSpace returns the data color space of an ICC profile, such as RGB
+// or CMYK, or an empty string when profile is too short
+func iccColorSpace(profile []byte) string {
+	if len(profile) < 20 {
+		return ""
+	}
+
+	return strings.TrimSpace(string(profile[16:20]))
+}
@@ -0,0 +1,83 @@
+package imager
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func createDeepImage() *image.NRGBA64 {
+	img := image.NewNRGBA64(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetNRGBA64(x, y, color.NRGBA64{uint16(x*3000 + 1), uint16(y*6000 + 3), 0x1234, 0xffff})
+		}
+	}
+	return img
+}
+
+func TestBitDepth(t *testing.T) {
+	for _, opts := range []EncodeOptions{{}, {PNGInterlace: true}, {PNGAutoPalette: true}} {
+		imgr, _ := NewImager(createDeepImage())
+		imgr.ImageType = IMPNG
+		data, err := imgr.Bytes(opts)
+		if err != nil {
+			t.Fatalf("Bytes(%+v) returned an error: %v", opts, err)
+		}
+
+		decoded, err := NewImagerFromBytes(data)
+		if err != nil {
+			t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+		}
+		if depth := decoded.BitDepth(); depth != 16 {
+			t.Fatalf("Bytes(%+v) wrote a %d-bit image", opts, depth)
+		}
+		if c := color.NRGBA64Model.Convert(decoded.Image.At(7, 3)); c != (color.NRGBA64{21001, 18003, 0x1234, 0xffff}) {
+			t.Fatalf("Bytes(%+v) lost the precision of the pixels: %v", opts, c)
+		}
+
+		if depth := decoded.Resize(10, 5, MD_STRETCH).BitDepth(); depth != 8 {
+			t.Fatalf("BitDepth returned %d after a resize", depth)
+		}
+		if depth := decoded.Reset().BitDepth(); depth != 16 {
+			t.Fatalf("Reset did not restore the 16-bit image, got %d bits", depth)
+		}
+	}
+}
+
+func TestCMYKDecode(t *testing.T) {
+	img := image.NewCMYK(image.Rect(0, 0, 4, 4))
+	for k := 0; k < len(img.Pix); k += 4 {
+		img.Pix[k], img.Pix[k+1], img.Pix[k+2], img.Pix[k+3] = 0, 255, 255, 0
+	}
+
+	// A JPEG stream carrying a CMYK profile, as written by print workflows
+	profile := make([]byte, 128)
+	copy(profile[16:], "CMYK")
+	buf := bytes.NewBuffer(nil)
+	if err := jpeg.Encode(buf, createTestImage(), nil); err != nil {
+		t.Fatalf("jpeg.Encode returned an error: %v", err)
+	}
+
+	imgr := &Imager{}
+	imgr.setImage(img, IMJPEG, insertJPEGICC(buf.Bytes(), profile))
+	if imgr.ICCProfile != nil {
+		t.Fatalf("setImage kept the CMYK profile")
+	}
+	if _, ok := imgr.Image.(*image.NRGBA); !ok {
+		t.Fatalf("setImage kept a %T", imgr.Image)
+	}
+	if c := pixel(imgr.Image, 1, 1); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("setImage converted CMYK red to %v", c)
+	}
+
+	if space := iccColorSpace(profile); space != "CMYK" {
+		t.Fatalf("iccColorSpace returned %q", space)
+	}
+	copy(profile[16:], "RGB ")
+	if space := iccColorSpace(profile); space != "RGB" {
+		t.Fatalf("iccColorSpace returned %q", space)
+	}
+}
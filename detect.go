@@ -0,0 +1,130 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// DetectorFunc returns the regions of interest of an image, such as faces,
+// in the image coordinates
+type DetectorFunc func(img image.Image) ([]image.Rectangle, error)
+
+// CropAround crops the image to width x height centered on the regions found
+// by detector. When nothing is found the image is cropped with SmartCrop.
+// Detection runs on the first frame of animations. Sizes below 1 and a nil
+// detector record ErrInvalidArgument
+// i.e :
+// imgr.CropAround(200, 200, imager.SkinDetector)
+// imgr.CropAround(200, 200, func(img image.Image) ([]image.Rectangle, error) { return faces(img) })
+func (i *Imager) CropAround(width, height int, detector DetectorFunc) *Imager {
+	defer i.operation("CropAround")()
+
+	if width <= 0 || height <= 0 {
+		i.setErr(fmt.Errorf("%w: crop size %dx%d", ErrInvalidArgument, width, height))
+		return i
+	}
+	if detector == nil {
+		i.setErr(fmt.Errorf("%w: nil detector", ErrInvalidArgument))
+		return i
+	}
+	if i.err != nil {
+		return i
+	}
+
+	regions, err := detector(i.Image)
+	if err != nil {
+		i.setErr(err)
+		return i
+	}
+
+	bounds := i.Image.Bounds()
+	var focus image.Rectangle
+	for _, region := range regions {
+		focus = focus.Union(region.Intersect(bounds))
+	}
+	if focus.Empty() {
+		return i.SmartCrop(width, height)
+	}
+
+	width, height = min(width, bounds.Dx()), min(height, bounds.Dy())
+	center := image.Pt((focus.Min.X+focus.Max.X)/2, (focus.Min.Y+focus.Max.Y)/2)
+	x := min(max(center.X-width/2, bounds.Min.X), bounds.Max.X-width)
+	y := min(max(center.Y-height/2, bounds.Min.Y), bounds.Max.Y-height)
+
+	i.cropFocal(bounds, image.Rect(x, y, x+width, y+height))
+	return i.apply("CropAround", func(img image.Image) image.Image {
+		return imaging.Crop(img, image.Rect(x, y, x+width, y+height))
+	})
+}
+
+// skinDetectorSize is the size images are reduced to before skin detection
+const skinDetectorSize = 100
+
+// SkinDetector is a simple DetectorFunc returning the largest area with a
+// skin tone, a rough stand-in for a face detector
+// i.e :
+// imgr.CropAround(200, 200, imager.SkinDetector)
+func SkinDetector(img image.Image) ([]image.Rectangle, error) {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, nil
+	}
+
+	small := imaging.Fit(img, skinDetectorSize, skinDetectorSize, imaging.Box)
+	w, h := small.Bounds().Dx(), small.Bounds().Dy()
+	skin := make([]bool, w*h)
+	for k := range skin {
+		pix := small.Pix[k*4 : k*4+4]
+		skin[k] = pix[3] > 128 && skinScore(pix[0], pix[1], pix[2]) > 0
+	}
+
+	// Largest 4-connected area of skin pixels
+	var best image.Rectangle
+	bestSize := 0
+	seen := make([]bool, w*h)
+	for start := range skin {
+		if !skin[start] || seen[start] {
+			continue
+		}
+
+		size := 0
+		area := image.Rect(start%w, start/w, start%w+1, start/w+1)
+		stack := []int{start}
+		seen[start] = true
+		for len(stack) > 0 {
+			k := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := k%w, k/w
+			size++
+			area = area.Union(image.Rect(x, y, x+1, y+1))
+
+			for _, n := range [4]int{k - w, k + w, k - 1, k + 1} {
+				if n < 0 || n >= len(skin) || (n == k-1 && x == 0) || (n == k+1 && x == w-1) {
+					continue
+				}
+				if skin[n] && !seen[n] {
+					seen[n] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+
+		if size > bestSize {
+			best, bestSize = area, size
+		}
+	}
+	if bestSize == 0 {
+		return nil, nil
+	}
+
+	// Back to the image coordinates
+	scale := func(v, from, to int) int { return v * to / from }
+	region := image.Rect(
+		scale(best.Min.X, w, bounds.Dx()), scale(best.Min.Y, h, bounds.Dy()),
+		scale(best.Max.X, w, bounds.Dx()), scale(best.Max.Y, h, bounds.Dy()),
+	).Add(bounds.Min)
+
+	return []image.Rectangle{region}, nil
+}
@@ -0,0 +1,90 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCropAround(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	img.Set(170, 20, color.White)
+
+	imgr, _ := NewImager(img)
+	imgr.CropAround(50, 50, func(image.Image) ([]image.Rectangle, error) {
+		return []image.Rectangle{image.Rect(160, 10, 180, 30)}, nil
+	})
+
+	if imgr.Image.Bounds().Size() != image.Pt(50, 50) {
+		t.Fatalf("CropAround returned %v", imgr.Image.Bounds())
+	}
+	// The region center (170, 20) is clamped within the image, the window is
+	// [145, 195) x [0, 50)
+	if c := pixel(imgr.Image, 25, 20); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("CropAround did not center the window on the region")
+	}
+
+	boom := errors.New("boom")
+	imgr.CropAround(10, 10, func(image.Image) ([]image.Rectangle, error) { return nil, boom })
+	if !errors.Is(imgr.Err(), boom) {
+		t.Fatalf("CropAround did not record the detector error")
+	}
+}
+
+func TestSkinDetector(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			img.Set(x, y, color.RGBA{30, 60, 30, 255})
+		}
+	}
+	for y := 120; y < 180; y++ {
+		for x := 30; x < 90; x++ {
+			img.Set(x, y, color.RGBA{200, 146, 113, 255})
+		}
+	}
+
+	regions, err := SkinDetector(img)
+	if err != nil || len(regions) != 1 {
+		t.Fatalf("SkinDetector returned %v, %v", regions, err)
+	}
+	if !regions[0].Overlaps(image.Rect(30, 120, 90, 180)) || regions[0].Dx() > 80 {
+		t.Fatalf("SkinDetector found %v", regions[0])
+	}
+
+	imgr, _ := NewImager(img)
+	imgr.CropAround(100, 100, SkinDetector)
+	if c := pixel(imgr.Image, 50, 50); c != (color.NRGBA{200, 146, 113, 255}) {
+		t.Fatalf("CropAround did not center on the skin area: %v", c)
+	}
+}
+
+func TestCropAroundInvalid(t *testing.T) {
+	called := false
+	detector := func(image.Image) ([]image.Rectangle, error) {
+		called = true
+		return []image.Rectangle{image.Rect(10, 10, 20, 20)}, nil
+	}
+
+	for name, crop := range map[string]func(i *Imager) *Imager{
+		"negative width": func(i *Imager) *Imager { return i.CropAround(-5, 10, detector) },
+		"zero height":    func(i *Imager) *Imager { return i.CropAround(10, 0, detector) },
+		"nil detector":   func(i *Imager) *Imager { return i.CropAround(10, 10, nil) },
+	} {
+		imgr, _ := NewImager(createTestImage())
+		if err := crop(imgr).Err(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+		if imgr.Image.Bounds().Dx() != 100 {
+			t.Errorf("%s: the image was changed", name)
+		}
+	}
+
+	// The detector is not run after a failed operation
+	imgr, _ := NewImager(createTestImage())
+	imgr.Crop(500, 500, 0, 0).CropAround(10, 10, detector)
+	if called || !errors.Is(imgr.Err(), ErrOutOfBounds) {
+		t.Errorf("CropAround ran the detector after a failed operation: %v", imgr.Err())
+	}
+}
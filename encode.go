@@ -0,0 +1,268 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"time"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// EncodeOptions holds the options used to encode the image
+type EncodeOptions struct {
+	// JPEGQuality ranges from 1 to 100, zero uses Imager.JPEGQuality
+	JPEGQuality int
+
+	// JPEGProgressive encodes JPEG images progressively, they are displayed
+	// at a low quality first then refined while loading
+	JPEGProgressive bool
+
+	// JPEGSubsampling is the chroma subsampling of JPEG images, 4:2:0 by
+	// default. Other values than the Subsampling constants fail the encoding
+	// with ErrInvalidArgument
+	JPEGSubsampling JPEGSubsampling
+
+	// PNGCompression is the compression level of PNG images, such as
+	// png.BestSpeed or png.BestCompression
+	PNGCompression png.CompressionLevel
+
+	// PNGInterlace writes Adam7 interlaced PNG images, displayed at a low
+	// resolution first then refined while loading
+	PNGInterlace bool
+
+	// PNGAutoPalette writes PNG images with at most 256 colors as paletted
+	// PNG8 images, much smaller for icons and screenshots. No color is lost
+	PNGAutoPalette bool
+
+	// GIFNumColors is the maximum number of colors of GIF images, from 1 to
+	// 256. Zero means 256
+	GIFNumColors int
+
+	// GIFNoDither disables the Floyd-Steinberg dithering of GIF images, which
+	// smooths gradients but adds noise to flat areas. The palette is built
+	// with the median cut algorithm either way
+	GIFNoDither bool
+
+	// AVIFQuality ranges from 1 to 100, zero means 60. AVIF needs an encoder,
+	// see the imageravif package
+	AVIFQuality int
+
+	// AVIFSpeed trades the encoding time for the size of AVIF images, from 1,
+	// the slowest and smallest, to 10. Zero means 6
+	AVIFSpeed int
+
+	// Background overrides Imager.Background when set
+	Background color.Color
+
+	// FallbackFormat is the format used when the requested one can't be
+	// encoded, such as an empty or HEIF ImageType. Without it encoding
+	// returns ErrUnsupportedFormat
+	FallbackFormat string
+
+	// StripMetadata drops all the metadata, whatever the metadata policy
+	StripMetadata bool
+
+	// Metadata overrides the metadata policy of the Imager when set
+	Metadata *MetadataPolicy
+}
+
+// mergeEncodeOptions returns the last of opts, or the zero options
+func mergeEncodeOptions(opts []EncodeOptions) EncodeOptions {
+	var merged EncodeOptions
+	for _, opt := range opts {
+		merged = opt
+	}
+
+	return merged
+}
+
+// Encode writes the image to w encoded as format, one of the IM* constants.
+// An empty format uses ImageType
+// i.e :
+// err := imgr.Encode(w, "")
+// err := imgr.Encode(w, imager.IMJPEG, imager.EncodeOptions{JPEGQuality: 75})
+func (i *Imager) Encode(w io.Writer, format string, opts ...EncodeOptions) error {
+	if format == "" {
+		format = i.ImageType
+	}
+
+	return i.encode(w, format, mergeEncodeOptions(opts))
+}
+
+// encode writes the image to w encoded as imageType, or returns the error
+// recorded by the operations
+func (i *Imager) encode(w io.Writer, imageType string, opts EncodeOptions) error {
+	if i.err != nil {
+		return i.err
+	}
+	if !encodable(imageType) {
+		if err := i.checkEncodable(imageType, opts); err != nil {
+			return err
+		}
+		imageType = opts.FallbackFormat
+	}
+
+	obs := currentObserver()
+	if obs == nil {
+		return i.encodeAs(w, imageType, opts)
+	}
+
+	cw := &countingWriter{w: w}
+	start := time.Now()
+	err := i.encodeAs(cw, imageType, opts)
+	obs.OnEncode(EncodeEvent{Format: imageType, Bytes: cw.n, Duration: time.Since(start), Err: err})
+
+	return err
+}
+
+// encodeAs writes the image to w as imageType, which can be encoded
+func (i *Imager) encodeAs(w io.Writer, imageType string, opts EncodeOptions) error {
+	switch imageType {
+	case IMJPG, IMJPEG:
+		if opts.JPEGSubsampling < Subsampling420 || opts.JPEGSubsampling > Subsampling444 {
+			return fmt.Errorf("%w: JPEG subsampling %d", ErrInvalidArgument, opts.JPEGSubsampling)
+		}
+
+		quality := opts.JPEGQuality
+		if quality <= 0 {
+			quality = i.jpegQuality()
+		}
+
+		// JPEG has no alpha, transparent pixels would turn black
+		img := i.Image
+		if !isOpaque(img) {
+			bounds := img.Bounds()
+			scratch := getScratch(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+			defer putScratch(scratch)
+			img = flattenInto(scratch, img, i.background(opts))
+		}
+
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if opts.JPEGProgressive || opts.JPEGSubsampling != Subsampling420 {
+			if err := encodeJPEG(buf, img, quality, opts.JPEGSubsampling, opts.JPEGProgressive); err != nil {
+				return err
+			}
+		} else if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return err
+		}
+
+		data := insertJPEGICC(buf.Bytes(), i.ICCProfile)
+		_, err := w.Write(i.insertJPEGMetadata(data, i.metadataPolicy(opts)))
+		return err
+	case IMPNG:
+		if len(i.ICCProfile) > 0 {
+			w = &pngICCWriter{w: w, profile: i.ICCProfile}
+		}
+		if i.Animation != nil {
+			return encodeAPNG(w, i.Animation, opts)
+		}
+
+		return encodePNG(w, i.Image, opts)
+	case IMGIF:
+		numColors := opts.GIFNumColors
+		if numColors <= 0 || numColors > 256 {
+			numColors = 256
+		}
+		if i.Animation != nil {
+			return encodeGIFAnimation(w, i.Animation, numColors, !opts.GIFNoDither)
+		}
+		return gif.Encode(w, gifFrame(i.Image, numColors, !opts.GIFNoDither), nil)
+	case IMWEBP:
+		if i.Animation != nil {
+			return encodeWebPAnimation(w, i.Animation)
+		}
+		return encodeWebP(w, i.Image)
+	case IMTIFF, IMTIF:
+		tiffOpts := &tiff.Options{Compression: tiff.Deflate, Predictor: true}
+		policy := i.metadataPolicy(opts)
+		if !policy.KeepEXIF || len(i.EXIF) == 0 {
+			return tiff.Encode(w, i.Image, tiffOpts)
+		}
+
+		// The EXIF fields join the fields of the image
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if err := tiff.Encode(buf, i.Image, tiffOpts); err != nil {
+			return err
+		}
+		_, err := w.Write(insertTIFFEXIF(buf.Bytes(), filterEXIF(i.EXIF, policy.EXIFTags)))
+		return err
+	case IMBMP:
+		return bmp.Encode(w, i.Image)
+	case IMAVIF:
+		return encodeAVIF(w, i.Image, opts)
+	}
+
+	if encode := registeredEncoder(imageType); encode != nil {
+		return encode(w, i.Image, opts)
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, imageType)
+}
+
+// encodable reports whether imager can write format, AVIF only once an
+// encoder is registered
+func encodable(format string) bool {
+	switch format {
+	case IMJPG, IMJPEG, IMPNG, IMGIF, IMWEBP, IMTIFF, IMTIF, IMBMP:
+		return true
+	}
+
+	return registeredEncoder(format) != nil
+}
+
+// checkEncodable returns ErrUnsupportedFormat when neither format nor the
+// fallback format of opts can be encoded
+func (i *Imager) checkEncodable(format string, opts EncodeOptions) error {
+	if encodable(format) || encodable(opts.FallbackFormat) {
+		return nil
+	}
+	switch format {
+	case "":
+		return fmt.Errorf("%w: no image type", ErrUnsupportedFormat)
+	case IMAVIF:
+		return errAVIFEncoder
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// metadataPolicy returns the metadata policy used with opts
+func (i *Imager) metadataPolicy(opts EncodeOptions) MetadataPolicy {
+	switch {
+	case opts.StripMetadata:
+		return MetadataStripAll
+	case opts.Metadata != nil:
+		return *opts.Metadata
+	}
+
+	return i.metadata
+}
+
+// background returns the background of the formats without alpha
+func (i *Imager) background(opts EncodeOptions) color.Color {
+	switch {
+	case opts.Background != nil:
+		return opts.Background
+	case i.Background != nil:
+		return i.Background
+	}
+
+	return defaultBackground
+}
+
+// jpegQuality returns the configured JPEG quality or the default one
+func (i *Imager) jpegQuality() int {
+	if i.JPEGQuality <= 0 {
+		return 100
+	}
+
+	return i.JPEGQuality
+}
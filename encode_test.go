@@ -0,0 +1,158 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+// createGradientImage creates a 100x100 image with many distinct colors
+func createGradientImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 2), uint8(y * 2), uint8(x + y), 255})
+		}
+	}
+	return img
+}
+
+func TestEncodeOptions(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+
+	imgr.ImageType = IMJPEG
+	best, err := imgr.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	low, err := imgr.Bytes(EncodeOptions{JPEGQuality: 20})
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if len(low) >= len(best) {
+		t.Fatalf("JPEGQuality option did not reduce the output size: %d >= %d", len(low), len(best))
+	}
+
+	imgr.ImageType = IMPNG
+	raw, _ := imgr.Bytes(EncodeOptions{PNGCompression: png.NoCompression})
+	small, _ := imgr.Bytes(EncodeOptions{PNGCompression: png.BestCompression})
+	if len(small) >= len(raw) {
+		t.Fatalf("PNGCompression option did not reduce the output size: %d >= %d", len(small), len(raw))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := imgr.Encode(buf, IMGIF, EncodeOptions{GIFNumColors: 8}); err != nil {
+		t.Fatalf("Encode returned an error: %v", err)
+	}
+	decoded, err := gif.Decode(buf)
+	if err != nil {
+		t.Fatalf("failed to decode gif: %v", err)
+	}
+	if n := len(decoded.(*image.Paletted).Palette); n > 8 {
+		t.Fatalf("GIFNumColors option produced a palette of %d colors", n)
+	}
+}
+
+func TestEncodeGIFDither(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+
+	// Dithering scatters the palette colors, so neighbours differ more often
+	changes := func(opts EncodeOptions) int {
+		buf := new(bytes.Buffer)
+		if err := imgr.Encode(buf, IMGIF, opts); err != nil {
+			t.Fatalf("Encode returned an error: %v", err)
+		}
+		decoded, err := gif.Decode(buf)
+		if err != nil {
+			t.Fatalf("failed to decode gif: %v", err)
+		}
+
+		paletted := decoded.(*image.Paletted)
+		n := 0
+		for y := 0; y < 100; y++ {
+			for x := 1; x < 100; x++ {
+				if paletted.ColorIndexAt(x, y) != paletted.ColorIndexAt(x-1, y) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	dithered := changes(EncodeOptions{GIFNumColors: 8})
+	plain := changes(EncodeOptions{GIFNumColors: 8, GIFNoDither: true})
+	if plain*2 > dithered {
+		t.Fatalf("GIFNoDither did not disable dithering: %d changes, %d dithered", plain, dithered)
+	}
+}
+
+func TestSaveTIFFAndBMP(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+
+	for _, name := range []string{"scan.tiff", "scan.tif", "scan.bmp"} {
+		location := filepath.Join(t.TempDir(), name)
+		if err := imgr.Save(location); err != nil {
+			t.Fatalf("Save(%s) returned an error: %v", name, err)
+		}
+
+		saved, err := NewImagerFromFile(location)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", name, err)
+		}
+
+		want := IMTIFF
+		if name == "scan.bmp" {
+			want = IMBMP
+		}
+		if saved.ImageType != want {
+			t.Fatalf("%s was loaded as %s", name, saved.ImageType)
+		}
+
+		// Both formats are lossless, round tripping Bytes keeps every pixel
+		data, err := saved.Bytes()
+		if err != nil {
+			t.Fatalf("Bytes returned an error for %s: %v", name, err)
+		}
+		decoded, err := NewImagerFromBytes(data)
+		if err != nil {
+			t.Fatalf("failed to decode %s bytes: %v", name, err)
+		}
+		if c, _ := imgr.Compare(decoded); c.MAE != 0 {
+			t.Fatalf("%s round trip changed the pixels, MAE %v", name, c.MAE)
+		}
+	}
+}
+
+func TestBytesUnsupportedFormat(t *testing.T) {
+	for _, imageType := range []string{"", "heif", "xyz"} {
+		imgr, _ := NewImager(createTestImage())
+		imgr.ImageType = imageType
+
+		data, err := imgr.Bytes()
+		if !errors.Is(err, ErrUnsupportedFormat) || len(data) != 0 {
+			t.Fatalf("Bytes returned %d bytes and %v for %q, want ErrUnsupportedFormat", len(data), err, imageType)
+		}
+
+		data, err = imgr.Bytes(EncodeOptions{FallbackFormat: IMPNG})
+		if err != nil {
+			t.Fatalf("Bytes returned an error with a fallback format: %v", err)
+		}
+		if _, format, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || format != IMPNG {
+			t.Fatalf("Bytes did not encode to the fallback format: %q %v", format, err)
+		}
+	}
+
+	dir := t.TempDir()
+	imgr, _ := NewImager(createTestImage())
+	if err := imgr.Save(filepath.Join(dir, "image")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Save returned %v, want ErrUnsupportedFormat", err)
+	}
+	if err := imgr.Save(filepath.Join(dir, "image"), EncodeOptions{FallbackFormat: IMJPEG}); err != nil {
+		t.Fatalf("Save returned an error with a fallback format: %v", err)
+	}
+}
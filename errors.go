@@ -0,0 +1,35 @@
+package imager
+
+import (
+	"errors"
+)
+
+var (
+	// ErrSizeMismatch is returned when two images must have the same dimensions
+	ErrSizeMismatch = errors.New("imager: images have different dimensions")
+
+	// ErrInvalidRatio is recorded when an aspect ratio component is not positive
+	ErrInvalidRatio = errors.New("imager: invalid aspect ratio")
+
+	// ErrUnknownFormat is returned when data is not a supported image
+	ErrUnknownFormat = errors.New("imager: unknown image format")
+
+	// ErrCorrupt is returned when sanitized data is truncated or malformed
+	ErrCorrupt = errors.New("imager: corrupt image data")
+
+	// ErrUnsupportedFormat is returned when encoding to a format imager can't
+	// write, such as an empty ImageType
+	ErrUnsupportedFormat = errors.New("imager: unsupported encoding format")
+
+	// ErrTooLarge is returned when an image exceeds the allowed dimensions
+	ErrTooLarge = errors.New("imager: image too large")
+
+	// ErrInvalidArgument is returned when a parameter is out of its valid range
+	ErrInvalidArgument = errors.New("imager: invalid argument")
+
+	// ErrOutOfBounds is recorded when a region lies outside of the image
+	ErrOutOfBounds = errors.New("imager: region out of the image bounds")
+
+	// ErrNoMetadata is returned when the source image has no EXIF data
+	ErrNoMetadata = errors.New("imager: no metadata")
+)
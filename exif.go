@@ -0,0 +1,573 @@
+package imager
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+// errInvalidTIFF is returned when EXIF data is not a valid TIFF structure
+var errInvalidTIFF = errors.New("imager: invalid exif data")
+
+// AutoOrient rotates and flips the image so it is displayed upright
+// according to its EXIF orientation. The orientation stored in EXIF is reset
+// so the image is not rotated twice, by a viewer or another call
+// i.e :
+// imgr.AutoOrient()
+func (i *Imager) AutoOrient() *Imager {
+	tiff, err := newTIFFReader(i.EXIF)
+	if err != nil {
+		return i
+	}
+
+	entry, ok := tiff.lookup(TagOrientation)
+	if !ok {
+		return i
+	}
+
+	var op func(image.Image) *image.NRGBA
+	var point func(x, y float64) (float64, float64)
+	switch tiff.uint(entry, 0) {
+	case 2:
+		op, point = imaging.FlipH, flipHPoint
+	case 3:
+		op, point = imaging.Rotate180, rotate180Point
+	case 4:
+		op, point = imaging.FlipV, flipVPoint
+	case 5:
+		op, point = imaging.Transpose, transposePoint
+	case 6:
+		op, point = imaging.Rotate270, rotate270Point
+	case 7:
+		op, point = imaging.Transverse, transversePoint
+	case 8:
+		op, point = imaging.Rotate90, rotate90Point
+	default:
+		return i
+	}
+
+	i.moveFocal(point)
+	i.transform("AutoOrient", op)
+
+	// Mark the image as upright
+	i.EXIF = append([]byte(nil), i.EXIF...)
+	tiff.order.PutUint16(i.EXIF[entry.valueOffset:], 1)
+
+	return i
+}
+
+// tiffReader reads the IFD entries of EXIF (TIFF) data
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// tiffEntry is an entry of an IFD
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+
+	// valueOffset is the position of the value within the TIFF data
+	valueOffset int
+}
+
+// tiffTypeSizes holds the size of the TIFF field types
+var tiffTypeSizes = map[uint16]int{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8, 13: 4,
+}
+
+// newTIFFReader checks the TIFF header of data
+func newTIFFReader(data []byte) (*tiffReader, error) {
+	if len(data) < 8 {
+		return nil, errInvalidTIFF
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errInvalidTIFF
+	}
+
+	if order.Uint16(data[2:]) != 42 {
+		return nil, errInvalidTIFF
+	}
+
+	return &tiffReader{data: data, order: order}, nil
+}
+
+// ifd returns the entries of the IFD at offset and the offset of the next IFD
+func (r *tiffReader) ifd(offset uint32) ([]tiffEntry, uint32, error) {
+	pos := int(offset)
+	if offset == 0 || pos+2 > len(r.data) {
+		return nil, 0, errInvalidTIFF
+	}
+
+	count := int(r.order.Uint16(r.data[pos:]))
+	pos += 2
+	if pos+count*12+4 > len(r.data) {
+		return nil, 0, errInvalidTIFF
+	}
+
+	entries := make([]tiffEntry, 0, count)
+	for k := 0; k < count; k, pos = k+1, pos+12 {
+		entry := tiffEntry{
+			tag:         r.order.Uint16(r.data[pos:]),
+			typ:         r.order.Uint16(r.data[pos+2:]),
+			count:       r.order.Uint32(r.data[pos+4:]),
+			valueOffset: pos + 8,
+		}
+
+		size, ok := tiffTypeSizes[entry.typ]
+		if !ok {
+			continue
+		}
+		length := int64(size) * int64(entry.count)
+		if length > 4 {
+			entry.valueOffset = int(r.order.Uint32(r.data[pos+8:]))
+		}
+		if length > int64(len(r.data)) || entry.valueOffset+int(length) > len(r.data) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, r.order.Uint32(r.data[pos:]), nil
+}
+
+// lookup returns the entry of tag in the first IFD
+func (r *tiffReader) lookup(tag uint16) (tiffEntry, bool) {
+	entries, _, err := r.ifd(r.order.Uint32(r.data[4:]))
+	if err != nil {
+		return tiffEntry{}, false
+	}
+
+	return find(entries, tag)
+}
+
+// uint returns the n-th value of a BYTE, SHORT, LONG or IFD entry
+func (r *tiffReader) uint(e tiffEntry, n int) uint32 {
+	if uint32(n) >= e.count {
+		return 0
+	}
+
+	switch e.typ {
+	case 1, 7:
+		return uint32(r.data[e.valueOffset+n])
+	case 3:
+		return uint32(r.order.Uint16(r.data[e.valueOffset+2*n:]))
+	case 4, 13:
+		return r.order.Uint32(r.data[e.valueOffset+4*n:])
+	}
+
+	return 0
+}
+
+// EXIF tags of the first IFD, TagExifIFD and TagGPSIFD point to the camera
+// settings and the location
+const (
+	TagImageDescription uint16 = 0x010E
+	TagMake             uint16 = 0x010F
+	TagModel            uint16 = 0x0110
+	TagOrientation      uint16 = 0x0112
+	TagSoftware         uint16 = 0x0131
+	TagDateTime         uint16 = 0x0132
+	TagArtist           uint16 = 0x013B
+	TagCopyright        uint16 = 0x8298
+	TagExifIFD          uint16 = 0x8769
+	TagGPSIFD           uint16 = 0x8825
+)
+
+// EXIF tags of the sub IFDs
+const (
+	tagInteropIFD       = 0xA005
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xA002
+	tagPixelYDimension  = 0xA003
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+	tagGPSAltitudeRef  = 0x0005
+	tagGPSAltitude     = 0x0006
+)
+
+// exifTimeLayout is the layout of the EXIF date and time fields
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// Metadata holds the EXIF fields of an image, empty fields were not found
+type Metadata struct {
+	Make      string
+	Model     string
+	Software  string
+	Artist    string
+	Copyright string
+
+	// DateTime is when the picture was taken, or last modified when the
+	// original date is missing. EXIF has no time zone, it is in UTC
+	DateTime time.Time
+
+	// Orientation is the EXIF orientation, from 1 to 8
+	Orientation int
+
+	// Width and Height are the dimensions recorded by the camera
+	Width  int
+	Height int
+
+	// GPS is the location of the picture, nil when not recorded
+	GPS *GPS
+}
+
+// GPS is a location recorded in EXIF
+type GPS struct {
+	// Latitude and Longitude in decimal degrees, negative south and west
+	Latitude  float64
+	Longitude float64
+
+	// Altitude in meters, negative below sea level
+	Altitude float64
+}
+
+// Metadata parses the EXIF data of the source image
+// i.e :
+// meta, err := imgr.Metadata()
+// fmt.Println(meta.Model, meta.DateTime)
+func (i *Imager) Metadata() (*Metadata, error) {
+	if len(i.EXIF) == 0 {
+		return nil, ErrNoMetadata
+	}
+
+	tiff, err := newTIFFReader(i.EXIF)
+	if err != nil {
+		return nil, err
+	}
+
+	ifd0, _, err := tiff.ifd(tiff.order.Uint32(tiff.data[4:]))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Metadata{
+		Make:        tiff.string(ifd0, TagMake),
+		Model:       tiff.string(ifd0, TagModel),
+		Software:    tiff.string(ifd0, TagSoftware),
+		Artist:      tiff.string(ifd0, TagArtist),
+		Copyright:   tiff.string(ifd0, TagCopyright),
+		Orientation: int(tiff.value(ifd0, TagOrientation)),
+	}
+	meta.DateTime, _ = time.Parse(exifTimeLayout, tiff.string(ifd0, TagDateTime))
+
+	if offset := tiff.value(ifd0, TagExifIFD); offset != 0 {
+		if exifIFD, _, err := tiff.ifd(offset); err == nil {
+			if original, err := time.Parse(exifTimeLayout, tiff.string(exifIFD, tagDateTimeOriginal)); err == nil {
+				meta.DateTime = original
+			}
+			meta.Width = int(tiff.value(exifIFD, tagPixelXDimension))
+			meta.Height = int(tiff.value(exifIFD, tagPixelYDimension))
+		}
+	}
+
+	if offset := tiff.value(ifd0, TagGPSIFD); offset != 0 {
+		if gpsIFD, _, err := tiff.ifd(offset); err == nil {
+			meta.GPS = tiff.gps(gpsIFD)
+		}
+	}
+
+	return meta, nil
+}
+
+// gps returns the location of a GPS IFD, nil without coordinates
+func (r *tiffReader) gps(entries []tiffEntry) *GPS {
+	coordinate := func(tag, refTag uint16, negative string) (float64, bool) {
+		entry, ok := find(entries, tag)
+		if !ok || entry.count < 3 {
+			return 0, false
+		}
+
+		value := r.rational(entry, 0) + r.rational(entry, 1)/60 + r.rational(entry, 2)/3600
+		if r.string(entries, refTag) == negative {
+			value = -value
+		}
+		return value, true
+	}
+
+	latitude, okLat := coordinate(tagGPSLatitude, tagGPSLatitudeRef, "S")
+	longitude, okLon := coordinate(tagGPSLongitude, tagGPSLongitudeRef, "W")
+	if !okLat || !okLon {
+		return nil
+	}
+
+	gps := &GPS{Latitude: latitude, Longitude: longitude}
+	if entry, ok := find(entries, tagGPSAltitude); ok {
+		gps.Altitude = r.rational(entry, 0)
+		if r.value(entries, tagGPSAltitudeRef) == 1 {
+			gps.Altitude = -gps.Altitude
+		}
+	}
+
+	return gps
+}
+
+// find returns the entry of tag
+func find(entries []tiffEntry, tag uint16) (tiffEntry, bool) {
+	for _, entry := range entries {
+		if entry.tag == tag {
+			return entry, true
+		}
+	}
+
+	return tiffEntry{}, false
+}
+
+// value returns the first integer value of tag, 0 when missing
+func (r *tiffReader) value(entries []tiffEntry, tag uint16) uint32 {
+	entry, ok := find(entries, tag)
+	if !ok {
+		return 0
+	}
+
+	return r.uint(entry, 0)
+}
+
+// string returns the ASCII value of tag, empty when missing
+func (r *tiffReader) string(entries []tiffEntry, tag uint16) string {
+	entry, ok := find(entries, tag)
+	if !ok || entry.typ != 2 {
+		return ""
+	}
+
+	value := r.data[entry.valueOffset : entry.valueOffset+int(entry.count)]
+	return strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+}
+
+// rational returns the n-th value of a RATIONAL entry
+func (r *tiffReader) rational(e tiffEntry, n int) float64 {
+	if e.typ != 5 || uint32(n) >= e.count {
+		return 0
+	}
+
+	num := r.order.Uint32(r.data[e.valueOffset+8*n:])
+	den := r.order.Uint32(r.data[e.valueOffset+8*n+4:])
+	if den == 0 {
+		return 0
+	}
+
+	return float64(num) / float64(den)
+}
+
+// tiffField is an IFD entry to write, sub holds the IFD a pointer tag points to
+type tiffField struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+	sub   []tiffField
+}
+
+// isIFDPointer reports whether tag holds the offset of a sub IFD
+func isIFDPointer(tag uint16) bool {
+	return tag == TagExifIFD || tag == TagGPSIFD || tag == tagInteropIFD
+}
+
+// fields returns the fields of the IFD at offset along with their sub IFDs
+func (r *tiffReader) fields(offset uint32, depth int) ([]tiffField, error) {
+	entries, _, err := r.ifd(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]tiffField, 0, len(entries))
+	for _, entry := range entries {
+		if isIFDPointer(entry.tag) {
+			if depth > 2 {
+				continue
+			}
+			sub, err := r.fields(r.uint(entry, 0), depth+1)
+			if err != nil {
+				continue
+			}
+			fields = append(fields, tiffField{tag: entry.tag, typ: 4, count: 1, sub: sub})
+			continue
+		}
+
+		length := tiffTypeSizes[entry.typ] * int(entry.count)
+		fields = append(fields, tiffField{
+			tag:   entry.tag,
+			typ:   entry.typ,
+			count: entry.count,
+			value: r.data[entry.valueOffset : entry.valueOffset+length],
+		})
+	}
+
+	return fields, nil
+}
+
+// encodeTIFF returns TIFF data holding a single IFD made of fields
+func encodeTIFF(order binary.ByteOrder, fields []tiffField) []byte {
+	w := &tiffWriter{order: order}
+	if order == binary.BigEndian {
+		w.buf = []byte{'M', 'M', 0, 42, 0, 0, 0, 8}
+	} else {
+		w.buf = []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	}
+
+	w.writeIFD(fields)
+	return w.buf
+}
+
+// tiffWriter appends IFDs to a TIFF buffer
+type tiffWriter struct {
+	buf   []byte
+	order binary.ByteOrder
+}
+
+// writeIFD appends an IFD, then its values and sub IFDs, and returns its offset
+func (w *tiffWriter) writeIFD(fields []tiffField) uint32 {
+	fields = append([]tiffField(nil), fields...)
+	sort.Slice(fields, func(a, b int) bool { return fields[a].tag < fields[b].tag })
+
+	w.align()
+	offset := len(w.buf)
+	w.buf = append(w.buf, make([]byte, 2+12*len(fields)+4)...)
+	w.order.PutUint16(w.buf[offset:], uint16(len(fields)))
+
+	for k, field := range fields {
+		pos := offset + 2 + 12*k
+		w.order.PutUint16(w.buf[pos:], field.tag)
+		w.order.PutUint16(w.buf[pos+2:], field.typ)
+		w.order.PutUint32(w.buf[pos+4:], field.count)
+
+		value := field.value
+		if field.sub != nil {
+			value = make([]byte, 4)
+			w.order.PutUint32(value, w.writeIFD(field.sub))
+		}
+		if len(value) > 4 {
+			w.align()
+			w.order.PutUint32(w.buf[pos+8:], uint32(len(w.buf)))
+			w.buf = append(w.buf, value...)
+		} else {
+			copy(w.buf[pos+8:pos+12], value)
+		}
+	}
+
+	return uint32(offset)
+}
+
+// align pads the buffer to a word boundary as required by TIFF
+func (w *tiffWriter) align() {
+	if len(w.buf)%2 == 1 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// asciiField returns an ASCII field holding value
+func asciiField(tag uint16, value string) tiffField {
+	return tiffField{tag: tag, typ: 2, count: uint32(len(value) + 1), value: append([]byte(value), 0)}
+}
+
+// setEXIFFields returns EXIF data with the ASCII fields of tags set in the
+// first IFD, the empty values removing their tag. The other fields of data
+// are kept, except the embedded thumbnail
+func setEXIFFields(data []byte, tags map[uint16]string) []byte {
+	var order binary.ByteOrder = binary.LittleEndian
+	var fields []tiffField
+	if tiff, err := newTIFFReader(data); err == nil {
+		order = tiff.order
+		// Invalid data is replaced
+		fields, _ = tiff.fields(tiff.order.Uint32(tiff.data[4:]), 0)
+	}
+
+	fields = slices.DeleteFunc(fields, func(field tiffField) bool {
+		_, ok := tags[field.tag]
+		return ok
+	})
+	for tag, value := range tags {
+		if value != "" {
+			fields = append(fields, asciiField(tag, value))
+		}
+	}
+
+	return encodeTIFF(order, fields)
+}
+
+// insertTIFFEXIF adds the fields of the first IFD of exif, and its sub IFDs,
+// to the first IFD of the TIFF image data. The fields describing the image
+// are the ones of data. The new IFD is appended, the rest of data is left as
+// is
+func insertTIFFEXIF(data, exif []byte) []byte {
+	img, err := newTIFFReader(data)
+	if err != nil {
+		return data
+	}
+	fields, err := img.fields(img.order.Uint32(img.data[4:]), 0)
+	if err != nil {
+		return data
+	}
+	src, err := newTIFFReader(exif)
+	if err != nil {
+		return data
+	}
+	extra, err := src.fields(src.order.Uint32(src.data[4:]), 0)
+	if err != nil {
+		return data
+	}
+
+	for _, field := range extra {
+		if !slices.ContainsFunc(fields, func(f tiffField) bool { return f.tag == field.tag }) {
+			if src.order != img.order {
+				field = swapFieldOrder(field)
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	// Appending to a clipped slice leaves data untouched
+	w := &tiffWriter{buf: slices.Clip(data), order: img.order}
+	offset := w.writeIFD(fields)
+	w.order.PutUint32(w.buf[4:], offset)
+
+	return w.buf
+}
+
+// swapFieldOrder returns field, and its sub IFD, with the values converted
+// to the other byte order
+func swapFieldOrder(field tiffField) tiffField {
+	if field.sub != nil {
+		sub := make([]tiffField, len(field.sub))
+		for k, f := range field.sub {
+			sub[k] = swapFieldOrder(f)
+		}
+		field.sub = sub
+		return field
+	}
+
+	// Rationals are pairs of 4 byte values, doubles single 8 byte values
+	size := tiffTypeSizes[field.typ]
+	switch field.typ {
+	case 5, 10:
+		size = 4
+	case 1, 2, 6, 7:
+		return field
+	}
+
+	value := slices.Clone(field.value)
+	for k := 0; k+size <= len(value); k += size {
+		slices.Reverse(value[k : k+size])
+	}
+	field.value = value
+
+	return field
+}
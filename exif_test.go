@@ -0,0 +1,216 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"testing"
+	"time"
+)
+
+// createOrientationEXIF returns little endian EXIF data holding orientation
+func createOrientationEXIF(orientation uint16) []byte {
+	exif := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00}
+	exif = binary.LittleEndian.AppendUint16(exif, 1)
+	exif = binary.LittleEndian.AppendUint16(exif, TagOrientation)
+	exif = binary.LittleEndian.AppendUint16(exif, 3)
+	exif = binary.LittleEndian.AppendUint32(exif, 1)
+	exif = binary.LittleEndian.AppendUint16(exif, orientation)
+	exif = binary.LittleEndian.AppendUint16(exif, 0)
+
+	return binary.LittleEndian.AppendUint32(exif, 0)
+}
+
+// createOrientedJPEG returns a 60x30 JPEG, left half white and right half
+// black, tagged with orientation
+func createOrientedJPEG(t *testing.T, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 60, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 60; x++ {
+			if x < 30 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	return insertJPEGSegment(buf.Bytes(), 0xE1, append([]byte(exifHeader), createOrientationEXIF(orientation)...))
+}
+
+func TestAutoOrient(t *testing.T) {
+	// Orientation 6 means the image must be rotated 90 degrees clockwise
+	imgr, err := NewImagerFromBytes(createOrientedJPEG(t, 6), WithAutoOrient())
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+
+	if imgr.Image.Bounds().Dx() != 30 || imgr.Image.Bounds().Dy() != 60 {
+		t.Fatalf("AutoOrient did not rotate the image: got %v", imgr.Image.Bounds())
+	}
+
+	// The white left half ends up on top
+	if r, _, _, _ := imgr.Image.At(15, 5).RGBA(); r>>8 < 200 {
+		t.Fatalf("AutoOrient rotated the image in the wrong direction")
+	}
+
+	// The orientation is reset so a second call is a no-op
+	imgr.AutoOrient()
+	if imgr.Image.Bounds().Dx() != 30 {
+		t.Fatalf("AutoOrient rotated the image twice")
+	}
+
+	// Reset keeps the oriented image
+	if imgr.Reset().Image.Bounds().Dx() != 30 {
+		t.Fatalf("Reset reverted the automatic orientation")
+	}
+}
+
+func TestAutoOrientFlip(t *testing.T) {
+	imgr, _ := NewImagerFromBytes(createOrientedJPEG(t, 2))
+	if r, _, _, _ := imgr.Image.At(5, 5).RGBA(); r>>8 < 200 {
+		t.Fatalf("image was oriented without WithAutoOrient")
+	}
+
+	imgr.AutoOrient()
+	if r, _, _, _ := imgr.Image.At(5, 5).RGBA(); r>>8 > 50 {
+		t.Fatalf("AutoOrient did not flip the image horizontally")
+	}
+}
+
+// testTag is an IFD entry used to build test EXIF data, when ifd is set the
+// value is the offset of ifds[ifd]
+type testTag struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+	ifd   int
+}
+
+// createEXIF returns little endian EXIF data holding ifds, the first one is
+// IFD0 and the others are referenced by their pointer tags
+func createEXIF(ifds [][]testTag) []byte {
+	offsets := make([]uint32, len(ifds))
+	end := uint32(8)
+	for k, ifd := range ifds {
+		offsets[k] = end
+		end += uint32(2 + 12*len(ifd) + 4)
+	}
+
+	le := binary.LittleEndian
+	exif := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00}
+	var values []byte
+	for _, ifd := range ifds {
+		exif = le.AppendUint16(exif, uint16(len(ifd)))
+		for _, tag := range ifd {
+			value := tag.value
+			if tag.ifd > 0 {
+				value = le.AppendUint32(nil, offsets[tag.ifd])
+			}
+
+			exif = le.AppendUint16(exif, tag.tag)
+			exif = le.AppendUint16(exif, tag.typ)
+			exif = le.AppendUint32(exif, tag.count)
+			if len(value) > 4 {
+				exif = le.AppendUint32(exif, end+uint32(len(values)))
+				values = append(values, value...)
+			} else {
+				exif = append(exif, append(value, make([]byte, 4-len(value))...)...)
+			}
+		}
+		exif = le.AppendUint32(exif, 0)
+	}
+
+	return append(exif, values...)
+}
+
+// asciiTag returns an ASCII entry holding s
+func asciiTag(tag uint16, s string) testTag {
+	return testTag{tag: tag, typ: 2, count: uint32(len(s) + 1), value: append([]byte(s), 0)}
+}
+
+// rationalTag returns a RATIONAL entry holding values over 100
+func rationalTag(tag uint16, values ...uint32) testTag {
+	var value []byte
+	for _, v := range values {
+		value = binary.LittleEndian.AppendUint32(value, v)
+		value = binary.LittleEndian.AppendUint32(value, 100)
+	}
+
+	return testTag{tag: tag, typ: 5, count: uint32(len(values)), value: value}
+}
+
+func TestMetadata(t *testing.T) {
+	exif := createEXIF([][]testTag{
+		{
+			asciiTag(TagMake, "Canon"),
+			asciiTag(TagModel, "EOS 5D"),
+			{tag: TagOrientation, typ: 3, count: 1, value: []byte{6, 0}},
+			asciiTag(TagDateTime, "2020:01:01 10:00:00"),
+			{tag: TagExifIFD, typ: 4, count: 1, ifd: 1},
+			{tag: TagGPSIFD, typ: 4, count: 1, ifd: 2},
+		},
+		{
+			asciiTag(tagDateTimeOriginal, "2019:06:15 08:30:00"),
+			{tag: tagPixelXDimension, typ: 4, count: 1, value: []byte{0x80, 0x07, 0, 0}},
+			{tag: tagPixelYDimension, typ: 3, count: 1, value: []byte{0x38, 0x04}},
+		},
+		{
+			asciiTag(tagGPSLatitudeRef, "S"),
+			rationalTag(tagGPSLatitude, 3300, 3000, 0),
+			asciiTag(tagGPSLongitudeRef, "E"),
+			rationalTag(tagGPSLongitude, 15100, 1200, 3600),
+			{tag: tagGPSAltitudeRef, typ: 1, count: 1, value: []byte{0}},
+			rationalTag(tagGPSAltitude, 5800),
+		},
+	})
+
+	imgr := &Imager{EXIF: exif}
+	meta, err := imgr.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata returned an error: %v", err)
+	}
+
+	if meta.Make != "Canon" || meta.Model != "EOS 5D" || meta.Orientation != 6 {
+		t.Fatalf("unexpected camera fields: %+v", meta)
+	}
+	if !meta.DateTime.Equal(time.Date(2019, 6, 15, 8, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected the original date, got %v", meta.DateTime)
+	}
+	if meta.Width != 1920 || meta.Height != 1080 {
+		t.Fatalf("expected 1920x1080, got %dx%d", meta.Width, meta.Height)
+	}
+
+	if meta.GPS == nil {
+		t.Fatalf("expected a GPS location")
+	}
+	if math.Abs(meta.GPS.Latitude+33.5) > 1e-9 || math.Abs(meta.GPS.Longitude-151.21) > 1e-9 || meta.GPS.Altitude != 58 {
+		t.Fatalf("unexpected GPS location: %+v", *meta.GPS)
+	}
+}
+
+func TestMetadataFromJPEG(t *testing.T) {
+	imgr, _ := NewImagerFromBytes(createOrientedJPEG(t, 3))
+	meta, err := imgr.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata returned an error: %v", err)
+	}
+	if meta.Orientation != 3 || meta.GPS != nil || !meta.DateTime.IsZero() {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+
+	imgr, _ = NewImager(createTestImage())
+	if _, err := imgr.Metadata(); !errors.Is(err, ErrNoMetadata) {
+		t.Fatalf("expected ErrNoMetadata, got %v", err)
+	}
+}
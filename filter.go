@@ -0,0 +1,72 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// GaussianBlur blurs the image, sigma is the standard deviation of the
+// gaussian kernel in pixels. A sigma of zero or less leaves the image as is
+// i.e :
+// imgr.GaussianBlur(1.5)
+func (i *Imager) GaussianBlur(sigma float64) *Imager {
+	if sigma <= 0 {
+		return i
+	}
+
+	return i.apply("GaussianBlur", func(img image.Image) image.Image {
+		return imaging.Blur(img, sigma)
+	})
+}
+
+// Sharpen sharpens the image, sigma controls the strength of the effect.
+// A sigma of zero or less leaves the image as is
+// i.e :
+// imgr.Resize(200, 200).Sharpen(0.5)
+func (i *Imager) Sharpen(sigma float64) *Imager {
+	if sigma <= 0 {
+		return i
+	}
+
+	return i.apply("Sharpen", func(img image.Image) image.Image {
+		return imaging.Sharpen(img, sigma)
+	})
+}
+
+// UnsharpMask sharpens the image by adding amount times the difference with
+// a gaussian blur of sigma. Differences below threshold, from 0 to 255, are
+// left alone so noise and flat areas are not sharpened
+// i.e :
+// imgr.Resize(200, 200).UnsharpMask(1, 0.8, 3)
+func (i *Imager) UnsharpMask(sigma, amount float64, threshold uint8) *Imager {
+	if amount < 0 {
+		i.setErr(fmt.Errorf("%w: unsharp mask amount %v", ErrInvalidArgument, amount))
+		return i
+	}
+	if sigma <= 0 || amount == 0 {
+		return i
+	}
+
+	return i.apply("UnsharpMask", func(img image.Image) image.Image {
+		return unsharpMask(img, sigma, amount, int(threshold))
+	})
+}
+
+// unsharpMask returns img sharpened with an unsharp mask, alpha is kept
+func unsharpMask(img image.Image, sigma, amount float64, threshold int) *image.NRGBA {
+	dst := imaging.Clone(img)
+	blurred := imaging.Blur(dst, sigma)
+
+	for k := 0; k < len(dst.Pix); k += 4 {
+		for c := 0; c < 3; c++ {
+			diff := int(dst.Pix[k+c]) - int(blurred.Pix[k+c])
+			if diff >= threshold || -diff >= threshold {
+				dst.Pix[k+c] = clampUint8(float64(dst.Pix[k+c]) + amount*float64(diff))
+			}
+		}
+	}
+
+	return dst
+}
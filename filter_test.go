@@ -0,0 +1,75 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// createEdgeImage returns a 20x20 image, black on the left and white on the
+// right
+func createEdgeImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 10; x < 20; x++ {
+			img.Set(x, y, color.White)
+		}
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.Black)
+		}
+	}
+
+	return img
+}
+
+// gray returns the red channel of the pixel at x, y
+func gray(img image.Image, x, y int) int {
+	r, _, _, _ := img.At(x, y).RGBA()
+	return int(r >> 8)
+}
+
+func TestGaussianBlur(t *testing.T) {
+	imgr, _ := NewImager(createEdgeImage())
+	imgr.GaussianBlur(2)
+
+	if v := gray(imgr.Image, 9, 10); v == 0 || v == 255 {
+		t.Fatalf("GaussianBlur did not soften the edge: got %d", v)
+	}
+	if v := gray(imgr.Image, 0, 10); v != 0 {
+		t.Fatalf("GaussianBlur changed the flat area: got %d", v)
+	}
+}
+
+func TestSharpen(t *testing.T) {
+	imgr, _ := NewImager(createEdgeImage())
+	imgr.GaussianBlur(1)
+	before := gray(imgr.Image, 10, 10)
+
+	imgr.Sharpen(1)
+	if after := gray(imgr.Image, 10, 10); after <= before {
+		t.Fatalf("Sharpen did not increase the edge contrast: %d -> %d", before, after)
+	}
+}
+
+func TestUnsharpMask(t *testing.T) {
+	imgr, _ := NewImager(createEdgeImage())
+	imgr.GaussianBlur(1)
+	soft := imgr.Image
+	before := gray(soft, 10, 10)
+
+	imgr.UnsharpMask(1, 1, 0)
+	if after := gray(imgr.Image, 10, 10); after <= before {
+		t.Fatalf("UnsharpMask did not increase the edge contrast: %d -> %d", before, after)
+	}
+
+	// Differences are all below the threshold
+	imgr, _ = NewImager(soft)
+	imgr.UnsharpMask(1, 1, 255)
+	assertSamePixels(t, imgr.Image, soft)
+
+	imgr.UnsharpMask(1, -1, 0)
+	if !errors.Is(imgr.Err(), ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", imgr.Err())
+	}
+}
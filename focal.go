@@ -0,0 +1,104 @@
+package imager
+
+import (
+	"image"
+	"math"
+)
+
+// SetFocalPoint marks the subject of the image at x, y, fractions of the
+// width and height from 0 to 1, out of range values record
+// ErrInvalidArgument. The crops then keep it in frame: MD_CROP and MD_SMART,
+// CropToRatio and the crops without explicit gravity. The point follows the
+// crops, paddings, flips and rotations, Reset clears it
+// i.e :
+// imgr.SetFocalPoint(0.3, 0.25).Resize(400, 400, imager.MD_CROP)
+func (i *Imager) SetFocalPoint(x, y float64) *Imager {
+	if !i.checkRange("focal point x", x, 0, 1) || !i.checkRange("focal point y", y, 0, 1) {
+		return i
+	}
+
+	i.focal = &focalPoint{x: x, y: y}
+	return i
+}
+
+// FocalPoint returns the focal point set by SetFocalPoint, as moved by the
+// following operations, ok is false when there is none
+// i.e :
+// if x, y, ok := imgr.FocalPoint(); ok {
+func (i *Imager) FocalPoint() (x, y float64, ok bool) {
+	if i.focal == nil {
+		return 0, 0, false
+	}
+
+	return i.focal.x, i.focal.y, true
+}
+
+// gravity returns the focal point as a Gravity, fallback when there is none
+func (i *Imager) gravity(fallback Gravity) Gravity {
+	if i.focal == nil {
+		return fallback
+	}
+
+	return *i.focal
+}
+
+// moveFocal moves the focal point by point, which maps fractions of the size
+// before an operation to fractions of the size after it
+func (i *Imager) moveFocal(point func(x, y float64) (float64, float64)) {
+	if i.focal == nil || i.err != nil {
+		return
+	}
+
+	x, y := point(i.focal.x, i.focal.y)
+	i.focal = &focalPoint{x: math.Max(0, math.Min(1, x)), y: math.Max(0, math.Min(1, y))}
+}
+
+// cropFocal moves the focal point into rect, the area of bounds kept by a
+// crop. A point left out of the area goes to its nearest edge
+func (i *Imager) cropFocal(bounds, rect image.Rectangle) {
+	if rect.Empty() {
+		return
+	}
+
+	i.moveFocal(func(x, y float64) (float64, float64) {
+		return (x*float64(bounds.Dx()) - float64(rect.Min.X-bounds.Min.X)) / float64(rect.Dx()),
+			(y*float64(bounds.Dy()) - float64(rect.Min.Y-bounds.Min.Y)) / float64(rect.Dy())
+	})
+}
+
+// padFocal moves the focal point of an image of size placed at origin on a
+// canvas of the canvas size
+func (i *Imager) padFocal(size, canvas, origin image.Point) {
+	if canvas.X == 0 || canvas.Y == 0 {
+		return
+	}
+
+	i.moveFocal(func(x, y float64) (float64, float64) {
+		return (x*float64(size.X) + float64(origin.X)) / float64(canvas.X),
+			(y*float64(size.Y) + float64(origin.Y)) / float64(canvas.Y)
+	})
+}
+
+// rotateFocal moves the focal point by a counter-clockwise rotation of
+// degrees around the center, from an image of size to one of rotated
+func (i *Imager) rotateFocal(degrees float64, size, rotated image.Point) {
+	if rotated.X == 0 || rotated.Y == 0 {
+		return
+	}
+
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	i.moveFocal(func(x, y float64) (float64, float64) {
+		// Y goes down, the rotation matrix is the one of a clockwise rotation
+		dx, dy := (x-0.5)*float64(size.X), (y-0.5)*float64(size.Y)
+		return 0.5 + (dx*cos+dy*sin)/float64(rotated.X), 0.5 + (dy*cos-dx*sin)/float64(rotated.Y)
+	})
+}
+
+// The moves of the focal point by the flips and the rotations of 90 degrees
+func flipHPoint(x, y float64) (float64, float64)      { return 1 - x, y }
+func flipVPoint(x, y float64) (float64, float64)      { return x, 1 - y }
+func transposePoint(x, y float64) (float64, float64)  { return y, x }
+func transversePoint(x, y float64) (float64, float64) { return 1 - y, 1 - x }
+func rotate90Point(x, y float64) (float64, float64)   { return y, 1 - x }
+func rotate180Point(x, y float64) (float64, float64)  { return 1 - x, 1 - y }
+func rotate270Point(x, y float64) (float64, float64)  { return 1 - y, x }
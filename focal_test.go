@@ -0,0 +1,196 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// createSplitImage returns a 100x50 image, red on its left half and blue on
+// its right half
+func createSplitImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			if x < 50 {
+				img.Set(x, y, color.NRGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.NRGBA{0, 0, 255, 255})
+			}
+		}
+	}
+
+	return img
+}
+
+// isBlue reports whether the whole image is blue
+func isBlue(img image.Image) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r != 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestSetFocalPoint(t *testing.T) {
+	imgr, _ := NewImager(createSplitImage())
+	if _, _, ok := imgr.FocalPoint(); ok {
+		t.Fatal("expected no focal point before SetFocalPoint")
+	}
+
+	// The crop keeps the right half, where the point is
+	imgr.SetFocalPoint(0.9, 0.5).Resize(50, 50, MD_CROP)
+	if imgr.Err() != nil {
+		t.Fatalf("unexpected error: %v", imgr.Err())
+	}
+	if b := imgr.Image.Bounds(); b.Dx() != 50 || b.Dy() != 50 || !isBlue(imgr.Image) {
+		t.Fatalf("expected the 50x50 blue right half, got %v", b)
+	}
+	// The point follows the crop
+	if x, y, ok := imgr.FocalPoint(); !ok || math.Abs(x-0.8) > 1e-9 || y != 0.5 {
+		t.Errorf("expected the focal point at 0.8, 0.5, got %v, %v", x, y)
+	}
+
+	imgr.Reset()
+	if _, _, ok := imgr.FocalPoint(); ok {
+		t.Error("Reset should clear the focal point")
+	}
+
+	for name, crop := range map[string]func(i *Imager) *Imager{
+		"smart": func(i *Imager) *Imager { return i.Resize(50, 50, MD_SMART) },
+		"ratio": func(i *Imager) *Imager { return i.CropToRatio(1, 1) },
+	} {
+		imgr, _ := NewImager(createSplitImage())
+		crop(imgr.SetFocalPoint(0.75, 0.5))
+		if !isBlue(imgr.Image) {
+			t.Errorf("%s: the crop should keep the focal point", name)
+		}
+	}
+
+	// An explicit gravity wins
+	imgr, _ = NewImager(createSplitImage())
+	imgr.SetFocalPoint(0.9, 0.5).CropAnchor(50, 50, AnchorLeft)
+	if isBlue(imgr.Image) {
+		t.Error("the anchor should be used over the focal point")
+	}
+	if x, _, _ := imgr.FocalPoint(); x != 1 {
+		t.Errorf("expected the point left out to move to the edge, got %v", x)
+	}
+
+	imgr, _ = NewImager(createSplitImage())
+	if err := imgr.SetFocalPoint(1.5, 0).Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestFocalPointTransforms(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(i *Imager) *Imager
+		x, y float64
+	}{
+		{"flip-h", (*Imager).FlipH, 0.8, 0.25},
+		{"flip-v", (*Imager).FlipV, 0.2, 0.75},
+		{"transpose", (*Imager).Transpose, 0.25, 0.2},
+		{"transverse", (*Imager).Transverse, 0.75, 0.8},
+		{"rotate-90", (*Imager).Rotate90, 0.25, 0.8},
+		{"rotate-180", (*Imager).Rotate180, 0.8, 0.75},
+		{"rotate-270", (*Imager).Rotate270, 0.75, 0.2},
+		{"rotate-with-90", func(i *Imager) *Imager { return i.RotateWith(90, RotateOptions{}) }, 0.25, 0.8},
+		{"rotate-with-45", func(i *Imager) *Imager { return i.RotateWith(45, RotateOptions{}) }, 0.5, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imgr, _ := NewImager(createSplitImage())
+			if tt.name == "rotate-with-45" {
+				imgr.SetFocalPoint(0.5, 0.5)
+			} else {
+				imgr.SetFocalPoint(0.2, 0.25)
+			}
+			tt.op(imgr)
+
+			x, y, _ := imgr.FocalPoint()
+			if math.Abs(x-tt.x) > 0.01 || math.Abs(y-tt.y) > 0.01 {
+				t.Errorf("expected the focal point at %v, %v, got %v, %v", tt.x, tt.y, x, y)
+			}
+		})
+	}
+}
+
+func TestFocalPointPipeline(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{"focal-point": {"x": 0.9, "y": 0.5}, "resize": {"w": 50, "h": 50, "mode": "crop"}}`))
+	if err != nil {
+		t.Fatalf("ParseSpec returned an error: %v", err)
+	}
+	imgr, err := spec.Pipeline.Apply(createSplitImage())
+	if err != nil {
+		t.Fatalf("Apply returned an error: %v", err)
+	}
+	if !isBlue(imgr.Image) {
+		t.Error("the spec crop should keep the focal point")
+	}
+
+	if err := NewPipeline().SetFocalPoint(2, 0).Validate(); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestFocalPointCanvas(t *testing.T) {
+	// The detected region is on the left, away from the point
+	left := func(img image.Image) ([]image.Rectangle, error) {
+		return []image.Rectangle{image.Rect(0, 0, 10, 50)}, nil
+	}
+	imgr, _ := NewImager(createSplitImage())
+	imgr.SetFocalPoint(0.9, 0.5).CropAround(50, 50, left)
+	if x, y, _ := imgr.FocalPoint(); x != 1 || y != 0.5 {
+		t.Errorf("CropAround: expected the focal point at 1, 0.5, got %v, %v", x, y)
+	}
+
+	imgr, _ = NewImager(createSplitImage())
+	imgr.SetFocalPoint(0, 0).Pad(0, 0, 100, 100, nil)
+	if x, y, _ := imgr.FocalPoint(); x != 0.5 || y != 0 {
+		t.Errorf("Pad: expected the focal point at 0.5, 0, got %v, %v", x, y)
+	}
+
+	// The 100x50 image is centered on a 200x100 canvas
+	imgr, _ = NewImager(createSplitImage())
+	imgr.SetFocalPoint(1, 1).PadTo(200, 100, AnchorCenter, nil)
+	if x, y, _ := imgr.FocalPoint(); x != 0.75 || y != 0.75 {
+		t.Errorf("PadTo: expected the focal point at 0.75, 0.75, got %v, %v", x, y)
+	}
+
+	// The image is 10 pixels from the left and top of the canvas
+	for name, pad := range map[string]struct {
+		op   func(i *Imager) *Imager
+		w, h float64
+	}{
+		"border": {func(i *Imager) *Imager { return i.Border(10, color.White) }, 120, 70},
+		"frame":  {func(i *Imager) *Imager { return i.Frame(FrameOptions{Outer: []Stroke{{Width: 4}, {Width: 6}}}) }, 120, 70},
+		"shadow": {func(i *Imager) *Imager { return i.DropShadow(-10, -10, 0, nil, 1) }, 110, 60},
+	} {
+		imgr, _ = NewImager(createSplitImage())
+		pad.op(imgr.SetFocalPoint(0, 0))
+		if x, y, _ := imgr.FocalPoint(); math.Abs(x-10/pad.w) > 1e-9 || math.Abs(y-10/pad.h) > 1e-9 {
+			t.Errorf("%s: expected the focal point at 10, 10 pixels, got %v, %v", name, x, y)
+		}
+	}
+
+	// The crop after the padding keeps the point in frame
+	imgr, _ = NewImager(createSplitImage())
+	imgr.SetFocalPoint(0.9, 0.5).FitToRatio(1, 1, nil)
+	if x, y, _ := imgr.FocalPoint(); x != 0.9 || y != 0.5 {
+		t.Errorf("FitToRatio: expected the focal point at 0.9, 0.5, got %v, %v", x, y)
+	}
+	imgr.Resize(50, 50, MD_CROP)
+	if !isBlue(imgr.Image) {
+		t.Error("FitToRatio: the crop should keep the focal point")
+	}
+}
@@ -0,0 +1,61 @@
+package imager
+
+import (
+	"image"
+	"io"
+	"strings"
+	"sync"
+)
+
+// EncodeFunc writes img to w in a format registered by RegisterFormat
+type EncodeFunc func(w io.Writer, img image.Image, opts EncodeOptions) error
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncodeFunc{}
+)
+
+// RegisterFormat plugs in a codec for the format name, such as a cgo-backed
+// JPEG XL or RAW codec, without the package depending on it. decode is
+// registered with the image package for the data starting with magic, "?"
+// matching any byte, so the loading functions, DetectFormat and Probe
+// recognize the format, whose ImageType is then name. Probe decodes the
+// whole image to get its dimensions. encode is used by Encode, Bytes and
+// Save, which picks name from the file extension, the metadata and the
+// frames after the first being left out. Either function can be nil, and
+// the built-in formats keep their own encoder. Decoders can't be removed,
+// registering a nil encode removes the encoder
+// i.e :
+// imager.RegisterFormat("jxl", "\xff\x0a", jxl.Decode, encodeJXL)
+// err := imgr.Save("photo.jxl")
+func RegisterFormat(name, magic string, decode func(io.Reader) (image.Image, error), encode EncodeFunc) {
+	name = strings.ToLower(name)
+	if decode != nil {
+		image.RegisterFormat(name, magic, decode, func(r io.Reader) (image.Config, error) {
+			img, err := decode(r)
+			if err != nil {
+				return image.Config{}, err
+			}
+			bounds := img.Bounds()
+			return image.Config{ColorModel: img.ColorModel(), Width: bounds.Dx(), Height: bounds.Dy()}, nil
+		})
+	}
+
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	if encode == nil {
+		delete(encoders, name)
+		return
+	}
+	encoders[name] = encode
+}
+
+// registeredEncoder returns the encoder registered for format, nil when
+// there is none
+func registeredEncoder(format string) EncodeFunc {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	return encoders[format]
+}
@@ -0,0 +1,87 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The raw test format: a magic, the size and the NRGBA pixels
+const rawMagic = "RAW8"
+
+func decodeRaw(r io.Reader) (image.Image, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	width, height := int(binary.BigEndian.Uint16(header[4:])), int(binary.BigEndian.Uint16(header[6:]))
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if _, err := io.ReadFull(r, img.Pix); err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+func encodeRaw(w io.Writer, img image.Image, opts EncodeOptions) error {
+	src := image.NewNRGBA(img.Bounds())
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			src.Set(x, y, img.At(x, y))
+		}
+	}
+
+	header := binary.BigEndian.AppendUint16([]byte(rawMagic), uint16(src.Rect.Dx()))
+	header = binary.BigEndian.AppendUint16(header, uint16(src.Rect.Dy()))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(src.Pix)
+	return err
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("raw8", rawMagic, decodeRaw, encodeRaw)
+
+	imgr, _ := NewImager(createColorImage(color.NRGBA{10, 20, 30, 255}))
+	data, err := imgr.ConvertTo("raw8").Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(rawMagic)) {
+		t.Fatalf("the registered encoder was not used")
+	}
+
+	loaded, err := NewImagerFromBytes(data)
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+	if loaded.ImageType != "raw8" || color.NRGBAModel.Convert(loaded.Image.At(5, 5)) != (color.NRGBA{10, 20, 30, 255}) {
+		t.Fatalf("the registered decoder was not used: %s", loaded.ImageType)
+	}
+
+	info, err := ProbeBytes(data)
+	if err != nil || info.Format != "raw8" || info.Width != 10 || info.Height != 10 {
+		t.Fatalf("Probe returned %+v, %v", info, err)
+	}
+
+	location := filepath.Join(t.TempDir(), "out.raw8")
+	if err := loaded.ConvertTo(IMPNG).Save(location); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	if saved, _ := os.ReadFile(location); !bytes.HasPrefix(saved, []byte(rawMagic)) {
+		t.Fatalf("Save did not pick the format from the extension")
+	}
+
+	RegisterFormat("raw8", rawMagic, nil, nil)
+	if _, err := loaded.Clone().ConvertTo("raw8").Bytes(); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat once the encoder is removed, got %v", err)
+	}
+}
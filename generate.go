@@ -0,0 +1,100 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// NewBlank creates a new Imager of width x height filled with c, nil being
+// transparent. Like the other generated images it has the PNG ImageType
+// i.e :
+// imgr, err := imager.NewBlank(1200, 630, color.White)
+func NewBlank(width, height int, c color.Color) (*Imager, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%w: blank image of %dx%d", ErrInvalidArgument, width, height)
+	}
+	if c == nil {
+		c = color.Transparent
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Rect, image.NewUniform(c), image.Point{}, draw.Src)
+
+	return newGenerated(img)
+}
+
+// NewGradient creates a new Imager of width x height filled with a linear
+// gradient of stops, see GradientOverlay. angle is the direction of the
+// gradient in degrees, like CSS linear-gradient: 0 runs from the bottom,
+// offset 0, to the top, offset 1, 90 from left to right and 180 from top to
+// bottom. The corners get the offsets 0 and 1 whatever the angle
+// i.e :
+// imgr, err := imager.NewGradient(1200, 630, []imager.GradientStop{{0, color.RGBA{20, 30, 80, 255}}, {1, color.RGBA{120, 40, 160, 255}}}, 135)
+func NewGradient(width, height int, stops []GradientStop, angle float64) (*Imager, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%w: gradient of %dx%d", ErrInvalidArgument, width, height)
+	}
+	if len(stops) == 0 {
+		return nil, fmt.Errorf("%w: gradient without stops", ErrInvalidArgument)
+	}
+	for _, stop := range stops {
+		if stop.Offset < 0 || stop.Offset > 1 {
+			return nil, fmt.Errorf("%w: gradient stop offset %v out of [0, 1]", ErrInvalidArgument, stop.Offset)
+		}
+		if stop.Color == nil {
+			return nil, fmt.Errorf("%w: gradient stop at %v without color", ErrInvalidArgument, stop.Offset)
+		}
+	}
+
+	ramp := newGradientRamp(stops)
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	w, h := float64(width), float64(height)
+	length := math.Abs(w*sin) + math.Abs(h*cos)
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		// Sampling at the pixel centers, y grows downwards
+		dy := float64(y) + 0.5 - h/2
+		for x := 0; x < width; x++ {
+			dx := float64(x) + 0.5 - w/2
+			c := ramp.at((dx*sin-dy*cos)/length + 0.5)
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return newGenerated(img)
+}
+
+// NewFromPattern creates a new Imager of width x height tiled with tile
+// from the top left corner
+// i.e :
+// imgr, err := imager.NewFromPattern(texture, 1200, 630)
+func NewFromPattern(tile image.Image, width, height int) (*Imager, error) {
+	if width <= 0 || height <= 0 || tile.Bounds().Empty() {
+		return nil, fmt.Errorf("%w: pattern of %v over %dx%d", ErrInvalidArgument, tile.Bounds().Size(), width, height)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	size := tile.Bounds().Size()
+	for y := 0; y < height; y += size.Y {
+		for x := 0; x < width; x += size.X {
+			draw.Draw(img, image.Rectangle{Min: image.Pt(x, y), Max: image.Pt(x, y).Add(size)}, tile, tile.Bounds().Min, draw.Src)
+		}
+	}
+
+	return newGenerated(img)
+}
+
+// newGenerated returns an Imager of img with the PNG ImageType
+func newGenerated(img image.Image) (*Imager, error) {
+	imgr, err := NewImager(img)
+	if err != nil {
+		return nil, err
+	}
+	imgr.ImageType = IMPNG
+
+	return imgr, nil
+}
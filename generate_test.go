@@ -0,0 +1,83 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestNewBlank(t *testing.T) {
+	imgr, err := NewBlank(30, 20, color.RGBA{0, 0, 255, 255})
+	if err != nil {
+		t.Fatalf("NewBlank returned an error: %v", err)
+	}
+	if imgr.Width() != 30 || imgr.Height() != 20 || imgr.ImageType != IMPNG {
+		t.Fatalf("unexpected image %dx%d of type %s", imgr.Width(), imgr.Height(), imgr.ImageType)
+	}
+	if c := color.NRGBAModel.Convert(imgr.Image.At(29, 19)); c != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("unexpected color %v", c)
+	}
+
+	transparent, _ := NewBlank(10, 10, nil)
+	if _, _, _, a := transparent.Image.At(5, 5).RGBA(); a != 0 {
+		t.Fatalf("a nil color is not transparent")
+	}
+
+	if _, err := NewBlank(0, 10, color.White); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestNewGradient(t *testing.T) {
+	stops := []GradientStop{{0, color.Black}, {1, color.White}}
+	for _, tt := range []struct {
+		angle       float64
+		dark, light image.Point
+	}{
+		{0, image.Pt(50, 49), image.Pt(50, 0)},
+		{90, image.Pt(0, 10), image.Pt(99, 10)},
+		{180, image.Pt(50, 0), image.Pt(50, 49)},
+		{135, image.Pt(0, 0), image.Pt(99, 49)},
+	} {
+		imgr, err := NewGradient(100, 50, stops, tt.angle)
+		if err != nil {
+			t.Fatalf("NewGradient returned an error: %v", err)
+		}
+		if v := gray(imgr.Image, tt.dark.X, tt.dark.Y); v > 10 {
+			t.Fatalf("angle %v: the start %v is %d", tt.angle, tt.dark, v)
+		}
+		if v := gray(imgr.Image, tt.light.X, tt.light.Y); v < 245 {
+			t.Fatalf("angle %v: the end %v is %d", tt.angle, tt.light, v)
+		}
+	}
+
+	if _, err := NewGradient(10, 10, nil, 0); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := NewGradient(10, 10, []GradientStop{{0, nil}}, 0); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for a stop without color, got %v", err)
+	}
+}
+
+func TestNewFromPattern(t *testing.T) {
+	tile := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	tile.SetNRGBA(0, 0, color.NRGBA{255, 0, 0, 255})
+
+	imgr, err := NewFromPattern(tile, 5, 3)
+	if err != nil {
+		t.Fatalf("NewFromPattern returned an error: %v", err)
+	}
+	for _, pt := range []image.Point{{0, 0}, {2, 0}, {4, 2}} {
+		if c := color.NRGBAModel.Convert(imgr.Image.At(pt.X, pt.Y)); c != (color.NRGBA{255, 0, 0, 255}) {
+			t.Fatalf("the tile is not repeated at %v: %v", pt, c)
+		}
+	}
+	if _, _, _, a := imgr.Image.At(3, 1).RGBA(); a != 0 {
+		t.Fatalf("the tile is misplaced")
+	}
+
+	if _, err := NewFromPattern(image.NewNRGBA(image.Rect(0, 0, 0, 0)), 5, 5); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package imager
+
+// Width returns the width of the image as displayed: the EXIF orientations
+// 5 to 8, left until AutoOrient, swap the stored width and height
+// i.e :
+// width := imgr.Width()
+func (i *Imager) Width() int {
+	width, _ := i.displaySize()
+	return width
+}
+
+// Height returns the height of the image as displayed, see Width
+// i.e :
+// height := imgr.Height()
+func (i *Imager) Height() int {
+	_, height := i.displaySize()
+	return height
+}
+
+// AspectRatio returns the width of the image as displayed divided by its
+// height, see Width
+// i.e :
+// ratio := imgr.AspectRatio()
+func (i *Imager) AspectRatio() float64 {
+	width, height := i.displaySize()
+	if height == 0 {
+		return 0
+	}
+
+	return float64(width) / float64(height)
+}
+
+// IsLandscape reports whether the image as displayed is wider than tall,
+// see Width
+// i.e :
+// if imgr.IsLandscape() {
+func (i *Imager) IsLandscape() bool {
+	width, height := i.displaySize()
+	return width > height
+}
+
+// IsPortrait reports whether the image as displayed is taller than wide,
+// see Width
+// i.e :
+// if imgr.IsPortrait() {
+func (i *Imager) IsPortrait() bool {
+	width, height := i.displaySize()
+	return height > width
+}
+
+// FitDimensions returns the size the image as displayed gets when fitted
+// within maxWidth x maxHeight keeping its aspect ratio, like MD_FIT, which
+// never enlarges it. Zero leaves the dimension unbounded
+// i.e :
+// width, height := imgr.FitDimensions(800, 600)
+func (i *Imager) FitDimensions(maxWidth, maxHeight int) (int, int) {
+	width, height := i.displaySize()
+	if maxWidth <= 0 {
+		maxWidth = width
+	}
+	if maxHeight <= 0 {
+		maxHeight = height
+	}
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+
+	return fitSize(width, height, maxWidth, maxHeight)
+}
+
+// displaySize returns the size of the image once oriented by its EXIF data
+func (i *Imager) displaySize() (int, int) {
+	if i.Image == nil {
+		return 0, 0
+	}
+
+	size := i.Image.Bounds().Size()
+	if orientation := exifOrientation(i.EXIF); orientation >= 5 && orientation <= 8 {
+		return size.Y, size.X
+	}
+
+	return size.X, size.Y
+}
@@ -0,0 +1,39 @@
+package imager
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeometry(t *testing.T) {
+	imgr, err := NewImagerFromBytes(createOrientedJPEG(t, 1))
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+	if imgr.Width() != 60 || imgr.Height() != 30 || imgr.AspectRatio() != 2 || !imgr.IsLandscape() || imgr.IsPortrait() {
+		t.Fatalf("unexpected geometry %dx%d", imgr.Width(), imgr.Height())
+	}
+	if w, h := imgr.FitDimensions(40, 40); w != 40 || h != 20 {
+		t.Fatalf("FitDimensions returned %dx%d", w, h)
+	}
+	if w, h := imgr.FitDimensions(1000, 0); w != 60 || h != 30 {
+		t.Fatalf("FitDimensions enlarged the image to %dx%d", w, h)
+	}
+
+	// Orientation 6 is displayed rotated, until AutoOrient the stored pixels
+	// are not
+	rotated, err := NewImagerFromBytes(createOrientedJPEG(t, 6))
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+	if rotated.Width() != 30 || rotated.Height() != 60 || !rotated.IsPortrait() || math.Abs(rotated.AspectRatio()-0.5) > 1e-9 {
+		t.Fatalf("the orientation was ignored: %dx%d", rotated.Width(), rotated.Height())
+	}
+	if w, h := rotated.FitDimensions(0, 30); w != 15 || h != 30 {
+		t.Fatalf("FitDimensions returned %dx%d", w, h)
+	}
+	rotated.AutoOrient()
+	if rotated.Width() != 30 || rotated.Height() != 60 {
+		t.Fatalf("AutoOrient changed the displayed size to %dx%d", rotated.Width(), rotated.Height())
+	}
+}
@@ -4,4 +4,9 @@ go 1.22.2
 
 require github.com/disintegration/imaging v1.6.2
 
-require golang.org/x/image v0.0.0-20191009234506-e7c1f5e7dbb8 // indirect
+require (
+	golang.org/x/image v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/text v0.22.0 // indirect
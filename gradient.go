@@ -0,0 +1,161 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+// GradientKind is the shape of the gradient drawn by GradientOverlay
+type GradientKind int
+
+const (
+	// GradientLinear runs from the top edge, offset 0, to the bottom edge,
+	// offset 1
+	GradientLinear GradientKind = iota
+
+	// GradientRadial runs from the center, offset 0, to the corners, offset
+	// 1, along ellipses fitted to the image
+	GradientRadial
+)
+
+// GradientStop is a color of a gradient at offset, from 0 to 1
+type GradientStop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// GradientOverlay draws a gradient of kind over the image, the colors
+// between the stops being interpolated and the colors before the first and
+// after the last stop being extended. opacity ranges from 0 to 1 and
+// multiplies the alpha of the stops. Stops without color record
+// ErrInvalidArgument
+// i.e :
+// imgr.GradientOverlay(imager.GradientLinear, []imager.GradientStop{{0.6, color.Transparent}, {1, color.Black}}, 0.7)
+// imgr.GradientOverlay(imager.GradientRadial, []imager.GradientStop{{0, color.White}, {1, color.Transparent}}, 0.3)
+func (i *Imager) GradientOverlay(kind GradientKind, stops []GradientStop, opacity float64) *Imager {
+	if kind != GradientLinear && kind != GradientRadial {
+		i.setErr(fmt.Errorf("%w: gradient kind %d", ErrInvalidArgument, kind))
+		return i
+	}
+	if len(stops) == 0 {
+		i.setErr(fmt.Errorf("%w: gradient without stops", ErrInvalidArgument))
+		return i
+	}
+	for _, stop := range stops {
+		if !i.checkRange("gradient stop offset", stop.Offset, 0, 1) {
+			return i
+		}
+		if stop.Color == nil {
+			i.setErr(fmt.Errorf("%w: gradient stop at %v without color", ErrInvalidArgument, stop.Offset))
+			return i
+		}
+	}
+	if !i.checkRange("opacity", opacity, 0, 1) {
+		return i
+	}
+
+	ramp := newGradientRamp(stops)
+	return i.apply("GradientOverlay", func(img image.Image) image.Image {
+		return gradientOverlay(img, kind, ramp, opacity)
+	})
+}
+
+// Vignette darkens the edges of the image towards c, nil being black, such
+// as to keep a text laid over a photo readable. strength ranges from 0 to 1
+// and is the opacity of c in the corners
+// i.e :
+// imgr.Vignette(0.6, nil)
+// imgr.Vignette(0.4, color.White)
+func (i *Imager) Vignette(strength float64, c color.Color) *Imager {
+	defer i.operation("Vignette")()
+
+	if !i.checkRange("strength", strength, 0, 1) {
+		return i
+	}
+	if c == nil {
+		c = color.Black
+	}
+	edge := color.NRGBAModel.Convert(c).(color.NRGBA)
+	center := edge
+	center.A = 0
+
+	// The center is left untouched and the falloff eases towards the corners
+	return i.GradientOverlay(GradientRadial, []GradientStop{
+		{Offset: 0.45, Color: center},
+		{Offset: 0.75, Color: color.NRGBA{edge.R, edge.G, edge.B, uint8(float64(edge.A)*0.45 + 0.5)}},
+		{Offset: 1, Color: edge},
+	}, strength)
+}
+
+// gradientRamp is a sorted list of gradient stops converted to NRGBA
+type gradientRamp struct {
+	offsets []float64
+	colors  []color.NRGBA
+}
+
+func newGradientRamp(stops []GradientStop) gradientRamp {
+	sorted := append([]GradientStop(nil), stops...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Offset < sorted[b].Offset })
+
+	ramp := gradientRamp{offsets: make([]float64, len(sorted)), colors: make([]color.NRGBA, len(sorted))}
+	for idx, stop := range sorted {
+		ramp.offsets[idx] = stop.Offset
+		ramp.colors[idx] = color.NRGBAModel.Convert(stop.Color).(color.NRGBA)
+	}
+
+	return ramp
+}
+
+// at returns the color of the ramp at offset t
+func (r gradientRamp) at(t float64) color.NRGBA {
+	last := len(r.offsets) - 1
+	switch {
+	case t <= r.offsets[0]:
+		return r.colors[0]
+	case t >= r.offsets[last]:
+		return r.colors[last]
+	}
+
+	next := sort.SearchFloat64s(r.offsets, t)
+	span := r.offsets[next] - r.offsets[next-1]
+	if span == 0 {
+		return r.colors[next]
+	}
+	w := (t - r.offsets[next-1]) / span
+
+	// Premultiplied so a transparent stop doesn't tint its neighbour
+	var acc premultiplied
+	acc.add(r.colors[next-1], 1-w)
+	acc.add(r.colors[next], w)
+	return acc.color()
+}
+
+// gradientOverlay returns img with the gradient of kind drawn over it
+func gradientOverlay(img image.Image, kind GradientKind, ramp gradientRamp, opacity float64) *image.NRGBA {
+	dst := imaging.Clone(img)
+	w, h := float64(dst.Rect.Dx()), float64(dst.Rect.Dy())
+	normal := blendFuncs[BlendNormal]
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		// Sampling at the pixel centers
+		fy := (float64(y) + 0.5) / h
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			t := fy
+			if kind == GradientRadial {
+				dx, dy := 2*(float64(x)+0.5)/w-1, 2*fy-1
+				t = math.Sqrt((dx*dx + dy*dy) / 2)
+			}
+
+			c := ramp.at(t)
+			off := y*dst.Stride + x*4
+			compositePixel(dst.Pix[off:off+4], []uint8{c.R, c.G, c.B, c.A}, opacity, normal)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,65 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func createGrayImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Rect, image.NewUniform(color.NRGBA{200, 200, 200, 255}), image.Point{}, draw.Src)
+	return img
+}
+
+func TestGradientOverlay(t *testing.T) {
+	imgr, _ := NewImager(createGrayImage(100, 100))
+	imgr.GradientOverlay(GradientLinear, []GradientStop{{1, color.Black}, {0.5, color.Transparent}}, 1)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("GradientOverlay returned an error: %v", err)
+	}
+
+	if c := pixel(imgr.Image, 50, 20); c != (color.NRGBA{200, 200, 200, 255}) {
+		t.Fatalf("GradientOverlay changed the pixels before the first stop: %v", c)
+	}
+	if c := pixel(imgr.Image, 50, 75); c.R < 90 || c.R > 110 || c.A != 255 {
+		t.Fatalf("GradientOverlay did not interpolate the stops: %v", c)
+	}
+	if c := pixel(imgr.Image, 50, 99); c.R > 5 {
+		t.Fatalf("GradientOverlay did not reach the last stop: %v", c)
+	}
+
+	imgr, _ = NewImager(createGrayImage(100, 100))
+	imgr.GradientOverlay(GradientRadial, []GradientStop{{0, color.White}, {1, color.White}}, 0.5)
+	if c := pixel(imgr.Image, 10, 90); c.R < 226 || c.R > 229 {
+		t.Fatalf("GradientOverlay did not apply the opacity: %v", c)
+	}
+
+	for _, stops := range [][]GradientStop{nil, {{1.5, color.Black}}, {{0, color.Black}, {1, nil}}} {
+		imgr, _ = NewImager(createGrayImage(10, 10))
+		if err := imgr.GradientOverlay(GradientLinear, stops, 1).Err(); err == nil {
+			t.Fatalf("GradientOverlay did not record an error for the stops %v", stops)
+		}
+	}
+}
+
+func TestVignette(t *testing.T) {
+	imgr, _ := NewImager(createGrayImage(100, 60))
+	imgr.Vignette(0.8, nil)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("Vignette returned an error: %v", err)
+	}
+
+	center, edge, corner := pixel(imgr.Image, 50, 30), pixel(imgr.Image, 99, 30), pixel(imgr.Image, 0, 0)
+	if center != (color.NRGBA{200, 200, 200, 255}) {
+		t.Fatalf("Vignette changed the center: %v", center)
+	}
+	if !(corner.R < edge.R && edge.R < center.R) {
+		t.Fatalf("Vignette did not darken towards the corners: %v %v %v", center, edge, corner)
+	}
+
+	if err := imgr.Vignette(1.2, nil).Err(); err == nil {
+		t.Fatalf("Vignette did not record an error for a strength out of range")
+	}
+}
@@ -0,0 +1,196 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+// HashAlgorithm is a perceptual hashing algorithm, see PerceptualHash
+type HashAlgorithm int
+
+const (
+	// HashAverage compares each pixel of an 8x8 thumbnail to their mean, it is
+	// the fastest and the least robust
+	HashAverage HashAlgorithm = iota
+
+	// HashDifference compares each pixel of a 9x8 thumbnail to its right
+	// neighbour, robust to brightness and contrast changes
+	HashDifference
+
+	// HashPerceptual compares the low frequencies of the discrete cosine
+	// transform of a 32x32 thumbnail to their median, the most robust
+	HashPerceptual
+)
+
+// PerceptualHash returns a 64-bit hash of the image content, similar images
+// get hashes within a small HammingDistance of each other, usually below 10.
+// Colors are ignored. Empty images return ErrInvalidArgument
+// i.e :
+// a, err := imgr.PerceptualHash(imager.HashPerceptual)
+// b, err := other.PerceptualHash(imager.HashPerceptual)
+// duplicate := imager.HammingDistance(a, b) <= 5
+func (i *Imager) PerceptualHash(algo HashAlgorithm) (uint64, error) {
+	if i.err != nil {
+		return 0, i.err
+	}
+	if i.Image.Bounds().Empty() {
+		return 0, fmt.Errorf("%w: empty image", ErrInvalidArgument)
+	}
+
+	switch algo {
+	case HashAverage:
+		return averageHash(i.Image), nil
+	case HashDifference:
+		return differenceHash(i.Image), nil
+	case HashPerceptual:
+		return dctHash(i.Image), nil
+	}
+
+	return 0, fmt.Errorf("%w: hash algorithm %d", ErrInvalidArgument, algo)
+}
+
+// HammingDistance returns the number of bits that differ between two hashes
+// i.e :
+// distance := imager.HammingDistance(a, b)
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// lumaSample returns the Rec. 601 luminance of img resized to width x height
+func lumaSample(img image.Image, width, height int) []float64 {
+	sample := imaging.Resize(img, width, height, imaging.Box)
+
+	values := make([]float64, width*height)
+	for k := range values {
+		p := sample.Pix[k*4 : k*4+3]
+		values[k] = 0.299*float64(p[0]) + 0.587*float64(p[1]) + 0.114*float64(p[2])
+	}
+
+	return values
+}
+
+// hashAbove returns the hash whose bits are set for the values above
+// threshold, the first value being the highest bit
+func hashAbove(values []float64, threshold float64) uint64 {
+	var hash uint64
+	for _, v := range values {
+		hash <<= 1
+		if v > threshold {
+			hash |= 1
+		}
+	}
+
+	return hash
+}
+
+// averageHash computes the HashAverage of img
+func averageHash(img image.Image) uint64 {
+	values := lumaSample(img, 8, 8)
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+
+	return hashAbove(values, mean/float64(len(values)))
+}
+
+// differenceHash computes the HashDifference of img
+func differenceHash(img image.Image) uint64 {
+	values := lumaSample(img, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if values[y*9+x] > values[y*9+x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// dctHash computes the HashPerceptual of img
+func dctHash(img image.Image) uint64 {
+	const size = 32
+	values := lumaSample(img, size, size)
+
+	// Separable DCT-II, only the 8x8 lowest frequencies are needed
+	cosines := make([]float64, 8*size)
+	for u := 0; u < 8; u++ {
+		for x := 0; x < size; x++ {
+			cosines[u*size+x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * size))
+		}
+	}
+
+	rows := make([]float64, size*8)
+	for y := 0; y < size; y++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for x := 0; x < size; x++ {
+				sum += values[y*size+x] * cosines[u*size+x]
+			}
+			rows[y*8+u] = sum
+		}
+	}
+
+	coefficients := make([]float64, 64)
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for y := 0; y < size; y++ {
+				sum += rows[y*8+u] * cosines[v*size+y]
+			}
+			coefficients[v*8+u] = sum
+		}
+	}
+
+	sorted := append([]float64(nil), coefficients...)
+	sort.Float64s(sorted)
+	median := (sorted[31] + sorted[32]) / 2
+
+	return hashAbove(coefficients, median)
+}
+
+// Similar returns the similarity score of the image and other, from 0 for
+// unrelated images to 1 for identical ones, and whether it reaches
+// threshold. It combines the distance of their HashPerceptual hashes with
+// the difference of their colors at a 16x16 thumbnail, so re-uploads that
+// were resized, recompressed or slightly edited keep a high score, usually
+// above 0.9. The sizes of the images may differ. Images that cannot be
+// hashed, see PerceptualHash, score 0
+// i.e :
+// score, duplicate := upload.Similar(banned, 0.9)
+func (i *Imager) Similar(other *Imager, threshold float64) (float64, bool) {
+	hash, err := i.PerceptualHash(HashPerceptual)
+	if err != nil {
+		return 0, false
+	}
+	otherHash, err := other.PerceptualHash(HashPerceptual)
+	if err != nil {
+		return 0, false
+	}
+
+	// Unrelated hashes differ by 32 bits on average
+	hashScore := math.Max(0, 1-float64(HammingDistance(hash, otherHash))/32)
+
+	a := imaging.Resize(i.Image, 16, 16, imaging.Box)
+	b := imaging.Resize(other.Image, 16, 16, imaging.Box)
+	absolutes := 0.0
+	for p := 0; p < len(a.Pix); p += 4 {
+		for ch := 0; ch < 3; ch++ {
+			absolutes += math.Abs(float64(a.Pix[p+ch]) - float64(b.Pix[p+ch]))
+		}
+	}
+	pixelScore := math.Max(0, 1-absolutes/float64(len(a.Pix)/4*3)/128)
+
+	score := (hashScore + pixelScore) / 2
+	return score, score >= threshold
+}
@@ -0,0 +1,102 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// createPatternImage returns an image with diagonal stripes and a circle
+func createPatternImage(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx, fy := float64(x)/float64(width), float64(y)/float64(height)
+			v := uint8(255 * fx * fy)
+			if (fx-0.6)*(fx-0.6)+(fy-0.4)*(fy-0.4) < 0.04 {
+				v = 255 - v
+			}
+			img.Set(x, y, color.NRGBA{v, v / 2, 255 - v, 255})
+		}
+	}
+
+	return img
+}
+
+func TestPerceptualHash(t *testing.T) {
+	imgr, _ := NewImager(createPatternImage(300, 200))
+	resized, _ := NewImager(createPatternImage(300, 200))
+	resized.Resize(150, 100, MD_STRETCH).AdjustBrightness(10)
+	flipped, _ := NewImager(createPatternImage(300, 200))
+	flipped.FlipH().FlipV()
+
+	for _, algo := range []HashAlgorithm{HashAverage, HashDifference, HashPerceptual} {
+		hash, err := imgr.PerceptualHash(algo)
+		if err != nil {
+			t.Fatalf("PerceptualHash(%d) returned an error: %v", algo, err)
+		}
+
+		similar, _ := resized.PerceptualHash(algo)
+		if d := HammingDistance(hash, similar); d > 8 {
+			t.Fatalf("PerceptualHash(%d) distance to the resized image is %d", algo, d)
+		}
+
+		different, _ := flipped.PerceptualHash(algo)
+		if d := HammingDistance(hash, different); d < 16 {
+			t.Fatalf("PerceptualHash(%d) distance to the flipped image is %d", algo, d)
+		}
+	}
+
+	if _, err := imgr.PerceptualHash(HashAlgorithm(42)); err == nil {
+		t.Fatalf("PerceptualHash did not return an error for an unknown algorithm")
+	}
+
+	empty, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 0, 0)))
+	if _, err := empty.PerceptualHash(HashPerceptual); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for an empty image, got %v", err)
+	}
+
+	failed, _ := NewImager(createPatternImage(30, 20))
+	failed.Crop(100, 100, 0, 0)
+	if _, err := failed.PerceptualHash(HashPerceptual); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("expected the recorded error, got %v", err)
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	if d := HammingDistance(0b1011, 0b0110); d != 3 {
+		t.Fatalf("HammingDistance returned %d, want 3", d)
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	imgr, _ := NewImager(createPatternImage(300, 200))
+	if score, similar := imgr.Similar(imgr.Clone(), 1); score != 1 || !similar {
+		t.Fatalf("an image is not identical to its clone, score %f", score)
+	}
+
+	copied := imgr.Clone()
+	copied.ImageType = IMJPEG
+	data, _ := copied.Resize(150, 100, MD_STRETCH).AdjustBrightness(5).Bytes(EncodeOptions{JPEGQuality: 60})
+	reupload, err := NewImagerFromBytes(data)
+	if err != nil {
+		t.Fatalf("failed to decode the re-upload: %v", err)
+	}
+	if score, similar := imgr.Similar(reupload, 0.9); !similar {
+		t.Fatalf("the re-upload scored %f", score)
+	}
+
+	flipped, _ := NewImager(createPatternImage(300, 200))
+	flipped.FlipH().FlipV()
+	if score, similar := imgr.Similar(flipped, 0.9); similar {
+		t.Fatalf("the flipped image scored %f", score)
+	}
+
+	empty, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 0, 0)))
+	for _, pair := range [][2]*Imager{{imgr, empty}, {empty, imgr}} {
+		if score, similar := pair[0].Similar(pair[1], 0); score != 0 || similar {
+			t.Fatalf("an empty image scored %f", score)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"slices"
+)
+
+// heifBrands are the major brands of the ISO BMFF ftyp box of HEIF images
+var heifBrands = []string{"heic", "heix", "heim", "heis", "hevc", "hevx", "hevm", "hevs", "mif1", "msf1"}
+
+// ftypBrand returns the major brand of the ISO BMFF file in data, such as
+// heic or avif, or an empty string
+func ftypBrand(data []byte) string {
+	if len(data) < 12 || !bytes.Equal(data[4:8], []byte("ftyp")) {
+		return ""
+	}
+
+	return string(data[8:12])
+}
+
+// ftypCompatible returns the compatible brands of the ISO BMFF ftyp box
+// starting data, as far as data holds them
+func ftypCompatible(data []byte) []string {
+	if ftypBrand(data) == "" {
+		return nil
+	}
+
+	end := min(len(data), int(binary.BigEndian.Uint32(data[:4])))
+	var brands []string
+	for off := 16; off+4 <= end; off += 4 {
+		brands = append(brands, string(data[off:off+4]))
+	}
+
+	return brands
+}
+
+// isHEIF reports whether data starts like a HEIF image, such as the HEIC
+// photos taken by iPhones. AVIF images also use the generic mif1 and msf1
+// brands, they are not HEIF ones
+func isHEIF(data []byte) bool {
+	return slices.Contains(heifBrands, ftypBrand(data)) && !isAVIF(data)
+}
+
+// decodeError explains why data, starting with header, could not be decoded.
+// HEIF and AVIF are only decoded once the imagerheif and imageravif packages
+// are imported, PDF documents are rendered page by page
+func decodeError(header []byte, err error) error {
+	if err != image.ErrFormat {
+		return err
+	}
+
+	switch {
+	case isAVIF(header):
+		return fmt.Errorf("%w: AVIF images need the imageravif package", ErrUnknownFormat)
+	case isHEIF(header):
+		return fmt.Errorf("%w: HEIF images need the imagerheif package", ErrUnknownFormat)
+	case isPDF(header):
+		return fmt.Errorf("%w: PDF documents are read by NewImagerFromPage", ErrUnknownFormat)
+	}
+
+	return err
+}
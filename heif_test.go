@@ -0,0 +1,24 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestHEIFWithoutDecoder(t *testing.T) {
+	data := append([]byte{0, 0, 0, 24}, "ftypheic\x00\x00\x00\x00mif1heic"...)
+	if !isHEIF(data) {
+		t.Fatalf("isHEIF did not recognize a HEIC header")
+	}
+	if isHEIF([]byte("\x00\x00\x00\x20ftypisom")) {
+		t.Fatalf("isHEIF recognized an MP4 header")
+	}
+
+	if _, err := NewImagerFromBytes(data); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("NewImagerFromBytes returned %v, expected ErrUnknownFormat", err)
+	}
+	if _, err := NewImagerFromReader(bytes.NewReader(data)); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("NewImagerFromReader returned %v, expected ErrUnknownFormat", err)
+	}
+}
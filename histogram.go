@@ -0,0 +1,148 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/disintegration/imaging"
+)
+
+// Histogram holds the number of pixels for each 8-bit channel value
+type Histogram struct {
+	Red   [256]int
+	Green [256]int
+	Blue  [256]int
+	Luma  [256]int
+}
+
+// Histogram computes the per channel and luminance histogram of the image.
+// Luma uses the Rec. 601 weights, the image is not modified
+// i.e :
+// hist := imgr.Histogram()
+// dark := hist.Luma[0]
+func (i *Imager) Histogram() *Histogram {
+	hist := &Histogram{}
+	bounds := i.Image.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(i.Image.At(x, y)).(color.NRGBA)
+			hist.Red[c.R]++
+			hist.Green[c.G]++
+			hist.Blue[c.B]++
+			hist.Luma[luma(c)]++
+		}
+	}
+
+	return hist
+}
+
+// luma returns the Rec. 601 luminance of c
+func luma(c color.NRGBA) uint8 {
+	return uint8((299*int(c.R) + 587*int(c.G) + 114*int(c.B) + 500) / 1000)
+}
+
+// Normalize stretches the tonal range so the darkest channel value becomes 0
+// and the brightest 255. Full range and single color images are left as is
+// i.e :
+// imgr.Normalize()
+func (i *Imager) Normalize() *Imager {
+	defer i.operation("Normalize")()
+
+	return i.AutoContrast(0)
+}
+
+// AutoContrast is like Normalize but ignores the darkest and the brightest
+// clipPercent percent of the channel values, from 0 to 50, so a few
+// outliers such as specks of dust on a scan do not prevent the stretch
+// i.e :
+// imgr.AutoContrast(0.5)
+func (i *Imager) AutoContrast(clipPercent float64) *Imager {
+	if !i.checkRange("clip percent", clipPercent, 0, 50) {
+		return i
+	}
+
+	hist := i.Histogram()
+	var counts [256]int
+	total := 0
+	for v := range counts {
+		counts[v] = hist.Red[v] + hist.Green[v] + hist.Blue[v]
+		total += counts[v]
+	}
+	clip := int(float64(total) * clipPercent / 100)
+
+	low, seen := 0, 0
+	for ; low < 255; low++ {
+		if seen += counts[low]; seen > clip {
+			break
+		}
+	}
+	high, seen := 255, 0
+	for ; high > 0; high-- {
+		if seen += counts[high]; seen > clip {
+			break
+		}
+	}
+
+	if high <= low || (low == 0 && high == 255) {
+		return i
+	}
+
+	levels := levelsFunc(low, high)
+	return i.apply("AutoContrast", func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, levels)
+	})
+}
+
+// EqualizeHistogram spreads the luminance evenly over the tonal range,
+// bringing out the details of dark or washed out images. The mapping is
+// computed from the luminance and applied to the three channels, so the
+// colors keep their hue
+// i.e :
+// imgr.EqualizeHistogram()
+func (i *Imager) EqualizeHistogram() *Imager {
+	hist := i.Histogram()
+
+	var cdf [256]int
+	total, first := 0, 0
+	for v := range cdf {
+		total += hist.Luma[v]
+		cdf[v] = total
+		if first == 0 {
+			first = total
+		}
+	}
+	if total == first {
+		// A single luminance cannot be spread
+		return i
+	}
+
+	var table [256]uint8
+	for v := range table {
+		table[v] = uint8(max(0, cdf[v]-first) * 255 / (total - first))
+	}
+
+	return i.apply("EqualizeHistogram", func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			return color.NRGBA{table[c.R], table[c.G], table[c.B], c.A}
+		})
+	})
+}
+
+// levelsFunc returns a color mapping that stretches [low, high] to [0, 255]
+func levelsFunc(low, high int) func(c color.NRGBA) color.NRGBA {
+	var table [256]uint8
+	for v := range table {
+		s := (v - low) * 255 / (high - low)
+		if s < 0 {
+			s = 0
+		} else if s > 255 {
+			s = 255
+		}
+		table[v] = uint8(s)
+	}
+
+	return func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{table[c.R], table[c.G], table[c.B], c.A}
+	}
+}
@@ -0,0 +1,116 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			if x < 50 {
+				img.Set(x, y, color.Black)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	hist := imgr.Histogram()
+
+	if hist.Luma[0] != 5000 || hist.Luma[255] != 5000 {
+		t.Fatalf("Histogram returned unexpected luma counts: 0=%d 255=%d", hist.Luma[0], hist.Luma[255])
+	}
+
+	for v := 1; v < 255; v++ {
+		if hist.Luma[v] != 0 {
+			t.Fatalf("Histogram returned a count of %d for luma %d", hist.Luma[v], v)
+		}
+	}
+
+	if hist.Red[255] != 5000 || hist.Green[0] != 5000 || hist.Blue[255] != 5000 {
+		t.Fatalf("Histogram returned unexpected channel counts")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 51, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 51; x++ {
+			img.SetGray(x, y, color.Gray{uint8(100 + x)})
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	hist := imgr.Normalize().Histogram()
+
+	if hist.Luma[0] == 0 || hist.Luma[255] == 0 {
+		t.Fatalf("Normalize did not stretch the range to 0-255: 0=%d 255=%d", hist.Luma[0], hist.Luma[255])
+	}
+}
+
+func TestNormalizeFlat(t *testing.T) {
+	img := createTestImage()
+	imgr, _ := NewImager(img)
+
+	if imgr.Normalize().Image != img {
+		t.Fatalf("Normalize modified a single color image")
+	}
+}
+
+func TestAutoContrast(t *testing.T) {
+	// A narrow ramp with a black and a white speck
+	img := image.NewGray(image.Rect(0, 0, 51, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 51; x++ {
+			img.SetGray(x, y, color.Gray{uint8(100 + x)})
+		}
+	}
+	img.SetGray(0, 0, color.Gray{0})
+	img.SetGray(1, 0, color.Gray{255})
+
+	imgr, _ := NewImager(img)
+	if imgr.Normalize().Image != img {
+		t.Fatalf("Normalize stretched an image with a full range")
+	}
+
+	hist := imgr.AutoContrast(1).Histogram()
+	if hist.Luma[0] < 5 || hist.Luma[255] < 5 {
+		t.Fatalf("AutoContrast did not stretch the range past the specks: 0=%d 255=%d", hist.Luma[0], hist.Luma[255])
+	}
+
+	if err := imgr.AutoContrast(60).Err(); err == nil {
+		t.Fatalf("AutoContrast did not record an error for 60 percent")
+	}
+}
+
+func TestEqualizeHistogram(t *testing.T) {
+	// Most pixels are dark, a few are bright
+	img := image.NewGray(image.Rect(0, 0, 100, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 100; x++ {
+			v := uint8(20 + x/10)
+			if x >= 90 {
+				v = 200
+			}
+			img.SetGray(x, y, color.Gray{v})
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	imgr.EqualizeHistogram()
+
+	if c := pixel(imgr.Image, 0, 0); c.R != 0 {
+		t.Fatalf("EqualizeHistogram mapped the darkest value to %d, want 0", c.R)
+	}
+	if c := pixel(imgr.Image, 99, 0); c.R != 255 {
+		t.Fatalf("EqualizeHistogram mapped the brightest value to %d, want 255", c.R)
+	}
+	// The dark values are spread evenly
+	if c := pixel(imgr.Image, 50, 0); c.R < 130 || c.R > 160 {
+		t.Fatalf("EqualizeHistogram mapped the middle value to %d, want about 142", c.R)
+	}
+}
@@ -0,0 +1,62 @@
+package imager
+
+import "image"
+
+// defaultHistorySize is the number of snapshots kept when HistorySize is zero
+const defaultHistorySize = 10
+
+// imagerState is a copy of the pixels of an Imager, saved by Snapshot
+type imagerState struct {
+	image     image.Image
+	animation *Animation
+	focal     *focalPoint
+}
+
+// Snapshot saves a copy of the current image, restored by the next call to
+// Restore. Up to HistorySize snapshots are kept, the oldest are dropped
+// i.e :
+// imgr.Snapshot().Grayscale()
+// imgr.Restore()
+func (i *Imager) Snapshot() *Imager {
+	state := imagerState{image: cloneImage(i.Image), focal: i.focal}
+	if i.Animation != nil {
+		state.animation = i.Clone().Animation
+		state.image = state.animation.Frames[0]
+	}
+
+	size := i.HistorySize
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	if len(i.history) >= size {
+		i.history = append(i.history[:0], i.history[len(i.history)-size+1:]...)
+	}
+	i.history = append(i.history, state)
+
+	return i
+}
+
+// Restore reverts the image to the last snapshot and removes it from the
+// history, so successive calls undo further back. Nothing is done when the
+// history is empty, see Snapshots
+// i.e :
+// imgr.Restore().Restore()
+func (i *Imager) Restore() *Imager {
+	if len(i.history) == 0 {
+		return i
+	}
+
+	state := i.history[len(i.history)-1]
+	i.history[len(i.history)-1] = imagerState{}
+	i.history = i.history[:len(i.history)-1]
+	i.Image, i.Animation, i.focal = state.image, state.animation, state.focal
+
+	return i
+}
+
+// Snapshots returns the number of snapshots that can be restored
+// i.e :
+// if imgr.Snapshots() > 0 {
+func (i *Imager) Snapshots() int {
+	return len(i.history)
+}
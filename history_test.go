@@ -0,0 +1,43 @@
+package imager
+
+import (
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+
+	imgr.Snapshot().Resize(50, 50, MD_STRETCH)
+	imgr.Snapshot().Resize(10, 10, MD_STRETCH)
+	if imgr.Snapshots() != 2 {
+		t.Fatalf("Snapshots returned %d, expected 2", imgr.Snapshots())
+	}
+
+	if imgr.Restore().Image.Bounds().Dx() != 50 {
+		t.Fatalf("Restore did not revert to the last snapshot")
+	}
+	if imgr.Restore().Image.Bounds().Dx() != 100 {
+		t.Fatalf("Restore did not revert to the first snapshot")
+	}
+	if imgr.Restore().Image.Bounds().Dx() != 100 || imgr.Snapshots() != 0 {
+		t.Fatalf("Restore changed the image with an empty history")
+	}
+}
+
+func TestSnapshotHistorySize(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.HistorySize = 2
+
+	for _, size := range []int{90, 80, 70} {
+		imgr.Snapshot().Resize(size, size, MD_STRETCH)
+	}
+	if imgr.Snapshots() != 2 {
+		t.Fatalf("Snapshots returned %d, expected 2", imgr.Snapshots())
+	}
+
+	// The oldest snapshot, at 100 pixels, was dropped
+	imgr.Restore().Restore()
+	if imgr.Image.Bounds().Dx() != 90 {
+		t.Fatalf("Restore reverted to %d pixels, expected 90", imgr.Image.Bounds().Dx())
+	}
+}
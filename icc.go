@@ -0,0 +1,127 @@
+package imager
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+const (
+	// iccHeader prefixes each ICC profile chunk inside a JPEG APP2 segment
+	iccHeader = "ICC_PROFILE\x00"
+
+	// iccChunkSize is the largest part of a profile fitting in an APP2 segment
+	iccChunkSize = 0xFFFF - 2 - len(iccHeader) - 2
+
+	// pngSignature starts every PNG stream
+	pngSignature = "\x89PNG\r\n\x1a\n"
+)
+
+// jpegICC returns the ICC profile of a JPEG stream, reassembled from its
+// APP2 chunks
+func jpegICC(data []byte) []byte {
+	type chunk struct {
+		seq  byte
+		data []byte
+	}
+
+	var chunks []chunk
+	for _, segment := range jpegSegments(data) {
+		if segment.marker != 0xE2 || len(segment.data) < len(iccHeader)+2 ||
+			!bytes.HasPrefix(segment.data, []byte(iccHeader)) {
+			continue
+		}
+		chunks = append(chunks, chunk{seq: segment.data[len(iccHeader)], data: segment.data[len(iccHeader)+2:]})
+	}
+	sort.SliceStable(chunks, func(a, b int) bool { return chunks[a].seq < chunks[b].seq })
+
+	var profile []byte
+	for _, chunk := range chunks {
+		profile = append(profile, chunk.data...)
+	}
+
+	return profile
+}
+
+// insertJPEGICC inserts profile into JPEG data, split into APP2 chunks.
+// Profiles needing more than 255 chunks are dropped
+func insertJPEGICC(data []byte, profile []byte) []byte {
+	count := (len(profile) + iccChunkSize - 1) / iccChunkSize
+	if count == 0 || count > 255 {
+		return data
+	}
+
+	// Segments are inserted after SOI, so in reverse order
+	for seq := count; seq >= 1; seq-- {
+		part := profile[(seq-1)*iccChunkSize : min(seq*iccChunkSize, len(profile))]
+		payload := append([]byte(iccHeader), byte(seq), byte(count))
+		data = insertJPEGSegment(data, 0xE2, append(payload, part...))
+	}
+
+	return data
+}
+
+// pngICC returns the ICC profile of a PNG stream, from its iCCP chunk
+func pngICC(data []byte) []byte {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil
+	}
+
+	for pos := len(pngSignature); pos+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) || kind == "IDAT" {
+			return nil
+		}
+
+		if kind == "iCCP" {
+			chunk := data[pos+8 : pos+8+length]
+
+			// Profile name, null separator and compression method
+			name := bytes.IndexByte(chunk, 0)
+			if name < 0 || name+2 > len(chunk) {
+				return nil
+			}
+			r, err := zlib.NewReader(bytes.NewReader(chunk[name+2:]))
+			if err != nil {
+				return nil
+			}
+			profile, err := io.ReadAll(r)
+			if err != nil {
+				return nil
+			}
+			return profile
+		}
+
+		pos += 12 + length
+	}
+
+	return nil
+}
+
+// insertPNGICC inserts profile into PNG data as an iCCP chunk after IHDR
+func insertPNGICC(data []byte, profile []byte) []byte {
+	if len(profile) == 0 || len(data) < pngIHDREnd || !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return data
+	}
+
+	chunk := iccPNGChunk(profile)
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:pngIHDREnd]...)
+	out = append(out, chunk...)
+
+	return append(out, data[pngIHDREnd:]...)
+}
+
+// iccPNGChunk returns the iCCP chunk holding profile
+func iccPNGChunk(profile []byte) []byte {
+	data := bytes.NewBuffer(nil)
+	data.WriteString("ICC Profile\x00\x00")
+	zw := zlib.NewWriter(data)
+	zw.Write(profile)
+	zw.Close()
+
+	return pngChunk("iCCP", data.Bytes())
+}
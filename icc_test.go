@@ -0,0 +1,83 @@
+package imager
+
+import (
+	"bytes"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// createTestProfile returns fake ICC profile data of size bytes
+func createTestProfile(size int) []byte {
+	profile := make([]byte, size)
+	for k := range profile {
+		profile[k] = byte(k * 7)
+	}
+
+	return profile
+}
+
+func TestICCProfileJPEG(t *testing.T) {
+	// Large enough to be split across two APP2 segments
+	profile := createTestProfile(70000)
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, createTestImage(), nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	imgr, err := NewImagerFromBytes(insertJPEGICC(buf.Bytes(), profile))
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+	if !bytes.Equal(imgr.ICCProfile, profile) {
+		t.Fatalf("NewImagerFromBytes did not capture the ICC profile")
+	}
+
+	// The profile survives re-encoding, even when metadata is stripped
+	data, err := imgr.Resize(50, 50).StripMetadata().Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+
+	reloaded, err := NewImagerFromBytes(data)
+	if err != nil {
+		t.Fatalf("the JPEG with a profile is not valid: %v", err)
+	}
+	if !bytes.Equal(reloaded.ICCProfile, profile) {
+		t.Fatalf("Bytes dropped the ICC profile")
+	}
+}
+
+func TestICCProfilePNG(t *testing.T) {
+	profile := createTestProfile(3000)
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, createTestImage()); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	imgr, err := NewImagerFromBytes(insertPNGICC(buf.Bytes(), profile))
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+	if !bytes.Equal(imgr.ICCProfile, profile) {
+		t.Fatalf("NewImagerFromBytes did not capture the ICC profile")
+	}
+
+	data, _ := imgr.Bytes()
+	reloaded, err := NewImagerFromBytes(data)
+	if err != nil {
+		t.Fatalf("the PNG with a profile is not valid: %v", err)
+	}
+	if !bytes.Equal(reloaded.ICCProfile, profile) {
+		t.Fatalf("Bytes dropped the ICC profile")
+	}
+
+	// Dropping the profile
+	reloaded.ICCProfile = nil
+	data, _ = reloaded.Bytes()
+	if bytes.Contains(data, []byte("iCCP")) {
+		t.Fatalf("Bytes kept a dropped ICC profile")
+	}
+}
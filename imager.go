@@ -1,60 +1,177 @@
 package imager
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"fmt"
 	"image"
-	"image/gif"
-	"image/jpeg"
-	"image/png"
+	"image/color"
+	"image/draw"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
 )
 
-// Imager is a struct that can be used to manipulate an image
+// Imager is a struct that can be used to manipulate an image.
+//
+// An Imager is not safe for concurrent use, its methods replace its fields.
+// The operations never write to the pixels or the metadata they start from
+// though, they build new ones, so the images and slices handed out by an
+// Imager stay as they are. To transform one decoded image from several
+// goroutines give each its own Fork, or Clone when the pixels are to be
+// edited in place
 type Imager struct {
 	Image     image.Image
 	ImageType string
+
+	// JPEGQuality is the quality used when encoding JPEG, ranges from 1 to 100.
+	// Zero means the default quality of 100
+	JPEGQuality int
+
+	// Background fills the transparent areas when encoding to JPEG, which has
+	// no alpha channel. Nil means white
+	Background color.Color
+
+	// EXIF holds the raw EXIF (TIFF) data of the source image, if any
+	EXIF []byte
+
+	// XMP holds the XMP packet of the source image, if any
+	XMP []byte
+
+	// IPTC holds the Photoshop resources of the source image, where IPTC data
+	// is stored, if any
+	IPTC []byte
+
+	// ICCProfile holds the color profile of the source image, if any. It is
+	// embedded again when encoding JPEG and PNG, set it to nil to drop it.
+	// The colors are not converted, the pixels keep the profile color space
+	ICCProfile []byte
+
+	// Animation holds the frames of animated images, nil for still images.
+	// Image is always the first frame
+	Animation *Animation
+
+	// HistorySize is the number of snapshots kept by Snapshot, zero means 10
+	HistorySize int
+
+	original          image.Image
+	originalAnimation *Animation
+	history           []imagerState
+	metadata          MetadataPolicy
+	focal             *focalPoint
+	operationName     string
+	err               error
 }
 
 // NewImager creates a new Imager
 // i.e :
 // imgr, err := imager.NewImager(img)
 func NewImager(img image.Image) (*Imager, error) {
-	return &Imager{Image: img}, nil
+	return &Imager{Image: img, original: cloneImage(img)}, nil
 }
 
-// NewImagerFromFile creates a new Imager from a file
+// Err returns the first error recorded by a chainable operation. Once an
+// error is recorded the following operations leave the image as is and the
+// encoding methods, such as Bytes and Save, return the error. Reset clears it
+// i.e :
+// data, err := imgr.Resize(800, 0, imager.MD_SCALE).Crop(400, 400, 0, 0).Bytes()
+// if err := imgr.FitToRatio(16, 9, color.White).Err(); err != nil {
+func (i *Imager) Err() error {
+	return i.err
+}
+
+// setErr records err unless an error was already recorded
+func (i *Imager) setErr(err error) {
+	if i.err == nil {
+		i.err = err
+	}
+}
+
+// NewImagerFromFile creates a new Imager from a file, or from the storage
+// registered for the scheme of location by RegisterStorage
 // i.e :
 // imgr, err := imager.NewImagerFromFile("image.jpg")
-func NewImagerFromFile(location string) (*Imager, error) {
-	fp, err := os.Open(location)
+// imgr, err := imager.NewImagerFromFile("image.jpg", imager.WithAutoOrient())
+// imgr, err := imager.NewImagerFromFile("s3://bucket/image.jpg")
+func NewImagerFromFile(location string, opts ...LoadOption) (*Imager, error) {
+	if store, key, ok := storageFor(location); ok {
+		return NewImagerFromStorage(context.Background(), store, key, opts...)
+	}
+
+	config := newLoadConfig(opts)
+	if config.limits.MaxBytes > 0 {
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, err
+		}
+		if err := config.limits.checkSize(info.Size()); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(location)
 	if err != nil {
 		return nil, err
 	}
-	defer fp.Close()
 
-	img, imageType, err := image.Decode(fp)
+	return NewImagerFromBytes(data, opts...)
+}
+
+// NewImagerFromFS creates a new Imager from the file name of fsys, such as
+// an embed.FS or a zip.Reader
+// i.e :
+// imgr, err := imager.NewImagerFromFS(assets, "images/logo.png")
+// imgr, err := imager.NewImagerFromFS(os.DirFS("photos"), "beach.jpg", imager.WithAutoOrient())
+func NewImagerFromFS(fsys fs.FS, name string, opts ...LoadOption) (*Imager, error) {
+	config := newLoadConfig(opts)
+	if config.limits.MaxBytes > 0 {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := config.limits.checkSize(info.Size()); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return nil, err
 	}
 
-	imgr, err := NewImager(img)
-	imgr.ImageType = imageType
-
-	return imgr, err
+	return NewImagerFromBytes(data, opts...)
 }
 
 // NewImagerFromBytes creates a new Imager from bytes
 // i.e :
 // imgr, err := imager.NewImagerFromBytes(data)
-func NewImagerFromBytes(data []byte) (*Imager, error) {
-	img, imageType, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
+// imgr, err := imager.NewImagerFromBytes(data, imager.WithAutoOrient())
+func NewImagerFromBytes(data []byte, opts ...LoadOption) (*Imager, error) {
+	config := newLoadConfig(opts)
+	if err := config.limits.checkBytes(data); err != nil {
+		return nil, err
+	}
+	if config.sanitize {
+		if err := checkComplete(data); err != nil {
+			return nil, err
+		}
+	}
+
+	imgr := &Imager{}
+	if err := imgr.LoadByte(data); err != nil {
 		return nil, err
 	}
 
-	return &Imager{Image: img, ImageType: imageType}, nil
+	return imgr.applyLoadOptions(config), nil
 }
 
 const (
@@ -63,48 +180,198 @@ const (
 	IMGIF  string = "gif"
 	IMPNG  string = "png"
 	IMWEBP string = "webp"
+	IMTIFF string = "tiff"
+	IMTIF  string = "tif"
+	IMBMP  string = "bmp"
+	IMHEIF string = "heif"
+	IMAVIF string = "avif"
 )
 
 // Bytes returns the image as a byte array
-func (i *Imager) Bytes() ([]byte, error) {
-	buf := bytes.NewBuffer(nil)
-	var err error
-
-	switch i.ImageType {
-	case IMJPG, IMJPEG:
-		err = jpeg.Encode(buf, i.Image, &jpeg.Options{Quality: 100})
-	case IMPNG:
-		err = png.Encode(buf, i.Image)
-	case IMGIF:
-		err = gif.Encode(buf, i.Image, &gif.Options{})
-	}
+// i.e :
+// data, err := imgr.Bytes()
+// data, err := imgr.Bytes(imager.EncodeOptions{JPEGQuality: 80})
+func (i *Imager) Bytes(opts ...EncodeOptions) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err := i.encode(buf, i.ImageType, mergeEncodeOptions(opts))
 
-	return buf.Bytes(), err
+	return bytes.Clone(buf.Bytes()), err
 }
 
 // LoadByte loads a byte array into the image
 func (i *Imager) LoadByte(data []byte) error {
-	var err error
-	i.Image, i.ImageType, err = image.Decode(bytes.NewReader(data))
+	start := time.Now()
+	err := i.loadBytes(data)
+	i.observeDecode(start, int64(len(data)), err)
 
 	return err
 }
 
+// loadBytes decodes data into the image
+func (i *Imager) loadBytes(data []byte) error {
+	// The TIFF decoder would return the small thumbnail of the first IFD
+	if isRAW(data) {
+		if i.loadRAW(data) {
+			return nil
+		}
+		// Such as the TIFF images of Nikon scanners, the others are sensor
+		// data only
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || config.Width == 0 || config.Height == 0 {
+			return fmt.Errorf("%w: RAW file without a JPEG preview", ErrUnknownFormat)
+		}
+	}
+
+	img, imageType, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return decodeError(data, err)
+	}
+
+	i.setImage(img, imageType, data)
+	return nil
+}
+
+// setImage stores a decoded image along with the metadata found in header,
+// the beginning of the encoded data
+func (i *Imager) setImage(img image.Image, imageType string, header []byte) {
+	i.Image, i.ImageType = img, imageType
+	i.Animation = nil
+	switch {
+	case imageType == IMGIF:
+		i.Animation = decodeGIFAnimation(header)
+	case imageType == IMPNG && isAPNG(header):
+		i.Animation = decodeAPNG(header)
+	}
+	if i.Animation != nil {
+		i.Image = i.Animation.Frames[0]
+	}
+
+	i.EXIF, i.XMP, i.IPTC, i.ICCProfile = nil, nil, nil, nil
+	switch imageType {
+	case IMJPEG:
+		i.EXIF = jpegEXIF(header)
+		i.XMP = jpegSegmentData(header, 0xE1, xmpHeader)
+		i.IPTC = jpegSegmentData(header, 0xED, iptcHeader)
+		i.ICCProfile = jpegICC(header)
+	case IMPNG:
+		i.ICCProfile = pngICC(header)
+	}
+
+	// The operations and most encoders expect RGB, the CMYK profile no
+	// longer matches the pixels once converted
+	if cmyk, ok := i.Image.(*image.CMYK); ok {
+		i.Image = cmykToNRGBA(cmyk)
+		if iccColorSpace(i.ICCProfile) == "CMYK" {
+			i.ICCProfile = nil
+		}
+	}
+
+	i.snapshot()
+}
+
+// snapshot keeps a copy of the current image for Reset
+func (i *Imager) snapshot() {
+	i.original = cloneImage(i.Image)
+	i.originalAnimation = i.Animation
+}
+
 // LoadFile loads a file into the image
 func (i *Imager) LoadFile(location string) error {
-	fp, err := os.Open(location)
+	data, err := os.ReadFile(location)
 	if err != nil {
 		return err
 	}
-	defer fp.Close()
 
-	i.Image, i.ImageType, err = image.Decode(fp)
-	return err
+	return i.LoadByte(data)
 }
 
-// Save saves the image
-func (i *Imager) Save(location string) error {
-	return imaging.Save(i.Image, location)
+// Save saves the image, the format is chosen from the file extension.
+// When the extension is missing or unknown the image is encoded as ImageType,
+// or EncodeOptions.FallbackFormat when ImageType can't be encoded either.
+// WebP images are always written lossless and TIFF images deflate compressed.
+// Locations with a scheme registered by RegisterStorage are saved there, the
+// files are written while encoding and replaced once complete
+// i.e :
+// imgr.Save("image.jpg")
+// imgr.Save("image.webp")
+// imgr.Save("scan.tiff")
+// imgr.Save("image.avif", imager.EncodeOptions{AVIFQuality: 50})
+// imgr.Save("image")
+// imgr.Save("image.png", imager.EncodeOptions{PNGCompression: png.BestCompression})
+// imgr.Save("s3://bucket/image.jpg")
+func (i *Imager) Save(location string, opts ...EncodeOptions) error {
+	if store, key, ok := storageFor(location); ok {
+		return i.SaveTo(context.Background(), store, key, opts...)
+	}
+
+	imageType := i.formatFor(location)
+	if err := i.checkEncodable(imageType, mergeEncodeOptions(opts)); err != nil {
+		return err
+	}
+
+	// The image is encoded straight to a temporary file, renamed once
+	// complete so a failure leaves no truncated file behind
+	f, err := os.CreateTemp(filepath.Dir(location), ".imager-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	w := bufio.NewWriter(f)
+	err = i.encode(w, imageType, mergeEncodeOptions(opts))
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), location)
+}
+
+// encodeFor encodes the image in the format of the extension of location,
+// or as ImageType when the extension is missing or unknown
+func (i *Imager) encodeFor(location string, opts []EncodeOptions) ([]byte, error) {
+	imageType := i.formatFor(location)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := i.encode(buf, imageType, mergeEncodeOptions(opts)); err != nil {
+		return nil, err
+	}
+
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// formatFor returns the format of the extension of location, or ImageType
+// when the extension is missing or unknown
+func (i *Imager) formatFor(location string) string {
+	format, err := imaging.FormatFromFilename(location)
+	if err == nil {
+		switch format {
+		case imaging.JPEG:
+			return IMJPEG
+		case imaging.PNG:
+			return IMPNG
+		case imaging.GIF:
+			return IMGIF
+		case imaging.TIFF:
+			return IMTIFF
+		case imaging.BMP:
+			return IMBMP
+		}
+	}
+	if ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(location), ".")); ext == IMWEBP || ext == IMAVIF || registeredEncoder(ext) != nil {
+		return ext
+	}
+
+	return i.ImageType
 }
 
 // ResizeMode is a flag that can be used to resize an image
@@ -122,44 +389,270 @@ const (
 
 	// MD_STRETCH - Resize to exact dimensions without keeping the aspect ratio
 	MD_STRETCH
+
+	// MD_SMART - Crop the image to the region with the most detail
+	MD_SMART
+
+	// MD_SEAM - Scale the image to cover the dimensions then remove the seams
+	// with the least detail, see SeamCarve
+	MD_SEAM
 )
 
-// Resize resizes the image
+// Resize resizes the image, the options are a ResizeMode, MD_FIT by default,
+// and options such as WithFilter, WithGravity or WithoutEnlargement. Invalid
+// sizes record ErrInvalidArgument, see ResizeWithFilter
 // i.e :
 // imgr.Resize(100, 100, imager.MD_FIT)
 // imgr.Resize(100, 100, imager.MD_CROP)
 // imgr.Resize(100, 100, imager.MD_SCALE)
-func (i *Imager) Resize(width, height int, modes ...ResizeMode) *Imager {
-	mode := MD_FIT
-	for _, md := range modes {
-		mode = md
+// imgr.Resize(100, 100, imager.MD_SMART)
+// imgr.Resize(2000, 0, imager.MD_SCALE, imager.WithoutEnlargement())
+// imgr.Resize(100, 100, imager.MD_STRETCH, imager.WithFilter(imaging.Linear))
+// imgr.Resize(100, 100, imager.MD_CROP, imager.WithGravity(imager.AnchorBottom))
+// imgr.Resize(8000, 0, imager.MD_SCALE, imager.WithWorkers(4))
+func (i *Imager) Resize(width, height int, opts ...ResizeOption) *Imager {
+	defer i.operation("Resize")()
+
+	config := newResizeConfig(opts)
+	if !i.checkResizeSize(width, height, config.mode) {
+		return i
+	}
+	width, height = config.limit(i.Image.Bounds(), width, height)
+	if config.mode == MD_CROP && config.gravity != nil {
+		return i.CropAnchor(width, height, config.gravity)
+	}
+	if config.workers > 0 && (config.mode == MD_FIT || config.mode == MD_SCALE || config.mode == MD_STRETCH) {
+		filter := config.resampleFilter()
+		return i.apply("Resize", func(img image.Image) image.Image {
+			if config.mode == MD_FIT {
+				return fitParallel(img, width, height, filter, config.workers)
+			}
+			return resizeParallel(img, width, height, filter, config.workers)
+		})
+	}
+
+	return i.ResizeWithFilter(width, height, config.mode, config.resampleFilter())
+}
+
+// ResizeWithFilter resizes the image using filter for the resampling, the
+// crop modes don't resample and ignore it. Negative sizes record
+// ErrInvalidArgument, as do zero sizes except a single one with MD_SCALE and
+// MD_STRETCH which keeps the aspect ratio
+// i.e :
+// imgr.ResizeWithFilter(100, 100, imager.MD_FIT, imaging.Box)
+// imgr.ResizeWithFilter(100, 100, imager.MD_STRETCH, imaging.Linear)
+func (i *Imager) ResizeWithFilter(width, height int, mode ResizeMode, filter imaging.ResampleFilter) *Imager {
+	defer i.operation("ResizeWithFilter")()
+
+	if !i.checkResizeSize(width, height, mode) {
+		return i
 	}
+
 	switch mode {
 	case MD_SCALE:
 		// Resize keeping the aspect ratio
-		i.Image = imaging.Resize(i.Image, width, height, imaging.Lanczos)
+		i.apply("ResizeWithFilter", func(img image.Image) image.Image {
+			return imaging.Resize(img, width, height, filter)
+		})
 	case MD_CROP:
-		// Crop the image to the center
-		i.Image = imaging.CropCenter(i.Image, width, height)
+		// Crop the image to the center, or around the focal point
+		if i.focal != nil {
+			return i.CropAnchor(width, height, *i.focal)
+		}
+		i.apply("ResizeWithFilter", func(img image.Image) image.Image {
+			return imaging.CropCenter(img, width, height)
+		})
 	case MD_FIT:
 		// Fit the image within the specified dimensions, maintaining the aspect ratio
-		i.Image = imaging.Fit(i.Image, width, height, imaging.Lanczos)
+		i.apply("ResizeWithFilter", func(img image.Image) image.Image {
+			return imaging.Fit(img, width, height, filter)
+		})
 	case MD_STRETCH:
 		// Resize to exact dimensions without keeping the aspect ratio
-		i.Image = imaging.Resize(i.Image, width, height, imaging.NearestNeighbor)
+		i.apply("ResizeWithFilter", func(img image.Image) image.Image {
+			return imaging.Resize(img, width, height, filter)
+		})
+	case MD_SMART:
+		// Crop the image to the region with the most detail
+		i.SmartCrop(width, height)
+	case MD_SEAM:
+		// Scale to cover the dimensions then carve the excess
+		bounds := i.Image.Bounds()
+		scale := max(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+		coverW, coverH := max(width, int(float64(bounds.Dx())*scale+0.5)), max(height, int(float64(bounds.Dy())*scale+0.5))
+		i.apply("ResizeWithFilter", func(img image.Image) image.Image {
+			return seamCarve(imaging.Resize(img, coverW, coverH, filter), width, height)
+		})
 	}
 
 	return i
 }
 
-// Crop crops the image
+// Crop crops the image to the width x height area whose top left corner is
+// at x, y. Areas reaching out of the image record ErrOutOfBounds
+// i.e :
+// imgr.Crop(400, 300, 100, 50)
 func (i *Imager) Crop(width, height int, x, y int) *Imager {
-	i.Image = imaging.Crop(i.Image, image.Rect(x, y, x+width, y+height))
-	return i
+	if width <= 0 || height <= 0 {
+		i.setErr(fmt.Errorf("%w: crop size %dx%d", ErrInvalidArgument, width, height))
+		return i
+	}
+	if rect := image.Rect(x, y, x+width, y+height); i.err == nil && !rect.In(i.Image.Bounds()) {
+		i.setErr(fmt.Errorf("%w: crop %v of a %v image", ErrOutOfBounds, rect, i.Image.Bounds()))
+		return i
+	}
+
+	i.cropFocal(i.Image.Bounds(), image.Rect(x, y, x+width, y+height))
+	return i.apply("Crop", func(img image.Image) image.Image {
+		return imaging.Crop(img, image.Rect(x, y, x+width, y+height))
+	})
 }
 
-// Rotate rotates the image
+// Rotate rotates the image counter-clockwise, expanding the canvas with
+// transparent corners. Multiples of 90 degrees take a fast path without
+// resampling, see RotateWith for the other options
+// i.e :
+// imgr.Rotate(90)
+// imgr.Rotate(-45)
 func (i *Imager) Rotate(degrees int) *Imager {
-	i.Image = imaging.Rotate(i.Image, float64(degrees), &image.Uniform{})
+	defer i.operation("Rotate")()
+
+	if degrees%360 == 0 {
+		return i
+	}
+
+	return i.RotateWith(float64(degrees), RotateOptions{})
+}
+
+// Reset reverts all the edits, restoring the image as it was loaded, and
+// clears the recorded error and the focal point
+// i.e :
+// imgr.Resize(100, 100).Reset()
+func (i *Imager) Reset() *Imager {
+	i.err, i.focal = nil, nil
+	if i.original != nil {
+		i.Image = cloneImage(i.original)
+	}
+	i.Animation = i.originalAnimation
+	if i.Animation != nil {
+		i.Image = i.Animation.Frames[0]
+	}
+
 	return i
 }
+
+// Fork returns a copy of the imager sharing its pixels and metadata, which
+// is cheap whatever the image size. The operations leave the shared data as
+// is, so the imager and its forks can each be transformed by their own
+// goroutine. The shared data must not be edited in place, through Image or
+// EXIF, use Clone for that. The snapshot history is not copied
+// i.e :
+// go func(imgr *imager.Imager) { imgr.Resize(200, 200).Save("thumb.jpg") }(source.Fork())
+func (i *Imager) Fork() *Imager {
+	fork := *i
+	fork.history = nil
+	if i.Animation != nil {
+		anim := *i.Animation
+		fork.Animation = &anim
+	}
+
+	return &fork
+}
+
+// Clone returns a deep copy of the imager, edits made to either one leave the
+// other untouched. Use it to produce several variants of an image decoded once.
+// The snapshot history is not copied
+// i.e :
+// thumb := imgr.Clone().Resize(200, 200, imager.MD_CROP)
+func (i *Imager) Clone() *Imager {
+	clone := *i
+	clone.history = nil
+	clone.Image = cloneImage(i.Image)
+	clone.EXIF = slices.Clone(i.EXIF)
+	clone.XMP = slices.Clone(i.XMP)
+	clone.IPTC = slices.Clone(i.IPTC)
+	clone.ICCProfile = slices.Clone(i.ICCProfile)
+
+	if i.Animation != nil {
+		anim := *i.Animation
+		anim.Frames = make([]image.Image, len(i.Animation.Frames))
+		for idx, frame := range i.Animation.Frames {
+			anim.Frames[idx] = cloneImage(frame)
+		}
+		anim.Delays = slices.Clone(i.Animation.Delays)
+		clone.Animation = &anim
+		clone.Image = anim.Frames[0]
+	}
+
+	return &clone
+}
+
+// cloneImage returns a deep copy of img keeping its concrete type for the
+// types of the image package, other types are copied to *image.NRGBA
+func cloneImage(img image.Image) image.Image {
+	switch img := img.(type) {
+	case nil:
+		return nil
+	case *image.RGBA:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.NRGBA:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.RGBA64:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.NRGBA64:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.Gray:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.Gray16:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.Alpha:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.CMYK:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		return &clone
+	case *image.Paletted:
+		clone := *img
+		clone.Pix = slices.Clone(img.Pix)
+		clone.Palette = slices.Clone(img.Palette)
+		return &clone
+	case *image.YCbCr:
+		clone := *img
+		clone.Y, clone.Cb, clone.Cr = slices.Clone(img.Y), slices.Clone(img.Cb), slices.Clone(img.Cr)
+		return &clone
+	}
+
+	return imaging.Clone(img)
+}
+
+// ToRGBA converts the image to a *image.RGBA keeping its bounds, so callers
+// can rely on a single pixel model whatever the decoder returned. Note that
+// the transformations return *image.NRGBA, call ToRGBA after them when the
+// concrete type matters
+// i.e :
+// rgba := imgr.ToRGBA().Image.(*image.RGBA)
+func (i *Imager) ToRGBA() *Imager {
+	return i.apply("ToRGBA", func(img image.Image) image.Image {
+		if _, ok := img.(*image.RGBA); ok {
+			return img
+		}
+
+		dst := image.NewRGBA(img.Bounds())
+		draw.Draw(dst, dst.Bounds(), img, dst.Bounds().Min, draw.Src)
+		return dst
+	})
+}
@@ -2,11 +2,20 @@ package imager
 
 import (
 	"bytes"
+	"errors"
 	"image"
 	"image/color"
 	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"math"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"testing/fstest"
+
+	"github.com/disintegration/imaging"
 )
 
 // createTestImage creates a simple 100x100 red image for testing
@@ -118,3 +127,300 @@ func TestBytes(t *testing.T) {
 		t.Fatalf("Decoded image bounds do not match original: got %v", decodedImg.Bounds())
 	}
 }
+
+func TestSaveWithoutExtension(t *testing.T) {
+	imgr, err := NewImager(createTestImage())
+	if err != nil {
+		t.Fatalf("NewImager returned an error: %v", err)
+	}
+	imgr.ImageType = IMPNG
+
+	location := filepath.Join(t.TempDir(), "image")
+	if err := imgr.Save(location); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	saved, err := NewImagerFromFile(location)
+	if err != nil {
+		t.Fatalf("failed to load saved image: %v", err)
+	}
+
+	if saved.ImageType != IMPNG {
+		t.Fatalf("Save wrote an unexpected image type: %v", saved.ImageType)
+	}
+
+	if saved.Image.Bounds() != imgr.Image.Bounds() {
+		t.Fatalf("Saved image bounds do not match original: got %v", saved.Image.Bounds())
+	}
+}
+
+func TestSaveUnknownType(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+
+	if err := imgr.Save(filepath.Join(t.TempDir(), "image")); err == nil {
+		t.Fatalf("Save did not return an error without extension nor image type")
+	}
+}
+
+func TestJPEGQuality(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMJPEG
+
+	best, _ := imgr.Bytes()
+	imgr.JPEGQuality = 10
+	low, _ := imgr.Bytes()
+
+	if len(low) >= len(best) {
+		t.Fatalf("JPEGQuality did not reduce the output size: %d >= %d", len(low), len(best))
+	}
+}
+
+func TestToRGBA(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, createTestImage(), nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	imgr, err := NewImagerFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+
+	if _, ok := imgr.Image.(*image.YCbCr); !ok {
+		t.Fatalf("expected a YCbCr image from the JPEG decoder, got %T", imgr.Image)
+	}
+	bounds := imgr.Image.Bounds()
+
+	rgba, ok := imgr.ToRGBA().Image.(*image.RGBA)
+	if !ok {
+		t.Fatalf("ToRGBA returned %T, want *image.RGBA", imgr.Image)
+	}
+
+	if rgba.Bounds() != bounds {
+		t.Fatalf("ToRGBA changed the bounds: got %v, want %v", rgba.Bounds(), bounds)
+	}
+}
+
+func TestResizeWithFilter(t *testing.T) {
+	gradient := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			gradient.Set(x, y, color.RGBA{uint8(x * 2), uint8(y * 2), uint8((x + y) % 256), 255})
+		}
+	}
+
+	nearest, _ := NewImager(gradient)
+	nearest.ResizeWithFilter(37, 37, MD_SCALE, imaging.NearestNeighbor)
+
+	lanczos, _ := NewImager(gradient)
+	lanczos.ResizeWithFilter(37, 37, MD_SCALE, imaging.Lanczos)
+
+	psnr, err := nearest.CompareTo(lanczos)
+	if err != nil {
+		t.Fatalf("CompareTo returned an error: %v", err)
+	}
+
+	if math.IsInf(psnr, 1) {
+		t.Fatalf("ResizeWithFilter produced the same output for different filters")
+	}
+}
+
+func TestReset(t *testing.T) {
+	img := createTestImage()
+	imgr, _ := NewImager(img)
+
+	imgr.Resize(50, 50)
+	// In place edits of the original must not leak into the snapshot
+	img.(*image.RGBA).Set(0, 0, color.Black)
+
+	imgr.Reset()
+	if imgr.Image.Bounds().Dx() != 100 || imgr.Image.Bounds().Dy() != 100 {
+		t.Fatalf("Reset did not restore the original dimensions: got %v", imgr.Image.Bounds())
+	}
+
+	if r, _, _, _ := imgr.Image.At(0, 0).RGBA(); r>>8 != 255 {
+		t.Fatalf("Reset restored a modified original")
+	}
+}
+
+func TestClone(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.EXIF = []byte{1, 2, 3}
+
+	clone := imgr.Clone()
+	clone.Image.(*image.RGBA).Set(0, 0, color.RGBA{0, 0, 255, 255})
+	clone.EXIF[0] = 9
+	clone.Resize(10, 10, MD_STRETCH)
+
+	if got := color.RGBAModel.Convert(imgr.Image.At(0, 0)); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Fatalf("editing the clone changed the original pixels: %v", got)
+	}
+	if imgr.Image.Bounds().Dx() != 100 || imgr.EXIF[0] != 1 {
+		t.Fatalf("editing the clone changed the original")
+	}
+	if clone.Image.Bounds().Dx() != 10 {
+		t.Fatalf("Resize did not apply to the clone")
+	}
+
+	// The concrete type is kept
+	if _, ok := imgr.Clone().Image.(*image.RGBA); !ok {
+		t.Fatalf("Clone changed the image type to %T", imgr.Clone().Image)
+	}
+}
+
+func TestFork(t *testing.T) {
+	source, _ := NewImager(createGradientImage())
+	source.ImageType = IMPNG
+	source.EXIF = []byte{1, 2, 3}
+	want := pixel(source.Image, 30, 60)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func(imgr *Imager, n int) {
+			defer wg.Done()
+			imgr.Resize(20+n, 20, MD_CROP).GaussianBlur(1).AdjustHue(float64(n * 10))
+			if _, err := imgr.Bytes(); err != nil {
+				t.Errorf("Bytes returned an error: %v", err)
+			}
+			imgr.Reset()
+		}(source.Fork(), n)
+	}
+	wg.Wait()
+
+	if got := pixel(source.Image, 30, 60); got != want || source.Image.Bounds().Dx() != 100 {
+		t.Fatalf("transforming the forks changed the source: %v", got)
+	}
+	if fork := source.Fork(); &fork.EXIF[0] != &source.EXIF[0] {
+		t.Fatalf("Fork copied the metadata")
+	}
+}
+
+func TestNewImagerFromFS(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, createTestImage()); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	fsys := fstest.MapFS{"images/red.png": &fstest.MapFile{Data: buf.Bytes()}}
+
+	imgr, err := NewImagerFromFS(fsys, "images/red.png")
+	if err != nil {
+		t.Fatalf("NewImagerFromFS returned an error: %v", err)
+	}
+	if imgr.ImageType != IMPNG || imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("NewImagerFromFS loaded a %s of %v", imgr.ImageType, imgr.Image.Bounds())
+	}
+
+	if _, err := NewImagerFromFS(fsys, "images/missing.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("NewImagerFromFS returned %v for a missing file", err)
+	}
+	if _, err := NewImagerFromFS(fsys, "images/red.png", WithDecodeLimits(DecodeLimits{MaxBytes: 10})); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("NewImagerFromFS returned %v above the limits", err)
+	}
+}
+
+func TestResizeWithoutEnlargement(t *testing.T) {
+	tests := []struct {
+		width, height int
+		mode          ResizeMode
+		wantW, wantH  int
+	}{
+		{2000, 0, MD_SCALE, 100, 100},
+		{2000, 1000, MD_STRETCH, 100, 100},
+		{50, 2000, MD_STRETCH, 50, 100},
+		{400, 200, MD_SMART, 100, 50},
+		{80, 40, MD_CROP, 80, 40},
+	}
+	for _, tt := range tests {
+		imgr, _ := NewImager(createTestImage())
+		imgr.Resize(tt.width, tt.height, tt.mode, WithoutEnlargement())
+		if b := imgr.Image.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("Resize(%d, %d, %v) returned %dx%d, expected %dx%d", tt.width, tt.height, tt.mode, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+
+	// Upscaling still happens by default
+	imgr, _ := NewImager(createTestImage())
+	if imgr.Resize(200, 0, MD_SCALE).Image.Bounds().Dx() != 200 {
+		t.Fatalf("Resize did not enlarge the image without WithoutEnlargement")
+	}
+}
+
+func TestResizeInvalidSize(t *testing.T) {
+	tests := []struct {
+		width, height int
+		mode          ResizeMode
+	}{
+		{-10, 10, MD_FIT},
+		{0, 10, MD_FIT},
+		{10, 0, MD_CROP},
+		{0, 0, MD_SCALE},
+		{10, -1, MD_STRETCH},
+		{0, 10, MD_SMART},
+	}
+	for _, tt := range tests {
+		imgr, _ := NewImager(createTestImage())
+		imgr.Resize(tt.width, tt.height, tt.mode)
+		if !errors.Is(imgr.Err(), ErrInvalidArgument) {
+			t.Errorf("Resize(%d, %d, %v) recorded %v", tt.width, tt.height, tt.mode, imgr.Err())
+		}
+		if imgr.Image.Bounds().Dx() != 100 {
+			t.Errorf("Resize(%d, %d, %v) changed the image", tt.width, tt.height, tt.mode)
+		}
+	}
+}
+
+func TestResizeWithFilterOption(t *testing.T) {
+	gradient := createGradientImage()
+
+	// MD_STRETCH uses NearestNeighbor by default, WithFilter changes it
+	for _, mode := range []ResizeMode{MD_STRETCH, MD_FIT, MD_SCALE} {
+		byOption, _ := NewImager(gradient)
+		byOption.Resize(37, 37, mode, WithFilter(imaging.Box))
+
+		byMethod, _ := NewImager(gradient)
+		byMethod.ResizeWithFilter(37, 37, mode, imaging.Box)
+
+		if c, _ := byOption.Compare(byMethod); c.MAE != 0 {
+			t.Errorf("WithFilter was not applied with mode %v", mode)
+		}
+	}
+
+	stretched, _ := NewImager(gradient)
+	stretched.Resize(37, 37, MD_STRETCH)
+	linear, _ := NewImager(gradient)
+	linear.Resize(37, 37, MD_STRETCH, WithFilter(imaging.Linear))
+	if c, _ := stretched.Compare(linear); c.MAE == 0 {
+		t.Fatalf("WithFilter did not change the MD_STRETCH filter")
+	}
+}
+
+func TestErrPropagation(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMPNG
+
+	_, err := imgr.Resize(50, 50, MD_STRETCH).Crop(40, 40, 20, 20).GaussianBlur(2).Bytes()
+	if !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("Bytes returned %v, want ErrOutOfBounds", err)
+	}
+	if imgr.Image.Bounds().Dx() != 50 {
+		t.Fatalf("the operations after the error changed the image: %v", imgr.Image.Bounds())
+	}
+	if err := imgr.Save(filepath.Join(t.TempDir(), "out.png")); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("Save returned %v, want ErrOutOfBounds", err)
+	}
+
+	if err := imgr.Reset().Crop(40, 40, 20, 20).Err(); err != nil {
+		t.Fatalf("Reset did not clear the error: %v", err)
+	}
+	if _, err := imgr.Bytes(); err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+
+	if err := imgr.Crop(0, 10, 0, 0).Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Crop recorded %v for an empty size", err)
+	}
+	if err := imgr.Reset().RotateWith(math.NaN(), RotateOptions{}).Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("RotateWith recorded %v for NaN degrees", err)
+	}
+}
@@ -0,0 +1,25 @@
+// Package imageravif adds AVIF decoding and encoding to imager with
+// github.com/gen2brain/avif. It is a module of its own so that imager does
+// not depend on it, importing it registers the codec
+// i.e :
+// import _ "github.com/mamur-rezeki/imager/imageravif"
+package imageravif
+
+import (
+	"image"
+	"io"
+
+	"github.com/gen2brain/avif"
+	"github.com/mamur-rezeki/imager"
+)
+
+// Importing avif registers the "avif" format with the image package, the
+// encoder is registered with imager
+func init() {
+	imager.RegisterFormat(imager.IMAVIF, "", nil, encode)
+}
+
+// encode writes img to w as AVIF, imager sets the AVIF options of opts
+func encode(w io.Writer, img image.Image, opts imager.EncodeOptions) error {
+	return avif.Encode(w, img, avif.Options{Quality: opts.AVIFQuality, QualityAlpha: opts.AVIFQuality, Speed: opts.AVIFSpeed})
+}
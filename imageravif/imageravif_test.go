@@ -0,0 +1,32 @@
+package imageravif
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/mamur-rezeki/imager"
+)
+
+func TestRoundTrip(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x * 8), 0, 200, 255})
+		}
+	}
+
+	imgr, _ := imager.NewImager(img)
+	data, err := imgr.ConvertTo(imager.IMAVIF).Bytes()
+	if err != nil {
+		t.Fatalf("encoding as AVIF returned an error: %v", err)
+	}
+
+	out, err := imager.NewImagerFromBytes(data)
+	if err != nil {
+		t.Fatalf("decoding the AVIF image returned an error: %v", err)
+	}
+	if b := out.Image.Bounds(); out.ImageType != imager.IMAVIF || b.Dx() != 32 || b.Dy() != 16 {
+		t.Errorf("expected a 32x16 avif image, got %s %v", out.ImageType, b)
+	}
+}
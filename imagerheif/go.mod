@@ -0,0 +1,5 @@
+module github.com/mamur-rezeki/imager/imagerheif
+
+go 1.22.2
+
+require github.com/strukturag/libheif v1.17.6
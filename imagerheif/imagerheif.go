@@ -0,0 +1,10 @@
+// Package imagerheif adds HEIF decoding to imager with libheif, through cgo
+// and the libheif library. It is a module of its own so that imager does not
+// depend on it. Importing it registers the "heif" format with the image
+// package, HEIC photos are then loaded like any other image and can be
+// converted to JPEG or WebP
+// i.e :
+// import _ "github.com/mamur-rezeki/imager/imagerheif"
+package imagerheif
+
+import _ "github.com/strukturag/libheif/go/heif"
@@ -0,0 +1,328 @@
+// Package imagerhttp serves images transformed on the fly by imager, driven
+// by URL parameters
+package imagerhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mamur-rezeki/imager"
+)
+
+// Source fetches the original image stored at a path
+type Source interface {
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// SourceFunc adapts a function to Source
+type SourceFunc func(ctx context.Context, name string) (io.ReadCloser, error)
+
+// Open calls f
+func (f SourceFunc) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return f(ctx, name)
+}
+
+// DirSource returns a Source reading the images below root
+// i.e :
+// source := imagerhttp.DirSource("/var/images")
+func DirSource(root string) Source {
+	return SourceFunc(func(ctx context.Context, name string) (io.ReadCloser, error) {
+		// Cleaning as an absolute path keeps the name within root
+		return os.Open(filepath.Join(root, filepath.FromSlash(path.Clean("/"+name))))
+	})
+}
+
+// StorageSource returns a Source reading the images of store, such as an
+// imager.S3Storage
+// i.e :
+// source := imagerhttp.StorageSource(imager.GCSStorage("my-bucket", accessID, secret))
+func StorageSource(store imager.Storage) Source {
+	return SourceFunc(store.Get)
+}
+
+// DefaultCacheControl is the Cache-Control header of the transformed images
+const DefaultCacheControl = "public, max-age=86400"
+
+// defaultMaxSize is the largest width and height served by default
+const defaultMaxSize = 4096
+
+// contentTypes holds the Content-Type of each output format
+var contentTypes = map[string]string{
+	imager.IMJPEG: "image/jpeg",
+	imager.IMPNG:  "image/png",
+	imager.IMGIF:  "image/gif",
+	imager.IMWEBP: "image/webp",
+	imager.IMAVIF: "image/avif",
+}
+
+// formatAuto is the format parameter picking the format from the Accept
+// header of the request, see imager.BestFormat
+const formatAuto = "auto"
+
+// Handler is an http.Handler serving the images of Source transformed
+// according to the URL parameters:
+//
+//	width or w, height or h  the size to resize to, with a single dimension
+//	                         the aspect ratio is kept
+//	mode                     the resize mode: fit, crop, scale, stretch, smart or seam
+//	format                   jpeg, png, gif, webp or avif, the source format by default.
+//	                         auto picks the best format accepted by the client
+//	quality                  the JPEG quality, from 1 to 100
+//	focal                    the focal point kept in frame by the crop modes, as x,y
+//	                         fractions of the width and height such as 0.3,0.25
+//	preset                   a preset registered by imager.RegisterPreset, run
+//	                         before the resize
+//
+// The request path, without its leading slash, is the name of the source image
+type Handler struct {
+	// Source fetches the original images
+	Source Source
+
+	// CacheControl is the Cache-Control header of the responses, empty uses
+	// DefaultCacheControl
+	CacheControl string
+
+	// MaxWidth and MaxHeight limit the requested size, zero means 4096
+	MaxWidth  int
+	MaxHeight int
+
+	// Secret, when set, makes the handler only serve URLs signed with it,
+	// see SignURL. Requests with a missing or wrong signature are forbidden.
+	// The signed path is the one seen by the handler, after http.StripPrefix
+	Secret []byte
+
+	// Cache, when set, keeps the transformed images so the same source
+	// transformed the same way is only processed once, see imager.MemoryCache.
+	// The sources are still fetched, their content is part of the key
+	Cache imager.Cache
+}
+
+// NewHandler creates a Handler serving the images of source
+// i.e :
+// http.Handle("/images/", http.StripPrefix("/images/", imagerhttp.NewHandler(imagerhttp.DirSource("static"))))
+func NewHandler(source Source) *Handler {
+	return &Handler{Source: source}
+}
+
+// ServeHTTP transforms and writes the requested image
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.Secret != nil && !Verify(h.Secret, r.URL.Path, r.URL.Query()) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	pipeline, format, opts, err := h.parseQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	src, err := h.Source.Open(r.Context(), strings.TrimPrefix(r.URL.Path, "/"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "failed to fetch the image", http.StatusBadGateway)
+		}
+		return
+	}
+	defer src.Close()
+
+	negotiated := format == formatAuto
+	var data []byte
+	if h.Cache == nil {
+		data, format, err = h.transform(r, src, pipeline, format, opts)
+	} else {
+		data, format, err = h.transformCached(r, src, pipeline, format, opts)
+	}
+	if err != nil {
+		var status *statusError
+		if errors.As(err, &status) {
+			http.Error(w, status.msg, status.code)
+		} else {
+			http.Error(w, "failed to fetch the image", http.StatusBadGateway)
+		}
+		return
+	}
+	if negotiated {
+		w.Header().Set("Vary", "Accept")
+	}
+
+	cacheControl := h.CacheControl
+	if cacheControl == "" {
+		cacheControl = DefaultCacheControl
+	}
+
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", cacheControl)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// statusError is an error answered with its HTTP status code
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// transform decodes the source image read from src, runs pipeline on it and
+// returns it encoded along with its format
+func (h *Handler) transform(r *http.Request, src io.Reader, pipeline *imager.Pipeline, format string, opts imager.EncodeOptions) ([]byte, string, error) {
+	imgr, err := imager.NewImagerFromReader(src, imager.WithAutoOrient())
+	if err != nil {
+		return nil, "", &statusError{http.StatusUnsupportedMediaType, "unsupported image"}
+	}
+	if err := pipeline.Run(imgr); err != nil {
+		return nil, "", &statusError{http.StatusBadRequest, err.Error()}
+	}
+
+	if format == formatAuto {
+		format = imgr.BestFormat(r.Header.Get("Accept"), imager.BestFormatOptions{})
+	}
+	if format == "" {
+		format = imgr.ImageType
+	}
+	if _, ok := contentTypes[format]; !ok {
+		// Sources in other formats are served as PNG
+		format = imager.IMPNG
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := imgr.Encode(buf, format, opts); err != nil {
+		return nil, "", &statusError{http.StatusInternalServerError, "failed to encode the image"}
+	}
+
+	return buf.Bytes(), format, nil
+}
+
+// transformCached is transform reading the result from the cache when the
+// same source was transformed the same way before. The negotiated format
+// depends on the Accept header, which is part of the key
+func (h *Handler) transformCached(r *http.Request, src io.Reader, pipeline *imager.Pipeline, format string, opts imager.EncodeOptions) ([]byte, string, error) {
+	source, err := io.ReadAll(src)
+	if err != nil {
+		return nil, "", err
+	}
+
+	variant := format
+	if format == formatAuto {
+		variant += ";" + r.Header.Get("Accept")
+	}
+	key := imager.CacheKey(source, pipeline, variant, opts)
+	if data, ok := h.Cache.Get(r.Context(), key); ok {
+		if format, err := imager.DetectFormat(data); err == nil {
+			return data, format, nil
+		}
+	}
+
+	data, format, err := h.transform(r, bytes.NewReader(source), pipeline, format, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	h.Cache.Put(r.Context(), key, data)
+
+	return data, format, nil
+}
+
+// parseQuery returns the transformation requested by the URL parameters
+func (h *Handler) parseQuery(r *http.Request) (*imager.Pipeline, string, imager.EncodeOptions, error) {
+	query := r.URL.Query()
+	var opts imager.EncodeOptions
+
+	integer := func(names ...string) (int, error) {
+		for _, name := range names {
+			if value := query.Get(name); value != "" {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					return 0, fmt.Errorf("invalid %s %q", name, value)
+				}
+				return n, nil
+			}
+		}
+		return 0, nil
+	}
+
+	width, err := integer("width", "w")
+	if err != nil {
+		return nil, "", opts, err
+	}
+	height, err := integer("height", "h")
+	if err != nil {
+		return nil, "", opts, err
+	}
+	if opts.JPEGQuality, err = integer("quality"); err != nil || opts.JPEGQuality > 100 {
+		return nil, "", opts, fmt.Errorf("invalid quality %q", query.Get("quality"))
+	}
+
+	maxWidth, maxHeight := h.MaxWidth, h.MaxHeight
+	if maxWidth <= 0 {
+		maxWidth = defaultMaxSize
+	}
+	if maxHeight <= 0 {
+		maxHeight = defaultMaxSize
+	}
+	if width > maxWidth || height > maxHeight {
+		return nil, "", opts, fmt.Errorf("size %dx%d exceeds %dx%d", width, height, maxWidth, maxHeight)
+	}
+
+	format := strings.ToLower(query.Get("format"))
+	if format == imager.IMJPG {
+		format = imager.IMJPEG
+	}
+	if _, ok := contentTypes[format]; format != "" && format != formatAuto && !ok {
+		return nil, "", opts, fmt.Errorf("unsupported format %q", format)
+	}
+
+	pipeline := imager.NewPipeline()
+	if name := query.Get("preset"); name != "" {
+		preset, ok := imager.Preset(name)
+		if !ok {
+			return nil, "", opts, fmt.Errorf("unknown preset %q", name)
+		}
+		pipeline = preset
+	}
+	if value := query.Get("focal"); value != "" {
+		xPart, yPart, _ := strings.Cut(value, ",")
+		x, errX := strconv.ParseFloat(xPart, 64)
+		y, errY := strconv.ParseFloat(yPart, 64)
+		if errX != nil || errY != nil {
+			return nil, "", opts, fmt.Errorf("invalid focal %q", value)
+		}
+		pipeline.SetFocalPoint(x, y)
+	}
+	if width > 0 || height > 0 {
+		mode := query.Get("mode")
+		if width == 0 || height == 0 {
+			// A single dimension is scaled keeping the aspect ratio
+			mode = "scale"
+		}
+		pipeline.Add(imager.Op{Name: "resize", Width: width, Height: height, Mode: mode})
+	}
+	if err := pipeline.Validate(); err != nil {
+		return nil, "", opts, err
+	}
+
+	return pipeline, format, opts, nil
+}
@@ -0,0 +1,237 @@
+package imagerhttp
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mamur-rezeki/imager"
+)
+
+// createTestPNG returns a 100x50 PNG image
+func createTestPNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// memorySource serves a single image named photo.png
+func memorySource(data []byte) Source {
+	return SourceFunc(func(ctx context.Context, name string) (io.ReadCloser, error) {
+		if name != "photo.png" {
+			return nil, fs.ErrNotExist
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+}
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler(memorySource(createTestPNG(t)))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/photo.png?w=40&format=jpeg&quality=70", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Fatalf("unexpected Content-Type %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != DefaultCacheControl {
+		t.Fatalf("unexpected Cache-Control %q", cc)
+	}
+
+	imgr, err := imager.NewImagerFromBytes(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("the response is not an image: %v", err)
+	}
+	if imgr.ImageType != imager.IMJPEG || imgr.Image.Bounds().Dx() != 40 || imgr.Image.Bounds().Dy() != 20 {
+		t.Fatalf("unexpected image: %s %v", imgr.ImageType, imgr.Image.Bounds())
+	}
+}
+
+func TestHandlerAutoFormat(t *testing.T) {
+	handler := NewHandler(memorySource(createTestPNG(t)))
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"image/webp,image/*,*/*;q=0.8", "image/webp"},
+		{"image/*,*/*;q=0.8", "image/jpeg"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/photo.png?format=auto", nil)
+		req.Header.Set("Accept", test.accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Accept %q: expected 200, got %d: %s", test.accept, rec.Code, rec.Body)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != test.contentType {
+			t.Errorf("Accept %q: expected Content-Type %q, got %q", test.accept, test.contentType, ct)
+		}
+		if vary := rec.Header().Get("Vary"); vary != "Accept" {
+			t.Errorf("Accept %q: expected Vary Accept, got %q", test.accept, vary)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	handler := NewHandler(memorySource(createTestPNG(t)))
+	handler.MaxWidth = 500
+
+	tests := []struct {
+		target string
+		code   int
+	}{
+		{"/missing.png", http.StatusNotFound},
+		{"/photo.png?w=abc", http.StatusBadRequest},
+		{"/photo.png?w=600", http.StatusBadRequest},
+		{"/photo.png?w=10&h=10&mode=fill", http.StatusBadRequest},
+		{"/photo.png?format=bmp", http.StatusBadRequest},
+		{"/photo.png?quality=101", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.code {
+			t.Fatalf("%s: expected %d, got %d", tt.target, tt.code, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/photo.png", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "photo.png"), createTestPNG(t), 0o644); err != nil {
+		t.Fatalf("failed to write test image: %v", err)
+	}
+
+	handler := NewHandler(DirSource(filepath.Join(dir)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/photo.png?h=10&mode=crop", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	// Paths can't escape the root
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/photo.png", nil)
+	req.URL.Path = "/../" + filepath.Base(dir) + "/photo.png"
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a path outside the root, got %d", rec.Code)
+	}
+}
+
+func TestHandlerCache(t *testing.T) {
+	var opened int
+	data := createTestPNG(t)
+	handler := NewHandler(SourceFunc(func(ctx context.Context, name string) (io.ReadCloser, error) {
+		opened++
+		return memorySource(data).Open(ctx, name)
+	}))
+	handler.Cache = imager.MemoryCache(1 << 20)
+
+	var bodies [][]byte
+	for _, target := range []string{"/photo.png?w=40&format=webp", "/photo.png?w=40&format=webp", "/photo.png?w=20&format=webp"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/webp" {
+			t.Fatalf("%s: unexpected response %d %q", target, rec.Code, rec.Header().Get("Content-Type"))
+		}
+		bodies = append(bodies, rec.Body.Bytes())
+	}
+
+	if opened != 3 {
+		t.Fatalf("the source was opened %d times, want 3", opened)
+	}
+	if !bytes.Equal(bodies[0], bodies[1]) || bytes.Equal(bodies[0], bodies[2]) {
+		t.Fatalf("the cached responses don't match their requests")
+	}
+}
+
+func TestHandlerPreset(t *testing.T) {
+	imager.RegisterPreset("test-square", imager.NewPipeline().Resize(30, 30, imager.MD_CROP))
+	defer imager.RegisterPreset("test-square", nil)
+	handler := NewHandler(memorySource(createTestPNG(t)))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/photo.png?preset=test-square", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	imgr, err := imager.NewImagerFromBytes(rec.Body.Bytes())
+	if err != nil || imgr.Image.Bounds().Dx() != 30 || imgr.Image.Bounds().Dy() != 30 {
+		t.Fatalf("the preset was not applied: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/photo.png?preset=missing", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown preset, got %d", rec.Code)
+	}
+}
+
+func TestHandlerFocal(t *testing.T) {
+	// Red on the left, blue on the right
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.RGBA{uint8(255 * (1 - x/50)), 0, uint8(255 * (x / 50)), 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	handler := NewHandler(memorySource(buf.Bytes()))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/photo.png?w=50&h=50&mode=crop&focal=0.9,0.5", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	imgr, err := imager.NewImagerFromBytes(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	if r, _, b, _ := imgr.Image.At(0, 25).RGBA(); r != 0 || b == 0 {
+		t.Error("the crop should keep the blue half around the focal point")
+	}
+
+	for _, focal := range []string{"0.5", "x,0.5", "1.5,0.5"} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/photo.png?w=50&h=50&mode=crop&focal="+focal, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for focal=%s, got %d", focal, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package imagerhttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+// SignatureParam is the URL parameter holding the signature
+const SignatureParam = "sig"
+
+// Sign returns the signature of a request path and its parameters, an HMAC
+// SHA-256 of both keyed with secret. The signature parameter itself and the
+// order of the parameters are ignored
+// i.e :
+// sig := imagerhttp.Sign(secret, "/photo.jpg", url.Values{"w": {"800"}})
+func Sign(secret []byte, path string, query url.Values) string {
+	params := url.Values{}
+	for name, values := range query {
+		if name != SignatureParam {
+			params[name] = values
+		}
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("/" + strings.TrimPrefix(path, "/")))
+	mac.Write([]byte{'?'})
+	// Encode sorts the parameters by name
+	mac.Write([]byte(params.Encode()))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignURL adds the signature parameter to u, which must be relative to the
+// Handler, and returns the signed URL
+// i.e :
+// signed := imagerhttp.SignURL(secret, "/photo.jpg?w=800&h=600")
+func SignURL(secret []byte, u string) (string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsed.Query()
+	query.Set(SignatureParam, Sign(secret, parsed.Path, query))
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
+// Verify reports whether the signature parameter of query matches the path
+// and the other parameters
+// i.e :
+// ok := imagerhttp.Verify(secret, r.URL.Path, r.URL.Query())
+func Verify(secret []byte, path string, query url.Values) bool {
+	sig := query.Get(SignatureParam)
+	if sig == "" {
+		return false
+	}
+
+	return hmac.Equal([]byte(sig), []byte(Sign(secret, path, query)))
+}
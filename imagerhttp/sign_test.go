@@ -0,0 +1,61 @@
+package imagerhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	secret := []byte("secret")
+
+	sig := Sign(secret, "/photo.png", url.Values{"w": {"40"}, "h": {"20"}})
+	if sig != Sign(secret, "photo.png", url.Values{"h": {"20"}, "w": {"40"}}) {
+		t.Fatalf("the signature depends on the parameter order")
+	}
+	if sig == Sign(secret, "/photo.png", url.Values{"w": {"4000"}, "h": {"20"}}) {
+		t.Fatalf("the signature does not cover the parameters")
+	}
+	if sig == Sign(secret, "/other.png", url.Values{"w": {"40"}, "h": {"20"}}) {
+		t.Fatalf("the signature does not cover the path")
+	}
+	if sig == Sign([]byte("other"), "/photo.png", url.Values{"w": {"40"}, "h": {"20"}}) {
+		t.Fatalf("the signature does not depend on the secret")
+	}
+
+	signed, err := SignURL(secret, "/photo.png?w=40&h=20")
+	if err != nil {
+		t.Fatalf("SignURL returned an error: %v", err)
+	}
+	u, _ := url.Parse(signed)
+	if !Verify(secret, u.Path, u.Query()) {
+		t.Fatalf("Verify rejected a signed URL: %s", signed)
+	}
+}
+
+func TestHandlerSignature(t *testing.T) {
+	secret := []byte("secret")
+	handler := NewHandler(memorySource(createTestPNG(t)))
+	handler.Secret = secret
+
+	signed, _ := SignURL(secret, "/photo.png?w=40")
+	tampered := signed + "&h=4000"
+
+	tests := []struct {
+		target string
+		code   int
+	}{
+		{signed, http.StatusOK},
+		{"/photo.png?w=40", http.StatusForbidden},
+		{tampered, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.code {
+			t.Fatalf("%s: expected %d, got %d", tt.target, tt.code, rec.Code)
+		}
+	}
+}
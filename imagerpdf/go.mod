@@ -0,0 +1,19 @@
+module github.com/mamur-rezeki/imager/imagerpdf
+
+go 1.24.0
+
+require (
+	github.com/gen2brain/go-fitz v1.28.2
+	github.com/mamur-rezeki/imager v0.0.0
+)
+
+require (
+	github.com/disintegration/imaging v1.6.2 // indirect
+	github.com/ebitengine/purego v0.10.1 // indirect
+	golang.org/x/image v0.24.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/mamur-rezeki/imager => ../
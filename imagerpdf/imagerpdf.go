@@ -0,0 +1,40 @@
+// Package imagerpdf renders PDF documents for imager with MuPDF, through
+// github.com/gen2brain/go-fitz and cgo. It is a module of its own so that
+// imager does not depend on it, importing it registers the renderer used by
+// PageCount, NewImagerFromPage and EachPage
+// i.e :
+// import _ "github.com/mamur-rezeki/imager/imagerpdf"
+package imagerpdf
+
+import (
+	"image"
+
+	"github.com/gen2brain/go-fitz"
+	"github.com/mamur-rezeki/imager"
+)
+
+func init() {
+	imager.RegisterPDF(render, count)
+}
+
+// render renders the page of the PDF document in data at dpi
+func render(data []byte, page int, dpi float64) (image.Image, error) {
+	doc, err := fitz.NewFromMemory(data)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	return doc.ImageDPI(page, dpi)
+}
+
+// count returns the number of pages of the PDF document in data
+func count(data []byte) (int, error) {
+	doc, err := fitz.NewFromMemory(data)
+	if err != nil {
+		return 0, err
+	}
+	defer doc.Close()
+
+	return doc.NumPage(), nil
+}
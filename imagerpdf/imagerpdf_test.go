@@ -0,0 +1,52 @@
+package imagerpdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mamur-rezeki/imager"
+)
+
+// createPDF returns a PDF document of blank pages of 72x36 points, an inch
+// by half an inch
+func createPDF(pages int) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	var kids []string
+	for page := 0; page < pages; page++ {
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)+1))
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 72 36] >>")
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages)
+
+	doc := "%PDF-1.4\n"
+	offsets := make([]int, len(objects))
+	for idx, object := range objects {
+		offsets[idx] = len(doc)
+		doc += fmt.Sprintf("%d 0 obj\n%s\nendobj\n", idx+1, object)
+	}
+	xref := len(doc)
+	doc += fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		doc += fmt.Sprintf("%010d 00000 n \n", offset)
+	}
+	doc += fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return []byte(doc)
+}
+
+func TestRender(t *testing.T) {
+	data := createPDF(2)
+	if pages, err := imager.PageCount(data); err != nil || pages != 2 {
+		t.Fatalf("PageCount returned %d, %v, want 2 pages", pages, err)
+	}
+
+	// Pages are rendered at 150 DPI
+	imgr, err := imager.NewImagerFromPage(data, 1)
+	if err != nil {
+		t.Fatalf("NewImagerFromPage returned an error: %v", err)
+	}
+	if b := imgr.Image.Bounds(); b.Dx() != 150 || b.Dy() != 75 {
+		t.Errorf("expected a 150x75 page, got %v", b)
+	}
+}
@@ -0,0 +1,4 @@
+// Package imagerprom exports the work of imager as Prometheus metrics, see
+// NewObserver. It is a module of its own so that imager does not depend on
+// github.com/prometheus/client_golang
+package imagerprom
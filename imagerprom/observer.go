@@ -0,0 +1,75 @@
+package imagerprom
+
+import (
+	"github.com/mamur-rezeki/imager"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Observer is an imager.Observer recording the decodes, operations and
+// encodes as Prometheus histograms labelled by format or operation
+type Observer struct {
+	decodeSeconds    *prometheus.HistogramVec
+	decodeBytes      *prometheus.HistogramVec
+	operationSeconds *prometheus.HistogramVec
+	encodeSeconds    *prometheus.HistogramVec
+	encodeBytes      *prometheus.HistogramVec
+	errors           *prometheus.CounterVec
+}
+
+// sizeBuckets are the buckets of the byte sizes, from 1KB to 64MB
+var sizeBuckets = prometheus.ExponentialBuckets(1<<10, 4, 9)
+
+// NewObserver creates an Observer and registers its metrics with reg
+// i.e :
+// imager.SetObserver(imagerprom.NewObserver(prometheus.DefaultRegisterer))
+func NewObserver(reg prometheus.Registerer) *Observer {
+	histogram := func(name, help string, buckets []float64, label string) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "imager",
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		}, []string{label})
+	}
+
+	o := &Observer{
+		decodeSeconds:    histogram("decode_duration_seconds", "Time spent decoding images.", prometheus.DefBuckets, "format"),
+		decodeBytes:      histogram("decode_size_bytes", "Size of the decoded images.", sizeBuckets, "format"),
+		operationSeconds: histogram("operation_duration_seconds", "Time spent applying operations.", prometheus.DefBuckets, "operation"),
+		encodeSeconds:    histogram("encode_duration_seconds", "Time spent encoding images.", prometheus.DefBuckets, "format"),
+		encodeBytes:      histogram("encode_size_bytes", "Size of the encoded images.", sizeBuckets, "format"),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "imager",
+			Name:      "errors_total",
+			Help:      "Images that failed to decode or encode.",
+		}, []string{"stage"}),
+	}
+	reg.MustRegister(o.decodeSeconds, o.decodeBytes, o.operationSeconds, o.encodeSeconds, o.encodeBytes, o.errors)
+
+	return o
+}
+
+// OnDecode records the duration and size of a decode
+func (o *Observer) OnDecode(e imager.DecodeEvent) {
+	if e.Err != nil {
+		o.errors.WithLabelValues("decode").Inc()
+		return
+	}
+	o.decodeSeconds.WithLabelValues(e.Format).Observe(e.Duration.Seconds())
+	o.decodeBytes.WithLabelValues(e.Format).Observe(float64(e.Bytes))
+}
+
+// OnOperation records the duration of an operation
+func (o *Observer) OnOperation(e imager.OperationEvent) {
+	o.operationSeconds.WithLabelValues(e.Name).Observe(e.Duration.Seconds())
+}
+
+// OnEncode records the duration and size of an encode
+func (o *Observer) OnEncode(e imager.EncodeEvent) {
+	if e.Err != nil {
+		o.errors.WithLabelValues("encode").Inc()
+		return
+	}
+	o.encodeSeconds.WithLabelValues(e.Format).Observe(e.Duration.Seconds())
+	o.encodeBytes.WithLabelValues(e.Format).Observe(float64(e.Bytes))
+}
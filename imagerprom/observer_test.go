@@ -0,0 +1,33 @@
+package imagerprom
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mamur-rezeki/imager"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserver(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	o := NewObserver(reg)
+
+	o.OnDecode(imager.DecodeEvent{Format: imager.IMPNG, Bytes: 2048})
+	o.OnOperation(imager.OperationEvent{Name: "Resize"})
+	o.OnOperation(imager.OperationEvent{Name: "Resize"})
+	o.OnEncode(imager.EncodeEvent{Format: imager.IMJPEG, Err: errors.New("broken")})
+
+	if n := testutil.CollectAndCount(o.operationSeconds); n != 1 {
+		t.Errorf("expected a single operation series, got %d", n)
+	}
+	if n := testutil.ToFloat64(o.errors.WithLabelValues("encode")); n != 1 {
+		t.Errorf("expected an encode error, got %v", n)
+	}
+	if n := testutil.CollectAndCount(o.encodeSeconds); n != 0 {
+		t.Errorf("failed encodes should not be timed, got %d series", n)
+	}
+	if n, err := testutil.GatherAndCount(reg, "imager_decode_size_bytes"); err != nil || n != 1 {
+		t.Errorf("expected the decode size to be registered, got %d, %v", n, err)
+	}
+}
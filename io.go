@@ -0,0 +1,128 @@
+package imager
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"io"
+	"time"
+)
+
+// headerSize is the amount of encoded data kept to read the metadata of
+// images loaded from a reader, metadata is stored before the pixels
+const headerSize = 256 << 10
+
+// NewImagerFromReader creates a new Imager from a reader, the data is
+// decoded as it is read rather than buffered first
+// i.e :
+// imgr, err := imager.NewImagerFromReader(r.Body)
+// imgr, err := imager.NewImagerFromReader(r.Body, imager.WithAutoOrient())
+func NewImagerFromReader(r io.Reader, opts ...LoadOption) (*Imager, error) {
+	config := newLoadConfig(opts)
+	r, err := config.limits.checkReader(r)
+	if err != nil {
+		return nil, err
+	}
+	if config.sanitize {
+		// The end of the data is checked too
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return NewImagerFromBytes(data, opts...)
+	}
+
+	imgr := &Imager{}
+	if err := imgr.LoadReader(r); err != nil {
+		return nil, err
+	}
+
+	return imgr.applyLoadOptions(config), nil
+}
+
+// LoadReader loads the image read from r. GIF and animated PNG images are
+// read in full first since all their frames are needed, and so are RAW
+// camera files whose preview may be anywhere
+func (i *Imager) LoadReader(r io.Reader) error {
+	start := time.Now()
+	cr := &countingReader{r: r}
+	err := i.loadReader(cr)
+	i.observeDecode(start, cr.n, err)
+
+	return err
+}
+
+// loadReader decodes the data read from r into the image
+func (i *Imager) loadReader(r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(br.Size())
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if bytes.HasPrefix(magic, []byte("GIF")) || isAPNG(magic) || isRAW(magic) {
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return err
+		}
+		return i.loadBytes(data)
+	}
+
+	header := &headBuffer{limit: headerSize}
+	img, imageType, err := image.Decode(io.TeeReader(br, header))
+	if err != nil {
+		return decodeError(header.Bytes(), err)
+	}
+
+	i.setImage(img, imageType, header.Bytes())
+	return nil
+}
+
+// WriteTo writes the image to w encoded as ImageType, it implements io.WriterTo
+// i.e :
+// _, err := imgr.WriteTo(w)
+func (i *Imager) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := i.encode(cw, i.ImageType, EncodeOptions{})
+
+	return cw.n, err
+}
+
+// headBuffer keeps the first limit bytes written to it and drops the rest
+type headBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+
+	return len(p), nil
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
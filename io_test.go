@@ -0,0 +1,60 @@
+package imager
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func TestNewImagerFromReader(t *testing.T) {
+	data := createTestJPEG(t)
+
+	// Hide bytes.Reader so the data is really streamed
+	imgr, err := NewImagerFromReader(io.MultiReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("NewImagerFromReader returned an error: %v", err)
+	}
+
+	if imgr.ImageType != IMJPEG {
+		t.Fatalf("NewImagerFromReader returned incorrect image type: %v", imgr.ImageType)
+	}
+
+	if !bytes.Equal(imgr.EXIF, testEXIF) {
+		t.Fatalf("NewImagerFromReader did not capture the EXIF data: got %v", imgr.EXIF)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMPNG
+
+	buf := new(bytes.Buffer)
+	n, err := imgr.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("WriteTo returned an error: %v", err)
+	}
+
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo reported %d bytes, wrote %d", n, buf.Len())
+	}
+
+	if _, err := png.Decode(buf); err != nil {
+		t.Fatalf("WriteTo did not write a valid png: %v", err)
+	}
+}
+
+func TestEncodeFormat(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMJPEG
+
+	buf := new(bytes.Buffer)
+	if err := imgr.Encode(buf, IMPNG); err != nil {
+		t.Fatalf("Encode returned an error: %v", err)
+	}
+
+	format, err := DetectFormat(buf.Bytes())
+	if err != nil || format != IMPNG {
+		t.Fatalf("Encode wrote %q (%v), want png", format, err)
+	}
+}
@@ -0,0 +1,541 @@
+package imager
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// The JPEG encoder below is used instead of image/jpeg for the progressive
+// and the 4:2:2 and 4:4:4 chroma subsampling options. It uses the quantization
+// and Huffman tables of the JPEG specification, like image/jpeg, and splits
+// progressive images into spectral selection scans only: the DC scan first,
+// then the low and the high frequencies of each component.
+
+// JPEGSubsampling is the chroma subsampling of JPEG images
+type JPEGSubsampling int
+
+const (
+	// Subsampling420 halves the chroma resolution in both directions, the
+	// default and the smallest output
+	Subsampling420 JPEGSubsampling = iota
+
+	// Subsampling422 halves the chroma resolution horizontally
+	Subsampling422
+
+	// Subsampling444 keeps the full chroma resolution, sharper colored edges
+	// and text at the cost of larger files
+	Subsampling444
+)
+
+// jpegZigzag maps the zig-zag order to the natural order of a block
+var jpegZigzag = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// jpegQuant are the quantization tables of the specification for the
+// luminance and the chrominance, in zig-zag order
+var jpegQuant = [2][64]int{
+	{
+		16, 11, 12, 14, 12, 10, 16, 14,
+		13, 14, 18, 17, 16, 19, 24, 40,
+		26, 24, 22, 22, 24, 49, 35, 37,
+		29, 40, 58, 51, 61, 60, 57, 51,
+		56, 55, 64, 72, 92, 78, 64, 68,
+		87, 69, 55, 56, 80, 109, 81, 87,
+		95, 98, 103, 104, 103, 62, 77, 113,
+		121, 112, 100, 120, 92, 101, 103, 99,
+	},
+	{
+		17, 18, 18, 24, 21, 24, 47, 26,
+		26, 47, 99, 66, 56, 66, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+	},
+}
+
+// jpegHuffmanSpec is a Huffman table as stored in a DHT segment: the number
+// of codes of each length from 1 to 16 bits, then the values
+type jpegHuffmanSpec struct {
+	counts [16]byte
+	values []byte
+}
+
+// jpegHuffmanSpecs are the Huffman tables of the specification, DC then AC
+// for the luminance, then the same for the chrominance
+var jpegHuffmanSpecs = [4]jpegHuffmanSpec{
+	{
+		[16]byte{0, 1, 5, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 3, 3, 2, 4, 3, 5, 5, 4, 4, 0, 0, 1, 125},
+		[]byte{
+			0x01, 0x02, 0x03, 0x00, 0x04, 0x11, 0x05, 0x12,
+			0x21, 0x31, 0x41, 0x06, 0x13, 0x51, 0x61, 0x07,
+			0x22, 0x71, 0x14, 0x32, 0x81, 0x91, 0xa1, 0x08,
+			0x23, 0x42, 0xb1, 0xc1, 0x15, 0x52, 0xd1, 0xf0,
+			0x24, 0x33, 0x62, 0x72, 0x82, 0x09, 0x0a, 0x16,
+			0x17, 0x18, 0x19, 0x1a, 0x25, 0x26, 0x27, 0x28,
+			0x29, 0x2a, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+			0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+			0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59,
+			0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
+			0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79,
+			0x7a, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89,
+			0x8a, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98,
+			0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7,
+			0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6,
+			0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3, 0xc4, 0xc5,
+			0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2, 0xd3, 0xd4,
+			0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda, 0xe1, 0xe2,
+			0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9, 0xea,
+			0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+	{
+		[16]byte{0, 3, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 2, 4, 4, 3, 4, 7, 5, 4, 4, 0, 1, 2, 119},
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x11, 0x04, 0x05, 0x21,
+			0x31, 0x06, 0x12, 0x41, 0x51, 0x07, 0x61, 0x71,
+			0x13, 0x22, 0x32, 0x81, 0x08, 0x14, 0x42, 0x91,
+			0xa1, 0xb1, 0xc1, 0x09, 0x23, 0x33, 0x52, 0xf0,
+			0x15, 0x62, 0x72, 0xd1, 0x0a, 0x16, 0x24, 0x34,
+			0xe1, 0x25, 0xf1, 0x17, 0x18, 0x19, 0x1a, 0x26,
+			0x27, 0x28, 0x29, 0x2a, 0x35, 0x36, 0x37, 0x38,
+			0x39, 0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48,
+			0x49, 0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58,
+			0x59, 0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68,
+			0x69, 0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78,
+			0x79, 0x7a, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87,
+			0x88, 0x89, 0x8a, 0x92, 0x93, 0x94, 0x95, 0x96,
+			0x97, 0x98, 0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5,
+			0xa6, 0xa7, 0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4,
+			0xb5, 0xb6, 0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3,
+			0xc4, 0xc5, 0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2,
+			0xd3, 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda,
+			0xe2, 0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9,
+			0xea, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+}
+
+// jpegSpectralBands are the frequencies of the AC scans of progressive
+// images, in zig-zag order
+var jpegSpectralBands = [][2]int{{1, 5}, {6, 63}}
+
+// jpegComponent is a color component being encoded, its blocks cover the
+// whole MCU grid and hold quantized coefficients in zig-zag order
+type jpegComponent struct {
+	id     byte
+	h, v   int
+	table  int
+	blocks [][64]int32
+
+	// blocksW is the width of the block grid, blocksX and blocksY are the
+	// blocks covering the image, used by the non interleaved scans
+	blocksW, blocksX, blocksY int
+}
+
+// encodeJPEG writes the opaque img to w as a JPEG image
+func encodeJPEG(w io.Writer, img image.Image, quality int, subsampling JPEGSubsampling, progressive bool) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || width > 0xFFFF || height > 0xFFFF {
+		return fmt.Errorf("%w: jpeg dimensions %dx%d", ErrInvalidArgument, width, height)
+	}
+
+	quant := jpegScaledQuant(quality)
+
+	hMax, vMax := 2, 2
+	switch subsampling {
+	case Subsampling422:
+		vMax = 1
+	case Subsampling444:
+		hMax, vMax = 1, 1
+	}
+	components := []*jpegComponent{
+		{id: 1, h: hMax, v: vMax, table: 0},
+		{id: 2, h: 1, v: 1, table: 1},
+		{id: 3, h: 1, v: 1, table: 1},
+	}
+
+	mcusX := (width + 8*hMax - 1) / (8 * hMax)
+	mcusY := (height + 8*vMax - 1) / (8 * vMax)
+	planes := jpegPlanes(imaging.Clone(img))
+	for idx, c := range components {
+		c.blocksW = mcusX * c.h
+		c.blocksX = ((width*c.h+hMax-1)/hMax + 7) / 8
+		c.blocksY = ((height*c.v+vMax-1)/vMax + 7) / 8
+		c.blocks = jpegBlocks(planes[idx], width, height, c.blocksW, mcusY*c.v, hMax/c.h, vMax/c.v, &quant[c.table])
+	}
+
+	e := &jpegEncoder{}
+	e.marker(0xD8, nil)
+
+	// Quantization tables
+	dqt := make([]byte, 0, 130)
+	for t := range quant {
+		dqt = append(dqt, byte(t))
+		for _, q := range quant[t] {
+			dqt = append(dqt, byte(q))
+		}
+	}
+	e.marker(0xDB, dqt)
+
+	// Frame header
+	sof := binary.BigEndian.AppendUint16([]byte{8}, uint16(height))
+	sof = binary.BigEndian.AppendUint16(sof, uint16(width))
+	sof = append(sof, byte(len(components)))
+	for _, c := range components {
+		sof = append(sof, c.id, byte(c.h<<4|c.v), byte(c.table))
+	}
+	if progressive {
+		e.marker(0xC2, sof)
+	} else {
+		e.marker(0xC0, sof)
+	}
+
+	// Huffman tables
+	var dht []byte
+	for idx, spec := range jpegHuffmanSpecs {
+		dht = append(dht, byte(idx%2<<4|idx/2))
+		dht = append(dht, spec.counts[:]...)
+		dht = append(dht, spec.values...)
+	}
+	e.marker(0xC4, dht)
+
+	huffman := [4]jpegHuffmanCodes{}
+	for idx, spec := range jpegHuffmanSpecs {
+		huffman[idx] = newJPEGHuffmanCodes(spec)
+	}
+
+	if !progressive {
+		e.scan(components, 0, 63, func(b *jpegBitWriter) {
+			e.interleaved(b, components, mcusX, mcusY, huffman, 0, 63)
+		})
+	} else {
+		e.scan(components, 0, 0, func(b *jpegBitWriter) {
+			e.interleaved(b, components, mcusX, mcusY, huffman, 0, 0)
+		})
+		for _, band := range jpegSpectralBands {
+			for _, c := range components {
+				e.scan([]*jpegComponent{c}, band[0], band[1], func(b *jpegBitWriter) {
+					ac := huffman[c.table*2+1]
+					for by := 0; by < c.blocksY; by++ {
+						for bx := 0; bx < c.blocksX; bx++ {
+							b.writeAC(&c.blocks[by*c.blocksW+bx], band[0], band[1], ac)
+						}
+					}
+				})
+			}
+		}
+	}
+
+	e.marker(0xD9, nil)
+	if e.err != nil {
+		return e.err
+	}
+
+	_, err := w.Write(e.buf)
+	return err
+}
+
+// jpegScaledQuant returns the quantization tables scaled for quality, with
+// the formula of the IJG library
+func jpegScaledQuant(quality int) [2][64]int {
+	quality = max(1, min(100, quality))
+	scale := 200 - quality*2
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+
+	var quant [2][64]int
+	for t := range quant {
+		for k, q := range jpegQuant[t] {
+			quant[t][k] = max(1, min(255, (q*scale+50)/100))
+		}
+	}
+
+	return quant
+}
+
+// jpegPlanes converts img to its Y, Cb and Cr planes
+func jpegPlanes(img *image.NRGBA) [3][]uint8 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	var planes [3][]uint8
+	for idx := range planes {
+		planes[idx] = make([]uint8, w*h)
+	}
+
+	for k := 0; k < w*h; k++ {
+		p := img.Pix[k*4 : k*4+3]
+		planes[0][k], planes[1][k], planes[2][k] = color.RGBToYCbCr(p[0], p[1], p[2])
+	}
+
+	return planes
+}
+
+// jpegBlocks returns the quantized blocks of a plane over a grid of blocksW
+// x blocksH blocks, each sample averaging sx x sy pixels. The pixels past
+// the image edges repeat the last row and column
+func jpegBlocks(plane []uint8, width, height, blocksW, blocksH, sx, sy int, quant *[64]int) [][64]int32 {
+	blocks := make([][64]int32, blocksW*blocksH)
+
+	var samples [64]float64
+	for by := 0; by < blocksH; by++ {
+		for bx := 0; bx < blocksW; bx++ {
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					sum := 0
+					for dy := 0; dy < sy; dy++ {
+						py := min(height-1, ((by*8+y)*sy + dy))
+						for dx := 0; dx < sx; dx++ {
+							px := min(width-1, ((bx*8+x)*sx + dx))
+							sum += int(plane[py*width+px])
+						}
+					}
+					samples[y*8+x] = float64(sum)/float64(sx*sy) - 128
+				}
+			}
+
+			coefficients := fdct(&samples)
+			block := &blocks[by*blocksW+bx]
+			for k, natural := range jpegZigzag {
+				// Clamped to the largest magnitude category of baseline images
+				block[k] = int32(max(-1023, min(1023, math.Round(coefficients[natural]/float64(quant[k])))))
+			}
+		}
+	}
+
+	return blocks
+}
+
+// jpegCosines holds cos((2x + 1) u pi / 16) at [u][x]
+var jpegCosines = func() (cosines [8][8]float64) {
+	for u := range cosines {
+		for x := range cosines[u] {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / 16)
+		}
+	}
+	return cosines
+}()
+
+// fdct returns the forward discrete cosine transform of an 8x8 block, in
+// natural order
+func fdct(samples *[64]float64) [64]float64 {
+	var rows, out [64]float64
+	for y := 0; y < 8; y++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for x := 0; x < 8; x++ {
+				sum += samples[y*8+x] * jpegCosines[u][x]
+			}
+			rows[y*8+u] = sum
+		}
+	}
+
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for y := 0; y < 8; y++ {
+				sum += rows[y*8+u] * jpegCosines[v][y]
+			}
+
+			scale := 0.25
+			if u == 0 {
+				scale *= math.Sqrt2 / 2
+			}
+			if v == 0 {
+				scale *= math.Sqrt2 / 2
+			}
+			out[v*8+u] = sum * scale
+		}
+	}
+
+	return out
+}
+
+// jpegEncoder accumulates the segments of a JPEG image
+type jpegEncoder struct {
+	buf []byte
+	err error
+}
+
+// marker appends a marker followed by its segment, if any
+func (e *jpegEncoder) marker(marker byte, segment []byte) {
+	e.buf = append(e.buf, 0xFF, marker)
+	if segment == nil {
+		return
+	}
+	if len(segment)+2 > 0xFFFF {
+		e.err = fmt.Errorf("%w: jpeg segment too large", ErrInvalidArgument)
+		return
+	}
+
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(len(segment)+2))
+	e.buf = append(e.buf, segment...)
+}
+
+// scan appends a scan of components over the ss to se frequencies, its
+// entropy coded data written by encode
+func (e *jpegEncoder) scan(components []*jpegComponent, ss, se int, encode func(b *jpegBitWriter)) {
+	sos := []byte{byte(len(components))}
+	for _, c := range components {
+		sos = append(sos, c.id, byte(c.table<<4|c.table))
+	}
+	sos = append(sos, byte(ss), byte(se), 0)
+	e.marker(0xDA, sos)
+
+	b := &jpegBitWriter{buf: e.buf}
+	encode(b)
+	e.buf = b.flush()
+}
+
+// interleaved writes the ss to se frequencies of the components MCU by MCU
+func (e *jpegEncoder) interleaved(b *jpegBitWriter, components []*jpegComponent, mcusX, mcusY int, huffman [4]jpegHuffmanCodes, ss, se int) {
+	predictors := make([]int32, len(components))
+	for my := 0; my < mcusY; my++ {
+		for mx := 0; mx < mcusX; mx++ {
+			for idx, c := range components {
+				for v := 0; v < c.v; v++ {
+					for h := 0; h < c.h; h++ {
+						block := &c.blocks[(my*c.v+v)*c.blocksW+mx*c.h+h]
+						b.writeDC(block[0]-predictors[idx], huffman[c.table*2])
+						predictors[idx] = block[0]
+						if se > 0 {
+							b.writeAC(block, max(1, ss), se, huffman[c.table*2+1])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// jpegHuffmanCodes maps the values of a Huffman table to their code and
+// code length
+type jpegHuffmanCodes struct {
+	codes   [256]uint16
+	lengths [256]uint8
+}
+
+// newJPEGHuffmanCodes builds the canonical codes of spec
+func newJPEGHuffmanCodes(spec jpegHuffmanSpec) jpegHuffmanCodes {
+	var h jpegHuffmanCodes
+	code, k := uint16(0), 0
+	for length, count := range spec.counts {
+		for n := 0; n < int(count); n++ {
+			h.codes[spec.values[k]] = code
+			h.lengths[spec.values[k]] = uint8(length + 1)
+			code++
+			k++
+		}
+		code <<= 1
+	}
+
+	return h
+}
+
+// jpegBitWriter writes entropy coded data, most significant bit first,
+// stuffing a zero byte after each 0xFF byte
+type jpegBitWriter struct {
+	buf   []byte
+	bits  uint32
+	nBits uint
+}
+
+// write writes the n low bits of v
+func (b *jpegBitWriter) write(v uint32, n uint) {
+	b.bits = b.bits<<n | v&(1<<n-1)
+	b.nBits += n
+	for b.nBits >= 8 {
+		b.nBits -= 8
+		c := byte(b.bits >> b.nBits)
+		b.buf = append(b.buf, c)
+		if c == 0xFF {
+			b.buf = append(b.buf, 0)
+		}
+	}
+}
+
+// symbol writes the code of value
+func (b *jpegBitWriter) symbol(value byte, h jpegHuffmanCodes) {
+	b.write(uint32(h.codes[value]), uint(h.lengths[value]))
+}
+
+// magnitude writes the size category of v with the DC or AC table then its
+// extra bits, the category being combined with run for AC values
+func (b *jpegBitWriter) magnitude(v int32, run int, h jpegHuffmanCodes) {
+	a := v
+	if a < 0 {
+		a = -a
+		v--
+	}
+	size := uint(0)
+	for a > 0 {
+		size++
+		a >>= 1
+	}
+
+	b.symbol(byte(run<<4)|byte(size), h)
+	b.write(uint32(v), size)
+}
+
+// writeDC writes a DC difference
+func (b *jpegBitWriter) writeDC(diff int32, h jpegHuffmanCodes) {
+	b.magnitude(diff, 0, h)
+}
+
+// writeAC writes the ss to se coefficients of block, the trailing zeros
+// ending with an end of block
+func (b *jpegBitWriter) writeAC(block *[64]int32, ss, se int, h jpegHuffmanCodes) {
+	run := 0
+	for k := ss; k <= se; k++ {
+		if block[k] == 0 {
+			run++
+			continue
+		}
+		for run > 15 {
+			b.symbol(0xF0, h)
+			run -= 16
+		}
+		b.magnitude(block[k], run, h)
+		run = 0
+	}
+
+	if run > 0 {
+		b.symbol(0x00, h)
+	}
+}
+
+// flush pads the last byte with one bits and returns the data
+func (b *jpegBitWriter) flush() []byte {
+	if b.nBits > 0 {
+		b.write(1<<(8-b.nBits)-1, 8-b.nBits)
+	}
+
+	return b.buf
+}
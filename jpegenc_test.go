@@ -0,0 +1,64 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image/jpeg"
+	"testing"
+)
+
+func TestEncodeJPEGOptions(t *testing.T) {
+	src := createPatternImage(101, 67)
+
+	tests := []struct {
+		name        string
+		opts        EncodeOptions
+		sof         byte
+		lumaSamples byte
+	}{
+		{"baseline 4:4:4", EncodeOptions{JPEGSubsampling: Subsampling444}, 0xC0, 0x11},
+		{"baseline 4:2:2", EncodeOptions{JPEGSubsampling: Subsampling422}, 0xC0, 0x21},
+		{"progressive 4:2:0", EncodeOptions{JPEGProgressive: true}, 0xC2, 0x22},
+		{"progressive 4:4:4", EncodeOptions{JPEGProgressive: true, JPEGSubsampling: Subsampling444}, 0xC2, 0x11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imgr, _ := NewImager(src)
+			imgr.JPEGQuality = 90
+
+			buf := new(bytes.Buffer)
+			if err := imgr.Encode(buf, IMJPEG, tt.opts); err != nil {
+				t.Fatalf("Encode returned an error: %v", err)
+			}
+
+			// The frame header holds the sampling factors of the luma first
+			data := buf.Bytes()
+			sof := bytes.Index(data, []byte{0xFF, tt.sof})
+			if sof < 0 {
+				t.Fatalf("Encode did not write a %X frame header", tt.sof)
+			}
+			if got := data[sof+11]; got != tt.lumaSamples {
+				t.Fatalf("Encode wrote luma sampling factors %X, want %X", got, tt.lumaSamples)
+			}
+
+			decoded, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to decode the JPEG: %v", err)
+			}
+			other, _ := NewImager(decoded)
+			psnr, err := imgr.CompareTo(other)
+			if err != nil || psnr < 25 {
+				t.Fatalf("decoded JPEG has a PSNR of %v, %v", psnr, err)
+			}
+		})
+	}
+}
+
+func TestEncodeJPEGInvalidSubsampling(t *testing.T) {
+	imgr, _ := NewImager(createPatternImage(16, 16))
+	err := imgr.Encode(new(bytes.Buffer), IMJPEG, EncodeOptions{JPEGSubsampling: 7})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
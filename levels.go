@@ -0,0 +1,164 @@
+package imager
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/disintegration/imaging"
+)
+
+// CurvePoint maps the input value In of a channel to Out, both from 0 to 255
+type CurvePoint struct {
+	In, Out float64
+}
+
+// ChannelCurves holds the curves used by Curves. A curve is a list of at
+// least two points with distinct inputs, the values between them following
+// a smooth monotone interpolation and the values outside being flat. An
+// empty curve leaves its channel unchanged
+type ChannelCurves struct {
+	// RGB applies to the three channels, after their own curve
+	RGB []CurvePoint
+
+	Red, Green, Blue []CurvePoint
+}
+
+// Levels remaps the channels of the image from the input range
+// [blackPoint, whitePoint], from 0 to 255, to the full range, the values
+// outside being clipped, then applies the midtone gamma, from 0.1 to 10.
+// Like AdjustGamma, a gamma below 1 darkens the image and above 1 lightens
+// it
+// i.e :
+// imgr.Levels(12, 240, 1)
+// imgr.Levels(0, 220, 1.3)
+func (i *Imager) Levels(blackPoint, whitePoint, gamma float64) *Imager {
+	if !i.checkRange("black point", blackPoint, 0, 254) || !i.checkRange("white point", whitePoint, blackPoint+1, 255) ||
+		!i.checkRange("gamma", gamma, 0.1, 10) {
+		return i
+	}
+
+	var table [256]uint8
+	for v := range table {
+		t := math.Min(1, math.Max(0, (float64(v)-blackPoint)/(whitePoint-blackPoint)))
+		table[v] = clampUint8(255 * math.Pow(t, 1/gamma))
+	}
+
+	return i.applyTables("Levels", table, table, table)
+}
+
+// Curves remaps each channel of the image along its curve, see ChannelCurves
+// i.e :
+// imgr.Curves(imager.ChannelCurves{RGB: []imager.CurvePoint{{0, 0}, {64, 50}, {192, 210}, {255, 255}}})
+// imgr.Curves(imager.ChannelCurves{Blue: []imager.CurvePoint{{0, 20}, {255, 235}}})
+func (i *Imager) Curves(curves ChannelCurves) *Imager {
+	var tables [4][256]uint8
+	for idx, points := range [][]CurvePoint{curves.Red, curves.Green, curves.Blue, curves.RGB} {
+		table, err := curveTable(points)
+		if err != nil {
+			i.setErr(err)
+			return i
+		}
+		tables[idx] = table
+	}
+
+	rgb := tables[3]
+	for ch := 0; ch < 3; ch++ {
+		for v := range tables[ch] {
+			tables[ch][v] = rgb[tables[ch][v]]
+		}
+	}
+
+	return i.applyTables("Curves", tables[0], tables[1], tables[2])
+}
+
+// applyTables maps the red, green and blue channels of the image through
+// their lookup tables, keeping the alpha. name is the operation, see apply
+func (i *Imager) applyTables(name string, red, green, blue [256]uint8) *Imager {
+	return i.apply(name, func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			return color.NRGBA{red[c.R], green[c.G], blue[c.B], c.A}
+		})
+	})
+}
+
+// curveTable returns the lookup table of a curve, interpolated with the
+// Fritsch-Carlson monotone cubic so the curve never overshoots its points
+func curveTable(points []CurvePoint) ([256]uint8, error) {
+	var table [256]uint8
+	if len(points) == 0 {
+		for v := range table {
+			table[v] = uint8(v)
+		}
+		return table, nil
+	}
+	if len(points) < 2 {
+		return table, fmt.Errorf("%w: curve of a single point", ErrInvalidArgument)
+	}
+
+	sorted := slices.Clone(points)
+	slices.SortFunc(sorted, func(a, b CurvePoint) int {
+		return cmp.Compare(a.In, b.In)
+	})
+	for idx, p := range sorted {
+		if p.In < 0 || p.In > 255 || p.Out < 0 || p.Out > 255 {
+			return table, fmt.Errorf("%w: curve point %v out of [0, 255]", ErrInvalidArgument, p)
+		}
+		if idx > 0 && p.In == sorted[idx-1].In {
+			return table, fmt.Errorf("%w: curve points with the same input %v", ErrInvalidArgument, p.In)
+		}
+	}
+
+	// Secant slopes, then tangents limited to keep each segment monotone
+	n := len(sorted)
+	secants := make([]float64, n-1)
+	for k := range secants {
+		secants[k] = (sorted[k+1].Out - sorted[k].Out) / (sorted[k+1].In - sorted[k].In)
+	}
+	tangents := make([]float64, n)
+	tangents[0], tangents[n-1] = secants[0], secants[n-2]
+	for k := 1; k < n-1; k++ {
+		if secants[k-1]*secants[k] > 0 {
+			tangents[k] = (secants[k-1] + secants[k]) / 2
+		}
+	}
+	for k, secant := range secants {
+		if secant == 0 {
+			tangents[k], tangents[k+1] = 0, 0
+			continue
+		}
+		a, b := tangents[k]/secant, tangents[k+1]/secant
+		if s := a*a + b*b; s > 9 {
+			scale := 3 / math.Sqrt(s)
+			tangents[k], tangents[k+1] = scale*a*secant, scale*b*secant
+		}
+	}
+
+	segment := 0
+	for v := range table {
+		x := float64(v)
+		switch {
+		case x <= sorted[0].In:
+			table[v] = clampUint8(sorted[0].Out)
+			continue
+		case x >= sorted[n-1].In:
+			table[v] = clampUint8(sorted[n-1].Out)
+			continue
+		}
+		for x > sorted[segment+1].In {
+			segment++
+		}
+
+		p0, p1 := sorted[segment], sorted[segment+1]
+		h := p1.In - p0.In
+		t := (x - p0.In) / h
+		t2, t3 := t*t, t*t*t
+		table[v] = clampUint8((2*t3-3*t2+1)*p0.Out + (t3-2*t2+t)*h*tangents[segment] +
+			(-2*t3+3*t2)*p1.Out + (t3-t2)*h*tangents[segment+1])
+	}
+
+	return table, nil
+}
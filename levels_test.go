@@ -0,0 +1,70 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// createRampImage returns a 256x1 image whose pixels go from black to white
+func createRampImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{uint8(x), uint8(x), uint8(x), 255})
+	}
+
+	return img
+}
+
+func TestLevels(t *testing.T) {
+	imgr, _ := NewImager(createRampImage())
+	if err := imgr.Levels(50, 200, 1).Err(); err != nil {
+		t.Fatalf("Levels returned an error: %v", err)
+	}
+
+	img := imgr.Image.(*image.NRGBA)
+	for x, expected := range map[int]uint8{0: 0, 50: 0, 125: 128, 200: 255, 255: 255} {
+		if v := img.Pix[x*4]; v != expected {
+			t.Fatalf("value %d mapped to %d, want %d", x, v, expected)
+		}
+	}
+
+	lighter, _ := NewImager(createRampImage())
+	lighter.Levels(0, 255, 2)
+	if v := lighter.Image.(*image.NRGBA).Pix[128*4]; v <= 128 {
+		t.Fatalf("a gamma of 2 did not lighten the midtones: %d", v)
+	}
+
+	if err := imgr.Clone().Levels(200, 100, 1).Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestCurves(t *testing.T) {
+	imgr, _ := NewImager(createRampImage())
+	imgr.Curves(ChannelCurves{
+		RGB:  []CurvePoint{{0, 0}, {64, 32}, {192, 224}, {255, 255}},
+		Blue: []CurvePoint{{0, 255}, {255, 0}},
+	})
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("Curves returned an error: %v", err)
+	}
+
+	pix := imgr.Image.(*image.NRGBA).Pix
+	if pix[64*4] != 32 || pix[192*4] != 224 || pix[0] != 0 || pix[255*4] != 255 {
+		t.Fatalf("the curve misses its points: %d %d %d %d", pix[0], pix[64*4], pix[192*4], pix[255*4])
+	}
+	for x := 1; x < 256; x++ {
+		if pix[x*4] < pix[(x-1)*4] {
+			t.Fatalf("the curve is not monotone at %d", x)
+		}
+	}
+	if pix[2] != 255 || pix[255*4+2] != 0 || pix[64*4+1] != 32 {
+		t.Fatalf("unexpected channels %v %v", pix[:4], pix[255*4:])
+	}
+
+	if err := imgr.Clone().Curves(ChannelCurves{Red: []CurvePoint{{10, 10}, {10, 20}}}).Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// MaskOptions holds the options used by RoundCorners and CircleCrop
+type MaskOptions struct {
+	// Background, when set, fills the masked out areas instead of leaving them
+	// transparent, for formats without alpha such as JPEG
+	Background color.Color
+}
+
+// RoundCorners rounds the corners of the image with an anti-aliased alpha
+// mask of the given radius, limited to half the shortest side
+// i.e :
+// imgr.RoundCorners(16)
+// imgr.RoundCorners(16, imager.MaskOptions{Background: color.White})
+func (i *Imager) RoundCorners(radius int, opts ...MaskOptions) *Imager {
+	if radius < 0 {
+		i.setErr(fmt.Errorf("%w: negative radius %d", ErrInvalidArgument, radius))
+		return i
+	}
+
+	return i.apply("RoundCorners", func(img image.Image) image.Image {
+		size := img.Bounds().Size()
+		w, h := float64(size.X), float64(size.Y)
+		r := math.Min(float64(radius), math.Min(w, h)/2)
+
+		return applyMask(img, func(x, y float64) float64 {
+			// Distance to the center of the closest corner arc
+			cx := math.Max(r-x, x-(w-r))
+			cy := math.Max(r-y, y-(h-r))
+			if cx <= 0 || cy <= 0 {
+				return 1
+			}
+			return r - math.Hypot(cx, cy) + 0.5
+		}, mergeMaskOptions(opts))
+	})
+}
+
+// CircleCrop crops the largest centered square of the image and masks it
+// into an anti-aliased circle, for avatars
+// i.e :
+// imgr.CircleCrop()
+// imgr.CircleCrop(imager.MaskOptions{Background: color.White})
+func (i *Imager) CircleCrop(opts ...MaskOptions) *Imager {
+	return i.apply("CircleCrop", func(img image.Image) image.Image {
+		size := img.Bounds().Size()
+		side := min(size.X, size.Y)
+		square := imaging.CropCenter(img, side, side)
+
+		r := float64(side) / 2
+		return applyMask(square, func(x, y float64) float64 {
+			return r - math.Hypot(x-r, y-r) + 0.5
+		}, mergeMaskOptions(opts))
+	})
+}
+
+// mergeMaskOptions returns the last of opts, the zero value when empty
+func mergeMaskOptions(opts []MaskOptions) MaskOptions {
+	if len(opts) == 0 {
+		return MaskOptions{}
+	}
+
+	return opts[len(opts)-1]
+}
+
+// applyMask multiplies the alpha of img by coverage, evaluated at the
+// center of each pixel and clamped to [0, 1]
+func applyMask(img image.Image, coverage func(x, y float64) float64, opts MaskOptions) *image.NRGBA {
+	dst := imaging.Clone(img)
+	w, h := dst.Rect.Dx(), dst.Rect.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := math.Max(0, math.Min(1, coverage(float64(x)+0.5, float64(y)+0.5)))
+			if c < 1 {
+				p := y*dst.Stride + x*4 + 3
+				dst.Pix[p] = uint8(float64(dst.Pix[p])*c + 0.5)
+			}
+		}
+	}
+
+	if opts.Background != nil {
+		return flatten(dst, opts.Background)
+	}
+
+	return dst
+}
@@ -0,0 +1,57 @@
+package imager
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestRoundCorners(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.RoundCorners(20)
+
+	if c := pixel(imgr.Image, 0, 0); c.A != 0 {
+		t.Fatalf("RoundCorners did not clear the corner: %v", c)
+	}
+	if c := pixel(imgr.Image, 99, 99); c.A != 0 {
+		t.Fatalf("RoundCorners did not clear the opposite corner: %v", c)
+	}
+	if c := pixel(imgr.Image, 50, 0); c.A != 255 {
+		t.Fatalf("RoundCorners cleared the edge: %v", c)
+	}
+
+	// The edge of the arc is anti-aliased
+	partial := false
+	for x := 0; x < 20; x++ {
+		if a := pixel(imgr.Image, x, 3).A; a > 0 && a < 255 {
+			partial = true
+		}
+	}
+	if !partial {
+		t.Fatalf("RoundCorners did not anti-alias the arc")
+	}
+
+	imgr, _ = NewImager(createTestImage())
+	imgr.RoundCorners(20, MaskOptions{Background: color.White})
+	if c := pixel(imgr.Image, 0, 0); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("RoundCorners did not fill the corner with the background: %v", c)
+	}
+
+	if err := imgr.RoundCorners(-1).Err(); err == nil {
+		t.Fatalf("RoundCorners did not record an error for a negative radius")
+	}
+}
+
+func TestCircleCrop(t *testing.T) {
+	imgr, _ := NewImager(createPatternImage(120, 80))
+	imgr.CircleCrop()
+
+	if bounds := imgr.Image.Bounds(); bounds.Dx() != 80 || bounds.Dy() != 80 {
+		t.Fatalf("CircleCrop returned unexpected bounds: %v", bounds)
+	}
+	if c := pixel(imgr.Image, 10, 10); c.A != 0 {
+		t.Fatalf("CircleCrop did not clear outside the circle: %v", c)
+	}
+	if c := pixel(imgr.Image, 40, 1); c.A != 255 {
+		t.Fatalf("CircleCrop cleared inside the circle: %v", c)
+	}
+}
@@ -0,0 +1,287 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"time"
+)
+
+const (
+	// exifHeader prefixes the EXIF data inside a JPEG APP1 segment
+	exifHeader = "Exif\x00\x00"
+
+	// xmpHeader prefixes the XMP packet inside a JPEG APP1 segment
+	xmpHeader = "http://ns.adobe.com/xap/1.0/\x00"
+
+	// iptcHeader prefixes the Photoshop resources inside a JPEG APP13 segment
+	iptcHeader = "Photoshop 3.0\x00"
+)
+
+// MetadataPolicy controls which metadata of the source image is written back
+// when encoding JPEG, TIFF only holding the EXIF data. The zero value strips
+// everything
+type MetadataPolicy struct {
+	// KeepEXIF writes back the EXIF data
+	KeepEXIF bool
+
+	// EXIFTags restricts the EXIF data to these tags of the first IFD, empty
+	// keeps all of them. The camera settings and the location are only kept
+	// when TagExifIFD and TagGPSIFD are listed. The embedded thumbnail is
+	// always dropped when the tags are restricted
+	EXIFTags []uint16
+
+	// KeepXMP writes back the XMP packet
+	KeepXMP bool
+
+	// KeepIPTC writes back the IPTC data
+	KeepIPTC bool
+}
+
+var (
+	// MetadataStripAll drops all the metadata
+	MetadataStripAll = MetadataPolicy{}
+
+	// MetadataKeepAll writes back all the metadata
+	MetadataKeepAll = MetadataPolicy{KeepEXIF: true, KeepXMP: true, KeepIPTC: true}
+
+	// MetadataKeepEssential keeps the orientation and the copyright, dropping
+	// the location and the camera details
+	MetadataKeepEssential = MetadataPolicy{
+		KeepEXIF: true,
+		EXIFTags: []uint16{TagOrientation, TagCopyright, TagArtist},
+	}
+)
+
+// SetMetadataPolicy sets which metadata of the source image is written back
+// when encoding JPEG and TIFF
+// i.e :
+// imgr.SetMetadataPolicy(imager.MetadataKeepEssential).Bytes()
+// imgr.SetMetadataPolicy(imager.MetadataPolicy{KeepEXIF: true, EXIFTags: []uint16{imager.TagOrientation}})
+func (i *Imager) SetMetadataPolicy(policy MetadataPolicy) *Imager {
+	i.metadata = policy
+	return i
+}
+
+// PreserveMetadata controls whether the metadata of the source image is
+// written back when encoding JPEG. Metadata is stripped by default
+// i.e :
+// imgr.PreserveMetadata(true).Bytes()
+func (i *Imager) PreserveMetadata(preserve bool) *Imager {
+	if preserve {
+		return i.SetMetadataPolicy(MetadataKeepAll)
+	}
+
+	return i.SetMetadataPolicy(MetadataStripAll)
+}
+
+// StripMetadata makes sure no metadata of the source image is written when
+// encoding. This is the default since the image is always re-encoded, the
+// method exists to make the intent explicit
+// i.e :
+// imgr.StripMetadata().Bytes()
+func (i *Imager) StripMetadata() *Imager {
+	return i.PreserveMetadata(false)
+}
+
+// MetadataFields are the EXIF fields written by SetMetadata, the empty fields
+// are left as they are
+type MetadataFields struct {
+	Artist    string
+	Copyright string
+	Software  string
+
+	// Description is the ImageDescription, a title or caption
+	Description string
+
+	// DateTime is the last modification time, written in UTC
+	DateTime time.Time
+
+	// Tags sets other ASCII tags of the first IFD, an empty value removes
+	// the tag
+	Tags map[uint16]string
+}
+
+// SetMetadata writes fields into the EXIF data of the image, creating it
+// when the source had none, so the encoded JPEG and TIFF images carry them.
+// The policy is updated so the fields are written: a policy stripping the
+// EXIF data only keeps these fields, one restricting the tags gets them
+// added, so SetMetadataPolicy is called first
+// i.e :
+// imgr.SetMetadata(imager.MetadataFields{Copyright: "(c) 2024 ACME", Software: "imager"})
+// imgr.SetMetadataPolicy(imager.MetadataKeepEssential).SetMetadata(imager.MetadataFields{Artist: "Jane Doe"})
+func (i *Imager) SetMetadata(fields MetadataFields) *Imager {
+	tags := map[uint16]string{}
+	for tag, value := range fields.Tags {
+		tags[tag] = value
+	}
+	for tag, value := range map[uint16]string{
+		TagArtist:           fields.Artist,
+		TagCopyright:        fields.Copyright,
+		TagSoftware:         fields.Software,
+		TagImageDescription: fields.Description,
+	} {
+		if value != "" {
+			tags[tag] = value
+		}
+	}
+	if !fields.DateTime.IsZero() {
+		tags[TagDateTime] = fields.DateTime.UTC().Format(exifTimeLayout)
+	}
+	if len(tags) == 0 {
+		return i
+	}
+
+	i.EXIF = setEXIFFields(i.EXIF, tags)
+
+	var written []uint16
+	for tag := range tags {
+		written = append(written, tag)
+	}
+	slices.Sort(written)
+	switch {
+	case !i.metadata.KeepEXIF:
+		i.metadata.KeepEXIF = true
+		i.metadata.EXIFTags = written
+	case len(i.metadata.EXIFTags) > 0:
+		// Clipped so the tags of the predefined policies are not modified
+		tagsKept := slices.Clip(i.metadata.EXIFTags)
+		for _, tag := range written {
+			if !slices.Contains(tagsKept, tag) {
+				tagsKept = append(tagsKept, tag)
+			}
+		}
+		i.metadata.EXIFTags = tagsKept
+	}
+
+	return i
+}
+
+// insertJPEGMetadata adds the metadata allowed by policy to JPEG data
+func (i *Imager) insertJPEGMetadata(data []byte, policy MetadataPolicy) []byte {
+	// Segments are inserted after SOI, so in reverse order
+	if policy.KeepIPTC && len(i.IPTC) > 0 {
+		data = insertJPEGSegment(data, 0xED, append([]byte(iptcHeader), i.IPTC...))
+	}
+	if policy.KeepXMP && len(i.XMP) > 0 {
+		data = insertJPEGSegment(data, 0xE1, append([]byte(xmpHeader), i.XMP...))
+	}
+	if policy.KeepEXIF && len(i.EXIF) > 0 {
+		if exif := filterEXIF(i.EXIF, policy.EXIFTags); len(exif) > 0 {
+			data = insertJPEGSegment(data, 0xE1, append([]byte(exifHeader), exif...))
+		}
+	}
+
+	return data
+}
+
+// filterEXIF returns EXIF data holding only tags, nil when no tag is left or
+// the data is invalid. Empty tags returns the data unchanged
+func filterEXIF(data []byte, tags []uint16) []byte {
+	if len(tags) == 0 {
+		return data
+	}
+
+	tiff, err := newTIFFReader(data)
+	if err != nil {
+		return nil
+	}
+	fields, err := tiff.fields(tiff.order.Uint32(tiff.data[4:]), 0)
+	if err != nil {
+		return nil
+	}
+
+	kept := fields[:0]
+	for _, field := range fields {
+		if slices.Contains(tags, field.tag) {
+			kept = append(kept, field)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return encodeTIFF(tiff.order, kept)
+}
+
+// jpegSegment is a marker segment of a JPEG stream
+type jpegSegment struct {
+	marker byte
+	data   []byte
+
+	// end is the position following the segment in the stream
+	end int
+}
+
+// jpegSegments returns the marker segments found before the image data
+func jpegSegments(data []byte) []jpegSegment {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	var segments []jpegSegment
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			break
+		}
+
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// Fill byte
+			pos++
+			continue
+		}
+		if marker == 0xD9 || marker == 0xDA {
+			// End of image or start of scan, no more metadata
+			break
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			pos += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			break
+		}
+
+		segments = append(segments, jpegSegment{marker: marker, data: data[pos+4 : pos+2+length], end: pos + 2 + length})
+		pos += 2 + length
+	}
+
+	return segments
+}
+
+// jpegEXIF returns the EXIF data of a JPEG stream without the APP1 header
+func jpegEXIF(data []byte) []byte {
+	return jpegSegmentData(data, 0xE1, exifHeader)
+}
+
+// jpegSegmentData returns the payload of the first marker segment starting
+// with header, without the header
+func jpegSegmentData(data []byte, marker byte, header string) []byte {
+	for _, segment := range jpegSegments(data) {
+		if segment.marker == marker && bytes.HasPrefix(segment.data, []byte(header)) {
+			return append([]byte(nil), segment.data[len(header):]...)
+		}
+	}
+
+	return nil
+}
+
+// insertJPEGSegment inserts a marker segment right after the SOI marker.
+// Payloads too large for a single segment are dropped
+func insertJPEGSegment(data []byte, marker byte, payload []byte) []byte {
+	if len(data) < 2 || len(payload)+2 > 0xFFFF {
+		return data
+	}
+
+	out := make([]byte, 0, len(data)+len(payload)+4)
+	out = append(out, data[:2]...)
+	out = append(out, 0xFF, marker)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(payload)+2))
+	out = append(out, payload...)
+
+	return append(out, data[2:]...)
+}
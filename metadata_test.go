@@ -0,0 +1,190 @@
+package imager
+
+import (
+	"bytes"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"golang.org/x/image/tiff"
+)
+
+// testEXIF is a minimal little endian TIFF header with an empty IFD
+var testEXIF = []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+// createTestJPEG returns the test image encoded as JPEG with an EXIF segment
+func createTestJPEG(t *testing.T) []byte {
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, createTestImage(), nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	return insertJPEGSegment(buf.Bytes(), 0xE1, append([]byte(exifHeader), testEXIF...))
+}
+
+func TestEXIFCapture(t *testing.T) {
+	imgr, err := NewImagerFromBytes(createTestJPEG(t))
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+
+	if !bytes.Equal(imgr.EXIF, testEXIF) {
+		t.Fatalf("NewImagerFromBytes did not capture the EXIF data: got %v", imgr.EXIF)
+	}
+
+	data, err := imgr.PreserveMetadata(false).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if bytes.Contains(data, []byte(exifHeader)) {
+		t.Fatalf("Bytes kept the EXIF data while preserve is false")
+	}
+
+	data, err = imgr.PreserveMetadata(true).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if !bytes.Contains(data, append([]byte(exifHeader), testEXIF...)) {
+		t.Fatalf("Bytes dropped the EXIF data while preserve is true")
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Bytes with EXIF is not a valid JPEG: %v", err)
+	}
+}
+
+func TestMetadataPolicy(t *testing.T) {
+	exif := createEXIF([][]testTag{
+		{
+			asciiTag(TagMake, "Canon"),
+			{tag: TagOrientation, typ: 3, count: 1, value: []byte{6, 0}},
+			asciiTag(TagCopyright, "ACME"),
+			{tag: TagGPSIFD, typ: 4, count: 1, ifd: 1},
+		},
+		{
+			asciiTag(tagGPSLatitudeRef, "N"),
+			rationalTag(tagGPSLatitude, 100, 0, 0),
+			asciiTag(tagGPSLongitudeRef, "E"),
+			rationalTag(tagGPSLongitude, 200, 0, 0),
+		},
+	})
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, createTestImage(), nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	data := insertJPEGSegment(buf.Bytes(), 0xE1, append([]byte(xmpHeader), "<x:xmpmeta/>"...))
+	data = insertJPEGSegment(data, 0xE1, append([]byte(exifHeader), exif...))
+
+	imgr, err := NewImagerFromBytes(data)
+	if err != nil {
+		t.Fatalf("NewImagerFromBytes returned an error: %v", err)
+	}
+	if string(imgr.XMP) != "<x:xmpmeta/>" {
+		t.Fatalf("NewImagerFromBytes did not capture the XMP packet: got %q", imgr.XMP)
+	}
+
+	out, err := imgr.SetMetadataPolicy(MetadataKeepEssential).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if bytes.Contains(out, []byte(xmpHeader)) {
+		t.Fatalf("Bytes kept the XMP packet")
+	}
+
+	reloaded, _ := NewImagerFromBytes(out)
+	meta, err := reloaded.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata returned an error: %v", err)
+	}
+	if meta.Orientation != 6 || meta.Copyright != "ACME" {
+		t.Fatalf("the essential tags were not kept: %+v", meta)
+	}
+	if meta.Make != "" || meta.GPS != nil {
+		t.Fatalf("the camera and location were not stripped: %+v", meta)
+	}
+
+	// The policy keeps the sub IFDs that are listed
+	policy := MetadataPolicy{KeepEXIF: true, KeepXMP: true, EXIFTags: []uint16{TagGPSIFD}}
+	out, _ = imgr.Bytes(EncodeOptions{Metadata: &policy})
+	reloaded, _ = NewImagerFromBytes(out)
+	if meta, _ := reloaded.Metadata(); meta == nil || meta.GPS == nil || meta.GPS.Longitude != 2 {
+		t.Fatalf("the location was not kept: %+v", meta)
+	}
+	if string(reloaded.XMP) != "<x:xmpmeta/>" {
+		t.Fatalf("the XMP packet was not kept")
+	}
+
+	out, _ = imgr.Bytes(EncodeOptions{StripMetadata: true})
+	if bytes.Contains(out, []byte(exifHeader)) || bytes.Contains(out, []byte(xmpHeader)) {
+		t.Fatalf("StripMetadata kept some metadata")
+	}
+}
+
+func TestSetMetadata(t *testing.T) {
+	exif := createEXIF([][]testTag{
+		{
+			asciiTag(TagMake, "Canon"),
+			asciiTag(TagCopyright, "Old"),
+			{tag: TagGPSIFD, typ: 4, count: 1, ifd: 1},
+		},
+		{
+			asciiTag(tagGPSLatitudeRef, "N"),
+			rationalTag(tagGPSLatitude, 100, 0, 0),
+			asciiTag(tagGPSLongitudeRef, "E"),
+			rationalTag(tagGPSLongitude, 200, 0, 0),
+		},
+	})
+	fields := MetadataFields{
+		Artist:    "Jane Doe",
+		Copyright: "(c) ACME",
+		Software:  "imager",
+		DateTime:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	for _, format := range []string{IMJPEG, IMTIFF} {
+		imgr, _ := NewImager(createTestImage())
+		imgr.EXIF = exif
+		imgr.ImageType = format
+
+		// The default policy strips the source fields, only the set ones are written
+		data, err := imgr.SetMetadata(fields).Bytes()
+		if err != nil {
+			t.Fatalf("Bytes returned an error for %s: %v", format, err)
+		}
+
+		var meta *Metadata
+		if format == IMTIFF {
+			if _, err := tiff.Decode(bytes.NewReader(data)); err != nil {
+				t.Fatalf("Bytes with EXIF is not a valid TIFF: %v", err)
+			}
+			meta, err = (&Imager{EXIF: data}).Metadata()
+		} else {
+			reloaded, _ := NewImagerFromBytes(data)
+			meta, err = reloaded.Metadata()
+		}
+		if err != nil {
+			t.Fatalf("Metadata returned an error for %s: %v", format, err)
+		}
+		if meta.Artist != "Jane Doe" || meta.Copyright != "(c) ACME" || meta.Software != "imager" || !meta.DateTime.Equal(fields.DateTime) {
+			t.Fatalf("the fields were not written to %s: %+v", format, meta)
+		}
+		if meta.Make != "" || meta.GPS != nil {
+			t.Fatalf("the source fields were written to %s: %+v", format, meta)
+		}
+	}
+
+	// Kept policies write the source fields along
+	imgr, _ := NewImager(createTestImage())
+	imgr.EXIF = exif
+	imgr.ImageType = IMJPEG
+	data, _ := imgr.PreserveMetadata(true).SetMetadata(MetadataFields{Tags: map[uint16]string{TagMake: ""}}).Bytes()
+	reloaded, _ := NewImagerFromBytes(data)
+	if meta, _ := reloaded.Metadata(); meta == nil || meta.Make != "" || meta.Copyright != "Old" || meta.GPS == nil {
+		t.Fatalf("unexpected fields with the source metadata kept: %+v", meta)
+	}
+	imgr.SetMetadataPolicy(MetadataKeepEssential).SetMetadata(fields)
+	if len(MetadataKeepEssential.EXIFTags) != 3 || len(imgr.metadata.EXIFTags) != 5 {
+		t.Fatalf("SetMetadata modified a predefined policy")
+	}
+}
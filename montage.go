@@ -0,0 +1,109 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/disintegration/imaging"
+)
+
+// Montage lays images out in a grid of cols columns. Each image is fitted
+// into a cellW x cellH cell keeping its aspect ratio and centered on bg, the
+// cells left in the last row are filled with bg
+// i.e :
+// imgr, err := imager.Montage(thumbs, 4, 160, 120, color.White)
+func Montage(images []image.Image, cols int, cellW, cellH int, bg color.Color) (*Imager, error) {
+	return Collage(images, CollageOptions{Cols: cols, CellWidth: cellW, CellHeight: cellH, Background: bg})
+}
+
+// CollageOptions holds the layout used by Collage
+type CollageOptions struct {
+	// Cols is the number of columns of the grid
+	Cols int
+
+	// Rows is the number of rows of the grid, zero uses as many rows as the
+	// images need
+	Rows int
+
+	// CellWidth and CellHeight are the size of each cell
+	CellWidth  int
+	CellHeight int
+
+	// Gap is the space between the cells
+	Gap int
+
+	// Margin is the space around the grid
+	Margin int
+
+	// Background fills the gaps, the margin and the cells not covered by an
+	// image. Nil is transparent
+	Background color.Color
+
+	// Mode is how the images are fitted into their cell. MD_FIT centers the
+	// whole image in the cell, MD_CROP and MD_SMART scale it to cover the cell
+	// then crop the center or the most detailed area, MD_STRETCH and
+	// MD_SCALE resize it to the cell size
+	Mode ResizeMode
+
+	// Modes overrides Mode for the first len(Modes) images
+	Modes []ResizeMode
+}
+
+// Collage composes images into a single image laid out on a grid, in rows
+// from the top left cell
+// i.e :
+// imgr, err := imager.Collage(photos, imager.CollageOptions{Cols: 3, CellWidth: 300, CellHeight: 300, Gap: 8, Background: color.White, Mode: imager.MD_CROP})
+func Collage(images []image.Image, opts CollageOptions) (*Imager, error) {
+	rows := opts.Rows
+	if rows == 0 && opts.Cols > 0 {
+		rows = (len(images) + opts.Cols - 1) / opts.Cols
+	}
+	if len(images) == 0 || opts.Cols <= 0 || opts.CellWidth <= 0 || opts.CellHeight <= 0 || len(images) > rows*opts.Cols || opts.Gap < 0 || opts.Margin < 0 {
+		return nil, fmt.Errorf("%w: collage of %d images in %dx%d cells of %dx%d", ErrInvalidArgument, len(images), opts.Cols, rows, opts.CellWidth, opts.CellHeight)
+	}
+
+	width := opts.Cols*opts.CellWidth + (opts.Cols-1)*opts.Gap + 2*opts.Margin
+	height := rows*opts.CellHeight + (rows-1)*opts.Gap + 2*opts.Margin
+	sheet := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if opts.Background != nil {
+		draw.Draw(sheet, sheet.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+	}
+
+	for idx, img := range images {
+		mode := opts.Mode
+		if idx < len(opts.Modes) {
+			mode = opts.Modes[idx]
+		}
+
+		cell := image.Rect(0, 0, opts.CellWidth, opts.CellHeight).Add(image.Pt(
+			opts.Margin+(idx%opts.Cols)*(opts.CellWidth+opts.Gap),
+			opts.Margin+(idx/opts.Cols)*(opts.CellHeight+opts.Gap),
+		))
+		thumb := fitCell(img, opts.CellWidth, opts.CellHeight, mode)
+		origin := AnchorCenter.point(cell, thumb.Bounds().Size(), 0)
+		draw.Draw(sheet, thumb.Bounds().Add(origin), thumb, image.Point{}, draw.Over)
+	}
+
+	return NewImager(sheet)
+}
+
+// fitCell returns img fitted into a width x height cell with mode
+func fitCell(img image.Image, width, height int, mode ResizeMode) *image.NRGBA {
+	switch mode {
+	case MD_CROP:
+		return imaging.Fill(img, width, height, imaging.Center, imaging.Lanczos)
+	case MD_SMART:
+		// Scale to cover the cell then let SmartCrop pick the window
+		size := img.Bounds().Size()
+		scale := max(float64(width)/float64(size.X), float64(height)/float64(size.Y))
+		cover := imaging.Resize(img, max(width, int(float64(size.X)*scale+0.5)), max(height, int(float64(size.Y)*scale+0.5)), imaging.Lanczos)
+		imgr := &Imager{Image: cover}
+		return imaging.Clone(imgr.SmartCrop(width, height).Image)
+	case MD_STRETCH, MD_SCALE:
+		return imaging.Resize(img, width, height, imaging.Lanczos)
+	}
+
+	return imaging.Fit(img, width, height, imaging.Lanczos)
+}
@@ -0,0 +1,101 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func TestMontage(t *testing.T) {
+	colors := []color.NRGBA{
+		{255, 0, 0, 255},
+		{0, 255, 0, 255},
+		{0, 0, 255, 255},
+		{255, 255, 0, 255},
+	}
+
+	var images []image.Image
+	for _, c := range colors {
+		images = append(images, imaging.New(40, 40, c))
+	}
+
+	imgr, err := Montage(images, 2, 20, 20, color.White)
+	if err != nil {
+		t.Fatalf("Montage returned an error: %v", err)
+	}
+
+	if imgr.Image.Bounds().Dx() != 40 || imgr.Image.Bounds().Dy() != 40 {
+		t.Fatalf("Montage did not return the expected dimensions: got %v", imgr.Image.Bounds())
+	}
+
+	for idx, c := range colors {
+		x, y := (idx%2)*20+10, (idx/2)*20+10
+		if got := color.NRGBAModel.Convert(imgr.Image.At(x, y)); got != c {
+			t.Fatalf("Montage quadrant %d has color %v, want %v", idx, got, c)
+		}
+	}
+}
+
+func TestMontagePadding(t *testing.T) {
+	images := []image.Image{createTestImage(), createTestImage(), createTestImage()}
+
+	imgr, err := Montage(images, 2, 10, 10, color.White)
+	if err != nil {
+		t.Fatalf("Montage returned an error: %v", err)
+	}
+
+	if got := color.NRGBAModel.Convert(imgr.Image.At(15, 15)); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("Montage did not pad the last row with the background: got %v", got)
+	}
+
+	if _, err := Montage(images, 0, 10, 10, color.White); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Montage returned %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestCollage(t *testing.T) {
+	wide := imaging.New(80, 40, color.NRGBA{255, 0, 0, 255})
+	images := []image.Image{wide, wide, wide}
+
+	imgr, err := Collage(images, CollageOptions{
+		Cols:       2,
+		CellWidth:  40,
+		CellHeight: 40,
+		Gap:        4,
+		Margin:     2,
+		Background: color.White,
+		Modes:      []ResizeMode{MD_FIT, MD_CROP, MD_SMART},
+	})
+	if err != nil {
+		t.Fatalf("Collage returned an error: %v", err)
+	}
+
+	if bounds := imgr.Image.Bounds(); bounds.Dx() != 88 || bounds.Dy() != 88 {
+		t.Fatalf("Collage returned unexpected bounds: %v", bounds)
+	}
+
+	white, red := color.NRGBA{255, 255, 255, 255}, color.NRGBA{255, 0, 0, 255}
+	for _, tt := range []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{1, 20, white},  // margin
+		{42, 20, white}, // gap
+		{20, 5, white},  // letterbox of the fitted first cell
+		{20, 22, red},
+		{50, 5, red},  // the cropped second cell is covered
+		{20, 48, red}, // so is the smart cropped third one
+		{70, 70, white},
+	} {
+		if c := pixel(imgr.Image, tt.x, tt.y); c != tt.want {
+			t.Fatalf("Collage has %v at %d,%d, want %v", c, tt.x, tt.y, tt.want)
+		}
+	}
+
+	if _, err := Collage(images, CollageOptions{Cols: 1, Rows: 2, CellWidth: 10, CellHeight: 10}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Collage returned %v for too many images, want %v", err, ErrInvalidArgument)
+	}
+}
@@ -0,0 +1,74 @@
+package imager
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// BestFormatOptions holds the options used by BestFormat
+type BestFormatOptions struct {
+	// Formats are the modern formats tried in order of preference when the
+	// client accepts them, AVIF then WebP by default. AVIF is skipped unless
+	// an encoder is registered, see the imageravif package
+	Formats []string
+}
+
+// BestFormat returns the format to serve the image in to a client sending
+// the accept Accept header: the first of opts.Formats the client lists,
+// otherwise GIF for animations, PNG for images with transparency and JPEG
+// for the others. Animations are served as WebP when the client lists it,
+// as GIF otherwise, the other formats keeping a single frame. Responses
+// negotiated this way need a Vary: Accept header
+// i.e :
+// format := imgr.BestFormat(r.Header.Get("Accept"), imager.BestFormatOptions{})
+// data, err := imgr.ConvertTo(format).Bytes()
+func (i *Imager) BestFormat(accept string, opts BestFormatOptions) string {
+	accepted := acceptedTypes(accept)
+	if i.Animation != nil {
+		if accepted[mediaTypes[IMWEBP]] {
+			return IMWEBP
+		}
+		return IMGIF
+	}
+
+	formats := opts.Formats
+	if formats == nil {
+		formats = []string{IMAVIF, IMWEBP}
+	}
+	for _, format := range formats {
+		if format == IMAVIF && registeredEncoder(IMAVIF) == nil {
+			continue
+		}
+		if mediaType, ok := mediaTypes[format]; ok && accepted[mediaType] {
+			return format
+		}
+	}
+
+	if !isOpaque(i.Image) {
+		return IMPNG
+	}
+
+	return IMJPEG
+}
+
+// acceptedTypes returns the media types listed by an Accept header with a
+// non zero quality. Wildcards are left out, clients supporting the modern
+// formats list them explicitly
+func acceptedTypes(accept string) map[string]bool {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || strings.Contains(mediaType, "*") {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if quality, err := strconv.ParseFloat(q, 64); err != nil || quality <= 0 {
+				continue
+			}
+		}
+		accepted[mediaType] = true
+	}
+
+	return accepted
+}
@@ -0,0 +1,38 @@
+package imager
+
+import (
+	"image"
+	"testing"
+)
+
+func TestBestFormat(t *testing.T) {
+	opaque, _ := NewImager(createTestImage())
+	transparent, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 10, 10)))
+	animated, _ := NewImager(createTestImage())
+	animated.Animation = &Animation{Frames: []image.Image{createTestImage(), createTestImage()}}
+
+	chrome := "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8"
+	tests := []struct {
+		imgr   *Imager
+		accept string
+		opts   BestFormatOptions
+		want   string
+	}{
+		{opaque, chrome, BestFormatOptions{}, IMWEBP},
+		{transparent, chrome, BestFormatOptions{}, IMWEBP},
+		{animated, chrome, BestFormatOptions{}, IMWEBP},
+		{animated, "image/*", BestFormatOptions{}, IMGIF},
+		{opaque, "image/*,*/*", BestFormatOptions{}, IMJPEG},
+		{transparent, "", BestFormatOptions{}, IMPNG},
+		{opaque, "image/webp;q=0, image/png", BestFormatOptions{}, IMJPEG},
+		{opaque, chrome, BestFormatOptions{Formats: []string{}}, IMJPEG},
+		{opaque, "image/png", BestFormatOptions{Formats: []string{IMPNG}}, IMPNG},
+		{opaque, chrome, BestFormatOptions{Formats: []string{IMPNG}}, IMJPEG},
+	}
+
+	for _, test := range tests {
+		if got := test.imgr.BestFormat(test.accept, test.opts); got != test.want {
+			t.Errorf("BestFormat(%q, %+v) = %q, want %q", test.accept, test.opts, got, test.want)
+		}
+	}
+}
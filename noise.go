@@ -0,0 +1,183 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/disintegration/imaging"
+)
+
+// noiseSeed seeds the noise of AddNoise, so the same image always gets the
+// same grain and the results can be cached
+const noiseSeed = 1
+
+// AddNoise adds a gaussian noise to the image, such as a film grain. amount
+// ranges from 0 to 1 and is the standard deviation of the noise relative to
+// the full channel range. A monochrome noise shifts the three channels
+// together, leaving the colors as they are
+// i.e :
+// imgr.AddNoise(0.04, true)
+// imgr.AddNoise(0.1, false)
+func (i *Imager) AddNoise(amount float64, monochrome bool) *Imager {
+	if !i.checkRange("noise amount", amount, 0, 1) {
+		return i
+	}
+	if amount == 0 {
+		return i
+	}
+
+	return i.apply("AddNoise", func(img image.Image) image.Image {
+		dst := imaging.Clone(img)
+		rnd := rand.New(rand.NewSource(noiseSeed))
+		stddev := amount * 255
+
+		for k := 0; k < len(dst.Pix); k += 4 {
+			n := rnd.NormFloat64() * stddev
+			for c := 0; c < 3; c++ {
+				if !monochrome && c > 0 {
+					n = rnd.NormFloat64() * stddev
+				}
+				dst.Pix[k+c] = clampUint8(float64(dst.Pix[k+c]) + n)
+			}
+		}
+
+		return dst
+	})
+}
+
+// DenoiseMethod is how Denoise smooths the image
+type DenoiseMethod int
+
+const (
+	// DenoiseMedian replaces each channel by its median around the pixel,
+	// removing the specks of salt and pepper noise
+	DenoiseMedian DenoiseMethod = iota
+
+	// DenoiseBilateral averages the pixels around with weights falling
+	// with the distance and the color difference, smoothing the grain and
+	// the compression artifacts while keeping the edges
+	DenoiseBilateral
+)
+
+// bilateralRangeSigma is the difference on every channel at which the
+// bilateral weights fall to about 60%
+const bilateralRangeSigma = 25
+
+// Denoise reduces the noise of the image with method, looking at the pixels
+// up to radius away. Larger radiuses smooth more and are slower, a radius of
+// zero or less leaves the image as is
+// i.e :
+// imgr.Denoise(1, imager.DenoiseMedian)
+// imgr.Denoise(3, imager.DenoiseBilateral)
+func (i *Imager) Denoise(radius int, method DenoiseMethod) *Imager {
+	if method != DenoiseMedian && method != DenoiseBilateral {
+		i.setErr(fmt.Errorf("%w: denoise method %d", ErrInvalidArgument, method))
+		return i
+	}
+	if radius <= 0 {
+		return i
+	}
+
+	return i.apply("Denoise", func(img image.Image) image.Image {
+		if method == DenoiseBilateral {
+			return bilateralFilter(imaging.Clone(img), radius)
+		}
+		return medianFilter(imaging.Clone(img), radius)
+	})
+}
+
+// medianFilter returns the median of each color channel of src over a
+// square window of radius, the alpha is kept. src has its origin at 0, 0
+func medianFilter(src *image.NRGBA, radius int) *image.NRGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := imaging.Clone(src)
+	size := (2*radius + 1) * (2*radius + 1)
+
+	// The histograms of the window slide along each row, the edge pixels
+	// being repeated
+	var hist [3][256]int
+	column := func(x, y, delta int) {
+		x = min(max(x, 0), w-1)
+		for dy := -radius; dy <= radius; dy++ {
+			off := min(max(y+dy, 0), h-1)*src.Stride + x*4
+			for c := 0; c < 3; c++ {
+				hist[c][src.Pix[off+c]] += delta
+			}
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		hist = [3][256]int{}
+		for dx := -radius; dx <= radius; dx++ {
+			column(dx, y, 1)
+		}
+
+		for x := 0; x < w; x++ {
+			if x > 0 {
+				column(x-radius-1, y, -1)
+				column(x+radius, y, 1)
+			}
+
+			off := y*dst.Stride + x*4
+			for c := 0; c < 3; c++ {
+				count := 0
+				for v := 0; v < 256; v++ {
+					count += hist[c][v]
+					if 2*count > size {
+						dst.Pix[off+c] = uint8(v)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return dst
+}
+
+// bilateralFilter returns src smoothed by a bilateral filter of radius, src
+// has its origin at 0, 0
+func bilateralFilter(src *image.NRGBA, radius int) *image.NRGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewNRGBA(src.Rect)
+
+	spatialSigma := math.Max(float64(radius)/2, 1)
+	spatial := make([]float64, 0, (2*radius+1)*(2*radius+1))
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			spatial = append(spatial, math.Exp(-float64(dx*dx+dy*dy)/(2*spatialSigma*spatialSigma)))
+		}
+	}
+	rangeScale := -1.0 / (2 * bilateralRangeSigma * bilateralRangeSigma * 3)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			center := src.NRGBAAt(x, y)
+
+			var acc premultiplied
+			k := 0
+			for dy := -radius; dy <= radius; dy++ {
+				for dx := -radius; dx <= radius; dx++ {
+					px, py := x+dx, y+dy
+					if px < 0 || py < 0 || px >= w || py >= h {
+						k++
+						continue
+					}
+					c := src.NRGBAAt(px, py)
+					dr, dg, db := float64(c.R)-float64(center.R), float64(c.G)-float64(center.G), float64(c.B)-float64(center.B)
+					acc.add(c, spatial[k]*math.Exp((dr*dr+dg*dg+db*db)*rangeScale))
+					k++
+				}
+			}
+
+			c := acc.color()
+			c.A = center.A
+			off := y*dst.Stride + x*4
+			dst.Pix[off], dst.Pix[off+1], dst.Pix[off+2], dst.Pix[off+3] = c.R, c.G, c.B, c.A
+		}
+	}
+
+	return dst
+}
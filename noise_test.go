@@ -0,0 +1,87 @@
+package imager
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAddNoise(t *testing.T) {
+	imgr, _ := NewImager(createGrayImage(50, 50))
+	imgr.AddNoise(0.1, true)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("AddNoise returned an error: %v", err)
+	}
+
+	changed := 0
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 50; x++ {
+			c := pixel(imgr.Image, x, y)
+			if c.R != c.G || c.G != c.B {
+				t.Fatalf("AddNoise tinted the pixel at %d,%d with a monochrome noise: %v", x, y, c)
+			}
+			if c.R != 200 {
+				changed++
+			}
+		}
+	}
+	if changed < 2000 {
+		t.Fatalf("AddNoise changed only %d pixels", changed)
+	}
+
+	again, _ := NewImager(createGrayImage(50, 50))
+	if !bytes.Equal(again.AddNoise(0.1, true).Image.(*image.NRGBA).Pix, imgr.Image.(*image.NRGBA).Pix) {
+		t.Fatalf("AddNoise did not add the same noise twice")
+	}
+
+	colored, _ := NewImager(createGrayImage(50, 50))
+	colored.AddNoise(0.1, false)
+	if c := pixel(colored.Image, 10, 10); c.R == c.G && c.G == c.B {
+		t.Fatalf("AddNoise did not add a colored noise: %v", c)
+	}
+
+	if err := colored.AddNoise(2, false).Err(); err == nil {
+		t.Fatalf("AddNoise did not record an error for an amount out of range")
+	}
+}
+
+func TestDenoise(t *testing.T) {
+	img := createGrayImage(30, 30)
+	img.SetNRGBA(10, 10, color.NRGBA{255, 255, 255, 255})
+	img.SetNRGBA(20, 5, color.NRGBA{0, 0, 0, 255})
+
+	imgr, _ := NewImager(img)
+	imgr.Denoise(1, DenoiseMedian)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("Denoise returned an error: %v", err)
+	}
+	if c := pixel(imgr.Image, 10, 10); c != (color.NRGBA{200, 200, 200, 255}) {
+		t.Fatalf("Denoise did not remove the white speck: %v", c)
+	}
+	if c := pixel(imgr.Image, 20, 5); c != (color.NRGBA{200, 200, 200, 255}) {
+		t.Fatalf("Denoise did not remove the black speck: %v", c)
+	}
+
+	// A faint grain is smoothed while an edge is kept
+	img = createGrayImage(30, 30)
+	img.SetNRGBA(10, 10, color.NRGBA{212, 212, 212, 255})
+	for y := 0; y < 30; y++ {
+		for x := 20; x < 30; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{20, 20, 20, 255})
+		}
+	}
+
+	imgr, _ = NewImager(img)
+	imgr.Denoise(2, DenoiseBilateral)
+	if c := pixel(imgr.Image, 10, 10); c.R > 206 {
+		t.Fatalf("Denoise did not smooth the grain: %v", c)
+	}
+	if left, right := pixel(imgr.Image, 19, 15), pixel(imgr.Image, 20, 15); left.R < 195 || right.R > 25 {
+		t.Fatalf("Denoise blurred the edge: %v %v", left, right)
+	}
+
+	if err := imgr.Denoise(1, DenoiseMethod(9)).Err(); err == nil {
+		t.Fatalf("Denoise did not record an error for an unknown method")
+	}
+}
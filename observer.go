@@ -0,0 +1,127 @@
+package imager
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Observer is notified of the images decoded and encoded by every Imager,
+// and of the operations applied to them, such as to export metrics. The
+// methods are called synchronously, from the goroutine doing the work, so
+// they must be fast and safe for concurrent use
+type Observer interface {
+	OnDecode(DecodeEvent)
+	OnOperation(OperationEvent)
+	OnEncode(EncodeEvent)
+}
+
+// DecodeEvent describes an image loaded by LoadByte, LoadReader or the
+// NewImagerFrom* constructors
+type DecodeEvent struct {
+	// Format is the ImageType of the decoded image, empty on error
+	Format string
+
+	// Bytes is the size of the encoded data read
+	Bytes int64
+
+	// Width and Height are the dimensions of the decoded image
+	Width  int
+	Height int
+
+	Duration time.Duration
+	Err      error
+}
+
+// OperationEvent describes an operation applied to the image
+type OperationEvent struct {
+	// Name is the name of the method, such as Resize or GaussianBlur. A
+	// method calling another one, such as Rotate calling RotateWith, reports
+	// its own name
+	Name string
+
+	// Frames is the number of frames processed, more than 1 for animations
+	Frames int
+
+	Duration time.Duration
+}
+
+// EncodeEvent describes an image encoded by Bytes, Encode, Save and the
+// other encoding methods
+type EncodeEvent struct {
+	// Format is the format written, after the fallback format is applied
+	Format string
+
+	// Bytes is the size of the encoded data written
+	Bytes int64
+
+	Duration time.Duration
+	Err      error
+}
+
+// observerBox holds the registered observer
+type observerBox struct {
+	o Observer
+}
+
+// observer is the registered observer, nil when there is none
+var observer atomic.Pointer[observerBox]
+
+// SetObserver registers o to be notified by every Imager, replacing the
+// previous observer. A nil observer stops the notifications, nothing is
+// measured without one
+// i.e :
+// imager.SetObserver(imagerprom.NewObserver(prometheus.DefaultRegisterer))
+// imager.SetObserver(nil)
+func SetObserver(o Observer) {
+	if o == nil {
+		observer.Store(nil)
+		return
+	}
+
+	observer.Store(&observerBox{o: o})
+}
+
+// currentObserver returns the registered observer, nil when there is none
+func currentObserver() Observer {
+	if box := observer.Load(); box != nil {
+		return box.o
+	}
+
+	return nil
+}
+
+// observeDecode notifies the observer of the image decoded since start from
+// size bytes
+func (i *Imager) observeDecode(start time.Time, size int64, err error) {
+	obs := currentObserver()
+	if obs == nil {
+		return
+	}
+
+	event := DecodeEvent{Bytes: size, Duration: time.Since(start), Err: err}
+	if err == nil {
+		event.Format = i.ImageType
+		event.Width, event.Height = i.Image.Bounds().Dx(), i.Image.Bounds().Dy()
+	}
+	obs.OnDecode(event)
+}
+
+// observeOperation notifies obs of the operation name started at start, it
+// is deferred by apply
+func observeOperation(obs Observer, name string, start time.Time, frames int) {
+	obs.OnOperation(OperationEvent{Name: name, Frames: frames, Duration: time.Since(start)})
+}
+
+// operation names the operations applied until the returned function is
+// called after name, the methods calling other methods report their own
+// name. Nested calls keep the outermost name
+// i.e :
+// defer i.operation("Resize")()
+func (i *Imager) operation(name string) func() {
+	if i.operationName != "" {
+		return func() {}
+	}
+
+	i.operationName = name
+	return func() { i.operationName = "" }
+}
@@ -0,0 +1,103 @@
+package imager
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+)
+
+// recordingObserver keeps the events it is notified of
+type recordingObserver struct {
+	mu         sync.Mutex
+	decodes    []DecodeEvent
+	operations []OperationEvent
+	encodes    []EncodeEvent
+}
+
+func (r *recordingObserver) OnDecode(e DecodeEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decodes = append(r.decodes, e)
+}
+
+func (r *recordingObserver) OnOperation(e OperationEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operations = append(r.operations, e)
+}
+
+func (r *recordingObserver) OnEncode(e EncodeEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encodes = append(r.encodes, e)
+}
+
+func TestObserver(t *testing.T) {
+	rec := &recordingObserver{}
+	SetObserver(rec)
+	defer SetObserver(nil)
+
+	data := createTestJPEG(t)
+	imgr, err := NewImagerFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewImagerFromReader returned an error: %v", err)
+	}
+	out, err := imgr.Resize(50, 50, MD_STRETCH).GaussianBlur(1).ConvertTo(IMPNG).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+
+	if len(rec.decodes) != 1 || rec.decodes[0].Format != IMJPEG || rec.decodes[0].Bytes != int64(len(data)) || rec.decodes[0].Width != 100 {
+		t.Fatalf("unexpected decode events: %+v", rec.decodes)
+	}
+	if len(rec.operations) != 2 || rec.operations[0].Name != "Resize" || rec.operations[1].Name != "GaussianBlur" || rec.operations[0].Frames != 1 {
+		t.Fatalf("unexpected operation events: %+v", rec.operations)
+	}
+	if len(rec.encodes) != 1 || rec.encodes[0].Format != IMPNG || rec.encodes[0].Bytes != int64(len(out)) {
+		t.Fatalf("unexpected encode events: %+v", rec.encodes)
+	}
+
+	if _, err := NewImagerFromBytes([]byte("not an image")); err == nil || len(rec.decodes) != 2 || rec.decodes[1].Err == nil {
+		t.Fatalf("the failed decode was not reported: %+v", rec.decodes)
+	}
+
+	SetObserver(nil)
+	imgr.Resize(10, 10, MD_STRETCH)
+	if len(rec.operations) != 2 {
+		t.Fatalf("an operation was reported without observer")
+	}
+}
+
+func TestObserverOperationNames(t *testing.T) {
+	rec := &recordingObserver{}
+	SetObserver(rec)
+	defer SetObserver(nil)
+
+	// The methods calling other methods report their own name
+	tests := []struct {
+		name string
+		op   func(i *Imager) *Imager
+	}{
+		{"Rotate", func(i *Imager) *Imager { return i.Rotate(45) }},
+		{"RotateWith", func(i *Imager) *Imager { return i.RotateWith(45, RotateOptions{}) }},
+		{"Border", func(i *Imager) *Imager { return i.Border(2, nil) }},
+		{"Resize", func(i *Imager) *Imager { return i.Resize(50, 50, MD_CROP, WithGravity(AnchorTop)) }},
+		{"CropToRatio", func(i *Imager) *Imager { return i.CropToRatio(2, 1) }},
+		{"Grayscale", (*Imager).Grayscale},
+		{"Temperature", func(i *Imager) *Imager { return i.Temperature(500) }},
+		{"RotateCtx", func(i *Imager) *Imager {
+			i.RotateCtx(context.Background(), 90)
+			return i
+		}},
+	}
+
+	for _, tt := range tests {
+		rec.operations = nil
+		imgr, _ := NewImager(createTestImage())
+		tt.op(imgr)
+		if len(rec.operations) != 1 || rec.operations[0].Name != tt.name {
+			t.Errorf("expected a single %s operation, got %+v", tt.name, rec.operations)
+		}
+	}
+}
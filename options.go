@@ -0,0 +1,176 @@
+package imager
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+)
+
+// LoadOption configures how an image is loaded by the NewImagerFrom* constructors
+type LoadOption func(*loadConfig)
+
+// loadConfig holds the options of the NewImagerFrom* constructors
+type loadConfig struct {
+	autoOrient bool
+	limits     DecodeLimits
+
+	// sanitize is set by WithSanitize, along with the largest size kept
+	sanitize             bool
+	sanitizeW, sanitizeH int
+}
+
+// newLoadConfig returns the configuration set by opts
+func newLoadConfig(opts []LoadOption) loadConfig {
+	var config loadConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+// WithAutoOrient rotates and flips the loaded image according to its EXIF
+// orientation, see AutoOrient
+// i.e :
+// imgr, err := imager.NewImagerFromFile("photo.jpg", imager.WithAutoOrient())
+func WithAutoOrient() LoadOption {
+	return func(c *loadConfig) {
+		c.autoOrient = true
+	}
+}
+
+// DecodeLimits protects against decompression bombs, images that are small
+// once encoded but huge once decoded. Zero fields are not limited
+type DecodeLimits struct {
+	MaxWidth  int
+	MaxHeight int
+
+	// MaxPixels limits the width times the height
+	MaxPixels int64
+
+	// MaxBytes limits the size of the encoded data
+	MaxBytes int64
+}
+
+// WithDecodeLimits refuses to load images exceeding limits with ErrTooLarge.
+// The dimensions are read from the image header before decoding, so an
+// oversized image never allocates its pixels
+// i.e :
+// imgr, err := imager.NewImagerFromReader(r.Body, imager.WithDecodeLimits(imager.DecodeLimits{MaxPixels: 50_000_000, MaxBytes: 20 << 20}))
+func WithDecodeLimits(limits DecodeLimits) LoadOption {
+	return func(c *loadConfig) {
+		c.limits = limits
+	}
+}
+
+// checkSize returns ErrTooLarge when the encoded size exceeds the limits
+func (l DecodeLimits) checkSize(size int64) error {
+	if l.MaxBytes > 0 && size > l.MaxBytes {
+		return fmt.Errorf("%w: more than %d bytes", ErrTooLarge, l.MaxBytes)
+	}
+
+	return nil
+}
+
+// checkConfig returns ErrTooLarge when the dimensions exceed the limits
+func (l DecodeLimits) checkConfig(config image.Config) error {
+	if (l.MaxWidth > 0 && config.Width > l.MaxWidth) || (l.MaxHeight > 0 && config.Height > l.MaxHeight) {
+		return fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrTooLarge, config.Width, config.Height, l.MaxWidth, l.MaxHeight)
+	}
+	if pixels := int64(config.Width) * int64(config.Height); l.MaxPixels > 0 && pixels > l.MaxPixels {
+		return fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrTooLarge, config.Width, config.Height, l.MaxPixels)
+	}
+
+	return nil
+}
+
+// enabled reports whether any limit is set
+func (l DecodeLimits) enabled() bool {
+	return l != DecodeLimits{}
+}
+
+// checkBytes checks encoded data against the limits
+func (l DecodeLimits) checkBytes(data []byte) error {
+	if !l.enabled() {
+		return nil
+	}
+	if err := l.checkSize(int64(len(data))); err != nil {
+		return err
+	}
+
+	// RAW files are loaded from their preview, not from the first IFD
+	config, _, err := decodeConfig(data)
+	if err != nil {
+		return err
+	}
+
+	return l.checkConfig(config)
+}
+
+// checkReader checks the data of r against the limits, the returned reader
+// replaces r: it replays the header read for the check and fails once more
+// than MaxBytes are read
+func (l DecodeLimits) checkReader(r io.Reader) (io.Reader, error) {
+	if !l.enabled() {
+		return r, nil
+	}
+
+	if l.MaxBytes > 0 {
+		r = &limitedReader{r: r, remaining: l.MaxBytes, limits: l}
+	}
+
+	head := bytes.NewBuffer(nil)
+	config, _, err := image.DecodeConfig(io.TeeReader(r, head))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	if err := l.checkConfig(config); err != nil {
+		return nil, err
+	}
+
+	return io.MultiReader(head, r), nil
+}
+
+// limitedReader fails with ErrTooLarge when the data goes past remaining
+// bytes, no byte past the limit is ever returned
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	limits    DecodeLimits
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Only fail when there actually is more data
+		var probe [1]byte
+		n, err := io.ReadFull(l.r, probe[:])
+		if n == 0 {
+			return 0, err
+		}
+		return 0, l.limits.checkSize(l.limits.MaxBytes + 1)
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+
+	return n, err
+}
+
+// applyLoadOptions applies the options to a freshly loaded image. The result
+// becomes the image restored by Reset
+func (i *Imager) applyLoadOptions(config loadConfig) *Imager {
+	if config.autoOrient {
+		i.AutoOrient()
+		i.snapshot()
+	}
+	if config.sanitize {
+		i.Sanitize(config.sanitizeW, config.sanitizeH)
+		i.snapshot()
+	}
+
+	return i
+}
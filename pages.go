@@ -0,0 +1,200 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"sync"
+)
+
+// pdfDPI is the resolution PDF pages are rendered at
+const pdfDPI = 150
+
+// pdfRenderer renders a page of a PDF document and pdfPageCount counts them,
+// they are set by RegisterPDF
+var (
+	pdfMu        sync.RWMutex
+	pdfRenderer  func(data []byte, page int, dpi float64) (image.Image, error)
+	pdfPageCount func(data []byte) (int, error)
+)
+
+// RegisterPDF plugs in a PDF renderer, such as the MuPDF one of the
+// imagerpdf package, without the package depending on it. render renders
+// the page of data, counted from 0, at dpi and count counts the pages.
+// Registering nil functions removes the renderer
+// i.e :
+// imager.RegisterPDF(renderPage, countPages)
+func RegisterPDF(render func(data []byte, page int, dpi float64) (image.Image, error), count func(data []byte) (int, error)) {
+	pdfMu.Lock()
+	defer pdfMu.Unlock()
+
+	pdfRenderer, pdfPageCount = render, count
+}
+
+// pdfFuncs returns the functions set by RegisterPDF, nil when there are none
+func pdfFuncs() (func(data []byte, page int, dpi float64) (image.Image, error), func(data []byte) (int, error)) {
+	pdfMu.RLock()
+	defer pdfMu.RUnlock()
+
+	if pdfRenderer == nil || pdfPageCount == nil {
+		return nil, nil
+	}
+	return pdfRenderer, pdfPageCount
+}
+
+// isPDF reports whether data starts like a PDF document
+func isPDF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("%PDF-"))
+}
+
+// PageCount returns the number of pages of data: the images of a multi-page
+// TIFF, the pages of a PDF document, 1 for the other images. PDF documents
+// are only read once a renderer is registered, see RegisterPDF
+// i.e :
+// pages, err := imager.PageCount(data)
+func PageCount(data []byte) (int, error) {
+	switch {
+	case isPDF(data):
+		_, count := pdfFuncs()
+		if count == nil {
+			return 0, fmt.Errorf("%w: PDF documents need the imagerpdf package", ErrUnknownFormat)
+		}
+		return count(data)
+	case isTIFF(data):
+		// Broken directories are left to the decoder to report
+		return max(1, len(tiffPageOffsets(data))), nil
+	}
+
+	return 1, nil
+}
+
+// NewImagerFromPage creates a new Imager from the page of data, counted from
+// 0, see PageCount. PDF pages are rendered at 150 DPI and have the PNG
+// ImageType, the pages of other images keep their format
+// i.e :
+// imgr, err := imager.NewImagerFromPage(data, 2)
+// imgr, err := imager.NewImagerFromPage(pdf, 0, imager.WithDecodeLimits(imager.DecodeLimits{MaxPixels: 20_000_000}))
+func NewImagerFromPage(data []byte, page int, opts ...LoadOption) (*Imager, error) {
+	pages, err := PageCount(data)
+	if err != nil {
+		return nil, err
+	}
+	if page < 0 || page >= pages {
+		return nil, fmt.Errorf("%w: page %d of %d", ErrInvalidArgument, page, pages)
+	}
+
+	switch {
+	case isPDF(data):
+		return newImagerFromPDFPage(data, page, newLoadConfig(opts))
+	case isTIFF(data):
+		data = tiffPage(data, page)
+	}
+
+	return NewImagerFromBytes(data, opts...)
+}
+
+// EachPage calls fn with an Imager of each page of data in turn, see
+// NewImagerFromPage. Only one page is loaded at a time, the first error
+// stops the iteration and is returned
+// i.e :
+//
+//	err := imager.EachPage(data, func(page int, imgr *imager.Imager) error {
+//		return imgr.Resize(200, 0, imager.MD_SCALE).Save(fmt.Sprintf("page-%d.jpg", page))
+//	})
+func EachPage(data []byte, fn func(page int, imgr *Imager) error, opts ...LoadOption) error {
+	pages, err := PageCount(data)
+	if err != nil {
+		return err
+	}
+
+	for page := 0; page < pages; page++ {
+		imgr, err := NewImagerFromPage(data, page, opts...)
+		if err != nil {
+			return err
+		}
+		if err := fn(page, imgr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newImagerFromPDFPage renders the page of a PDF document, checking the
+// limits of config against the size of the encoded data and of the page
+func newImagerFromPDFPage(data []byte, page int, config loadConfig) (*Imager, error) {
+	if err := config.limits.checkSize(int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	render, _ := pdfFuncs()
+	if render == nil {
+		return nil, fmt.Errorf("%w: PDF documents need the imagerpdf package", ErrUnknownFormat)
+	}
+	img, err := render(data, page, pdfDPI)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	if err := config.limits.checkConfig(image.Config{Width: bounds.Dx(), Height: bounds.Dy()}); err != nil {
+		return nil, err
+	}
+
+	imgr := &Imager{}
+	imgr.setImage(img, IMPNG, nil)
+
+	return imgr.applyLoadOptions(config), nil
+}
+
+// isTIFF reports whether data starts like a TIFF image, either byte order
+func isTIFF(data []byte) bool {
+	return len(data) >= 8 && (bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")))
+}
+
+// tiffByteOrder returns the byte order of the TIFF image in data
+func tiffByteOrder(data []byte) binary.ByteOrder {
+	if data[0] == 'M' {
+		return binary.BigEndian
+	}
+
+	return binary.LittleEndian
+}
+
+// tiffPageOffsets returns the offsets of the image file directories of a
+// TIFF image, one per page, following the chain from the header
+func tiffPageOffsets(data []byte) []uint32 {
+	order := tiffByteOrder(data)
+
+	var offsets []uint32
+	seen := map[uint32]bool{}
+	for offset := order.Uint32(data[4:]); offset != 0 && !seen[offset]; {
+		// Entry count, 12 bytes entries and the offset of the next one
+		if int64(offset)+2 > int64(len(data)) {
+			break
+		}
+		end := int64(offset) + 2 + 12*int64(order.Uint16(data[offset:]))
+		if end+4 > int64(len(data)) {
+			break
+		}
+
+		seen[offset] = true
+		offsets = append(offsets, offset)
+		offset = order.Uint32(data[end:])
+	}
+
+	return offsets
+}
+
+// tiffPage returns a TIFF image whose first directory is the one of page,
+// the other offsets being absolute the rest of the data is left as is
+func tiffPage(data []byte, page int) []byte {
+	if page == 0 {
+		return data
+	}
+
+	out := bytes.Clone(data)
+	tiffByteOrder(data).PutUint32(out[4:], tiffPageOffsets(data)[page])
+
+	return out
+}
@@ -0,0 +1,109 @@
+package imager
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// createMultiPageTIFF returns an uncompressed grayscale TIFF with a page of
+// each size, filled with the gray of the same index
+func createMultiPageTIFF(sizes [][2]int, grays []uint8) []byte {
+	le := binary.LittleEndian
+	data := []byte("II*\x00\x00\x00\x00\x00")
+	next := 4
+
+	for idx, size := range sizes {
+		w, h := size[0], size[1]
+		strip := len(data)
+		for p := 0; p < w*h; p++ {
+			data = append(data, grays[idx])
+		}
+		if len(data)%2 == 1 {
+			data = append(data, 0)
+		}
+
+		le.PutUint32(data[next:], uint32(len(data)))
+		entries := [][2]uint32{
+			{256, uint32(w)}, {257, uint32(h)}, {258, 8}, {259, 1}, {262, 1},
+			{273, uint32(strip)}, {277, 1}, {278, uint32(h)}, {279, uint32(w * h)},
+		}
+		data = le.AppendUint16(data, uint16(len(entries)))
+		for _, e := range entries {
+			data = le.AppendUint16(data, uint16(e[0]))
+			data = le.AppendUint16(data, 4)
+			data = le.AppendUint32(data, 1)
+			data = le.AppendUint32(data, e[1])
+		}
+		next = len(data)
+		data = le.AppendUint32(data, 0)
+	}
+
+	return data
+}
+
+func TestPages(t *testing.T) {
+	data := createMultiPageTIFF([][2]int{{20, 10}, {30, 15}, {8, 8}}, []uint8{10, 120, 250})
+
+	pages, err := PageCount(data)
+	if err != nil || pages != 3 {
+		t.Fatalf("PageCount returned %d, %v, want 3 pages", pages, err)
+	}
+
+	var widths []int
+	err = EachPage(data, func(page int, imgr *Imager) error {
+		widths = append(widths, imgr.Image.Bounds().Dx())
+		if got := color.GrayModel.Convert(imgr.Image.At(1, 1)).(color.Gray).Y; got != []uint8{10, 120, 250}[page] {
+			t.Errorf("page %d has gray %d", page, got)
+		}
+		if imgr.ImageType != IMTIFF {
+			t.Errorf("page %d has type %q", page, imgr.ImageType)
+		}
+		return nil
+	})
+	if err != nil || len(widths) != 3 || widths[0] != 20 || widths[1] != 30 || widths[2] != 8 {
+		t.Fatalf("EachPage loaded widths %v, %v", widths, err)
+	}
+
+	if _, err := NewImagerFromPage(data, 3); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("NewImagerFromPage returned %v for a missing page, want ErrInvalidArgument", err)
+	}
+
+	// Other images have a single page
+	png, _ := NewImager(createTestImage())
+	png.ImageType = IMPNG
+	single, _ := png.Bytes()
+	if pages, err := PageCount(single); err != nil || pages != 1 {
+		t.Fatalf("PageCount returned %d, %v for a png, want 1 page", pages, err)
+	}
+	if _, err := NewImagerFromPage(single, 0); err != nil {
+		t.Fatalf("NewImagerFromPage returned an error for a png: %v", err)
+	}
+
+	if _, err := NewImagerFromPage([]byte("%PDF-1.7\n"), 0); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("NewImagerFromPage returned %v for a pdf without renderer, want ErrUnknownFormat", err)
+	}
+}
+
+func TestRegisterPDF(t *testing.T) {
+	RegisterPDF(func(data []byte, page int, dpi float64) (image.Image, error) {
+		return image.NewNRGBA(image.Rect(0, 0, int(dpi), 10*(page+1))), nil
+	}, func(data []byte) (int, error) {
+		return 3, nil
+	})
+	t.Cleanup(func() { RegisterPDF(nil, nil) })
+
+	pdf := []byte("%PDF-1.7\n")
+	if pages, err := PageCount(pdf); err != nil || pages != 3 {
+		t.Fatalf("PageCount returned %d, %v, want 3 pages", pages, err)
+	}
+	imgr, err := NewImagerFromPage(pdf, 1)
+	if err != nil {
+		t.Fatalf("NewImagerFromPage returned an error: %v", err)
+	}
+	if b := imgr.Image.Bounds(); imgr.ImageType != IMPNG || b.Dx() != pdfDPI || b.Dy() != 20 {
+		t.Errorf("expected the second page rendered at 150 DPI, got %s %v", imgr.ImageType, b)
+	}
+}
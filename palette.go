@@ -0,0 +1,86 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+// paletteSampleSize is the size the image is downsampled to before its
+// colors are extracted
+const paletteSampleSize = 100
+
+// Palette returns up to n representative colors of the image, computed with
+// the median cut algorithm over a downsampled copy. The colors are ordered
+// from the most to the least common, transparent pixels are ignored
+// i.e :
+// colors := imgr.Palette(5)
+// hex := imager.HexColor(colors[0])
+func (i *Imager) Palette(n int) []color.Color {
+	if n <= 0 {
+		i.setErr(fmt.Errorf("%w: %d colors", ErrInvalidArgument, n))
+		return nil
+	}
+
+	boxes := splitBoxes(sampleColors(i.Image), n)
+	sort.SliceStable(boxes, func(a, b int) bool { return boxes[a].population() > boxes[b].population() })
+
+	palette := make([]color.Color, len(boxes))
+	for idx, b := range boxes {
+		palette[idx] = b.average()
+	}
+
+	return palette
+}
+
+// DominantColor returns the most common color of the image, among the
+// colors of a small palette so close shades count together. Fully
+// transparent images return color.Transparent
+// i.e :
+// background := imager.HexColor(imgr.DominantColor())
+func (i *Imager) DominantColor() color.Color {
+	palette := i.Palette(8)
+	if len(palette) == 0 {
+		return color.Transparent
+	}
+
+	return palette[0]
+}
+
+// HexColor returns c as a #rrggbb string, the alpha is dropped. It is the
+// format parsed by Pipeline colors
+// i.e :
+// hex := imager.HexColor(color.White) // "#ffffff"
+func HexColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+// sampleColors counts the colors of a downsampled copy of img, skipping
+// transparent pixels
+func sampleColors(img image.Image) map[[4]uint8]int {
+	sample := imaging.Fit(img, paletteSampleSize, paletteSampleSize, imaging.Box)
+
+	counts := map[[4]uint8]int{}
+	for p := 0; p+3 < len(sample.Pix); p += 4 {
+		if sample.Pix[p+3] == 0 {
+			continue
+		}
+		counts[[4]uint8{sample.Pix[p], sample.Pix[p+1], sample.Pix[p+2], sample.Pix[p+3]}]++
+	}
+
+	return counts
+}
+
+// population returns the number of pixels in the box
+func (b colorBox) population() int {
+	total := 0
+	for _, cc := range b {
+		total += cc.count
+	}
+
+	return total
+}
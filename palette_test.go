@@ -0,0 +1,43 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestPalette(t *testing.T) {
+	// Mostly blue, some yellow and a transparent band
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 200))
+	draw.Draw(img, image.Rect(0, 0, 200, 150), image.NewUniform(color.NRGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 150, 200, 180), image.NewUniform(color.NRGBA{255, 255, 0, 255}), image.Point{}, draw.Src)
+
+	imgr, _ := NewImager(img)
+	palette := imgr.Palette(4)
+	if len(palette) != 2 {
+		t.Fatalf("Palette returned %d colors, want 2: %v", len(palette), palette)
+	}
+
+	if got := HexColor(palette[0]); got != "#0000ff" {
+		t.Fatalf("Palette returned %s first, want #0000ff", got)
+	}
+	if got := HexColor(palette[1]); got != "#ffff00" {
+		t.Fatalf("Palette returned %s second, want #ffff00", got)
+	}
+
+	if got := HexColor(imgr.DominantColor()); got != "#0000ff" {
+		t.Fatalf("DominantColor returned %s, want #0000ff", got)
+	}
+
+	if imgr.Palette(0); imgr.Err() == nil {
+		t.Fatalf("Palette did not record an error for 0 colors")
+	}
+}
+
+func TestDominantColorTransparent(t *testing.T) {
+	imgr, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 10, 10)))
+	if got := imgr.DominantColor(); got != color.Transparent {
+		t.Fatalf("DominantColor returned %v, want transparent", got)
+	}
+}
@@ -0,0 +1,148 @@
+package imager
+
+import (
+	"image"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+// WithWorkers resamples with a pool of workers goroutines, each resizing a
+// horizontal band of the image, zero meaning GOMAXPROCS. imaging already
+// spreads the default resize over GOMAXPROCS, the option bounds the
+// goroutines of a resize, such as 1 on a server resizing many images at
+// once. It applies to MD_FIT, MD_SCALE and MD_STRETCH
+// i.e :
+// imgr.Resize(8000, 0, imager.MD_SCALE, imager.WithWorkers(0))
+// imgr.Resize(400, 400, imager.MD_FIT, imager.WithWorkers(1))
+func WithWorkers(workers int) ResizeOption {
+	return resizeOptionFunc(func(c *resizeConfig) {
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		c.workers = workers
+	})
+}
+
+// resizeParallel resizes img to width x height with filter, the rows being
+// split between workers goroutines. A zero width or height keeps the aspect
+// ratio
+func resizeParallel(img image.Image, width, height int, filter imaging.ResampleFilter, workers int) *image.NRGBA {
+	width, height, ok := tiledSize(img, width, height)
+	if !ok {
+		return &image.NRGBA{}
+	}
+
+	src := imaging.Clone(img)
+	if filter.Support <= 0 {
+		return resizeNearest(src, width, height, workers)
+	}
+
+	// Separable resampling, horizontally then vertically
+	if width != src.Rect.Dx() {
+		weights := resizeWeights(width, src.Rect.Dx(), filter)
+		tmp := image.NewNRGBA(image.Rect(0, 0, width, src.Rect.Dy()))
+		parallelRows(tmp.Rect.Dy(), workers, func(top, bottom int) {
+			for y := top; y < bottom; y++ {
+				resizeColumns(tmp, y, src, weights)
+			}
+		})
+		src = tmp
+	}
+	if height != src.Rect.Dy() {
+		weights := resizeWeights(height, src.Rect.Dy(), filter)
+		dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+		parallelRows(height, workers, func(top, bottom int) {
+			for y := top; y < bottom; y++ {
+				resizeRow(dst, y, src, 0, weights[y])
+			}
+		})
+		src = dst
+	}
+
+	return src
+}
+
+// fitParallel resizes img like imaging.Fit, within maxWidth x maxHeight
+// keeping the aspect ratio, with resizeParallel
+func fitParallel(img image.Image, maxWidth, maxHeight int, filter imaging.ResampleFilter, workers int) *image.NRGBA {
+	srcW, srcH := img.Bounds().Dx(), img.Bounds().Dy()
+	if srcW <= maxWidth && srcH <= maxHeight {
+		return imaging.Clone(img)
+	}
+
+	ratio := float64(srcW) / float64(srcH)
+	width, height := maxWidth, int(float64(maxWidth)/ratio)
+	if ratio <= float64(maxWidth)/float64(maxHeight) {
+		width, height = int(float64(maxHeight)*ratio), maxHeight
+	}
+
+	return resizeParallel(img, max(1, width), max(1, height), filter, workers)
+}
+
+// resizeColumns computes the row y of dst from the same row of src, each
+// output pixel mixing the source pixels of its weights
+func resizeColumns(dst *image.NRGBA, y int, src *image.NRGBA, weights [][]indexWeight) {
+	row := src.Pix[y*src.Stride:]
+	for x := 0; x < dst.Rect.Dx(); x++ {
+		var r, g, b, a float64
+		for _, w := range weights[x] {
+			s := row[w.index*4:]
+			aw := float64(s[3]) * w.weight
+			r += float64(s[0]) * aw
+			g += float64(s[1]) * aw
+			b += float64(s[2]) * aw
+			a += aw
+		}
+		if a != 0 {
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0] = clampUint8(r / a)
+			d[1] = clampUint8(g / a)
+			d[2] = clampUint8(b / a)
+			d[3] = clampUint8(a)
+		}
+	}
+}
+
+// resizeNearest resizes src to width x height picking the nearest source
+// pixels, like imaging.NearestNeighbor
+func resizeNearest(src *image.NRGBA, width, height int, workers int) *image.NRGBA {
+	dx := float64(src.Rect.Dx()) / float64(width)
+	dy := float64(src.Rect.Dy()) / float64(height)
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	parallelRows(height, workers, func(top, bottom int) {
+		for y := top; y < bottom; y++ {
+			sy := int(math.Floor((float64(y) + 0.5) * dy))
+			for x := 0; x < width; x++ {
+				sx := int(math.Floor((float64(x) + 0.5) * dx))
+				copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:])
+			}
+		}
+	})
+
+	return dst
+}
+
+// parallelRows calls fn on the bands of rows from 0 to height, one per
+// worker, and waits for all of them
+func parallelRows(height, workers int, fn func(top, bottom int)) {
+	workers = max(1, min(workers, height))
+	if workers == 1 {
+		fn(0, height)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		top, bottom := n*height/workers, (n+1)*height/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(top, bottom)
+		}()
+	}
+	wg.Wait()
+}
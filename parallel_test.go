@@ -0,0 +1,70 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func createPanoramaImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y * 3), uint8(x ^ y), 255})
+		}
+	}
+	return img
+}
+
+func TestWithWorkers(t *testing.T) {
+	img := createPanoramaImage(640, 200)
+	for _, mode := range []ResizeMode{MD_FIT, MD_SCALE, MD_STRETCH} {
+		for _, workers := range []int{0, 1, 3} {
+			for _, filter := range []imaging.ResampleFilter{imaging.Lanczos, imaging.NearestNeighbor} {
+				parallel, _ := NewImager(img)
+				parallel.Resize(211, 90, mode, WithFilter(filter), WithWorkers(workers))
+
+				plain, _ := NewImager(img)
+				plain.Resize(211, 90, mode, WithFilter(filter))
+
+				if parallel.Image.Bounds() != plain.Image.Bounds() {
+					t.Fatalf("WithWorkers(%d) resized to %v with mode %v, want %v", workers, parallel.Image.Bounds(), mode, plain.Image.Bounds())
+				}
+				psnr, err := parallel.CompareTo(plain)
+				if err != nil {
+					t.Fatalf("CompareTo returned an error: %v", err)
+				}
+				if psnr < 50 {
+					t.Fatalf("WithWorkers(%d) differs from Resize with mode %v: PSNR %v dB", workers, mode, psnr)
+				}
+			}
+		}
+	}
+
+	small, _ := NewImager(createTestImage())
+	if bounds := small.Resize(400, 400, MD_FIT, WithWorkers(2)).Image.Bounds(); bounds.Dx() != 100 {
+		t.Fatalf("WithWorkers enlarged an image fitting within the size: %v", bounds)
+	}
+}
+
+func BenchmarkResize(b *testing.B) {
+	img := createPanoramaImage(4000, 1000)
+	// Zero uses GOMAXPROCS workers
+	for _, workers := range []int{1, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				imgr, _ := NewImager(img)
+				imgr.Resize(1600, 0, MD_SCALE, WithWorkers(workers))
+			}
+		})
+	}
+	b.Run("imaging", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			imgr, _ := NewImager(img)
+			imgr.Resize(1600, 0, MD_SCALE)
+		}
+	})
+}
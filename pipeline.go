@@ -0,0 +1,379 @@
+package imager
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Op is a step of a Pipeline. Name selects the operation and the other
+// fields are its parameters, unused ones are left empty
+type Op struct {
+	Name string `json:"op"`
+
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	X      int `json:"x,omitempty"`
+	Y      int `json:"y,omitempty"`
+
+	// Mode is the resize mode: fit, crop, scale, stretch or smart. A resize
+	// to a single dimension scales
+	Mode string `json:"mode,omitempty"`
+
+	// Amount is the percent, sigma, degrees or strength of the operation
+	Amount float64 `json:"amount,omitempty"`
+
+	// Text, Color, Anchor and Size are the parameters of text operations,
+	// Color is a hex color such as #ff0000 and Anchor a position such as
+	// bottom-right
+	Text   string  `json:"text,omitempty"`
+	Color  string  `json:"color,omitempty"`
+	Anchor string  `json:"anchor,omitempty"`
+	Size   float64 `json:"size,omitempty"`
+
+	// FocalX and FocalY are the focal point, fractions of the width and
+	// height, see Imager.SetFocalPoint
+	FocalX float64 `json:"focal_x,omitempty"`
+	FocalY float64 `json:"focal_y,omitempty"`
+}
+
+// String describes the operation
+func (op Op) String() string {
+	data, _ := json.Marshal(op)
+	return string(data)
+}
+
+// opSpec runs an operation, check validates its parameters beforehand
+type opSpec struct {
+	run   func(i *Imager, op Op) *Imager
+	check func(op Op) error
+}
+
+// pipelineOps holds the operations known by Pipeline, by name
+var pipelineOps = map[string]opSpec{
+	"resize": {
+		run: func(i *Imager, op Op) *Imager {
+			return i.Resize(op.Width, op.Height, opResizeMode(op))
+		},
+		check: func(op Op) error {
+			if _, ok := resizeModes[op.Mode]; !ok {
+				return fmt.Errorf("unknown resize mode %q", op.Mode)
+			}
+			return resizeSizeError(op.Width, op.Height, opResizeMode(op))
+		},
+	},
+	"crop": {
+		run: func(i *Imager, op Op) *Imager {
+			return i.Crop(op.Width, op.Height, op.X, op.Y)
+		},
+		check: func(op Op) error {
+			if op.Width <= 0 || op.Height <= 0 {
+				return fmt.Errorf("invalid size %dx%d", op.Width, op.Height)
+			}
+			return nil
+		},
+	},
+	"rotate":      {run: func(i *Imager, op Op) *Imager { return i.Rotate(int(op.Amount)) }},
+	"flip-h":      {run: func(i *Imager, op Op) *Imager { return i.FlipH() }},
+	"flip-v":      {run: func(i *Imager, op Op) *Imager { return i.FlipV() }},
+	"auto-orient": {run: func(i *Imager, op Op) *Imager { return i.AutoOrient() }},
+	"blur":        {run: func(i *Imager, op Op) *Imager { return i.GaussianBlur(op.Amount) }},
+	"sharpen":     {run: func(i *Imager, op Op) *Imager { return i.Sharpen(op.Amount) }},
+	"brightness":  {run: func(i *Imager, op Op) *Imager { return i.AdjustBrightness(op.Amount) }},
+	"contrast":    {run: func(i *Imager, op Op) *Imager { return i.AdjustContrast(op.Amount) }},
+	"saturation":  {run: func(i *Imager, op Op) *Imager { return i.AdjustSaturation(op.Amount) }},
+	"hue":         {run: func(i *Imager, op Op) *Imager { return i.AdjustHue(op.Amount) }},
+	"gamma":       {run: func(i *Imager, op Op) *Imager { return i.AdjustGamma(op.Amount) }},
+	"grayscale":   {run: func(i *Imager, op Op) *Imager { return i.Grayscale() }},
+	"sepia":       {run: func(i *Imager, op Op) *Imager { return i.Sepia(op.Amount) }},
+	"invert":      {run: func(i *Imager, op Op) *Imager { return i.Invert() }},
+	"temperature": {run: func(i *Imager, op Op) *Imager { return i.Temperature(op.Amount) }},
+	"tint":        {run: func(i *Imager, op Op) *Imager { return i.Tint(op.Amount) }},
+	"auto-wb":     {run: func(i *Imager, op Op) *Imager { return i.AutoWhiteBalance() }},
+	"focal-point": {
+		run: func(i *Imager, op Op) *Imager {
+			return i.SetFocalPoint(op.FocalX, op.FocalY)
+		},
+		check: func(op Op) error {
+			if op.FocalX < 0 || op.FocalX > 1 || op.FocalY < 0 || op.FocalY > 1 {
+				return fmt.Errorf("focal point %v, %v out of [0, 1]", op.FocalX, op.FocalY)
+			}
+			return nil
+		},
+	},
+	"text": {
+		run: func(i *Imager, op Op) *Imager {
+			col, _ := parseHexColor(op.Color)
+			return i.Annotate(op.Text, TextOptions{Color: col, Size: op.Size, Anchor: anchors[op.Anchor], Margin: op.X})
+		},
+		check: func(op Op) error {
+			if op.Text == "" {
+				return fmt.Errorf("empty text")
+			}
+			if _, ok := anchors[op.Anchor]; !ok {
+				return fmt.Errorf("unknown anchor %q", op.Anchor)
+			}
+			_, err := parseHexColor(op.Color)
+			return err
+		},
+	},
+}
+
+// resizeModes holds the resize modes by name, empty is the default mode
+var resizeModes = map[string]ResizeMode{
+	"": MD_FIT, "fit": MD_FIT, "crop": MD_CROP, "scale": MD_SCALE, "stretch": MD_STRETCH, "smart": MD_SMART,
+	"seam": MD_SEAM,
+}
+
+// opResizeMode returns the resize mode of op. A single dimension is scaled
+// keeping the aspect ratio, whatever the mode
+func opResizeMode(op Op) ResizeMode {
+	if op.Width == 0 || op.Height == 0 {
+		return MD_SCALE
+	}
+
+	return resizeModes[op.Mode]
+}
+
+// anchors holds the anchors by name, empty is the default anchor
+var anchors = map[string]Anchor{
+	"": AnchorTopLeft, "top-left": AnchorTopLeft, "top": AnchorTop, "top-right": AnchorTopRight,
+	"left": AnchorLeft, "center": AnchorCenter, "right": AnchorRight,
+	"bottom-left": AnchorBottomLeft, "bottom": AnchorBottom, "bottom-right": AnchorBottomRight,
+}
+
+// Pipeline is a reusable list of operations. Operations are appended by the
+// builder methods, or by Add, and executed in order by Apply or Run.
+// A Pipeline serializes to JSON as the list of its operations
+type Pipeline struct {
+	ops []Op
+}
+
+// NewPipeline creates an empty Pipeline
+// i.e :
+// pipeline := imager.NewPipeline().Resize(800, 600, imager.MD_FIT).Sharpen(0.5)
+// imgr, err := pipeline.Apply(img)
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Add appends operations to the pipeline
+// i.e :
+// pipeline.Add(imager.Op{Name: "blur", Amount: 2})
+func (p *Pipeline) Add(ops ...Op) *Pipeline {
+	p.ops = append(p.ops, ops...)
+	return p
+}
+
+// Ops returns a copy of the operations of the pipeline
+func (p *Pipeline) Ops() []Op {
+	return append([]Op(nil), p.ops...)
+}
+
+// Resize appends a resize operation, see Imager.Resize
+func (p *Pipeline) Resize(width, height int, mode ResizeMode) *Pipeline {
+	for name, md := range resizeModes {
+		if md == mode && name != "" {
+			return p.Add(Op{Name: "resize", Width: width, Height: height, Mode: name})
+		}
+	}
+
+	return p.Add(Op{Name: "resize", Width: width, Height: height, Mode: fmt.Sprint(mode)})
+}
+
+// Crop appends a crop operation, see Imager.Crop
+func (p *Pipeline) Crop(width, height, x, y int) *Pipeline {
+	return p.Add(Op{Name: "crop", Width: width, Height: height, X: x, Y: y})
+}
+
+// Rotate appends a rotation, see Imager.Rotate
+func (p *Pipeline) Rotate(degrees int) *Pipeline {
+	return p.Add(Op{Name: "rotate", Amount: float64(degrees)})
+}
+
+// FlipH appends a horizontal flip
+func (p *Pipeline) FlipH() *Pipeline {
+	return p.Add(Op{Name: "flip-h"})
+}
+
+// FlipV appends a vertical flip
+func (p *Pipeline) FlipV() *Pipeline {
+	return p.Add(Op{Name: "flip-v"})
+}
+
+// AutoOrient appends an automatic orientation, see Imager.AutoOrient
+func (p *Pipeline) AutoOrient() *Pipeline {
+	return p.Add(Op{Name: "auto-orient"})
+}
+
+// GaussianBlur appends a blur, see Imager.GaussianBlur
+func (p *Pipeline) GaussianBlur(sigma float64) *Pipeline {
+	return p.Add(Op{Name: "blur", Amount: sigma})
+}
+
+// Sharpen appends a sharpening, see Imager.Sharpen
+func (p *Pipeline) Sharpen(sigma float64) *Pipeline {
+	return p.Add(Op{Name: "sharpen", Amount: sigma})
+}
+
+// AdjustBrightness appends a brightness adjustment, see Imager.AdjustBrightness
+func (p *Pipeline) AdjustBrightness(percent float64) *Pipeline {
+	return p.Add(Op{Name: "brightness", Amount: percent})
+}
+
+// AdjustContrast appends a contrast adjustment, see Imager.AdjustContrast
+func (p *Pipeline) AdjustContrast(percent float64) *Pipeline {
+	return p.Add(Op{Name: "contrast", Amount: percent})
+}
+
+// AdjustSaturation appends a saturation adjustment, see Imager.AdjustSaturation
+func (p *Pipeline) AdjustSaturation(percent float64) *Pipeline {
+	return p.Add(Op{Name: "saturation", Amount: percent})
+}
+
+// AdjustHue appends a hue rotation, see Imager.AdjustHue
+func (p *Pipeline) AdjustHue(degrees float64) *Pipeline {
+	return p.Add(Op{Name: "hue", Amount: degrees})
+}
+
+// AdjustGamma appends a gamma correction, see Imager.AdjustGamma
+func (p *Pipeline) AdjustGamma(gamma float64) *Pipeline {
+	return p.Add(Op{Name: "gamma", Amount: gamma})
+}
+
+// Grayscale appends a grayscale conversion
+func (p *Pipeline) Grayscale() *Pipeline {
+	return p.Add(Op{Name: "grayscale"})
+}
+
+// Sepia appends a sepia tone, see Imager.Sepia
+func (p *Pipeline) Sepia(strength float64) *Pipeline {
+	return p.Add(Op{Name: "sepia", Amount: strength})
+}
+
+// Invert appends a color inversion
+func (p *Pipeline) Invert() *Pipeline {
+	return p.Add(Op{Name: "invert"})
+}
+
+// Temperature appends a color temperature shift, see Imager.Temperature
+func (p *Pipeline) Temperature(kelvinShift float64) *Pipeline {
+	return p.Add(Op{Name: "temperature", Amount: kelvinShift})
+}
+
+// Tint appends a green-magenta shift, see Imager.Tint
+func (p *Pipeline) Tint(amount float64) *Pipeline {
+	return p.Add(Op{Name: "tint", Amount: amount})
+}
+
+// AutoWhiteBalance appends a white balance correction, see
+// Imager.AutoWhiteBalance
+func (p *Pipeline) AutoWhiteBalance() *Pipeline {
+	return p.Add(Op{Name: "auto-wb"})
+}
+
+// SetFocalPoint appends the marking of the subject, kept in frame by the
+// crops that follow, see Imager.SetFocalPoint
+func (p *Pipeline) SetFocalPoint(x, y float64) *Pipeline {
+	return p.Add(Op{Name: "focal-point", FocalX: x, FocalY: y})
+}
+
+// Watermark appends a text stamped at anchor, margin pixels away from the
+// edges. col is a hex color such as #ffffff, empty means black
+// i.e :
+// pipeline.Watermark("© ACME", "bottom-right", "#ffffff", 16, 10)
+func (p *Pipeline) Watermark(text, anchor, col string, size float64, margin int) *Pipeline {
+	return p.Add(Op{Name: "text", Text: text, Anchor: anchor, Color: col, Size: size, X: margin})
+}
+
+// Validate checks the operations of the pipeline without running them
+func (p *Pipeline) Validate() error {
+	for idx, op := range p.ops {
+		spec, ok := pipelineOps[op.Name]
+		if !ok {
+			return fmt.Errorf("%w: operation %d: unknown operation %q", ErrInvalidArgument, idx, op.Name)
+		}
+		if spec.check == nil {
+			continue
+		}
+		if err := spec.check(op); err != nil {
+			return fmt.Errorf("%w: operation %d (%s): %v", ErrInvalidArgument, idx, op.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Apply runs the pipeline on img and returns the resulting Imager
+// i.e :
+// imgr, err := pipeline.Apply(img)
+func (p *Pipeline) Apply(img image.Image) (*Imager, error) {
+	imgr, err := NewImager(img)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Run(imgr); err != nil {
+		return nil, err
+	}
+
+	return imgr, nil
+}
+
+// Run runs the pipeline on imgr, keeping its format and metadata. The
+// pipeline is validated first and nothing runs when it is invalid
+// i.e :
+// err := pipeline.Run(imgr)
+func (p *Pipeline) Run(imgr *Imager) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	for _, op := range p.ops {
+		pipelineOps[op.Name].run(imgr, op)
+		if err := imgr.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the pipeline as the list of its operations
+func (p *Pipeline) MarshalJSON() ([]byte, error) {
+	if p.ops == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(p.ops)
+}
+
+// UnmarshalJSON decodes a list of operations
+func (p *Pipeline) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &p.ops)
+}
+
+// parseHexColor parses a #rgb, #rrggbb or #rrggbbaa color, empty is black
+func parseHexColor(s string) (color.Color, error) {
+	if s == "" {
+		return color.Black, nil
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
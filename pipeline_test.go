@@ -0,0 +1,97 @@
+package imager
+
+import (
+	"encoding/json"
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	pipeline := NewPipeline().
+		Resize(50, 50, MD_STRETCH).
+		FlipH().
+		AdjustBrightness(10).
+		Watermark("Hi", "bottom-right", "#00ff00", 0, 2)
+
+	if ops := pipeline.Ops(); len(ops) != 4 || ops[0].Mode != "stretch" {
+		t.Fatalf("unexpected operations: %v", ops)
+	}
+
+	// The same pipeline runs on several images
+	for k := 0; k < 2; k++ {
+		imgr, err := pipeline.Apply(createTestImage())
+		if err != nil {
+			t.Fatalf("Apply returned an error: %v", err)
+		}
+		if imgr.Image.Bounds().Dx() != 50 {
+			t.Fatalf("Apply did not resize the image: got %v", imgr.Image.Bounds())
+		}
+	}
+}
+
+func TestPipelineJSON(t *testing.T) {
+	pipeline := NewPipeline().Resize(20, 0, MD_SCALE).Grayscale()
+
+	data, err := json.Marshal(pipeline)
+	if err != nil {
+		t.Fatalf("Marshal returned an error: %v", err)
+	}
+	if string(data) != `[{"op":"resize","width":20,"mode":"scale"},{"op":"grayscale"}]` {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+
+	decoded := NewPipeline()
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal returned an error: %v", err)
+	}
+
+	imgr, err := decoded.Apply(createTestImage())
+	if err != nil {
+		t.Fatalf("Apply returned an error: %v", err)
+	}
+	if c := pixel(imgr.Image, 5, 5); imgr.Image.Bounds().Dx() != 20 || c.R != c.G {
+		t.Fatalf("the decoded pipeline did not run: %v %v", imgr.Image.Bounds(), c)
+	}
+}
+
+func TestPipelineValidate(t *testing.T) {
+	tests := []*Pipeline{
+		NewPipeline().Add(Op{Name: "explode"}),
+		NewPipeline().Add(Op{Name: "resize", Mode: "fill", Width: 10}),
+		NewPipeline().Resize(0, 0, MD_FIT),
+		NewPipeline().Watermark("Hi", "nowhere", "", 0, 0),
+		NewPipeline().Watermark("Hi", "top", "#zzz", 0, 0),
+	}
+
+	for _, pipeline := range tests {
+		if err := pipeline.Validate(); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument for %v, got %v", pipeline.Ops(), err)
+		}
+	}
+
+	// Nothing runs when the pipeline is invalid
+	imgr, _ := NewImager(createTestImage())
+	pipeline := NewPipeline().Invert().Add(Op{Name: "explode"})
+	if err := pipeline.Run(imgr); err == nil || pixel(imgr.Image, 0, 0) != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("an invalid pipeline ran: %v", err)
+	}
+
+	// Errors of the operations are returned
+	if _, err := NewPipeline().AdjustGamma(0).Apply(createTestImage()); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestPipelineValidateResize(t *testing.T) {
+	// Validate accepts the resizes that run
+	for _, mode := range []ResizeMode{MD_FIT, MD_CROP, MD_SCALE, MD_STRETCH, MD_SMART} {
+		for _, size := range [][2]int{{40, 20}, {40, 0}, {0, 20}, {0, 0}, {-40, 20}, {40, -20}} {
+			pipeline := NewPipeline().Resize(size[0], size[1], mode)
+			_, runErr := pipeline.Apply(createTestImage())
+			if err := pipeline.Validate(); (err == nil) != (runErr == nil) {
+				t.Errorf("mode %v, size %v: Validate returned %v, the run %v", mode, size, err, runErr)
+			}
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package imager
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// placeholderSampleSize is the size the image is downsampled to before
+// computing its placeholders, they only hold a few frequencies anyway
+const placeholderSampleSize = 100
+
+// base83 is the alphabet of BlurHash strings
+const base83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// BlurHash returns the BlurHash of the image, a short string frontends
+// decode into a blurred placeholder. xComponents and yComponents, from 1 to
+// 9, are the horizontal and vertical details kept, 4 and 3 are common
+// i.e :
+// hash, err := imgr.BlurHash(4, 3)
+func (i *Imager) BlurHash(xComponents, yComponents int) (string, error) {
+	if xComponents < 1 || xComponents > 9 || yComponents < 1 || yComponents > 9 {
+		return "", fmt.Errorf("%w: %dx%d components, must be within 1 and 9", ErrInvalidArgument, xComponents, yComponents)
+	}
+
+	sample := imaging.Fit(i.Image, placeholderSampleSize, placeholderSampleSize, imaging.Box)
+	w, h := sample.Rect.Dx(), sample.Rect.Dy()
+	if w == 0 || h == 0 {
+		return "", fmt.Errorf("%w: empty image", ErrInvalidArgument)
+	}
+
+	linear := make([][3]float64, w*h)
+	for k := range linear {
+		for ch := 0; ch < 3; ch++ {
+			linear[k][ch] = srgbToLinear(sample.Pix[k*4+ch])
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for cy := 0; cy < yComponents; cy++ {
+		for cx := 0; cx < xComponents; cx++ {
+			var factor [3]float64
+			for y := 0; y < h; y++ {
+				fy := math.Cos(math.Pi * float64(cy) * float64(y) / float64(h))
+				for x := 0; x < w; x++ {
+					basis := math.Cos(math.Pi*float64(cx)*float64(x)/float64(w)) * fy
+					for ch := 0; ch < 3; ch++ {
+						factor[ch] += basis * linear[y*w+x][ch]
+					}
+				}
+			}
+
+			scale := 2 / float64(w*h)
+			if cx == 0 && cy == 0 {
+				scale = 1 / float64(w*h)
+			}
+			for ch := range factor {
+				factor[ch] *= scale
+			}
+			factors = append(factors, factor)
+		}
+	}
+
+	hash := encodeBase83(xComponents-1+(yComponents-1)*9, 1)
+
+	maxValue := 1.0
+	if len(factors) > 1 {
+		actualMax := 0.0
+		for _, factor := range factors[1:] {
+			for _, v := range factor {
+				actualMax = math.Max(actualMax, math.Abs(v))
+			}
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		hash += encodeBase83(quantisedMax, 1)
+	} else {
+		hash += encodeBase83(0, 1)
+	}
+
+	dc := factors[0]
+	hash += encodeBase83(linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4)
+
+	for _, factor := range factors[1:] {
+		value := 0
+		for _, v := range factor {
+			sign := 1.0
+			if v < 0 {
+				sign = -1
+			}
+			quantised := int(math.Max(0, math.Min(18, math.Floor(sign*math.Sqrt(math.Abs(v/maxValue))*9+9.5))))
+			value = value*19 + quantised
+		}
+		hash += encodeBase83(value, 2)
+	}
+
+	return hash, nil
+}
+
+// ThumbHash returns the base64 encoded ThumbHash of the image, a placeholder
+// format like BlurHash that also keeps the aspect ratio and the transparency
+// i.e :
+// hash := imgr.ThumbHash()
+func (i *Imager) ThumbHash() string {
+	sample := imaging.Fit(i.Image, placeholderSampleSize, placeholderSampleSize, imaging.Box)
+	return base64.StdEncoding.EncodeToString(thumbHash(sample))
+}
+
+// thumbHash encodes img, at most 100x100, following the reference
+// implementation of ThumbHash
+func thumbHash(img *image.NRGBA) []byte {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if w == 0 || h == 0 {
+		return nil
+	}
+	// round is Math.round of the reference implementation
+	round := func(v float64) int { return int(math.Floor(v + 0.5)) }
+
+	// Average color, used as the background of the transparent pixels
+	var avgR, avgG, avgB, avgA float64
+	for k := 0; k < w*h; k++ {
+		alpha := float64(img.Pix[k*4+3]) / 255
+		avgR += alpha / 255 * float64(img.Pix[k*4])
+		avgG += alpha / 255 * float64(img.Pix[k*4+1])
+		avgB += alpha / 255 * float64(img.Pix[k*4+2])
+		avgA += alpha
+	}
+	if avgA > 0 {
+		avgR, avgG, avgB = avgR/avgA, avgG/avgA, avgB/avgA
+	}
+
+	hasAlpha := avgA < float64(w*h)
+	limit := 7.0
+	if hasAlpha {
+		// Fewer luminance bits leave room for the alpha channel
+		limit = 5
+	}
+	longest := float64(max(w, h))
+	lx := max(1, round(limit*float64(w)/longest))
+	ly := max(1, round(limit*float64(h)/longest))
+
+	// Convert to luminance, yellow-blue, red-green and alpha channels
+	l, p, q, a := make([]float64, w*h), make([]float64, w*h), make([]float64, w*h), make([]float64, w*h)
+	for k := 0; k < w*h; k++ {
+		alpha := float64(img.Pix[k*4+3]) / 255
+		r := avgR*(1-alpha) + alpha/255*float64(img.Pix[k*4])
+		g := avgG*(1-alpha) + alpha/255*float64(img.Pix[k*4+1])
+		b := avgB*(1-alpha) + alpha/255*float64(img.Pix[k*4+2])
+		l[k] = (r + g + b) / 3
+		p[k] = (r+g)/2 - b
+		q[k] = r - g
+		a[k] = alpha
+	}
+
+	// encode returns the DC term and the normalized AC terms of a channel
+	encode := func(channel []float64, nx, ny int) (float64, []float64, float64) {
+		var dc, scale float64
+		var ac []float64
+		fx := make([]float64, w)
+		for cy := 0; cy < ny; cy++ {
+			for cx := 0; cx*ny < nx*(ny-cy); cx++ {
+				for x := 0; x < w; x++ {
+					fx[x] = math.Cos(math.Pi / float64(w) * float64(cx) * (float64(x) + 0.5))
+				}
+				f := 0.0
+				for y := 0; y < h; y++ {
+					fy := math.Cos(math.Pi / float64(h) * float64(cy) * (float64(y) + 0.5))
+					for x := 0; x < w; x++ {
+						f += channel[x+y*w] * fx[x] * fy
+					}
+				}
+				f /= float64(w * h)
+
+				if cx == 0 && cy == 0 {
+					dc = f
+					continue
+				}
+				ac = append(ac, f)
+				scale = math.Max(scale, math.Abs(f))
+			}
+		}
+		if scale > 0 {
+			for k := range ac {
+				ac[k] = 0.5 + 0.5/scale*ac[k]
+			}
+		}
+
+		return dc, ac, scale
+	}
+
+	lDC, lAC, lScale := encode(l, max(3, lx), max(3, ly))
+	pDC, pAC, pScale := encode(p, 3, 3)
+	qDC, qAC, qScale := encode(q, 3, 3)
+
+	isLandscape, alphaFlag, side := 0, 0, lx
+	if w > h {
+		isLandscape, side = 1, ly
+	}
+	if hasAlpha {
+		alphaFlag = 1
+	}
+
+	header24 := round(63*lDC) | round(31.5+31.5*pDC)<<6 | round(31.5+31.5*qDC)<<12 | round(31*lScale)<<18 | alphaFlag<<23
+	header16 := side | round(63*pScale)<<3 | round(63*qScale)<<9 | isLandscape<<15
+	hash := []byte{byte(header24), byte(header24 >> 8), byte(header24 >> 16), byte(header16), byte(header16 >> 8)}
+
+	channels := [][]float64{lAC, pAC, qAC}
+	if hasAlpha {
+		aDC, aAC, aScale := encode(a, 5, 5)
+		hash = append(hash, byte(round(15*aDC)|round(15*aScale)<<4))
+		channels = append(channels, aAC)
+	}
+
+	// The AC terms are packed two per byte
+	start, index := len(hash), 0
+	for _, ac := range channels {
+		for _, f := range ac {
+			if start+index>>1 == len(hash) {
+				hash = append(hash, 0)
+			}
+			hash[start+index>>1] |= byte(round(15*f) << ((index & 1) << 2))
+			index++
+		}
+	}
+
+	return hash
+}
+
+// encodeBase83 encodes value into length base83 digits
+func encodeBase83(value, length int) string {
+	digits := make([]byte, length)
+	for k := length - 1; k >= 0; k-- {
+		digits[k] = base83[value%83]
+		value /= 83
+	}
+
+	return string(digits)
+}
+
+// srgbToLinear converts an 8-bit sRGB value to a linear intensity in [0, 1]
+func srgbToLinear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts a linear intensity to an 8-bit sRGB value
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
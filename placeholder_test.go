@@ -0,0 +1,53 @@
+package imager
+
+import (
+	"encoding/base64"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBlurHash(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+
+	// The size flag then the average color follow the maximum AC value
+	hash, err := imgr.BlurHash(4, 3)
+	if err != nil {
+		t.Fatalf("BlurHash returned an error: %v", err)
+	}
+	if len(hash) != 28 || hash[0] != 'L' || hash[2:6] != encodeBase83(0xFF0000, 4) {
+		t.Fatalf("BlurHash returned %q", hash)
+	}
+
+	imgr, _ = NewImager(createPatternImage(120, 80))
+	if hash, _ = imgr.BlurHash(9, 9); len(hash) != 6+2*80 {
+		t.Fatalf("BlurHash returned %d characters, want %d", len(hash), 6+2*80)
+	}
+
+	if _, err := imgr.BlurHash(0, 3); err == nil {
+		t.Fatalf("BlurHash did not return an error for 0 components")
+	}
+}
+
+func TestThumbHash(t *testing.T) {
+	// Landscape opaque images get 7x4 luminance and 3x3 chrominance terms
+	imgr, _ := NewImager(createPatternImage(200, 100))
+	hash, err := base64.StdEncoding.DecodeString(imgr.ThumbHash())
+	if err != nil {
+		t.Fatalf("ThumbHash is not valid base64: %v", err)
+	}
+	if len(hash) != 19 {
+		t.Fatalf("ThumbHash returned %d bytes, want 19", len(hash))
+	}
+	if hash[2]&0x80 != 0 || hash[4]&0x80 == 0 {
+		t.Fatalf("ThumbHash has wrong alpha or landscape flags: %v", hash)
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 50, 50))
+	transparent.Set(10, 10, color.White)
+	imgr, _ = NewImager(transparent)
+	hash, _ = base64.StdEncoding.DecodeString(imgr.ThumbHash())
+	if hash[2]&0x80 == 0 {
+		t.Fatalf("ThumbHash has no alpha flag for a transparent image")
+	}
+}
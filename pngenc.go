@@ -0,0 +1,249 @@
+package imager
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/disintegration/imaging"
+)
+
+// adam7Passes are the x, y offsets and steps of the Adam7 interlace passes
+var adam7Passes = [7][4]int{
+	{0, 0, 8, 8},
+	{4, 0, 8, 8},
+	{0, 4, 4, 8},
+	{2, 0, 4, 4},
+	{0, 2, 2, 4},
+	{1, 0, 2, 2},
+	{0, 1, 1, 2},
+}
+
+// encodePNG writes img to w as PNG according to opts
+func encodePNG(w io.Writer, img image.Image, opts EncodeOptions) error {
+	// A palette holds 8-bit colors only
+	if opts.PNGAutoPalette && !is16Bit(img) {
+		if paletted, ok := exactPalette(img); ok {
+			img = paletted
+		}
+	}
+
+	if !opts.PNGInterlace {
+		encoder := png.Encoder{CompressionLevel: opts.PNGCompression}
+		return encoder.Encode(w, img)
+	}
+
+	return encodePNGInterlaced(w, img, opts.PNGCompression)
+}
+
+// exactPalette returns img as a paletted image when it has at most 256
+// colors, without losing any of them
+func exactPalette(img image.Image) (*image.Paletted, bool) {
+	if paletted, ok := img.(*image.Paletted); ok {
+		return paletted, true
+	}
+
+	src := imaging.Clone(img)
+	index := map[color.NRGBA]uint8{}
+	var palette color.Palette
+	dst := image.NewPaletted(src.Rect, nil)
+	for k := 0; k < len(dst.Pix); k++ {
+		p := src.Pix[k*4 : k*4+4]
+		c := color.NRGBA{p[0], p[1], p[2], p[3]}
+		if c.A == 0 {
+			// Every transparent pixel looks the same
+			c = color.NRGBA{}
+		}
+
+		idx, ok := index[c]
+		if !ok {
+			if len(palette) == 256 {
+				return nil, false
+			}
+			idx = uint8(len(palette))
+			index[c] = idx
+			palette = append(palette, c)
+		}
+		dst.Pix[k] = idx
+	}
+	dst.Palette = palette
+
+	return dst, true
+}
+
+// encodePNGInterlaced writes img to w as an Adam7 interlaced PNG, which
+// image/png does not support. Paletted images are written with their
+// palette, the 16-bit images as 16-bit RGB or RGBA and the others as 8-bit
+// RGB or RGBA
+func encodePNGInterlaced(w io.Writer, img image.Image, level png.CompressionLevel) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Pixels as bytes, bpp bytes per pixel
+	var pix []byte
+	var colorType byte
+	var chunks [][]byte
+	bpp, depth := 1, byte(8)
+	if paletted, ok := img.(*image.Paletted); ok && len(paletted.Palette) <= 256 {
+		colorType = 3
+		pix = make([]byte, 0, width*height)
+		for y := 0; y < height; y++ {
+			start := (y+bounds.Min.Y-paletted.Rect.Min.Y)*paletted.Stride + bounds.Min.X - paletted.Rect.Min.X
+			pix = append(pix, paletted.Pix[start:start+width]...)
+		}
+
+		plte, trns := make([]byte, 0, 3*len(paletted.Palette)), make([]byte, 0, len(paletted.Palette))
+		for _, c := range paletted.Palette {
+			n := color.NRGBAModel.Convert(c).(color.NRGBA)
+			plte = append(plte, n.R, n.G, n.B)
+			trns = append(trns, n.A)
+		}
+		chunks = append(chunks, pngChunk("PLTE", plte), pngChunk("tRNS", trns))
+	} else if is16Bit(img) {
+		// Samples are stored big endian, like the Pix of image.NRGBA64
+		nrgba := image.NewNRGBA64(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Rect, img, bounds.Min, draw.Src)
+		colorType, bpp, depth, pix = 6, 8, 16, nrgba.Pix
+		if nrgba.Opaque() {
+			colorType, bpp = 2, 6
+			pix = make([]byte, 0, width*height*6)
+			for k := 0; k < len(nrgba.Pix); k += 8 {
+				pix = append(pix, nrgba.Pix[k:k+6]...)
+			}
+		}
+	} else {
+		nrgba := imaging.Clone(img)
+		colorType, bpp, pix = 6, 4, nrgba.Pix
+		if isOpaque(nrgba) {
+			colorType, bpp = 2, 3
+			pix = make([]byte, 0, width*height*3)
+			for k := 0; k < len(nrgba.Pix); k += 4 {
+				pix = append(pix, nrgba.Pix[k:k+3]...)
+			}
+		}
+	}
+
+	ihdr := binary.BigEndian.AppendUint32(nil, uint32(width))
+	ihdr = binary.BigEndian.AppendUint32(ihdr, uint32(height))
+	ihdr = append(ihdr, depth, colorType, 0, 0, 1)
+
+	idat := bytes.NewBuffer(nil)
+	zw, err := zlib.NewWriterLevel(idat, zlibLevel(level))
+	if err != nil {
+		return err
+	}
+	for _, pass := range adam7Passes {
+		x0, y0, dx, dy := pass[0], pass[1], pass[2], pass[3]
+		passW := (width - x0 + dx - 1) / dx
+		if passW <= 0 {
+			continue
+		}
+
+		prev := make([]byte, passW*bpp)
+		row := make([]byte, passW*bpp)
+		for y := y0; y < height; y += dy {
+			for x, k := x0, 0; x < width; x, k = x+dx, k+bpp {
+				copy(row[k:k+bpp], pix[(y*width+x)*bpp:])
+			}
+			if _, err := zw.Write(filterRow(row, prev, bpp)); err != nil {
+				return err
+			}
+			prev, row = row, prev
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	out := []byte(pngSignature)
+	out = append(out, pngChunk("IHDR", ihdr)...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	out = append(out, pngChunk("IDAT", idat.Bytes())...)
+	out = append(out, pngChunk("IEND", nil)...)
+
+	_, err = w.Write(out)
+	return err
+}
+
+// filterRow returns row prefixed with its filter type, picking the filter
+// with the smallest sum of absolute residuals like image/png
+func filterRow(row, prev []byte, bpp int) []byte {
+	best, bestSum := []byte(nil), -1
+	filtered := make([]byte, len(row)+1)
+	for filter := byte(0); filter < 5; filter++ {
+		filtered[0] = filter
+		sum := 0
+		for k, v := range row {
+			var a, b, c byte
+			if k >= bpp {
+				a, c = row[k-bpp], prev[k-bpp]
+			}
+			b = prev[k]
+
+			var predicted byte
+			switch filter {
+			case 1:
+				predicted = a
+			case 2:
+				predicted = b
+			case 3:
+				predicted = byte((int(a) + int(b)) / 2)
+			case 4:
+				predicted = paeth(a, b, c)
+			}
+			filtered[k+1] = v - predicted
+			sum += absInt(int(int8(filtered[k+1])))
+		}
+
+		if bestSum < 0 || sum < bestSum {
+			best, bestSum = append(best[:0], filtered...), sum
+		}
+	}
+
+	return best
+}
+
+// paeth returns the Paeth predictor of a, b and c
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := absInt(p-int(a)), absInt(p-int(b)), absInt(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+
+	return c
+}
+
+// zlibLevel returns the zlib level matching a PNG compression level
+func zlibLevel(level png.CompressionLevel) int {
+	switch level {
+	case png.NoCompression:
+		return zlib.NoCompression
+	case png.BestSpeed:
+		return zlib.BestSpeed
+	case png.BestCompression:
+		return zlib.BestCompression
+	}
+
+	return zlib.DefaultCompression
+}
+
+// pngChunk returns a PNG chunk with its length and checksum
+func pngChunk(typ string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
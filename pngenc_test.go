@@ -0,0 +1,92 @@
+package imager
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestEncodePNGInterlace(t *testing.T) {
+	translucent := image.NewNRGBA(image.Rect(0, 0, 13, 9))
+	for k := range translucent.Pix {
+		translucent.Pix[k] = uint8(k * 7)
+	}
+	paletted := image.NewPaletted(image.Rect(0, 0, 5, 3), color.Palette{color.Black, color.NRGBA{255, 0, 0, 128}})
+	paletted.Pix[4] = 1
+
+	for name, img := range map[string]image.Image{
+		"opaque":      createPatternImage(37, 21),
+		"translucent": translucent,
+		"paletted":    paletted,
+		"single":      createColorImage(color.White).(*image.NRGBA).SubImage(image.Rect(2, 2, 3, 3)),
+	} {
+		imgr, _ := NewImager(img)
+		buf := new(bytes.Buffer)
+		if err := imgr.Encode(buf, IMPNG, EncodeOptions{PNGInterlace: true}); err != nil {
+			t.Fatalf("Encode %s returned an error: %v", name, err)
+		}
+
+		// The interlace method is the last byte of IHDR
+		if method := buf.Bytes()[28]; method != 1 {
+			t.Fatalf("Encode %s wrote interlace method %d, want 1", name, method)
+		}
+
+		decoded, err := png.Decode(buf)
+		if err != nil {
+			t.Fatalf("failed to decode the %s PNG: %v", name, err)
+		}
+		bounds := img.Bounds()
+		if decoded.Bounds().Size() != bounds.Size() {
+			t.Fatalf("decoded %s PNG has bounds %v", name, decoded.Bounds())
+		}
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				if a, b := pixel(img, bounds.Min.X+x, bounds.Min.Y+y), pixel(decoded, x, y); a != b {
+					t.Fatalf("decoded %s PNG has %v at %d,%d, want %v", name, b, x, y, a)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodePNGAutoPalette(t *testing.T) {
+	imgr, _ := NewImager(createPatternImage(64, 64))
+	imgr.Quantize(16).ToRGBA()
+	full, palette := new(bytes.Buffer), new(bytes.Buffer)
+	imgr.Encode(full, IMPNG)
+	if err := imgr.Encode(palette, IMPNG, EncodeOptions{PNGAutoPalette: true}); err != nil {
+		t.Fatalf("Encode returned an error: %v", err)
+	}
+
+	decoded, err := png.Decode(palette)
+	if err != nil {
+		t.Fatalf("failed to decode the PNG: %v", err)
+	}
+	if _, ok := decoded.(*image.Paletted); !ok {
+		t.Fatalf("Encode did not write a paletted PNG: %T", decoded)
+	}
+	if palette.Len() >= full.Len() {
+		t.Fatalf("paletted PNG is %d bytes, not smaller than %d", palette.Len(), full.Len())
+	}
+	if c := pixel(decoded, 0, 0); c != pixel(imgr.Image, 0, 0) {
+		t.Fatalf("paletted PNG changed the colors: %v", c)
+	}
+
+	// Too many colors for a palette
+	gradient := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			gradient.Set(x, y, color.NRGBA{uint8(x * 8), uint8(y * 8), 0, 255})
+		}
+	}
+	imgr, _ = NewImager(gradient)
+	buf := new(bytes.Buffer)
+	imgr.Encode(buf, IMPNG, EncodeOptions{PNGAutoPalette: true})
+	if decoded, _ := png.Decode(buf); decoded == nil {
+		t.Fatalf("failed to decode the PNG")
+	} else if _, ok := decoded.(*image.Paletted); ok {
+		t.Fatalf("Encode wrote a paletted PNG for more than 256 colors")
+	}
+}
@@ -0,0 +1,70 @@
+package imager
+
+import (
+	"bytes"
+	"image"
+	"sync"
+)
+
+// maxPooledSize is the largest buffer kept for reuse, larger ones are left
+// to the garbage collector so a single huge image doesn't pin its memory
+const maxPooledSize = 64 << 20
+
+// encodeBuffers holds the *bytes.Buffer used to encode images
+var encodeBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := encodeBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool, it must not be used afterwards
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledSize {
+		encodeBuffers.Put(buf)
+	}
+}
+
+// scratchPix holds the pixel slices of the temporary images built while
+// encoding
+var scratchPix sync.Pool
+
+// getScratch returns an *image.NRGBA of rect from the pool, its pixels are
+// not cleared
+func getScratch(rect image.Rectangle) *image.NRGBA {
+	size := rect.Dx() * rect.Dy() * 4
+	if pix, ok := scratchPix.Get().(*[]byte); ok {
+		if cap(*pix) >= size {
+			return &image.NRGBA{Pix: (*pix)[:size], Stride: rect.Dx() * 4, Rect: rect}
+		}
+		scratchPix.Put(pix)
+	}
+
+	return image.NewNRGBA(rect)
+}
+
+// putScratch returns the pixels of img to the pool, img must not be used
+// afterwards
+func putScratch(img *image.NRGBA) {
+	if cap(img.Pix) <= maxPooledSize {
+		pix := img.Pix[:0]
+		scratchPix.Put(&pix)
+	}
+}
+
+// AppendBytes appends the image encoded as ImageType to dst and returns the
+// extended slice, like Bytes but reusing the memory of dst. Encoding many
+// images into the same buffer spares the allocations
+// i.e :
+// buf, err = imgr.AppendBytes(buf[:0])
+// buf, err = imgr.AppendBytes(buf[:0], imager.EncodeOptions{JPEGQuality: 80})
+func (i *Imager) AppendBytes(dst []byte, opts ...EncodeOptions) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	err := i.encode(buf, i.ImageType, mergeEncodeOptions(opts))
+
+	return buf.Bytes(), err
+}
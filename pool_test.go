@@ -0,0 +1,83 @@
+package imager
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestAppendBytes(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+	imgr.ImageType = IMPNG
+
+	want, err := imgr.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+
+	prefix := []byte("head")
+	got, err := imgr.AppendBytes(prefix)
+	if err != nil {
+		t.Fatalf("AppendBytes returned an error: %v", err)
+	}
+	if !bytes.HasPrefix(got, prefix) || !bytes.Equal(got[len(prefix):], want) {
+		t.Fatalf("AppendBytes did not append the encoded image")
+	}
+
+	buf := make([]byte, 0, 64<<10)
+	got, _ = imgr.AppendBytes(buf)
+	if &got[0] != &buf[:1][0] {
+		t.Fatalf("AppendBytes did not reuse the buffer")
+	}
+}
+
+func TestBytesDoesNotShareBuffers(t *testing.T) {
+	red, _ := NewImager(createTestImage())
+	red.ImageType = IMJPEG
+	transparent, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 30, 30)))
+	transparent.ImageType = IMJPEG
+
+	first, _ := red.Bytes()
+	kept := bytes.Clone(first)
+	for n := 0; n < 5; n++ {
+		transparent.Bytes()
+		red.Bytes()
+	}
+
+	if !bytes.Equal(first, kept) {
+		t.Fatalf("Bytes returned memory reused by later calls")
+	}
+}
+
+func TestScratchPool(t *testing.T) {
+	img := getScratch(image.Rect(0, 0, 20, 10))
+	putScratch(img)
+
+	reused := getScratch(image.Rect(0, 0, 5, 4))
+	if reused.Stride != 20 || len(reused.Pix) != 80 || reused.Bounds() != image.Rect(0, 0, 5, 4) {
+		t.Fatalf("getScratch returned %v with a stride of %d and %d bytes", reused.Bounds(), reused.Stride, len(reused.Pix))
+	}
+
+	if larger := getScratch(image.Rect(0, 0, 100, 100)); len(larger.Pix) != 40000 {
+		t.Fatalf("getScratch returned %d bytes for a larger image", len(larger.Pix))
+	}
+}
+
+func BenchmarkBytes(b *testing.B) {
+	imgr, _ := NewImager(createGradientImage())
+	imgr.ImageType = IMJPEG
+
+	b.Run("Bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			imgr.Bytes()
+		}
+	})
+	b.Run("AppendBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []byte
+		for n := 0; n < b.N; n++ {
+			buf, _ = imgr.AppendBytes(buf[:0])
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package imager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// presets holds the pipelines registered by RegisterPreset, by name
+var (
+	presetsMu sync.RWMutex
+	presets   = map[string]*Pipeline{}
+)
+
+// RegisterPreset registers a copy of pipeline under name, so the same
+// transformation is applied by name from the code, the command line and
+// the HTTP handler. Registering a nil pipeline removes the preset
+// i.e :
+// imager.RegisterPreset("avatar", imager.NewPipeline().Resize(128, 128, imager.MD_SMART).Sharpen(0.5))
+// imgr.ApplyPreset("avatar")
+func RegisterPreset(name string, pipeline *Pipeline) {
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+
+	if pipeline == nil {
+		delete(presets, name)
+		return
+	}
+	presets[name] = NewPipeline().Add(pipeline.ops...)
+}
+
+// Preset returns a copy of the pipeline registered under name
+// i.e :
+// pipeline, ok := imager.Preset("avatar")
+func Preset(name string) (*Pipeline, bool) {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+
+	pipeline, ok := presets[name]
+	if !ok {
+		return nil, false
+	}
+
+	return NewPipeline().Add(pipeline.ops...), true
+}
+
+// LoadPresets registers the presets of a JSON object mapping the names to
+// the operations of their pipelines, see Pipeline. Nothing is registered
+// when a pipeline is invalid
+// i.e :
+// err := imager.LoadPresets([]byte(`{"avatar": [{"op": "resize", "width": 128, "height": 128, "mode": "smart"}]}`))
+func LoadPresets(data []byte) error {
+	var loaded map[string]*Pipeline
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("%w: presets: %v", ErrInvalidArgument, err)
+	}
+	for name, pipeline := range loaded {
+		if pipeline == nil {
+			return fmt.Errorf("%w: preset %q: no operations", ErrInvalidArgument, name)
+		}
+		if err := pipeline.Validate(); err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+	}
+
+	for name, pipeline := range loaded {
+		RegisterPreset(name, pipeline)
+	}
+
+	return nil
+}
+
+// ApplyPreset runs the preset registered under name on the image, an
+// unknown preset records ErrInvalidArgument
+// i.e :
+// data, err := imgr.ApplyPreset("thumbnail").Bytes()
+func (i *Imager) ApplyPreset(name string) *Imager {
+	pipeline, ok := Preset(name)
+	if !ok {
+		i.setErr(fmt.Errorf("%w: unknown preset %q", ErrInvalidArgument, name))
+		return i
+	}
+	if err := pipeline.Run(i); err != nil {
+		i.setErr(err)
+	}
+
+	return i
+}
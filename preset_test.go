@@ -0,0 +1,45 @@
+package imager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	pipeline := NewPipeline().Resize(40, 40, MD_STRETCH)
+	RegisterPreset("test-avatar", pipeline)
+	defer RegisterPreset("test-avatar", nil)
+
+	// The registered pipeline is a copy
+	pipeline.Rotate(90)
+
+	imgr, _ := NewImager(createTestImage())
+	if err := imgr.ApplyPreset("test-avatar").Err(); err != nil {
+		t.Fatalf("ApplyPreset returned an error: %v", err)
+	}
+	if imgr.Image.Bounds().Dx() != 40 || imgr.Image.Bounds().Dy() != 40 {
+		t.Fatalf("ApplyPreset did not resize the image: %v", imgr.Image.Bounds())
+	}
+
+	imgr, _ = NewImager(createTestImage())
+	if err := imgr.ApplyPreset("missing").Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("ApplyPreset returned %v for an unknown preset, want ErrInvalidArgument", err)
+	}
+
+	err := LoadPresets([]byte(`{"test-thumb": [{"op": "resize", "width": 10, "height": 10, "mode": "crop"}]}`))
+	if err != nil {
+		t.Fatalf("LoadPresets returned an error: %v", err)
+	}
+	defer RegisterPreset("test-thumb", nil)
+	if thumb, ok := Preset("test-thumb"); !ok || len(thumb.Ops()) != 1 {
+		t.Fatalf("LoadPresets did not register the preset")
+	}
+
+	err = LoadPresets([]byte(`{"test-valid": [], "test-invalid": [{"op": "explode"}]}`))
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("LoadPresets returned %v for an invalid preset, want ErrInvalidArgument", err)
+	}
+	if _, ok := Preset("test-valid"); ok {
+		t.Fatalf("LoadPresets registered presets along an invalid one")
+	}
+}
@@ -0,0 +1,154 @@
+package imager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// DetectFormat returns the format of the encoded image in data, one of the
+// IM* constants, without decoding the pixels. RAW files are described by
+// their largest JPEG preview, the image loaded from them
+// i.e :
+// format, err := imager.DetectFormat(data)
+func DetectFormat(data []byte) (string, error) {
+	_, format, err := decodeConfig(data)
+	return format, err
+}
+
+// decodeConfig is image.DecodeConfig agreeing with the loading functions,
+// RAW files are described by their largest JPEG preview
+func decodeConfig(data []byte) (image.Config, string, error) {
+	if isRAW(data) {
+		if config, ok := rawConfig(data); ok {
+			return config, IMJPEG, nil
+		}
+		// Such as the TIFF images of Nikon scanners, see loadBytes
+		config, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || config.Width == 0 || config.Height == 0 {
+			return image.Config{}, "", fmt.Errorf("%w: RAW file without a JPEG preview", ErrUnknownFormat)
+		}
+		return config, format, nil
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image.Config{}, "", fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+
+	return config, format, nil
+}
+
+// PeekDimensions returns the dimensions and format of the encoded image in
+// data without decoding the pixels, see DetectFormat
+// i.e :
+// width, height, format, err := imager.PeekDimensions(data)
+func PeekDimensions(data []byte) (width, height int, format string, err error) {
+	config, format, err := decodeConfig(data)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	return config.Width, config.Height, format, nil
+}
+
+// ProbeInfo describes an encoded image, as returned by Probe
+type ProbeInfo struct {
+	Width  int
+	Height int
+
+	// Format is one of the IM* constants
+	Format string
+
+	// Orientation is the EXIF orientation of JPEG images, from 1 to 8, zero
+	// when not recorded. Width and Height are the stored dimensions, they are
+	// swapped once displayed for orientations 5 to 8
+	Orientation int
+}
+
+// Probe reads the dimensions and format of the image in r by decoding its
+// header only, much cheaper than loading it when the pixels are not needed.
+// RAW files are read whole and described by their largest JPEG preview, see
+// DetectFormat
+// i.e :
+// info, err := imager.Probe(r.Body)
+func Probe(r io.Reader) (*ProbeInfo, error) {
+	// The previews of RAW files are found through the whole file
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(br.Size()); (err == nil || err == io.EOF) && isRAW(magic) {
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		config, format, err := decodeConfig(data)
+		if err != nil {
+			return nil, err
+		}
+		return &ProbeInfo{Width: config.Width, Height: config.Height, Format: format, Orientation: exifOrientation(data)}, nil
+	}
+	r = br
+
+	header := &headBuffer{limit: headerSize}
+	config, format, err := image.DecodeConfig(io.TeeReader(r, header))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+
+	info := &ProbeInfo{Width: config.Width, Height: config.Height, Format: format}
+	if format == IMJPEG {
+		// The EXIF segment comes before the frame header read by DecodeConfig
+		info.Orientation = exifOrientation(jpegEXIF(header.Bytes()))
+	}
+
+	return info, nil
+}
+
+// ProbeFile is like Probe for the image stored at location
+// i.e :
+// info, err := imager.ProbeFile("photo.jpg")
+func ProbeFile(location string) (*ProbeInfo, error) {
+	f, err := os.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Probe(f)
+}
+
+// ProbeBytes is like Probe for an image in memory
+// i.e :
+// info, err := imager.ProbeBytes(data)
+func ProbeBytes(data []byte) (*ProbeInfo, error) {
+	return Probe(bytes.NewReader(data))
+}
+
+// exifOrientation returns the orientation stored in EXIF data, zero when
+// missing
+func exifOrientation(exif []byte) int {
+	tiff, err := newTIFFReader(exif)
+	if err != nil {
+		return 0
+	}
+
+	entry, ok := tiff.lookup(TagOrientation)
+	if !ok {
+		return 0
+	}
+
+	return int(tiff.uint(entry, 0))
+}
+
+// NewImagerFromBytesLimited creates a new Imager from bytes, refusing to
+// decode images with more than maxPixels pixels. The dimensions are checked
+// before decoding so oversized images never allocate their pixels. A
+// WithDecodeLimits among opts replaces the maxPixels limit
+// i.e :
+// imgr, err := imager.NewImagerFromBytesLimited(data, 50_000_000)
+func NewImagerFromBytesLimited(data []byte, maxPixels int, opts ...LoadOption) (*Imager, error) {
+	limits := WithDecodeLimits(DecodeLimits{MaxPixels: int64(maxPixels)})
+	return NewImagerFromBytes(data, append([]LoadOption{limits}, opts...)...)
+}
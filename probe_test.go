@@ -0,0 +1,164 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	img := createTestImage()
+
+	jpegBuf, pngBuf, gifBuf := new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)
+	if err := jpeg.Encode(jpegBuf, img, nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	if err := png.Encode(pngBuf, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	if err := gif.Encode(gifBuf, img, nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	for want, data := range map[string][]byte{IMJPEG: jpegBuf.Bytes(), IMPNG: pngBuf.Bytes(), IMGIF: gifBuf.Bytes()} {
+		format, err := DetectFormat(data)
+		if err != nil {
+			t.Fatalf("DetectFormat returned an error for %s: %v", want, err)
+		}
+		if format != want {
+			t.Fatalf("DetectFormat returned %v, want %v", format, want)
+		}
+	}
+
+	if _, err := DetectFormat([]byte("definitely not an image")); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("DetectFormat returned %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestPeekDimensions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, 320, 240))); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	// The pixel data is cut off, only the header is available
+	width, height, format, err := PeekDimensions(buf.Bytes()[:64])
+	if err != nil {
+		t.Fatalf("PeekDimensions returned an error: %v", err)
+	}
+
+	if width != 320 || height != 240 || format != IMPNG {
+		t.Fatalf("PeekDimensions returned %dx%d %s, want 320x240 png", width, height, format)
+	}
+}
+
+func TestNewImagerFromBytesLimited(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, createTestImage()); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	if _, err := NewImagerFromBytesLimited(buf.Bytes(), 9999); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("NewImagerFromBytesLimited returned %v, want %v", err, ErrTooLarge)
+	}
+
+	imgr, err := NewImagerFromBytesLimited(buf.Bytes(), 10000)
+	if err != nil {
+		t.Fatalf("NewImagerFromBytesLimited returned an error: %v", err)
+	}
+	if imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("NewImagerFromBytesLimited returned unexpected bounds: %v", imgr.Image.Bounds())
+	}
+
+	// The limits passed as options win over maxPixels
+	if _, err := NewImagerFromBytesLimited(buf.Bytes(), 10000, WithDecodeLimits(DecodeLimits{MaxWidth: 50})); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("NewImagerFromBytesLimited returned %v with stricter limits, want %v", err, ErrTooLarge)
+	}
+	if _, err := NewImagerFromBytesLimited(buf.Bytes(), 9999, WithDecodeLimits(DecodeLimits{MaxPixels: 10000})); err != nil {
+		t.Fatalf("NewImagerFromBytesLimited returned %v with looser limits", err)
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, 320, 240))); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name    string
+		limits  DecodeLimits
+		wantErr bool
+	}{
+		{"none", DecodeLimits{}, false},
+		{"within", DecodeLimits{MaxWidth: 320, MaxHeight: 240, MaxPixels: 320 * 240, MaxBytes: int64(len(data))}, false},
+		{"width", DecodeLimits{MaxWidth: 319}, true},
+		{"height", DecodeLimits{MaxHeight: 239}, true},
+		{"pixels", DecodeLimits{MaxPixels: 320*240 - 1}, true},
+		{"bytes", DecodeLimits{MaxBytes: int64(len(data)) - 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := WithDecodeLimits(tt.limits)
+
+			_, err := NewImagerFromBytes(data, opt)
+			if tt.wantErr != errors.Is(err, ErrTooLarge) || !tt.wantErr && err != nil {
+				t.Fatalf("NewImagerFromBytes returned %v, want error %v", err, tt.wantErr)
+			}
+
+			_, err = NewImagerFromReader(bytes.NewReader(data), opt)
+			if tt.wantErr != errors.Is(err, ErrTooLarge) || !tt.wantErr && err != nil {
+				t.Fatalf("NewImagerFromReader returned %v, want error %v", err, tt.wantErr)
+			}
+
+			path := filepath.Join(t.TempDir(), "image.png")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatalf("failed to write test image: %v", err)
+			}
+			_, err = NewImagerFromFile(path, opt)
+			if tt.wantErr != errors.Is(err, ErrTooLarge) || !tt.wantErr && err != nil {
+				t.Fatalf("NewImagerFromFile returned %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	// createOrientedJPEG stores a 60x30 image
+	info, err := ProbeBytes(createOrientedJPEG(t, 6))
+	if err != nil {
+		t.Fatalf("ProbeBytes returned an error: %v", err)
+	}
+	if *info != (ProbeInfo{Width: 60, Height: 30, Format: IMJPEG, Orientation: 6}) {
+		t.Fatalf("ProbeBytes returned %+v", *info)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, 320, 240))); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write test image: %v", err)
+	}
+
+	info, err = ProbeFile(path)
+	if err != nil {
+		t.Fatalf("ProbeFile returned an error: %v", err)
+	}
+	if *info != (ProbeInfo{Width: 320, Height: 240, Format: IMPNG}) {
+		t.Fatalf("ProbeFile returned %+v", *info)
+	}
+
+	if _, err := Probe(bytes.NewReader([]byte("definitely not an image"))); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Probe returned %v, want %v", err, ErrUnknownFormat)
+	}
+}
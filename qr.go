@@ -0,0 +1,580 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// QRLevel is the error correction level of a QR code, the share of the code
+// that can be damaged, or covered by a logo, while it still scans
+type QRLevel int
+
+const (
+	// QRLevelM restores about 15% of the code, the default
+	QRLevelM QRLevel = iota
+
+	// QRLevelL restores about 7% of the code, the smallest codes
+	QRLevelL
+
+	// QRLevelQ restores about 25% of the code
+	QRLevelQ
+
+	// QRLevelH restores about 30% of the code
+	QRLevelH
+)
+
+// qrQuietZone is the width in modules of the light border around QR codes
+const qrQuietZone = 4
+
+// qrLevels holds the format bits and the error correction blocks of each
+// level: the number of error correction codewords per block and the number
+// of blocks, indexed by version
+var qrLevels = map[QRLevel]struct {
+	formatBits int
+	eccPerBlock,
+	blocks [41]int
+}{
+	QRLevelL: {
+		formatBits:  1,
+		eccPerBlock: [41]int{0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		blocks:      [41]int{0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	},
+	QRLevelM: {
+		formatBits:  0,
+		eccPerBlock: [41]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		blocks:      [41]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	},
+	QRLevelQ: {
+		formatBits:  3,
+		eccPerBlock: [41]int{0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		blocks:      [41]int{0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	},
+	QRLevelH: {
+		formatBits:  2,
+		eccPerBlock: [41]int{0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		blocks:      [41]int{0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	},
+}
+
+// QROptions holds the options used by GenerateQR and OverlayQR
+type QROptions struct {
+	// Level is the error correction level, QRLevelM by default
+	Level QRLevel
+
+	// Foreground and Background are the colors of the dark and light
+	// modules, black and white when nil
+	Foreground, Background color.Color
+}
+
+// GenerateQR returns a size x size image of a QR code holding data, in byte
+// mode, with the smallest version that fits. The modules are whole pixels
+// and the code is centered within its quiet zone, so size must leave at
+// least a pixel per module
+// i.e :
+// qr, err := imager.GenerateQR("https://example.com/t/8f3a", 300)
+// qr, err := imager.GenerateQR(ticket.ID, 200, imager.QROptions{Level: imager.QRLevelH})
+func GenerateQR(data string, size int, opts ...QROptions) (*Imager, error) {
+	var o QROptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if _, ok := qrLevels[o.Level]; !ok {
+		return nil, fmt.Errorf("%w: QR level %d", ErrInvalidArgument, o.Level)
+	}
+	if o.Foreground == nil {
+		o.Foreground = color.Black
+	}
+	if o.Background == nil {
+		o.Background = color.White
+	}
+
+	code, err := encodeQR([]byte(data), o.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := code.size + 2*qrQuietZone
+	scale := size / modules
+	if scale < 1 {
+		return nil, fmt.Errorf("%w: QR code of %d modules in %d pixels", ErrInvalidArgument, modules, size)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Rect, image.NewUniform(o.Background), image.Point{}, draw.Src)
+	offset := (size - code.size*scale) / 2
+	fg := image.NewUniform(o.Foreground)
+	for y := 0; y < code.size; y++ {
+		for x := 0; x < code.size; x++ {
+			if code.modules[y][x] {
+				module := image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale).Add(image.Pt(offset, offset))
+				draw.Draw(img, module, fg, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	return newGenerated(img)
+}
+
+// OverlayQR draws a size x size QR code holding data over the image at the
+// anchor, see GenerateQR. The quiet zone of the code keeps it clear of the
+// content below
+// i.e :
+// imgr.OverlayQR("https://example.com/t/8f3a", imager.AnchorBottomRight, 160)
+func (i *Imager) OverlayQR(data string, position Anchor, size int, opts ...QROptions) *Imager {
+	defer i.operation("OverlayQR")()
+
+	if i.err != nil {
+		return i
+	}
+
+	qr, err := GenerateQR(data, size, opts...)
+	if err != nil {
+		i.setErr(err)
+		return i
+	}
+
+	pt := position.point(i.Image.Bounds(), image.Pt(size, size), 0)
+	return i.Composite(qr.Image, pt.X, pt.Y, BlendNormal, 1)
+}
+
+// qrCode is the grid of modules of a QR code, isFunction marking the ones
+// of the patterns and format information rather than of the data
+type qrCode struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// encodeQR encodes data in byte mode into the smallest QR code of level
+func encodeQR(data []byte, level QRLevel) (*qrCode, error) {
+	version := 1
+	for ; version <= 40; version++ {
+		countBits := 8
+		if version >= 10 {
+			countBits = 16
+		}
+		if len(data) < 1<<countBits && 4+countBits+8*len(data) <= 8*qrDataCodewords(version, level) {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, fmt.Errorf("%w: %d bytes don't fit in a QR code", ErrInvalidArgument, len(data))
+	}
+
+	// Mode indicator, character count, data, terminator and padding
+	var bits qrBits
+	bits.append(0b0100, 4)
+	if version >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := 8 * qrDataCodewords(version, level)
+	bits.append(0, min(4, capacity-bits.len))
+	bits.append(0, (8-bits.len%8)%8)
+	for pad := 0xEC; bits.len < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	code := newQRCode(version)
+	code.drawCodewords(qrInterleave(bits.bytes, version, level))
+
+	// The mask leaving the fewest patterns that confuse the scanners wins
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormatBits(level, mask)
+		if penalty := code.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask)
+	}
+	code.applyMask(best)
+	code.drawFormatBits(level, best)
+
+	return code, nil
+}
+
+// qrBits is a bit buffer filled from the most significant bit
+type qrBits struct {
+	bytes []byte
+	len   int
+}
+
+// append appends the n low bits of v
+func (b *qrBits) append(v, n int) {
+	for k := n - 1; k >= 0; k-- {
+		if b.len%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		b.bytes[b.len/8] |= byte((v>>k)&1) << (7 - b.len%8)
+		b.len++
+	}
+}
+
+// qrRawModules returns the number of modules of a version available to the
+// data and error correction codewords
+func qrRawModules(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		modules -= (25*align-10)*align - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+
+	return modules
+}
+
+// qrDataCodewords returns the number of data codewords of a version at level
+func qrDataCodewords(version int, level QRLevel) int {
+	l := qrLevels[level]
+	return qrRawModules(version)/8 - l.eccPerBlock[version]*l.blocks[version]
+}
+
+// qrInterleave splits data into the blocks of the version, appends their
+// error correction codewords and interleaves them
+func qrInterleave(data []byte, version int, level QRLevel) []byte {
+	l := qrLevels[level]
+	numBlocks, eccLen := l.blocks[version], l.eccPerBlock[version]
+	raw := qrRawModules(version) / 8
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	divisor := reedSolomonDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	for b, k := 0, 0; b < numBlocks; b++ {
+		dataLen := shortLen - eccLen
+		if b >= numShort {
+			dataLen++
+		}
+		block := append([]byte(nil), data[k:k+dataLen]...)
+		k += dataLen
+		ecc := reedSolomonRemainder(block, divisor)
+		if b < numShort {
+			// Aligns the error correction of the short blocks on the long ones
+			block = append(block, 0)
+		}
+		blocks[b] = append(block, ecc...)
+	}
+
+	out := make([]byte, 0, raw)
+	for k := range blocks[0] {
+		for b, block := range blocks {
+			if k != shortLen-eccLen || b >= numShort {
+				out = append(out, block[k])
+			}
+		}
+	}
+
+	return out
+}
+
+// gfMultiply multiplies x and y in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	var z int
+	for k := 7; k >= 0; k-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>k)&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+// reedSolomonDivisor returns the generator polynomial of degree, without its
+// leading coefficient, from the highest to the lowest power
+func reedSolomonDivisor(degree int) []byte {
+	divisor := make([]byte, degree)
+	divisor[degree-1] = 1
+
+	root := byte(1)
+	for k := 0; k < degree; k++ {
+		for j := range divisor {
+			divisor[j] = gfMultiply(divisor[j], root)
+			if j+1 < degree {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return divisor
+}
+
+// reedSolomonRemainder returns the error correction codewords of data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	remainder := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+		for k, d := range divisor {
+			remainder[k] ^= gfMultiply(d, factor)
+		}
+	}
+
+	return remainder
+}
+
+// newQRCode returns the grid of a version with its function patterns drawn
+func newQRCode(version int) *qrCode {
+	size := 17 + 4*version
+	code := &qrCode{version: version, size: size, modules: make([][]bool, size), isFunction: make([][]bool, size)}
+	for y := range code.modules {
+		code.modules[y] = make([]bool, size)
+		code.isFunction[y] = make([]bool, size)
+	}
+
+	// Timing patterns
+	for k := 0; k < size; k++ {
+		code.setFunction(6, k, k%2 == 0)
+		code.setFunction(k, 6, k%2 == 0)
+	}
+
+	// Finder patterns and their separators
+	for _, center := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := center[0]+dx, center[1]+dy
+				if x >= 0 && x < size && y >= 0 && y < size {
+					dist := max(absInt(dx), absInt(dy))
+					code.setFunction(x, y, dist != 2 && dist != 4)
+				}
+			}
+		}
+	}
+
+	// Alignment patterns, but over the finder patterns
+	positions := qrAlignmentPositions(version)
+	last := len(positions) - 1
+	for a, y := range positions {
+		for b, x := range positions {
+			if (a == 0 && b == 0) || (a == 0 && b == last) || (a == last && b == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					code.setFunction(x+dx, y+dy, max(absInt(dx), absInt(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserves the format areas, drawn once the mask is chosen
+	code.drawFormatBits(QRLevelM, 0)
+
+	if version >= 7 {
+		rem := version
+		for k := 0; k < 12; k++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+		}
+		bits := version<<12 | rem
+		for k := 0; k < 18; k++ {
+			bit := (bits>>k)&1 != 0
+			a, b := size-11+k%3, k/3
+			code.setFunction(a, b, bit)
+			code.setFunction(b, a, bit)
+		}
+	}
+
+	return code
+}
+
+// qrAlignmentPositions returns the centers of the alignment patterns of a
+// version along each axis
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	count := version/7 + 2
+	step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for k, pos := count-1, 17+4*version-7; k >= 1; k, pos = k-1, pos-step {
+		positions[k] = pos
+	}
+
+	return positions
+}
+
+// setFunction sets the module at x, y as part of a function pattern
+func (c *qrCode) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+// drawFormatBits draws both copies of the level and mask information
+func (c *qrCode) drawFormatBits(level QRLevel, mask int) {
+	data := qrLevels[level].formatBits<<3 | mask
+	rem := data
+	for k := 0; k < 10; k++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(k int) bool { return (bits>>k)&1 != 0 }
+
+	for k := 0; k <= 5; k++ {
+		c.setFunction(8, k, bit(k))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for k := 9; k < 15; k++ {
+		c.setFunction(14-k, 8, bit(k))
+	}
+
+	for k := 0; k < 8; k++ {
+		c.setFunction(c.size-1-k, 8, bit(k))
+	}
+	for k := 8; k < 15; k++ {
+		c.setFunction(8, c.size-15+k, bit(k))
+	}
+	c.setFunction(8, c.size-8, true)
+}
+
+// drawCodewords places the codewords in the zigzag order of the data area,
+// two columns at a time from the bottom right corner
+func (c *qrCode) drawCodewords(codewords []byte) {
+	k := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// Skips the vertical timing pattern
+			right = 5
+		}
+		for vert := 0; vert < c.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.size - 1 - vert
+				}
+				if !c.isFunction[y][x] && k < len(codewords)*8 {
+					c.modules[y][x] = (codewords[k/8]>>(7-k%8))&1 != 0
+					k++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by mask, applying it twice
+// restores the code
+func (c *qrCode) applyMask(mask int) {
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.isFunction[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the runs, blocks, finder-like patterns and dark balance of
+// the code, the lower the easier to scan
+func (c *qrCode) penalty() int {
+	score := 0
+	for _, vertical := range []bool{false, true} {
+		for a := 0; a < c.size; a++ {
+			var history [7]int
+			runDark, run := false, 0
+			for b := 0; b < c.size; b++ {
+				dark := c.modules[a][b]
+				if vertical {
+					dark = c.modules[b][a]
+				}
+				if dark == runDark {
+					run++
+					if run == 5 {
+						score += 3
+					} else if run > 5 {
+						score++
+					}
+					continue
+				}
+				c.addRunHistory(run, &history)
+				if !runDark {
+					score += qrFinderPatterns(history) * 40
+				}
+				runDark, run = dark, 1
+			}
+			if runDark {
+				c.addRunHistory(run, &history)
+				run = 0
+			}
+			c.addRunHistory(run+c.size, &history)
+			score += qrFinderPatterns(history) * 40
+		}
+	}
+
+	dark := 0
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.size && y+1 < c.size {
+				m := c.modules[y][x]
+				if m == c.modules[y][x+1] && m == c.modules[y+1][x] && m == c.modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+	total := c.size * c.size
+	score += ((absInt(dark*20-total*10)+total-1)/total - 1) * 10
+
+	return score
+}
+
+// addRunHistory pushes a run length to the front of history, the first run
+// of a line including the light border before it
+func (c *qrCode) addRunHistory(run int, history *[7]int) {
+	if history[0] == 0 {
+		run += c.size
+	}
+	copy(history[1:], history[:6])
+	history[0] = run
+}
+
+// qrFinderPatterns counts the dark-light-dark runs of ratio 1:1:3:1:1 with
+// a light run of 4 on either side in history
+func qrFinderPatterns(history [7]int) int {
+	n := history[1]
+	core := n > 0 && history[2] == n && history[3] == n*3 && history[4] == n && history[5] == n
+
+	count := 0
+	if core && history[0] >= n*4 && history[6] >= n {
+		count++
+	}
+	if core && history[6] >= n*4 && history[0] >= n {
+		count++
+	}
+
+	return count
+}
@@ -0,0 +1,265 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// readQR decodes the byte mode data of a QR code image rendered with a
+// quiet zone of 4 modules, checking the error correction of every block
+func readQR(t *testing.T, img image.Image, level QRLevel) []byte {
+	t.Helper()
+
+	bounds := img.Bounds()
+	dark := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r < 0x8000
+	}
+
+	// The first and last dark columns give the width of the code
+	left, right := -1, -1
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			if dark(x, y) {
+				if left < 0 {
+					left = x
+				}
+				right = x
+				break
+			}
+		}
+	}
+	width := right - left + 1
+	for version := 1; version <= 40; version++ {
+		size := 17 + 4*version
+		if width%size == 0 {
+			scale := width / size
+			code := newQRCode(version)
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					code.modules[y][x] = dark(left+x*scale+scale/2, left+y*scale+scale/2)
+				}
+			}
+			if data := decodeQRModules(t, code, level); data != nil {
+				return data
+			}
+		}
+	}
+
+	t.Fatalf("no QR code found in %d pixels", width)
+	return nil
+}
+
+// decodeQRModules reads the data of a QR code of known version and level,
+// nil when its format bits don't match
+func decodeQRModules(t *testing.T, code *qrCode, level QRLevel) []byte {
+	format := 0
+	for k := 0; k < 8; k++ {
+		if code.modules[8][code.size-1-k] {
+			format |= 1 << k
+		}
+	}
+	for k := 8; k < 15; k++ {
+		if code.modules[code.size-15+k][8] {
+			format |= 1 << k
+		}
+	}
+	format ^= 0x5412
+	if format>>13 != qrLevels[level].formatBits {
+		return nil
+	}
+	mask := format >> 10 & 7
+	code.applyMask(mask)
+
+	var codewords []byte
+	var current byte
+	k := 0
+	for right := code.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < code.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = code.size - 1 - vert
+				}
+				if code.isFunction[y][x] {
+					continue
+				}
+				current = current<<1 | map[bool]byte{false: 0, true: 1}[code.modules[y][x]]
+				if k++; k%8 == 0 {
+					codewords = append(codewords, current)
+				}
+			}
+		}
+	}
+
+	// Deinterleaves the blocks and checks their syndromes
+	l := qrLevels[level]
+	numBlocks, eccLen := l.blocks[code.version], l.eccPerBlock[code.version]
+	raw := qrRawModules(code.version) / 8
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+	blocks := make([][]byte, numBlocks)
+	pos := 0
+	for k := 0; k < shortLen+1; k++ {
+		for b := range blocks {
+			if k == shortLen-eccLen && b < numShort {
+				continue
+			}
+			blocks[b] = append(blocks[b], codewords[pos])
+			pos++
+		}
+	}
+
+	var data []byte
+	for b, block := range blocks {
+		for root, alpha := 0, byte(1); root < eccLen; root, alpha = root+1, gfMultiply(alpha, 2) {
+			var syndrome byte
+			for _, c := range block {
+				syndrome = gfMultiply(syndrome, alpha) ^ c
+			}
+			if syndrome != 0 {
+				t.Fatalf("block %d has the syndrome %d at root %d", b, syndrome, root)
+			}
+		}
+		data = append(data, block[:len(block)-eccLen]...)
+	}
+
+	var bits qrBits
+	bits.bytes, bits.len = data, len(data)*8
+	read := func(offset, n int) int {
+		v := 0
+		for k := offset; k < offset+n; k++ {
+			v = v<<1 | int(bits.bytes[k/8]>>(7-k%8)&1)
+		}
+		return v
+	}
+	if mode := read(0, 4); mode != 0b0100 {
+		t.Fatalf("unexpected mode %04b", mode)
+	}
+	countBits := 8
+	if code.version >= 10 {
+		countBits = 16
+	}
+	count := read(4, countBits)
+	out := make([]byte, count)
+	for k := range out {
+		out[k] = byte(read(4+countBits+8*k, 8))
+	}
+
+	return out
+}
+
+func TestReedSolomon(t *testing.T) {
+	// HELLO WORLD at version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := reedSolomonRemainder(data, reedSolomonDivisor(10))
+	if !bytes.Equal(ecc, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}) {
+		t.Fatalf("unexpected error correction %v", ecc)
+	}
+}
+
+func TestQRFormatBits(t *testing.T) {
+	for _, tt := range []struct {
+		level    QRLevel
+		mask     int
+		expected int
+	}{
+		{QRLevelL, 4, 0b110011000101111},
+		{QRLevelM, 0, 0b101010000010010},
+		{QRLevelQ, 0, 0b011010101011111},
+		{QRLevelH, 0, 0b001011010001001},
+	} {
+		code := newQRCode(1)
+		code.drawFormatBits(tt.level, tt.mask)
+
+		format := 0
+		for k := 0; k < 8; k++ {
+			if code.modules[8][code.size-1-k] {
+				format |= 1 << k
+			}
+		}
+		for k := 8; k < 15; k++ {
+			if code.modules[code.size-15+k][8] {
+				format |= 1 << k
+			}
+		}
+		if format != tt.expected {
+			t.Fatalf("level %d mask %d: format %015b, want %015b", tt.level, tt.mask, format, tt.expected)
+		}
+	}
+}
+
+func TestQRVersionBits(t *testing.T) {
+	code := newQRCode(7)
+	bits := 0
+	for k := 0; k < 18; k++ {
+		if code.modules[code.size-11+k%3][k/3] {
+			bits |= 1 << k
+		}
+	}
+	if bits != 0x07C94 {
+		t.Fatalf("version 7 information %018b", bits)
+	}
+}
+
+func TestGenerateQR(t *testing.T) {
+	for _, tt := range []struct {
+		data  string
+		level QRLevel
+	}{
+		{"https://example.com/t/8f3a", QRLevelM},
+		{"ticket", QRLevelH},
+		{strings.Repeat("shipping label 0123456789 ", 12), QRLevelQ},
+		{strings.Repeat("x", 400), QRLevelL},
+	} {
+		qr, err := GenerateQR(tt.data, 400, QROptions{Level: tt.level})
+		if err != nil {
+			t.Fatalf("GenerateQR returned an error: %v", err)
+		}
+		if qr.Image.Bounds() != image.Rect(0, 0, 400, 400) || qr.ImageType != IMPNG {
+			t.Fatalf("unexpected image %v of type %s", qr.Image.Bounds(), qr.ImageType)
+		}
+		if data := readQR(t, qr.Image, tt.level); string(data) != tt.data {
+			t.Fatalf("read %q, want %q", data, tt.data)
+		}
+	}
+
+	if _, err := GenerateQR("too small", 20); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := GenerateQR(strings.Repeat("x", 3000), 4000); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestOverlayQR(t *testing.T) {
+	imgr, _ := NewImager(createColorImage(color.NRGBA{0, 0, 255, 255}))
+	imgr.Resize(200, 100, MD_STRETCH).OverlayQR("label", AnchorBottomRight, 60)
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("OverlayQR returned an error: %v", err)
+	}
+
+	// The quiet zone is white, the image is left blue elsewhere
+	if c := color.NRGBAModel.Convert(imgr.Image.At(199, 99)).(color.NRGBA); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("the corner of the code is %v", c)
+	}
+	if c := color.NRGBAModel.Convert(imgr.Image.At(10, 10)).(color.NRGBA); c != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("the image outside the code is %v", c)
+	}
+	qr := image.NewNRGBA(image.Rect(0, 0, 60, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 60; x++ {
+			qr.Set(x, y, imgr.Image.At(140+x, 40+y))
+		}
+	}
+	if data := readQR(t, qr, QRLevelM); string(data) != "label" {
+		t.Fatalf("read %q from the overlay", data)
+	}
+}
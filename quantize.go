@@ -0,0 +1,356 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// QuantizeOption is an option of Quantize, a DitherMode or a Quantizer
+type QuantizeOption interface {
+	applyQuantize(c *quantizeConfig)
+}
+
+// quantizeConfig holds the options of Quantize
+type quantizeConfig struct {
+	dither    DitherMode
+	quantizer Quantizer
+}
+
+// DitherMode is how Quantize spreads the error between the colors of the
+// image and the palette
+type DitherMode int
+
+const (
+	// DitherNone maps each pixel to its closest palette color, leaving bands
+	// on gradients
+	DitherNone DitherMode = iota
+
+	// DitherFloydSteinberg diffuses the error to the next pixels, the most
+	// faithful to photos
+	DitherFloydSteinberg
+
+	// DitherOrdered offsets the pixels by a Bayer matrix, giving the regular
+	// crosshatch of pixel art that also compresses better
+	DitherOrdered
+)
+
+func (d DitherMode) applyQuantize(c *quantizeConfig) {
+	c.dither = d
+}
+
+// Quantizer is the algorithm Quantize builds the palette with
+type Quantizer int
+
+const (
+	// QuantizeMedianCut splits the colors into boxes of equal population,
+	// the best for photos
+	QuantizeMedianCut Quantizer = iota
+
+	// QuantizeOctree merges the least used colors of an octree, faster and
+	// keeping the small areas of distinct colors
+	QuantizeOctree
+)
+
+func (q Quantizer) applyQuantize(c *quantizeConfig) {
+	c.quantizer = q
+}
+
+// Quantize reduces the image to at most numColors colors (up to 256), the
+// result is stored as an *image.Paletted usable as GIF or PNG8. The palette
+// is built by median cut and the pixels are not dithered unless opts say
+// otherwise
+// i.e :
+// imgr.Quantize(16)
+// imgr.Quantize(64, imager.DitherFloydSteinberg)
+// imgr.Quantize(8, imager.QuantizeOctree, imager.DitherOrdered)
+// palette := imgr.Image.(*image.Paletted).Palette
+func (i *Imager) Quantize(numColors int, opts ...QuantizeOption) *Imager {
+	if numColors <= 0 || numColors > 256 {
+		i.setErr(fmt.Errorf("%w: %d colors", ErrInvalidArgument, numColors))
+		return i
+	}
+
+	var c quantizeConfig
+	for _, opt := range opts {
+		opt.applyQuantize(&c)
+	}
+	if c.dither < DitherNone || c.dither > DitherOrdered {
+		i.setErr(fmt.Errorf("%w: dither mode %d", ErrInvalidArgument, c.dither))
+		return i
+	}
+	if c.quantizer != QuantizeMedianCut && c.quantizer != QuantizeOctree {
+		i.setErr(fmt.Errorf("%w: quantizer %d", ErrInvalidArgument, c.quantizer))
+		return i
+	}
+
+	return i.apply("Quantize", func(img image.Image) image.Image {
+		return quantize(img, numColors, c)
+	})
+}
+
+// quantize returns img reduced to a palette of numColors colors
+func quantize(img image.Image, numColors int, c quantizeConfig) *image.Paletted {
+	palette := medianCut(img, numColors)
+	if c.quantizer == QuantizeOctree {
+		palette = octreePalette(img, numColors)
+	}
+
+	bounds := img.Bounds()
+	dst := image.NewPaletted(bounds, palette)
+	switch c.dither {
+	case DitherFloydSteinberg:
+		draw.FloydSteinberg.Draw(dst, bounds, img, bounds.Min)
+	case DitherOrdered:
+		orderedDither(dst, img)
+	default:
+		draw.Draw(dst, bounds, img, bounds.Min, draw.Src)
+	}
+
+	return dst
+}
+
+// bayer8 is the 8x8 Bayer threshold matrix, from 0 to 63
+var bayer8 = [8][8]int{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+// orderedDither draws img onto dst, offsetting the pixels by the Bayer
+// matrix before picking their palette color. The offsets span about the
+// distance between the palette colors on each channel, the alpha is not
+// dithered
+func orderedDither(dst *image.Paletted, img image.Image) {
+	bounds := img.Bounds()
+	spread := 255 / math.Cbrt(float64(len(dst.Palette)))
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			offset := (float64(bayer8[y%8][x%8])+0.5)/64 - 0.5
+			shifted := color.NRGBA{
+				clampUint8(float64(c.R) + offset*spread),
+				clampUint8(float64(c.G) + offset*spread),
+				clampUint8(float64(c.B) + offset*spread),
+				c.A,
+			}
+			dst.SetColorIndex(dst.Rect.Min.X+x, dst.Rect.Min.Y+y, uint8(dst.Palette.Index(shifted)))
+		}
+	}
+}
+
+// octreeNode is a node of the color octree built by octreePalette. Each
+// level splits on one bit of every channel, alpha included, so nodes have
+// up to 16 children
+type octreeNode struct {
+	children [16]*octreeNode
+	sum      [4]int
+	count    int
+	leaf     bool
+}
+
+// octreePalette builds a palette of at most numColors colors for img by
+// merging the least used branches of an octree of its colors
+func octreePalette(img image.Image, numColors int) color.Palette {
+	root := &octreeNode{}
+	// levels holds the inner nodes by depth, the deepest are merged first
+	var levels [8][]*octreeNode
+	leaves := 0
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			channels := [4]uint8{c.R, c.G, c.B, c.A}
+
+			node := root
+			for depth := 0; ; depth++ {
+				for ch := range channels {
+					node.sum[ch] += int(channels[ch])
+				}
+				node.count++
+				if node.leaf || depth == 8 {
+					break
+				}
+
+				shift := 7 - depth
+				idx := int(c.R>>shift&1)<<3 | int(c.G>>shift&1)<<2 | int(c.B>>shift&1)<<1 | int(c.A>>shift&1)
+				if node.children[idx] == nil {
+					child := &octreeNode{leaf: depth == 7}
+					if child.leaf {
+						leaves++
+					} else {
+						levels[depth+1] = append(levels[depth+1], child)
+					}
+					node.children[idx] = child
+				}
+				node = node.children[idx]
+			}
+		}
+	}
+	levels[0] = []*octreeNode{root}
+
+	for depth := 7; depth >= 0 && leaves > numColors; depth-- {
+		nodes := levels[depth]
+		sort.Slice(nodes, func(m, n int) bool { return nodes[m].count < nodes[n].count })
+		for _, node := range nodes {
+			if leaves <= numColors {
+				break
+			}
+
+			// The children are leaves as the deeper levels are merged
+			for idx, child := range node.children {
+				if child != nil {
+					leaves--
+					node.children[idx] = nil
+				}
+			}
+			node.leaf = true
+			leaves++
+		}
+	}
+
+	palette := color.Palette{}
+	var collect func(node *octreeNode)
+	collect = func(node *octreeNode) {
+		if node.leaf {
+			palette = append(palette, color.NRGBA{
+				uint8((node.sum[0] + node.count/2) / node.count),
+				uint8((node.sum[1] + node.count/2) / node.count),
+				uint8((node.sum[2] + node.count/2) / node.count),
+				uint8((node.sum[3] + node.count/2) / node.count),
+			})
+			return
+		}
+		for _, child := range node.children {
+			if child != nil {
+				collect(child)
+			}
+		}
+	}
+	if root.count > 0 {
+		collect(root)
+	}
+
+	return palette
+}
+
+// colorCount is a distinct color of an image and its number of pixels
+type colorCount struct {
+	c     [4]uint8
+	count int
+}
+
+// colorBox is a set of colors split by the median cut algorithm
+type colorBox []colorCount
+
+// widest returns the channel with the largest range and that range
+func (b colorBox) widest() (int, int) {
+	channel, width := 0, -1
+	for ch := 0; ch < 4; ch++ {
+		low, high := uint8(255), uint8(0)
+		for _, cc := range b {
+			low, high = min(low, cc.c[ch]), max(high, cc.c[ch])
+		}
+		if int(high)-int(low) > width {
+			channel, width = ch, int(high)-int(low)
+		}
+	}
+
+	return channel, width
+}
+
+// average returns the pixel weighted mean color of the box
+func (b colorBox) average() color.NRGBA {
+	var sum [4]int
+	total := 0
+	for _, cc := range b {
+		for ch := 0; ch < 4; ch++ {
+			sum[ch] += int(cc.c[ch]) * cc.count
+		}
+		total += cc.count
+	}
+
+	return color.NRGBA{
+		uint8((sum[0] + total/2) / total),
+		uint8((sum[1] + total/2) / total),
+		uint8((sum[2] + total/2) / total),
+		uint8((sum[3] + total/2) / total),
+	}
+}
+
+// medianCut builds a palette of at most numColors colors for img
+func medianCut(img image.Image, numColors int) color.Palette {
+	counts := map[[4]uint8]int{}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			counts[[4]uint8{c.R, c.G, c.B, c.A}]++
+		}
+	}
+
+	palette := color.Palette{}
+	for _, b := range splitBoxes(counts, numColors) {
+		palette = append(palette, b.average())
+	}
+
+	return palette
+}
+
+// splitBoxes splits the colors of counts into at most numColors boxes using
+// the median cut algorithm, there are no empty boxes
+func splitBoxes(counts map[[4]uint8]int, numColors int) []colorBox {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	box := make(colorBox, 0, len(counts))
+	for c, count := range counts {
+		box = append(box, colorCount{c: c, count: count})
+	}
+	boxes := []colorBox{box}
+
+	for len(boxes) < numColors {
+		// Split the box with the widest channel range
+		target, channel, width := -1, 0, 0
+		for idx, b := range boxes {
+			if len(b) < 2 {
+				continue
+			}
+			if ch, w := b.widest(); w > width {
+				target, channel, width = idx, ch, w
+			}
+		}
+		if target < 0 {
+			break
+		}
+
+		b := boxes[target]
+		sort.Slice(b, func(m, n int) bool { return b[m].c[channel] < b[n].c[channel] })
+
+		total := b.population()
+		split, seen := 1, 0
+		for idx, cc := range b[:len(b)-1] {
+			seen += cc.count
+			split = idx + 1
+			if seen*2 >= total {
+				break
+			}
+		}
+
+		boxes[target] = b[:split]
+		boxes = append(boxes, b[split:])
+	}
+
+	return boxes
+}
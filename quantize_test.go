@@ -0,0 +1,86 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestQuantize(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.Quantize(16)
+
+	paletted, ok := imgr.Image.(*image.Paletted)
+	if !ok {
+		t.Fatalf("Quantize returned %T, want *image.Paletted", imgr.Image)
+	}
+
+	if len(paletted.Palette) > 16 {
+		t.Fatalf("Quantize returned a palette of %d colors", len(paletted.Palette))
+	}
+
+	if got := color.NRGBAModel.Convert(paletted.At(50, 50)); got != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("Quantize changed the color of the image: got %v", got)
+	}
+}
+
+func TestQuantizeGradient(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 256, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 256; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(255 - x), uint8(y * 60), 255})
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	palette := imgr.Quantize(8).Image.(*image.Paletted).Palette
+
+	if len(palette) != 8 {
+		t.Fatalf("Quantize returned a palette of %d colors, want 8", len(palette))
+	}
+
+	if err := imgr.Quantize(0).Err(); err == nil {
+		t.Fatalf("Quantize did not record an error for 0 colors")
+	}
+}
+
+func TestQuantizeOptions(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 4), uint8(y * 4), 128, 255})
+		}
+	}
+
+	for _, quantizer := range []Quantizer{QuantizeMedianCut, QuantizeOctree} {
+		for _, dither := range []DitherMode{DitherNone, DitherFloydSteinberg, DitherOrdered} {
+			imgr, _ := NewImager(img)
+			imgr.Quantize(8, quantizer, dither)
+			if err := imgr.Err(); err != nil {
+				t.Fatalf("Quantize(%d, %d) returned an error: %v", quantizer, dither, err)
+			}
+
+			paletted := imgr.Image.(*image.Paletted)
+			if len(paletted.Palette) == 0 || len(paletted.Palette) > 8 {
+				t.Fatalf("Quantize(%d, %d) returned a palette of %d colors", quantizer, dither, len(paletted.Palette))
+			}
+
+			// Dithering mixes the colors of neighbouring pixels in flat bands
+			used := map[uint8]bool{}
+			for x := 20; x < 28; x++ {
+				used[paletted.ColorIndexAt(x, 30)] = true
+			}
+			if dither != DitherNone && len(used) < 2 {
+				t.Fatalf("Quantize(%d, %d) did not dither", quantizer, dither)
+			}
+		}
+	}
+
+	imgr, _ := NewImager(createTestImage())
+	if c := color.NRGBAModel.Convert(imgr.Quantize(4, QuantizeOctree).Image.At(5, 5)); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("Quantize changed the color of a flat image: %v", c)
+	}
+	if err := imgr.Quantize(4, DitherMode(7)).Err(); err == nil {
+		t.Fatalf("Quantize did not record an error for an unknown dither mode")
+	}
+}
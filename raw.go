@@ -0,0 +1,174 @@
+package imager
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"slices"
+	"strings"
+)
+
+// TIFF tags locating the previews of RAW camera files
+const (
+	tagCompression     = 0x0103
+	tagStripOffsets    = 0x0111
+	tagStripByteCounts = 0x0117
+	tagSubIFDs         = 0x014A
+	tagJPEGOffset      = 0x0201
+	tagJPEGLength      = 0x0202
+	tagDNGVersion      = 0xC612
+)
+
+// rawMetadataTags are the fields of the first IFD of a RAW file kept as the
+// EXIF of its preview, the others describe the sensor data
+var rawMetadataTags = []uint16{
+	TagImageDescription, TagMake, TagModel, TagOrientation, TagSoftware,
+	TagDateTime, TagArtist, TagCopyright, TagExifIFD, TagGPSIFD,
+}
+
+// isRAW reports whether data is a RAW camera file built on TIFF: Canon CR2,
+// Nikon NEF, Sony ARW or DNG
+func isRAW(data []byte) bool {
+	if !isTIFF(data) {
+		return false
+	}
+	if len(data) >= 10 && string(data[8:10]) == "CR" {
+		return true
+	}
+
+	r, err := newTIFFReader(data)
+	if err != nil {
+		return false
+	}
+	entries, _, err := r.ifd(r.order.Uint32(data[4:]))
+	if err != nil {
+		return false
+	}
+	if _, ok := find(entries, tagDNGVersion); ok {
+		return true
+	}
+
+	maker := strings.ToUpper(r.string(entries, TagMake))
+	return strings.HasPrefix(maker, "NIKON") || strings.HasPrefix(maker, "SONY")
+}
+
+// loadRAW loads the largest JPEG preview embedded in a RAW camera file, the
+// sensor data itself is not demosaiced. The EXIF of the preview is made of
+// the camera fields of the RAW file. It returns false when there is no
+// preview the JPEG decoder reads, the lossless JPEG of the sensor data
+// being one of them
+func (i *Imager) loadRAW(data []byte) bool {
+	r, err := newTIFFReader(data)
+	if err != nil {
+		return false
+	}
+
+	for _, preview := range rawPreviews(r) {
+		img, err := jpeg.Decode(bytes.NewReader(preview))
+		if err != nil {
+			continue
+		}
+
+		i.setImage(img, IMJPEG, preview)
+		i.EXIF = rawEXIF(r)
+		return true
+	}
+
+	return false
+}
+
+// rawPreviews returns the JPEG images found in the IFD chain of a RAW file
+// and in their sub IFDs, from the largest to the smallest
+func rawPreviews(r *tiffReader) [][]byte {
+	type preview struct {
+		data   []byte
+		pixels int
+	}
+	var previews []preview
+
+	add := func(offset, length uint32) {
+		end := int64(offset) + int64(length)
+		if length == 0 || end > int64(len(r.data)) {
+			return
+		}
+		data := r.data[offset:end]
+		if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+			return
+		}
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		previews = append(previews, preview{data, config.Width * config.Height})
+	}
+
+	seen := map[uint32]bool{}
+	var walk func(offset uint32, depth int)
+	walk = func(offset uint32, depth int) {
+		for ; offset != 0 && !seen[offset] && depth < 3; depth++ {
+			seen[offset] = true
+			entries, next, err := r.ifd(offset)
+			if err != nil {
+				return
+			}
+
+			add(r.value(entries, tagJPEGOffset), r.value(entries, tagJPEGLength))
+			// Old-style JPEG (6) and JPEG (7) images stored as a single strip
+			if compression := r.value(entries, tagCompression); compression == 6 || compression == 7 {
+				offsets, okOffsets := find(entries, tagStripOffsets)
+				counts, okCounts := find(entries, tagStripByteCounts)
+				if okOffsets && okCounts && offsets.count == 1 {
+					add(r.uint(offsets, 0), r.uint(counts, 0))
+				}
+			}
+
+			if sub, ok := find(entries, tagSubIFDs); ok {
+				for n := 0; n < int(sub.count); n++ {
+					walk(r.uint(sub, n), depth+1)
+				}
+			}
+			offset = next
+		}
+	}
+	walk(r.order.Uint32(r.data[4:]), 0)
+
+	slices.SortStableFunc(previews, func(a, b preview) int { return b.pixels - a.pixels })
+	data := make([][]byte, len(previews))
+	for k, p := range previews {
+		data[k] = p.data
+	}
+
+	return data
+}
+
+// rawEXIF returns the EXIF data holding the camera fields of the first IFD of
+// a RAW file, nil when there are none
+func rawEXIF(r *tiffReader) []byte {
+	fields, err := r.fields(r.order.Uint32(r.data[4:]), 0)
+	if err != nil {
+		return nil
+	}
+	fields = slices.DeleteFunc(fields, func(f tiffField) bool {
+		return !slices.Contains(rawMetadataTags, f.tag)
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return encodeTIFF(r.order, fields)
+}
+
+// rawConfig returns the dimensions of the largest preview of a RAW file
+func rawConfig(data []byte) (image.Config, bool) {
+	r, err := newTIFFReader(data)
+	if err != nil {
+		return image.Config{}, false
+	}
+	previews := rawPreviews(r)
+	if len(previews) == 0 {
+		return image.Config{}, false
+	}
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(previews[0]))
+	return config, err == nil
+}
@@ -0,0 +1,156 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// createRawJPEG returns a JPEG image of w x h, a RAW preview
+func createRawJPEG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{0, 0, 255, 255})
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, nil); err != nil {
+		t.Fatalf("failed to encode test preview: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// createRawFile returns a TIFF based RAW file made of the IFD built by
+// fields, called with the offset the previews are appended at, then of the
+// previews
+func createRawFile(fields func(offset uint32) []tiffField, previews ...[]byte) []byte {
+	order := binary.LittleEndian
+
+	// The fields have the same size whatever the offset
+	offset := uint32(len(encodeTIFF(order, fields(0))))
+	data := encodeTIFF(order, fields(offset))
+	for _, preview := range previews {
+		data = append(data, preview...)
+	}
+
+	return data
+}
+
+func TestRawPreview(t *testing.T) {
+	order := binary.LittleEndian
+	long := func(v uint32) []byte { return order.AppendUint32(nil, v) }
+	short := func(v uint16) []byte { return order.AppendUint16(nil, v) }
+
+	// A NEF like file: a small preview in the first IFD, the large one in a
+	// sub IFD
+	small, large := createRawJPEG(t, 16, 8), createRawJPEG(t, 80, 40)
+	nef := createRawFile(func(offset uint32) []tiffField {
+		return []tiffField{
+			{tag: TagMake, typ: 2, count: 18, value: []byte("NIKON CORPORATION\x00")},
+			{tag: TagOrientation, typ: 3, count: 1, value: short(6)},
+			{tag: tagJPEGOffset, typ: 4, count: 1, value: long(offset)},
+			{tag: tagJPEGLength, typ: 4, count: 1, value: long(uint32(len(small)))},
+			{tag: tagSubIFDs, typ: 4, count: 1, sub: []tiffField{
+				{tag: tagJPEGOffset, typ: 4, count: 1, value: long(offset + uint32(len(small)))},
+				{tag: tagJPEGLength, typ: 4, count: 1, value: long(uint32(len(large)))},
+			}},
+		}
+	}, small, large)
+
+	if !isRAW(nef) {
+		t.Fatal("isRAW should detect the Nikon file")
+	}
+
+	for name, load := range map[string]func() (*Imager, error){
+		"bytes":  func() (*Imager, error) { return NewImagerFromBytes(nef) },
+		"reader": func() (*Imager, error) { return NewImagerFromReader(bytes.NewReader(nef)) },
+	} {
+		imgr, err := load()
+		if err != nil {
+			t.Fatalf("%s: loading the RAW file returned an error: %v", name, err)
+		}
+		if imgr.ImageType != IMJPEG {
+			t.Errorf("%s: expected the jpeg type, got %q", name, imgr.ImageType)
+		}
+		if b := imgr.Image.Bounds(); b.Dx() != 80 || b.Dy() != 40 {
+			t.Errorf("%s: expected the 80x40 preview, got %dx%d", name, b.Dx(), b.Dy())
+		}
+		// The orientation of the RAW file applies to the preview
+		if imgr.Width() != 40 || imgr.Height() != 80 {
+			t.Errorf("%s: expected a 40x80 display size, got %dx%d", name, imgr.Width(), imgr.Height())
+		}
+		meta, err := imgr.Metadata()
+		if err != nil {
+			t.Fatalf("%s: Metadata returned an error: %v", name, err)
+		}
+		if meta.Make != "NIKON CORPORATION" || meta.Orientation != 6 {
+			t.Errorf("%s: expected the camera metadata, got %+v", name, meta)
+		}
+	}
+
+	// Probing describes the preview too
+	if format, err := DetectFormat(nef); err != nil || format != IMJPEG {
+		t.Errorf("DetectFormat returned %q, %v, want jpeg", format, err)
+	}
+	if w, h, _, err := PeekDimensions(nef); err != nil || w != 80 || h != 40 {
+		t.Errorf("PeekDimensions returned %dx%d, %v, want 80x40", w, h, err)
+	}
+	info, err := Probe(bytes.NewReader(nef))
+	if err != nil || *info != (ProbeInfo{Width: 80, Height: 40, Format: IMJPEG, Orientation: 6}) {
+		t.Errorf("Probe returned %+v, %v", info, err)
+	}
+
+	// The decode limits apply to the preview
+	if _, err := NewImagerFromBytes(nef, WithDecodeLimits(DecodeLimits{MaxWidth: 50})); err == nil {
+		t.Error("expected the 80 pixels wide preview to exceed the limits")
+	}
+
+	// A CR2 like file: the signature follows the header, the preview is a
+	// JPEG strip of the first IFD
+	preview := createRawJPEG(t, 32, 24)
+	cr2 := append([]byte("II*\x00"), long(16)...)
+	cr2 = append(cr2, "CR\x02\x00\x00\x00\x00\x00"...)
+	cr2 = append(cr2, short(4)...)
+	for _, entry := range [][3]uint32{
+		{tagCompression, 3, 6},
+		{uint32(TagMake), 2, 70},
+		{tagStripOffsets, 4, 76},
+		{tagStripByteCounts, 4, uint32(len(preview))},
+	} {
+		count := uint32(1)
+		if entry[0] == uint32(TagMake) {
+			count = 6
+		}
+		cr2 = append(cr2, short(uint16(entry[0]))...)
+		cr2 = append(cr2, short(uint16(entry[1]))...)
+		cr2 = append(cr2, long(count)...)
+		cr2 = append(cr2, long(entry[2])...)
+	}
+	cr2 = append(cr2, long(0)...)
+	cr2 = append(cr2, "Canon\x00"...)
+	cr2 = append(cr2, preview...)
+
+	imgr, err := NewImagerFromBytes(cr2)
+	if err != nil {
+		t.Fatalf("loading the CR2 file returned an error: %v", err)
+	}
+	if b := imgr.Image.Bounds(); imgr.ImageType != IMJPEG || b.Dx() != 32 || b.Dy() != 24 {
+		t.Errorf("expected the 32x24 jpeg preview, got %s %dx%d", imgr.ImageType, b.Dx(), b.Dy())
+	}
+
+	// Without a readable preview there is no image to load
+	noPreview := nef[:len(nef)-len(small)-len(large)]
+	if _, err := NewImagerFromBytes(noPreview); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat without preview, got %v", err)
+	}
+	if _, err := ProbeBytes(noPreview); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected Probe to return ErrUnknownFormat without preview, got %v", err)
+	}
+}
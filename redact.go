@@ -0,0 +1,80 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// PixelateRegion replaces the rect area of the image by blocks of blockSize
+// pixels of their average color, such as to hide a face or a license plate.
+// rect is relative to the top left corner of the image and clipped to it
+// i.e :
+// imgr.PixelateRegion(image.Rect(120, 40, 220, 160), 12)
+func (i *Imager) PixelateRegion(rect image.Rectangle, blockSize int) *Imager {
+	if blockSize < 1 {
+		i.setErr(fmt.Errorf("%w: pixelate block size %d", ErrInvalidArgument, blockSize))
+		return i
+	}
+
+	return i.apply("PixelateRegion", func(img image.Image) image.Image {
+		dst := imaging.Clone(img)
+		region := rect.Intersect(dst.Rect)
+
+		for by := region.Min.Y; by < region.Max.Y; by += blockSize {
+			for bx := region.Min.X; bx < region.Max.X; bx += blockSize {
+				block := image.Rect(bx, by, bx+blockSize, by+blockSize).Intersect(region)
+				draw.Draw(dst, block, image.NewUniform(averageColor(dst, block)), image.Point{}, draw.Src)
+			}
+		}
+
+		return dst
+	})
+}
+
+// BlurRegion blurs the rect area of the image with a gaussian blur of sigma,
+// such as to hide personal data in a screenshot. The pixels around rect are
+// used for the blur so its edges don't fade. rect is relative to the top left
+// corner of the image and clipped to it. A sigma of zero or less leaves the
+// image as is
+// i.e :
+// imgr.BlurRegion(image.Rect(0, 0, 300, 40), 8)
+func (i *Imager) BlurRegion(rect image.Rectangle, sigma float64) *Imager {
+	if sigma <= 0 {
+		return i
+	}
+
+	return i.apply("BlurRegion", func(img image.Image) image.Image {
+		dst := imaging.Clone(img)
+		region := rect.Intersect(dst.Rect)
+		if region.Empty() {
+			return dst
+		}
+
+		margin := int(math.Ceil(3 * sigma))
+		area := region.Inset(-margin).Intersect(dst.Rect)
+		blurred := imaging.Blur(imaging.Crop(dst, area), sigma)
+		draw.Draw(dst, region, blurred, region.Min.Sub(area.Min), draw.Src)
+
+		return dst
+	})
+}
+
+// averageColor returns the average color of the rect area of img, weighted
+// by the alpha so transparent pixels don't darken it
+func averageColor(img *image.NRGBA, rect image.Rectangle) color.NRGBA {
+	var acc premultiplied
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			acc.add(img.NRGBAAt(x, y), 1)
+		}
+	}
+
+	c := acc.color()
+	c.A = uint8(acc.a/float64(rect.Dx()*rect.Dy()) + 0.5)
+	return c
+}
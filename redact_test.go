@@ -0,0 +1,63 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestPixelateRegion(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+	imgr.PixelateRegion(image.Rect(10, 10, 40, 35), 10)
+	orig := createGradientImage()
+
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			inside := image.Pt(x, y).In(image.Rect(10, 10, 40, 35))
+			same := color.NRGBAModel.Convert(imgr.Image.At(x, y)) == color.NRGBAModel.Convert(orig.At(x, y))
+			if !inside && !same {
+				t.Fatalf("PixelateRegion changed the pixel at %d,%d outside of the region", x, y)
+			}
+		}
+	}
+
+	// Every pixel of a block has the block color, the last row of blocks is clipped
+	for _, block := range []image.Rectangle{image.Rect(10, 10, 20, 20), image.Rect(30, 30, 40, 35)} {
+		first := imgr.Image.At(block.Min.X, block.Min.Y)
+		for y := block.Min.Y; y < block.Max.Y; y++ {
+			for x := block.Min.X; x < block.Max.X; x++ {
+				if imgr.Image.At(x, y) != first {
+					t.Fatalf("PixelateRegion block %v is not uniform at %d,%d", block, x, y)
+				}
+			}
+		}
+	}
+
+	if imgr.PixelateRegion(image.Rect(0, 0, 10, 10), 0); !errors.Is(imgr.Err(), ErrInvalidArgument) {
+		t.Fatalf("PixelateRegion recorded %v for a zero block size", imgr.Err())
+	}
+}
+
+func TestBlurRegion(t *testing.T) {
+	img := createPatternImage(80, 80)
+	imgr, _ := NewImager(img)
+	region := image.Rect(20, 20, 60, 50)
+	imgr.BlurRegion(region, 4)
+
+	changed := 0
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			same := imgr.Image.At(x, y) == img.At(x, y)
+			if !image.Pt(x, y).In(region) && !same {
+				t.Fatalf("BlurRegion changed the pixel at %d,%d outside of the region", x, y)
+			}
+			if !same {
+				changed++
+			}
+		}
+	}
+	if changed == 0 {
+		t.Fatalf("BlurRegion did not blur the region")
+	}
+}
@@ -0,0 +1,136 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// ResizeOption is an option of Resize, either a ResizeMode or one of the
+// options such as WithoutEnlargement
+type ResizeOption interface {
+	applyResize(c *resizeConfig)
+}
+
+// resizeConfig holds the options of Resize
+type resizeConfig struct {
+	mode      ResizeMode
+	filter    *imaging.ResampleFilter
+	gravity   Gravity
+	noEnlarge bool
+	workers   int
+}
+
+// newResizeConfig returns the config set by opts, the last mode wins
+func newResizeConfig(opts []ResizeOption) resizeConfig {
+	c := resizeConfig{mode: MD_FIT}
+	for _, opt := range opts {
+		opt.applyResize(&c)
+	}
+
+	return c
+}
+
+func (m ResizeMode) applyResize(c *resizeConfig) {
+	c.mode = m
+}
+
+// resizeOptionFunc adapts a function to ResizeOption
+type resizeOptionFunc func(c *resizeConfig)
+
+func (f resizeOptionFunc) applyResize(c *resizeConfig) {
+	f(c)
+}
+
+// WithoutEnlargement keeps Resize from upscaling, the requested size is
+// reduced to the size of the image when larger. The crop modes keep the
+// requested aspect ratio
+// i.e :
+// imgr.Resize(2000, 0, imager.MD_SCALE, imager.WithoutEnlargement())
+func WithoutEnlargement() ResizeOption {
+	return resizeOptionFunc(func(c *resizeConfig) {
+		c.noEnlarge = true
+	})
+}
+
+// WithFilter sets the resampling filter of Resize, trading speed for quality
+// from imaging.NearestNeighbor, the fastest, through imaging.Box,
+// imaging.Linear and imaging.CatmullRom to imaging.Lanczos. By default
+// MD_STRETCH uses NearestNeighbor and the other modes Lanczos. The crop modes
+// don't resample and ignore it
+// i.e :
+// imgr.Resize(800, 0, imager.MD_SCALE, imager.WithFilter(imaging.CatmullRom))
+func WithFilter(filter imaging.ResampleFilter) ResizeOption {
+	return resizeOptionFunc(func(c *resizeConfig) {
+		c.filter = &filter
+	})
+}
+
+// WithGravity sets the part of the image kept by MD_CROP, the center by
+// default. The other modes ignore it
+// i.e :
+// imgr.Resize(400, 400, imager.MD_CROP, imager.WithGravity(imager.AnchorTop))
+// imgr.Resize(400, 400, imager.MD_CROP, imager.WithGravity(imager.FocalPoint(0.3, 0.6)))
+func WithGravity(gravity Gravity) ResizeOption {
+	return resizeOptionFunc(func(c *resizeConfig) {
+		c.gravity = gravity
+	})
+}
+
+// resampleFilter returns the filter set by WithFilter, or the default
+// filter of the mode
+func (c resizeConfig) resampleFilter() imaging.ResampleFilter {
+	switch {
+	case c.filter != nil:
+		return *c.filter
+	case c.mode == MD_STRETCH:
+		return imaging.NearestNeighbor
+	}
+
+	return imaging.Lanczos
+}
+
+// limit returns width x height reduced to fit within the bounds when the
+// enlargement is disabled
+func (c resizeConfig) limit(bounds image.Rectangle, width, height int) (int, int) {
+	if !c.noEnlarge {
+		return width, height
+	}
+
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	switch c.mode {
+	case MD_CROP, MD_SMART, MD_SEAM:
+		scale := math.Min(1, math.Min(float64(srcW)/float64(width), float64(srcH)/float64(height)))
+		return max(1, int(float64(width)*scale)), max(1, int(float64(height)*scale))
+	}
+
+	return min(width, srcW), min(height, srcH)
+}
+
+// resizeSizeError returns an error when width x height is not a valid size
+// for mode, Resize and the resize of Pipeline share it. Only MD_SCALE and
+// MD_STRETCH accept a zero dimension, which keeps the aspect ratio
+func resizeSizeError(width, height int, mode ResizeMode) error {
+	valid := width > 0 && height > 0
+	if mode == MD_SCALE || mode == MD_STRETCH {
+		valid = width >= 0 && height >= 0 && width+height > 0
+	}
+	if !valid {
+		return fmt.Errorf("resize to %dx%d", width, height)
+	}
+
+	return nil
+}
+
+// checkResizeSize records ErrInvalidArgument when width x height is not a
+// valid size for mode, see resizeSizeError
+func (i *Imager) checkResizeSize(width, height int, mode ResizeMode) bool {
+	if err := resizeSizeError(width, height, mode); err != nil {
+		i.setErr(fmt.Errorf("%w: %v", ErrInvalidArgument, err))
+		return false
+	}
+
+	return true
+}
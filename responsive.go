@@ -0,0 +1,166 @@
+package imager
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultResponsiveTemplate names the files of WriteResponsive when
+// Breakpoints.Template is empty
+const defaultResponsiveTemplate = "{name}@{width}w.{ext}"
+
+// Breakpoints holds the options used by WriteResponsive
+type Breakpoints struct {
+	// Widths are the widths of the images, the ones above the image width
+	// are written at its own width
+	Widths []int
+
+	// Formats are the formats written at each width, one of the IM*
+	// constants, empty means ImageType
+	Formats []string
+
+	// Template names the files, relative to the output directory, from the
+	// {name}, {width}, {height} and {ext} placeholders. Empty means
+	// {name}@{width}w.{ext}
+	Template string
+
+	// Options are the encoding options of the images
+	Options EncodeOptions
+}
+
+// ResponsiveFile is an image written by WriteResponsive
+type ResponsiveFile struct {
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+	Type   string `json:"type"`
+	Size   int    `json:"size"`
+}
+
+// ResponsiveManifest lists the images written by WriteResponsive from the
+// largest to the smallest, it marshals to JSON for the site generators
+type ResponsiveManifest struct {
+	Name   string           `json:"name"`
+	Width  int              `json:"width"`
+	Height int              `json:"height"`
+	Files  []ResponsiveFile `json:"files"`
+}
+
+// SrcSet returns the srcset attribute of the images of format, their paths
+// being joined to base
+// i.e :
+// srcset := manifest.SrcSet(imager.IMWEBP, "/img")
+func (m *ResponsiveManifest) SrcSet(format, base string) string {
+	var entries []string
+	for _, file := range m.Files {
+		if file.Format == format {
+			entries = append(entries, path.Join(base, file.Path)+" "+strconv.Itoa(file.Width)+"w")
+		}
+	}
+
+	return strings.Join(entries, ", ")
+}
+
+// WriteResponsive writes the image below dir resized to each width of bp,
+// keeping the aspect ratio, in each of its formats, and returns the manifest
+// of the files. Like GenerateSetFunc the widths are generated from the
+// largest to the smallest, each one downscaled from the previous one, and
+// the image is not modified
+// i.e :
+// manifest, err := imgr.WriteResponsive("public/img", "hero", imager.Breakpoints{Widths: []int{800, 1600}, Formats: []string{imager.IMWEBP, imager.IMJPEG}})
+// manifest, err := imgr.WriteResponsive("public/img", "hero", imager.Breakpoints{Widths: []int{640}, Template: "{width}/{name}.{ext}"})
+func (i *Imager) WriteResponsive(dir, name string, bp Breakpoints) (*ResponsiveManifest, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+
+	bounds := i.Image.Bounds()
+	widths := make([]int, len(bp.Widths))
+	for idx, width := range bp.Widths {
+		if width <= 0 {
+			return nil, fmt.Errorf("%w: width %d", ErrInvalidArgument, width)
+		}
+		widths[idx] = min(width, bounds.Dx())
+	}
+	if len(widths) == 0 {
+		return nil, fmt.Errorf("%w: no breakpoint width", ErrInvalidArgument)
+	}
+	slices.Sort(widths)
+	widths = slices.Compact(widths)
+	slices.Reverse(widths)
+
+	formats := bp.Formats
+	if len(formats) == 0 {
+		formats = []string{i.ImageType}
+	}
+	for _, format := range formats {
+		if err := i.checkEncodable(format, bp.Options); err != nil {
+			return nil, err
+		}
+	}
+
+	template := bp.Template
+	if template == "" {
+		template = defaultResponsiveTemplate
+	}
+
+	manifest := &ResponsiveManifest{Name: name, Width: bounds.Dx(), Height: bounds.Dy()}
+
+	// The steps replace the image of the copy, leaving the source untouched
+	step := *i
+	for _, width := range widths {
+		if width < step.Image.Bounds().Dx() {
+			step.Resize(width, 0, MD_SCALE)
+		}
+		height := step.Image.Bounds().Dy()
+
+		for _, format := range formats {
+			buf := bytes.NewBuffer(nil)
+			if err := step.encode(buf, format, bp.Options); err != nil {
+				return nil, err
+			}
+
+			file := strings.NewReplacer(
+				"{name}", name,
+				"{width}", strconv.Itoa(width),
+				"{height}", strconv.Itoa(height),
+				"{ext}", responsiveExt(format),
+			).Replace(template)
+			location := filepath.Join(dir, filepath.FromSlash(file))
+			if err := os.MkdirAll(filepath.Dir(location), 0o755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(location, buf.Bytes(), 0o644); err != nil {
+				return nil, err
+			}
+
+			manifest.Files = append(manifest.Files, ResponsiveFile{
+				Path:   file,
+				Width:  width,
+				Height: height,
+				Format: format,
+				Type:   mediaTypes[format],
+				Size:   buf.Len(),
+			})
+		}
+	}
+
+	return manifest, nil
+}
+
+// responsiveExt returns the file extension of format, the usual jpg for
+// JPEG images
+func responsiveExt(format string) string {
+	if format == IMJPEG {
+		return IMJPG
+	}
+
+	return format
+}
@@ -0,0 +1,61 @@
+package imager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteResponsive(t *testing.T) {
+	dir := t.TempDir()
+	imgr, _ := NewImager(createGradientImage())
+	imgr.ImageType = IMPNG
+
+	manifest, err := imgr.WriteResponsive(dir, "hero", Breakpoints{
+		Widths:  []int{40, 80, 200},
+		Formats: []string{IMWEBP, IMJPEG},
+	})
+	if err != nil {
+		t.Fatalf("WriteResponsive returned an error: %v", err)
+	}
+	if len(manifest.Files) != 6 {
+		t.Fatalf("expected 6 files, got %d", len(manifest.Files))
+	}
+
+	expected := []string{"hero@100w.webp", "hero@100w.jpg", "hero@80w.webp", "hero@80w.jpg", "hero@40w.webp", "hero@40w.jpg"}
+	for idx, file := range manifest.Files {
+		if file.Path != expected[idx] {
+			t.Fatalf("file %d: expected %s, got %s", idx, expected[idx], file.Path)
+		}
+		out, err := NewImagerFromFile(filepath.Join(dir, file.Path))
+		if err != nil {
+			t.Fatalf("%s is not a valid image: %v", file.Path, err)
+		}
+		if out.Image.Bounds().Dx() != file.Width || out.Image.Bounds().Dy() != file.Height {
+			t.Fatalf("%s: manifest size %dx%d, image %v", file.Path, file.Width, file.Height, out.Image.Bounds())
+		}
+	}
+	if manifest.Files[1].Type != "image/jpeg" || manifest.Files[1].Size == 0 {
+		t.Fatalf("unexpected file entry %+v", manifest.Files[1])
+	}
+
+	if srcset := manifest.SrcSet(IMWEBP, "/img"); srcset != "/img/hero@100w.webp 100w, /img/hero@80w.webp 80w, /img/hero@40w.webp 40w" {
+		t.Fatalf("unexpected srcset %q", srcset)
+	}
+	if imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("WriteResponsive modified the image")
+	}
+
+	manifest, err = imgr.WriteResponsive(dir, "thumb", Breakpoints{Widths: []int{20}, Template: "{width}x{height}/{name}.{ext}"})
+	if err != nil {
+		t.Fatalf("WriteResponsive returned an error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "20x20", "thumb.png")); err != nil || manifest.Files[0].Path != "20x20/thumb.png" {
+		t.Fatalf("the template was not applied: %v", err)
+	}
+
+	if _, err := imgr.WriteResponsive(dir, "hero", Breakpoints{Widths: []int{0}}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
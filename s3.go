@@ -0,0 +1,216 @@
+package imager
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Storage is a Storage backed by an S3 compatible object storage, such as
+// AWS S3, MinIO or Cloudflare R2. Requests are signed with AWS Signature
+// Version 4 and use path style URLs
+type S3Storage struct {
+	// Endpoint is the base URL of the service, such as http://localhost:9000.
+	// Defaults to https://s3.<Region>.amazonaws.com
+	Endpoint string
+
+	// Region of the bucket, us-east-1 by default
+	Region string
+
+	// Bucket holding the images. When empty the first segment of the keys is
+	// the bucket, so that s3://bucket/key locations can be used with Save
+	Bucket string
+
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is only set with temporary credentials
+	SessionToken string
+
+	// Client sends the requests, http.DefaultClient when nil
+	Client *http.Client
+}
+
+// GCSStorage returns a Storage backed by a Google Cloud Storage bucket,
+// through its S3 compatible XML API authenticated with an HMAC key
+// i.e :
+// store := imager.GCSStorage("my-bucket", accessID, secret)
+func GCSStorage(bucket, accessID, secret string) *S3Storage {
+	return &S3Storage{
+		Endpoint:        "https://storage.googleapis.com",
+		Region:          "auto",
+		Bucket:          bucket,
+		AccessKeyID:     accessID,
+		SecretAccessKey: secret,
+	}
+}
+
+// Get opens the object stored at key
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// Put uploads data at key, the Content-Type is set from the extension of key
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// do sends a signed request for the object key, returning an error when the
+// response is not successful
+func (s *S3Storage) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	bucket := s.Bucket
+	if bucket == "" {
+		bucket, key, _ = strings.Cut(key, "/")
+	}
+
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	url := strings.TrimSuffix(endpoint, "/") + "/" + awsEscape(bucket, false) + "/" + awsEscape(key, false)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPut {
+		if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+	}
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	hash := sha256.Sum256(body)
+	signV4(req, hex.EncodeToString(hash[:]), region, "s3", s.AccessKeyID, s.SecretAccessKey, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, &fs.PathError{Op: strings.ToLower(method), Path: bucket + "/" + key, Err: fs.ErrNotExist}
+	case resp.StatusCode/100 != 2:
+		resp.Body.Close()
+		return nil, fmt.Errorf("imager: %s %s/%s: %s", method, bucket, key, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// signV4 signs req with AWS Signature Version 4, every header already set
+// is signed along with the host
+func signV4(req *http.Request, payloadHash, region, service, accessKeyID, secret string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(name, true)+"="+awsEscape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.Join(pairs, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := amzDate[:8] + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{amzDate[:8], region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsEscape percent-encodes s as AWS expects, every byte but the unreserved
+// characters is encoded. Slashes are kept unless encodeSlash is set
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for idx := 0; idx < len(s); idx++ {
+		c := s[idx]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', strings.IndexByte("-._~", c) >= 0:
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// WithSanitize hardens the loading of untrusted uploads: truncated images,
+// whose decoders would return the part read, are rejected with ErrCorrupt
+// and the loaded image is sanitized, see Sanitize. Readers are read in full.
+// Combine it with WithDecodeLimits to refuse the oversized data first
+// i.e :
+// imgr, err := imager.NewImagerFromReader(r.Body, imager.WithSanitize(4096, 4096))
+// imgr, err := imager.NewImagerFromBytes(data, imager.WithDecodeLimits(limits), imager.WithSanitize(0, 0))
+func WithSanitize(maxWidth, maxHeight int) LoadOption {
+	return func(c *loadConfig) {
+		c.sanitize = true
+		c.sanitizeW, c.sanitizeH = maxWidth, maxHeight
+	}
+}
+
+// Sanitize drops everything of the source image but its pixels: the EXIF,
+// XMP and IPTC data and the ICC profile, the metadata policy being reset to
+// strip everything. The other chunks and segments of the source, such as
+// the PNG text chunks and the JPEG comments, are never written since the
+// image is always encoded again. Images larger than maxWidth x maxHeight
+// are scaled down to fit, zero leaving the dimension unbounded
+// i.e :
+// data, err := imgr.Sanitize(2048, 2048).Bytes()
+// imgr.Sanitize(0, 0)
+func (i *Imager) Sanitize(maxWidth, maxHeight int) *Imager {
+	defer i.operation("Sanitize")()
+
+	i.EXIF, i.XMP, i.IPTC, i.ICCProfile = nil, nil, nil, nil
+	i.metadata = MetadataStripAll
+
+	bounds := i.Image.Bounds()
+	if maxWidth <= 0 {
+		maxWidth = bounds.Dx()
+	}
+	if maxHeight <= 0 {
+		maxHeight = bounds.Dy()
+	}
+	if bounds.Dx() > maxWidth || bounds.Dy() > maxHeight {
+		i.Resize(maxWidth, maxHeight, MD_FIT)
+	}
+
+	return i
+}
+
+// checkComplete returns ErrCorrupt when the encoded data of a JPEG, PNG, GIF
+// or WebP image ends before the image does. The decoders stop once they
+// have the pixels they need, and some fill the missing ones
+func checkComplete(data []byte) error {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		// The end of image marker follows the scans, the segments before
+		// them may hold a whole thumbnail
+		segments := jpegSegments(data)
+		if len(segments) == 0 {
+			return fmt.Errorf("%w: jpeg without segments", ErrCorrupt)
+		}
+		if !bytes.Contains(data[segments[len(segments)-1].end:], []byte{0xFF, 0xD9}) {
+			return fmt.Errorf("%w: jpeg without end of image", ErrCorrupt)
+		}
+	case bytes.HasPrefix(data, []byte(pngSignature)):
+		complete := false
+		pngChunks(data, func(kind string, chunk []byte) bool {
+			complete = kind == "IEND"
+			return !complete
+		})
+		if !complete {
+			return fmt.Errorf("%w: png without IEND chunk", ErrCorrupt)
+		}
+	case bytes.HasPrefix(data, []byte("GIF")):
+		if data[len(data)-1] != 0x3B {
+			return fmt.Errorf("%w: gif without trailer", ErrCorrupt)
+		}
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		if int64(binary.LittleEndian.Uint32(data[4:]))+8 > int64(len(data)) {
+			return fmt.Errorf("%w: webp shorter than its header", ErrCorrupt)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	jpegData := createTestJPEG(t)
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMPNG
+	imgr.ICCProfile = []byte("profile")
+	pngData, _ := imgr.Bytes()
+	pngData = insertPNGICC(pngData, []byte("profile"))
+	gifData := createTestGIF(t)
+
+	for name, data := range map[string][]byte{"jpeg": jpegData, "png": pngData, "gif": gifData} {
+		imgr, err := NewImagerFromBytes(data, WithSanitize(30, 0))
+		if err != nil {
+			t.Fatalf("NewImagerFromBytes returned an error for a complete %s: %v", name, err)
+		}
+		if imgr.EXIF != nil || imgr.ICCProfile != nil {
+			t.Fatalf("the metadata of the %s was kept", name)
+		}
+		if imgr.Image.Bounds().Dx() != 30 {
+			t.Fatalf("the %s was not scaled down: %v", name, imgr.Image.Bounds())
+		}
+
+		// Readers are checked as well
+		truncated := data[:len(data)-4]
+		if _, err := NewImagerFromBytes(truncated, WithSanitize(0, 0)); !errors.Is(err, ErrCorrupt) {
+			t.Fatalf("NewImagerFromBytes returned %v for a truncated %s, want ErrCorrupt", err, name)
+		}
+		if _, err := NewImagerFromReader(bytes.NewReader(truncated), WithSanitize(0, 0)); !errors.Is(err, ErrCorrupt) {
+			t.Fatalf("NewImagerFromReader returned %v for a truncated %s, want ErrCorrupt", err, name)
+		}
+	}
+
+	imgr, _ = NewImagerFromBytes(jpegData)
+	imgr.XMP = []byte("<x:xmpmeta/>")
+	out, err := imgr.PreserveMetadata(true).Sanitize(0, 0).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if bytes.Contains(out, []byte(exifHeader)) || bytes.Contains(out, []byte(xmpHeader)) {
+		t.Fatalf("Sanitize kept some metadata")
+	}
+	if imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("Sanitize resized the image without bounds")
+	}
+}
@@ -0,0 +1,118 @@
+package imager
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// SeamCarve narrows the image to width and shortens it to height by removing
+// the seams, paths of pixels crossing the image, with the least detail. The
+// subjects keep their proportions unlike with a stretch. Only removing is
+// supported, sizes larger than the image are left unchanged. The cost grows
+// with the number of seams removed, see MD_SEAM to scale the image first
+// i.e :
+// imgr.SeamCarve(800, 400)
+func (i *Imager) SeamCarve(width, height int) *Imager {
+	if !i.checkResizeSize(width, height, MD_SEAM) {
+		return i
+	}
+
+	return i.apply("SeamCarve", func(img image.Image) image.Image {
+		return seamCarve(img, width, height)
+	})
+}
+
+// seamCarve returns img carved down to width x height
+func seamCarve(img image.Image, width, height int) *image.NRGBA {
+	src := imaging.Clone(img)
+	for src.Rect.Dx() > width {
+		src = removeSeam(src)
+	}
+
+	// Horizontal seams are the vertical seams of the transposed image
+	if src.Rect.Dy() > height {
+		src = imaging.Transpose(src)
+		for src.Rect.Dx() > height {
+			src = removeSeam(src)
+		}
+		src = imaging.Transpose(src)
+	}
+
+	return src
+}
+
+// removeSeam returns src without its vertical seam of least energy
+func removeSeam(src *image.NRGBA) *image.NRGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	energy := seamEnergy(src)
+
+	// cost holds the lowest energy of the seams from the top to each pixel
+	cost := make([]int, w*h)
+	copy(cost, energy[:w])
+	for y := 1; y < h; y++ {
+		for x := 0; x < w; x++ {
+			best := cost[(y-1)*w+x]
+			if x > 0 {
+				best = min(best, cost[(y-1)*w+x-1])
+			}
+			if x < w-1 {
+				best = min(best, cost[(y-1)*w+x+1])
+			}
+			cost[y*w+x] = energy[y*w+x] + best
+		}
+	}
+
+	// Walk back up from the cheapest bottom pixel
+	seam := make([]int, h)
+	for x := 1; x < w; x++ {
+		if cost[(h-1)*w+x] < cost[(h-1)*w+seam[h-1]] {
+			seam[h-1] = x
+		}
+	}
+	for y := h - 2; y >= 0; y-- {
+		x := seam[y+1]
+		seam[y] = x
+		if x > 0 && cost[y*w+x-1] < cost[y*w+seam[y]] {
+			seam[y] = x - 1
+		}
+		if x < w-1 && cost[y*w+x+1] < cost[y*w+seam[y]] {
+			seam[y] = x + 1
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w-1, h))
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		out := dst.Pix[y*dst.Stride : y*dst.Stride+(w-1)*4]
+		copy(out, row[:seam[y]*4])
+		copy(out[seam[y]*4:], row[(seam[y]+1)*4:])
+	}
+
+	return dst
+}
+
+// seamEnergy returns the gradient magnitude of each pixel of src, the sum of
+// the absolute differences between its neighbours on the luma
+func seamEnergy(src *image.NRGBA) []int {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	luma := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := src.Pix[y*src.Stride+x*4:]
+			// Transparent pixels carry no detail
+			luma[y*w+x] = (299*int(p[0]) + 587*int(p[1]) + 114*int(p[2])) * int(p[3]) / 255000
+		}
+	}
+
+	energy := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			left, right := luma[y*w+max(0, x-1)], luma[y*w+min(w-1, x+1)]
+			up, down := luma[max(0, y-1)*w+x], luma[min(h-1, y+1)*w+x]
+			energy[y*w+x] = absInt(right-left) + absInt(down-up)
+		}
+	}
+
+	return energy
+}
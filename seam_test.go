@@ -0,0 +1,39 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestSeamCarve(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 120, 40))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	red, blue := color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 255}
+	draw.Draw(img, image.Rect(10, 15, 20, 25), image.NewUniform(red), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(95, 15, 105, 25), image.NewUniform(blue), image.Point{}, draw.Src)
+
+	imgr, _ := NewImager(img)
+	imgr.SeamCarve(60, 30)
+	if b := imgr.Image.Bounds(); b.Dx() != 60 || b.Dy() != 30 {
+		t.Fatalf("SeamCarve returned %v", b)
+	}
+
+	// The squares are kept whole, only the flat background is removed
+	counts := map[color.NRGBA]int{}
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 60; x++ {
+			counts[color.NRGBAModel.Convert(imgr.Image.At(x, y)).(color.NRGBA)]++
+		}
+	}
+	if counts[red] != 100 || counts[blue] != 100 {
+		t.Fatalf("SeamCarve damaged the subjects: %d red and %d blue pixels", counts[red], counts[blue])
+	}
+
+	seam, _ := NewImager(img)
+	seam.Resize(90, 20, MD_SEAM)
+	if b := seam.Image.Bounds(); b.Dx() != 90 || b.Dy() != 20 {
+		t.Fatalf("Resize with MD_SEAM returned %v", b)
+	}
+}
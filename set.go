@@ -0,0 +1,63 @@
+package imager
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+)
+
+// GenerateSet resizes the image to each of widths, keeping the aspect ratio,
+// and returns the results encoded as ImageType by width. The image is not
+// modified, see GenerateSetFunc
+// i.e :
+// set, err := imgr.GenerateSet([]int{320, 640, 1280})
+// set, err := imgr.GenerateSet([]int{320, 640}, imager.EncodeOptions{JPEGQuality: 80})
+func (i *Imager) GenerateSet(widths []int, opts ...EncodeOptions) (map[int][]byte, error) {
+	set := make(map[int][]byte, len(widths))
+	err := i.GenerateSetFunc(widths, func(width int, data []byte) error {
+		set[width] = data
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// GenerateSetFunc resizes the image to each of widths, keeping the aspect
+// ratio, and calls fn with each result encoded as ImageType. The sizes are
+// generated from the largest to the smallest, each one downscaled from the
+// previous one. Widths above the image width get the image at its own size.
+// The image is not modified and an error returned by fn stops the set
+// i.e :
+// err := imgr.GenerateSetFunc([]int{320, 640}, upload)
+func (i *Imager) GenerateSetFunc(widths []int, fn func(width int, data []byte) error, opts ...EncodeOptions) error {
+	sorted := slices.Clone(widths)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	slices.Reverse(sorted)
+
+	if len(sorted) > 0 && sorted[len(sorted)-1] <= 0 {
+		return fmt.Errorf("%w: width %d", ErrInvalidArgument, sorted[len(sorted)-1])
+	}
+
+	// The steps replace the image of the copy, leaving the source untouched
+	step := *i
+	encodeOpts := mergeEncodeOptions(opts)
+	for _, width := range sorted {
+		if width < step.Image.Bounds().Dx() {
+			step.Resize(width, 0, MD_SCALE)
+		}
+
+		buf := bytes.NewBuffer(nil)
+		if err := step.encode(buf, step.ImageType, encodeOpts); err != nil {
+			return err
+		}
+		if err := fn(width, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package imager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateSet(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+	imgr.ImageType = IMPNG
+
+	set, err := imgr.GenerateSet([]int{20, 80, 40, 200, 40})
+	if err != nil {
+		t.Fatalf("GenerateSet returned an error: %v", err)
+	}
+	if len(set) != 4 {
+		t.Fatalf("expected 4 sizes, got %d", len(set))
+	}
+
+	expected := map[int]int{20: 20, 40: 40, 80: 80, 200: 100}
+	for width, actual := range expected {
+		out, err := NewImagerFromBytes(set[width])
+		if err != nil {
+			t.Fatalf("size %d is not a valid image: %v", width, err)
+		}
+		if out.Image.Bounds().Dx() != actual || out.Image.Bounds().Dy() != actual {
+			t.Fatalf("size %d: expected %dx%d, got %v", width, actual, actual, out.Image.Bounds())
+		}
+	}
+
+	if imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("GenerateSet modified the image")
+	}
+}
+
+func TestGenerateSetFunc(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+	imgr.ImageType = IMJPEG
+
+	var order []int
+	stop := errors.New("stop")
+	err := imgr.GenerateSetFunc([]int{10, 30, 20}, func(width int, data []byte) error {
+		order = append(order, width)
+		if width == 20 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || len(order) != 2 || order[0] != 30 {
+		t.Fatalf("unexpected result: %v, %v", order, err)
+	}
+
+	if _, err := imgr.GenerateSet([]int{0}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// SmartCrop crops the image to the width x height window holding the most
+// detail, measured as the Sobel edge energy of the grayscale image. Skin
+// tones weigh more so faces are kept when cropping portraits. Windows with
+// the same energy are resolved in favor of the one closest to the center.
+// The focal point, when set, is kept instead, see SetFocalPoint. Sizes below
+// 1 record ErrInvalidArgument
+// i.e :
+// imgr.SmartCrop(100, 100)
+func (i *Imager) SmartCrop(width, height int) *Imager {
+	defer i.operation("SmartCrop")()
+
+	if width <= 0 || height <= 0 {
+		i.setErr(fmt.Errorf("%w: crop size %dx%d", ErrInvalidArgument, width, height))
+		return i
+	}
+	if i.err != nil {
+		return i
+	}
+
+	bounds := i.Image.Bounds()
+	if width >= bounds.Dx() && height >= bounds.Dy() {
+		return i
+	}
+	if i.focal != nil {
+		return i.CropAnchor(width, height, *i.focal)
+	}
+	if width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+	if height > bounds.Dy() {
+		height = bounds.Dy()
+	}
+
+	// The window of the first frame is used for the whole animation
+	x, y := bestWindow(energyMap(i.Image), bounds.Dx(), bounds.Dy(), width, height)
+	i.cropFocal(bounds, image.Rect(x, y, x+width, y+height).Add(bounds.Min))
+	return i.apply("SmartCrop", func(img image.Image) image.Image {
+		return imaging.Crop(img, image.Rect(x, y, x+width, y+height).Add(bounds.Min))
+	})
+}
+
+// skinWeight is the energy of a pixel matching the skin tone, about a
+// quarter of the strongest edge
+const skinWeight = 512
+
+// energyMap returns the Sobel gradient magnitude of img plus the skin tone
+// bonus as a summed-area table of (w+1) x (h+1) values, so the energy of any
+// window is O(1)
+func energyMap(img image.Image) []int64 {
+	src := imaging.Clone(img)
+	gray := imaging.Grayscale(src)
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+
+	at := func(x, y int) int {
+		if x < 0 {
+			x = 0
+		} else if x >= w {
+			x = w - 1
+		}
+		if y < 0 {
+			y = 0
+		} else if y >= h {
+			y = h - 1
+		}
+		return int(gray.Pix[y*gray.Stride+x*4])
+	}
+
+	sat := make([]int64, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		var row int64
+		for x := 0; x < w; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			if gx < 0 {
+				gx = -gx
+			}
+			if gy < 0 {
+				gy = -gy
+			}
+
+			pos := y*src.Stride + x*4
+			skin := skinScore(src.Pix[pos], src.Pix[pos+1], src.Pix[pos+2])
+
+			row += int64(gx + gy + int(skin*skinWeight))
+			sat[(y+1)*(w+1)+x+1] = sat[y*(w+1)+x+1] + row
+		}
+	}
+
+	return sat
+}
+
+// skinScore returns how close a color is to the skin tone, from 0 to 1. The
+// chromaticity is compared so it works for any lightness but the darkest and
+// brightest pixels
+func skinScore(r, g, b uint8) float64 {
+	rf, gf, bf := float64(r), float64(g), float64(b)
+	mag := math.Sqrt(rf*rf + gf*gf + bf*bf)
+	if mag < 40 || mag > 420 {
+		return 0
+	}
+
+	// Normalized skin tone of smartcrop.js
+	dr, dg, db := rf/mag-0.78, gf/mag-0.57, bf/mag-0.44
+	score := 1 - math.Sqrt(dr*dr+dg*dg+db*db)
+
+	// Only colors really close to the tone count
+	const threshold = 0.9
+	if score < threshold {
+		return 0
+	}
+
+	return (score - threshold) / (1 - threshold)
+}
+
+// bestWindow returns the top left corner of the width x height window with
+// the highest energy in the summed-area table of a w x h image
+func bestWindow(sat []int64, w, h, width, height int) (int, int) {
+	stride := w + 1
+	sum := func(x, y int) int64 {
+		return sat[(y+height)*stride+x+width] - sat[y*stride+x+width] - sat[(y+height)*stride+x] + sat[y*stride+x]
+	}
+
+	// Large images don't need every single offset to be evaluated
+	stepX, stepY := max(1, (w-width)/100), max(1, (h-height)/100)
+	centerX, centerY := (w-width)/2, (h-height)/2
+
+	bestX, bestY := centerX, centerY
+	best := sum(bestX, bestY)
+	for y := 0; y <= h-height; y += stepY {
+		for x := 0; x <= w-width; x += stepX {
+			energy := sum(x, y)
+			if energy > best || (energy == best && dist(x, y, centerX, centerY) < dist(bestX, bestY, centerX, centerY)) {
+				best, bestX, bestY = energy, x, y
+			}
+		}
+	}
+
+	return bestX, bestY
+}
+
+// dist returns the squared distance between two points
+func dist(x1, y1, x2, y2 int) int {
+	return (x1-x2)*(x1-x2) + (y1-y2)*(y1-y2)
+}
@@ -0,0 +1,95 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSmartCrop(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			img.Set(x, y, color.Gray{128})
+		}
+	}
+	// High detail checkerboard blob in the bottom right corner
+	for y := 160; y < 190; y++ {
+		for x := 160; x < 190; x++ {
+			if (x/3+y/3)%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	imgr.Resize(80, 80, MD_SMART)
+
+	if imgr.Image.Bounds().Dx() != 80 || imgr.Image.Bounds().Dy() != 80 {
+		t.Fatalf("SmartCrop did not return the expected dimensions: got %v", imgr.Image.Bounds())
+	}
+
+	hist := imgr.Histogram()
+	if hist.Luma[0] == 0 || hist.Luma[255] == 0 {
+		t.Fatalf("SmartCrop window does not include the detailed blob")
+	}
+}
+
+func TestSmartCropFlat(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	img.Set(50, 50, color.White)
+
+	imgr, _ := NewImager(img)
+	imgr.SmartCrop(10, 10)
+
+	if c := imgr.Image.At(5, 5); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("SmartCrop did not keep the center of a flat image: got %v", c)
+	}
+}
+
+func TestSmartCropSkin(t *testing.T) {
+	// Two flat patches, the brighter one has more edge energy but only the
+	// other one has a skin tone
+	img := image.NewRGBA(image.Rect(0, 0, 100, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.Gray{40})
+		}
+	}
+	skin := color.RGBA{200, 146, 113, 255}
+	other := color.RGBA{140, 165, 215, 255}
+	for y := 10; y < 30; y++ {
+		for x := 10; x < 30; x++ {
+			img.Set(x, y, other)
+			img.Set(x+60, y, skin)
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	imgr.SmartCrop(40, 40)
+
+	found := false
+	bounds := imgr.Image.Bounds()
+	for x := bounds.Min.X; x < bounds.Max.X && !found; x++ {
+		found = imgr.Image.At(x, 20) == color.NRGBA{200, 146, 113, 255}
+	}
+	if !found {
+		t.Fatalf("SmartCrop did not favor the skin tone patch")
+	}
+}
+
+func TestSmartCropInvalidSize(t *testing.T) {
+	for _, size := range [][2]int{{-5, 10}, {0, 0}} {
+		imgr, _ := NewImager(createTestImage())
+		imgr.SmartCrop(size[0], size[1])
+		if !errors.Is(imgr.Err(), ErrInvalidArgument) {
+			t.Errorf("SmartCrop(%d, %d): expected ErrInvalidArgument, got %v", size[0], size[1], imgr.Err())
+		}
+		if b := imgr.Image.Bounds(); b.Dx() != 100 || b.Dy() != 100 {
+			t.Errorf("SmartCrop(%d, %d) changed the image to %v", size[0], size[1], b)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package imager
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a transformation read from a JSON or YAML document, see ParseSpec
+type Spec struct {
+	// Pipeline holds the operations, in the order of the document
+	Pipeline *Pipeline
+
+	// Format is the output format, one of the IM* constants. Empty keeps the
+	// format of the source image
+	Format string
+
+	// Options are the encoding options
+	Options EncodeOptions
+}
+
+// specParams are the parameters of an operation in a spec, w and h are
+// short for width and height
+type specParams struct {
+	W      int     `yaml:"w"`
+	H      int     `yaml:"h"`
+	Width  int     `yaml:"width"`
+	Height int     `yaml:"height"`
+	X      int     `yaml:"x"`
+	Y      int     `yaml:"y"`
+	Mode   string  `yaml:"mode"`
+	Amount float64 `yaml:"amount"`
+	Text   string  `yaml:"text"`
+	Color  string  `yaml:"color"`
+	Anchor string  `yaml:"anchor"`
+	Size   float64 `yaml:"size"`
+}
+
+// ParseSpec parses a JSON or YAML transformation. The keys are operation
+// names, as used by Pipeline, run in the document order, along with the
+// quality, format and strip_metadata settings. An operation takes either an
+// object of parameters, a number for its amount, a string for its text or
+// true when it has no parameter. The same operation can be repeated
+// i.e :
+// spec, err := imager.ParseSpec([]byte(`{"resize": {"w": 800, "mode": "fit"}, "sharpen": 0.5, "quality": 80}`))
+// spec, err := imager.ParseSpec([]byte("resize: {w: 800}\ngrayscale: true\nformat: webp"))
+// spec, err := imager.ParseSpec([]byte(`{"focal-point": {"x": 0.3, "y": 0.25}, "resize": {"w": 400, "h": 400, "mode": "crop"}}`))
+// data, err := spec.Process(imgr)
+func ParseSpec(data []byte) (*Spec, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+
+	spec := &Spec{Pipeline: NewPipeline()}
+	if len(doc.Content) == 0 {
+		return spec, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: spec must be a mapping", ErrInvalidArgument)
+	}
+
+	for k := 0; k+1 < len(root.Content); k += 2 {
+		key, value := root.Content[k].Value, root.Content[k+1]
+		if err := spec.set(key, value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidArgument, key, err)
+		}
+	}
+
+	if err := spec.Pipeline.Validate(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// set applies the key of a spec document
+func (s *Spec) set(key string, value *yaml.Node) error {
+	switch key {
+	case "quality":
+		return value.Decode(&s.Options.JPEGQuality)
+	case "format":
+		return value.Decode(&s.Format)
+	case "strip_metadata":
+		return value.Decode(&s.Options.StripMetadata)
+	}
+
+	if _, ok := pipelineOps[key]; !ok {
+		return fmt.Errorf("unknown operation")
+	}
+
+	op := Op{Name: key}
+	switch {
+	case key == "focal-point" && value.Kind == yaml.MappingNode:
+		// Fractions of the size, where the other operations take pixels
+		var point struct{ X, Y float64 }
+		if err := value.Decode(&point); err != nil {
+			return err
+		}
+		op.FocalX, op.FocalY = point.X, point.Y
+	case value.Kind == yaml.MappingNode:
+		var params specParams
+		if err := value.Decode(&params); err != nil {
+			return err
+		}
+		op.Width, op.Height = max(params.Width, params.W), max(params.Height, params.H)
+		op.X, op.Y, op.Mode, op.Amount = params.X, params.Y, params.Mode, params.Amount
+		op.Text, op.Color, op.Anchor, op.Size = params.Text, params.Color, params.Anchor, params.Size
+	case value.Kind != yaml.ScalarNode:
+		return fmt.Errorf("invalid parameters")
+	case value.Tag == "!!bool":
+		var enabled bool
+		if err := value.Decode(&enabled); err != nil || !enabled {
+			return err
+		}
+	case value.Tag == "!!int" || value.Tag == "!!float":
+		if err := value.Decode(&op.Amount); err != nil {
+			return err
+		}
+	default:
+		op.Text = value.Value
+	}
+
+	s.Pipeline.Add(op)
+	return nil
+}
+
+// Process runs the spec on imgr and returns the encoded result
+// i.e :
+// data, err := spec.Process(imgr)
+func (s *Spec) Process(imgr *Imager) ([]byte, error) {
+	if err := s.Pipeline.Run(imgr); err != nil {
+		return nil, err
+	}
+
+	format := s.Format
+	if format == "" {
+		format = imgr.ImageType
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := imgr.Encode(buf, format, s.Options); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package imager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSpecJSON(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{"resize": {"w": 40, "h": 20, "mode": "stretch"}, "rotate": 90, "grayscale": true, "invert": false, "quality": 80, "format": "png"}`))
+	if err != nil {
+		t.Fatalf("ParseSpec returned an error: %v", err)
+	}
+
+	ops := spec.Pipeline.Ops()
+	if len(ops) != 3 || ops[0].Name != "resize" || ops[1].Name != "rotate" || ops[2].Name != "grayscale" {
+		t.Fatalf("unexpected operations: %v", ops)
+	}
+	if spec.Options.JPEGQuality != 80 || spec.Format != IMPNG {
+		t.Fatalf("unexpected settings: %+v", spec)
+	}
+
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMJPEG
+	data, err := spec.Process(imgr)
+	if err != nil {
+		t.Fatalf("Process returned an error: %v", err)
+	}
+
+	out, err := NewImagerFromBytes(data)
+	if err != nil || out.ImageType != IMPNG {
+		t.Fatalf("Process did not encode as png: %v", err)
+	}
+	if out.Image.Bounds().Dx() != 20 || out.Image.Bounds().Dy() != 40 {
+		t.Fatalf("Process did not resize and rotate: got %v", out.Image.Bounds())
+	}
+}
+
+func TestParseSpecExamples(t *testing.T) {
+	// The examples of the ParseSpec documentation, from the source to the
+	// encoded result
+	examples := []struct {
+		doc  string
+		w, h int
+	}{
+		{`{"resize": {"w": 800, "mode": "fit"}, "sharpen": 0.5, "quality": 80}`, 800, 400},
+		{"resize: {w: 800}\ngrayscale: true\nformat: webp", 800, 400},
+		{`{"focal-point": {"x": 0.3, "y": 0.25}, "resize": {"w": 400, "h": 400, "mode": "crop"}}`, 400, 400},
+	}
+
+	for _, ex := range examples {
+		spec, err := ParseSpec([]byte(ex.doc))
+		if err != nil {
+			t.Fatalf("%s: ParseSpec returned an error: %v", ex.doc, err)
+		}
+
+		imgr, _ := NewImager(createPatternImage(1600, 800))
+		imgr.ImageType = IMPNG
+		data, err := spec.Process(imgr)
+		if err != nil {
+			t.Fatalf("%s: Process returned an error: %v", ex.doc, err)
+		}
+		out, err := NewImagerFromBytes(data)
+		if err != nil {
+			t.Fatalf("%s: failed to decode the result: %v", ex.doc, err)
+		}
+		if b := out.Image.Bounds(); b.Dx() != ex.w || b.Dy() != ex.h {
+			t.Errorf("%s: expected %dx%d, got %v", ex.doc, ex.w, ex.h, b)
+		}
+	}
+}
+
+func TestParseSpecYAML(t *testing.T) {
+	spec, err := ParseSpec([]byte("blur: 1.5\ntext: {text: Hello, anchor: bottom}\nsharpen: 0.5\nblur: 1\n"))
+	if err != nil {
+		t.Fatalf("ParseSpec returned an error: %v", err)
+	}
+
+	ops := spec.Pipeline.Ops()
+	if len(ops) != 4 || ops[0].Amount != 1.5 || ops[1].Text != "Hello" || ops[1].Anchor != "bottom" || ops[3].Amount != 1 {
+		t.Fatalf("unexpected operations: %v", ops)
+	}
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	tests := []string{
+		`{"explode": true}`,
+		`{"resize": {"w": 10, "mode": "fill"}}`,
+		`{"quality": "high"}`,
+		`[1, 2]`,
+		`{"resize": [1, 2]}`,
+	}
+
+	for _, doc := range tests {
+		if _, err := ParseSpec([]byte(doc)); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument for %s, got %v", doc, err)
+		}
+	}
+}
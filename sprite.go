@@ -0,0 +1,124 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SpriteOptions holds the options used by SpriteSheet
+type SpriteOptions struct {
+	// Names are the manifest names of the images, the index of the image is
+	// used when missing
+	Names []string
+
+	// Padding is the space left between the sprites, to avoid bleeding when
+	// they are scaled
+	Padding int
+
+	// MaxWidth is the width of the sheet, zero packs the sprites into a
+	// roughly square sheet
+	MaxWidth int
+}
+
+// SpriteFrame is the position of a sprite within its sheet
+type SpriteFrame struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// SpriteManifest maps the sprite names to their position in the sheet, it
+// marshals to a JSON object
+type SpriteManifest map[string]SpriteFrame
+
+// SpriteSheet packs images into a single transparent sheet, in shelves
+// filled with the tallest images first, and returns it along with the
+// position of each image
+// i.e :
+// sheet, manifest, err := imager.SpriteSheet(icons, imager.SpriteOptions{Names: names, Padding: 2})
+// data, err := json.Marshal(manifest)
+func SpriteSheet(images []image.Image, opts SpriteOptions) (*Imager, SpriteManifest, error) {
+	if len(images) == 0 || opts.Padding < 0 {
+		return nil, nil, fmt.Errorf("%w: sprite sheet of %d images with a padding of %d", ErrInvalidArgument, len(images), opts.Padding)
+	}
+
+	names := make([]string, len(images))
+	area, widest := 0, 0
+	for idx, img := range images {
+		names[idx] = strconv.Itoa(idx)
+		if idx < len(opts.Names) {
+			names[idx] = opts.Names[idx]
+		}
+
+		size := img.Bounds().Size().Add(image.Pt(opts.Padding, opts.Padding))
+		area += size.X * size.Y
+		widest = max(widest, size.X)
+	}
+
+	width := opts.MaxWidth
+	if width <= 0 {
+		width = max(widest, int(math.Ceil(math.Sqrt(float64(area)))))
+	}
+	if widest-opts.Padding > width {
+		return nil, nil, fmt.Errorf("%w: sprite of width %d wider than the sheet", ErrInvalidArgument, widest-opts.Padding)
+	}
+
+	// Tallest first so the shelves waste little height
+	order := make([]int, len(images))
+	for idx := range order {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return images[order[a]].Bounds().Dy() > images[order[b]].Bounds().Dy()
+	})
+
+	manifest := make(SpriteManifest, len(images))
+	x, y, shelf, sheetW := 0, 0, 0, 0
+	for _, idx := range order {
+		size := images[idx].Bounds().Size()
+		if x > 0 && x+size.X > width {
+			x, y, shelf = 0, y+shelf+opts.Padding, 0
+		}
+
+		manifest[names[idx]] = SpriteFrame{X: x, Y: y, W: size.X, H: size.Y}
+		sheetW = max(sheetW, x+size.X)
+		shelf = max(shelf, size.Y)
+		x += size.X + opts.Padding
+	}
+
+	sheet := image.NewNRGBA(image.Rect(0, 0, sheetW, y+shelf))
+	for idx, img := range images {
+		frame := manifest[names[idx]]
+		draw.Draw(sheet, image.Rect(frame.X, frame.Y, frame.X+frame.W, frame.Y+frame.H), img, img.Bounds().Min, draw.Src)
+	}
+
+	imgr, err := NewImager(sheet)
+	return imgr, manifest, err
+}
+
+// CSS returns a stylesheet with a class per sprite, named prefix-name,
+// showing the sprite from the sheet at url
+// i.e :
+// css := manifest.CSS("icon", "/static/icons.png")
+func (m SpriteManifest) CSS(prefix, url string) string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		frame := m[name]
+		fmt.Fprintf(&b, ".%s-%s { background: url(%q) -%dpx -%dpx no-repeat; width: %dpx; height: %dpx; }\n",
+			prefix, name, url, frame.X, frame.Y, frame.W, frame.H)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package imager
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func TestSpriteSheet(t *testing.T) {
+	images := []image.Image{
+		imaging.New(10, 10, color.NRGBA{255, 0, 0, 255}),
+		imaging.New(20, 30, color.NRGBA{0, 255, 0, 255}),
+		imaging.New(15, 5, color.NRGBA{0, 0, 255, 255}),
+		imaging.New(30, 20, color.NRGBA{255, 255, 0, 255}),
+	}
+
+	sheet, manifest, err := SpriteSheet(images, SpriteOptions{Names: []string{"red", "green", "blue"}, Padding: 1})
+	if err != nil {
+		t.Fatalf("SpriteSheet returned an error: %v", err)
+	}
+	if len(manifest) != 4 {
+		t.Fatalf("SpriteSheet returned %d frames, want 4", len(manifest))
+	}
+
+	// Every sprite is copied at its frame, without overlapping the others
+	bounds := sheet.Image.Bounds()
+	var frames []image.Rectangle
+	for idx, name := range []string{"red", "green", "blue", "3"} {
+		frame, ok := manifest[name]
+		if !ok {
+			t.Fatalf("SpriteSheet manifest has no frame %q", name)
+		}
+
+		rect := image.Rect(frame.X, frame.Y, frame.X+frame.W, frame.Y+frame.H)
+		if rect.Size() != images[idx].Bounds().Size() || !rect.In(bounds) {
+			t.Fatalf("SpriteSheet frame %q is %v within %v", name, rect, bounds)
+		}
+		for _, other := range frames {
+			if rect.Overlaps(other.Inset(-1)) {
+				t.Fatalf("SpriteSheet frame %q overlaps %v", name, other)
+			}
+		}
+		frames = append(frames, rect)
+
+		if c := pixel(sheet.Image, rect.Max.X-1, rect.Max.Y-1); c != pixel(images[idx], 0, 0) {
+			t.Fatalf("SpriteSheet frame %q holds %v", name, c)
+		}
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil || !strings.Contains(string(data), `"red":{"x":`) {
+		t.Fatalf("SpriteManifest marshaled to %s, %v", data, err)
+	}
+
+	css := manifest.CSS("icon", "icons.png")
+	if !strings.Contains(css, `.icon-red { background: url("icons.png")`) || strings.Count(css, "\n") != 4 {
+		t.Fatalf("SpriteManifest.CSS returned %q", css)
+	}
+
+	if _, _, err := SpriteSheet(images, SpriteOptions{MaxWidth: 20}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("SpriteSheet returned %v for a sheet too narrow, want %v", err, ErrInvalidArgument)
+	}
+}
@@ -0,0 +1,124 @@
+package imager
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Storage reads and writes encoded images by key, such as the objects of a
+// bucket or the files below a directory
+type Storage interface {
+	// Get opens the image stored at key, errors wrap fs.ErrNotExist when
+	// there is none
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Put stores data at key, replacing any previous image
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// DirStorage returns a Storage keeping the images below the root directory,
+// the keys are slash separated paths relative to root
+// i.e :
+// store := imager.DirStorage("/var/images")
+func DirStorage(root string) Storage {
+	return dirStorage(root)
+}
+
+// dirStorage is the Storage returned by DirStorage
+type dirStorage string
+
+func (d dirStorage) path(key string) string {
+	// Cleaning as an absolute path keeps the key within root
+	return filepath.Join(string(d), filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (d dirStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return os.Open(d.path(key))
+}
+
+func (d dirStorage) Put(ctx context.Context, key string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	location := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(location), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(location, data, 0o644)
+}
+
+var (
+	storagesMu sync.RWMutex
+	storages   = map[string]Storage{}
+)
+
+// RegisterStorage makes the locations starting with scheme:// refer to the
+// keys of store, for NewImagerFromFile and Save. Registering a nil store
+// removes the scheme
+// i.e :
+// imager.RegisterStorage("s3", &imager.S3Storage{Region: "eu-west-1", AccessKeyID: id, SecretAccessKey: secret})
+// err := imgr.Save("s3://bucket/thumbs/photo.jpg")
+func RegisterStorage(scheme string, store Storage) {
+	storagesMu.Lock()
+	defer storagesMu.Unlock()
+
+	if store == nil {
+		delete(storages, scheme)
+		return
+	}
+	storages[scheme] = store
+}
+
+// storageFor returns the registered storage and the key location refers to,
+// ok is false for plain file paths
+func storageFor(location string) (store Storage, key string, ok bool) {
+	scheme, key, found := strings.Cut(location, "://")
+	if !found {
+		return nil, "", false
+	}
+
+	storagesMu.RLock()
+	defer storagesMu.RUnlock()
+
+	store, ok = storages[scheme]
+	return store, key, ok
+}
+
+// NewImagerFromStorage creates a new Imager from the image stored at key
+// i.e :
+// imgr, err := imager.NewImagerFromStorage(ctx, store, "uploads/photo.jpg")
+// imgr, err := imager.NewImagerFromStorage(ctx, store, "uploads/photo.jpg", imager.WithAutoOrient())
+func NewImagerFromStorage(ctx context.Context, store Storage, key string, opts ...LoadOption) (*Imager, error) {
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return NewImagerFromReader(r, opts...)
+}
+
+// SaveTo stores the image at key, the format is chosen from the extension
+// of key as with Save
+// i.e :
+// err := imgr.SaveTo(ctx, store, "thumbs/photo.webp")
+// err := imgr.SaveTo(ctx, store, "thumbs/photo.jpg", imager.EncodeOptions{JPEGQuality: 80})
+func (i *Imager) SaveTo(ctx context.Context, store Storage, key string, opts ...EncodeOptions) error {
+	data, err := i.encodeFor(key, opts)
+	if err != nil {
+		return err
+	}
+
+	return store.Put(ctx, key, data)
+}
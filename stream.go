@@ -0,0 +1,179 @@
+package imager
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+)
+
+// pngIHDREnd is the length of the PNG signature and IHDR chunk, which start
+// every PNG stream
+const pngIHDREnd = len(pngSignature) + 12 + 13
+
+// pngIDATSize is the largest IDAT chunk written by the streaming encoder
+const pngIDATSize = 1 << 16
+
+// StreamResize resizes the image like ResizeTiled and writes the result to w
+// as PNG band by band, tileSize rows at a time. Neither the resized image nor
+// its encoding are held in memory, only the source and a band, which suits
+// very large outputs such as print or map renders. The options set the PNG
+// compression, the image itself is left as is
+// i.e :
+// err := imgr.StreamResize(w, 20000, 0, 256)
+// err := imgr.StreamResize(file, 12000, 8000, 512, imager.EncodeOptions{PNGCompression: png.BestSpeed})
+func (i *Imager) StreamResize(w io.Writer, width, height, tileSize int, opts ...EncodeOptions) error {
+	if i.err != nil {
+		return i.err
+	}
+	outW, outH, ok := tiledSize(i.Image, width, height)
+	if !ok {
+		return fmt.Errorf("%w: size %dx%d", ErrInvalidArgument, width, height)
+	}
+	width, height = outW, outH
+
+	var chunks [][]byte
+	if len(i.ICCProfile) > 0 {
+		chunks = append(chunks, iccPNGChunk(i.ICCProfile))
+	}
+	enc, err := newPNGStreamEncoder(w, width, height, !isOpaque(i.Image), mergeEncodeOptions(opts), chunks)
+	if err != nil {
+		return err
+	}
+
+	if err := resizeBands(i.Image, width, height, tileSize, enc.writeRows); err != nil {
+		return err
+	}
+
+	return enc.close()
+}
+
+// pngStreamEncoder writes a PNG stream row by row, the rows being 8-bit RGB
+// or RGBA
+type pngStreamEncoder struct {
+	w    io.Writer
+	zw   *zlib.Writer
+	idat *bufio.Writer
+	bpp  int
+	row  []byte
+	prev []byte
+}
+
+// newPNGStreamEncoder writes the PNG header, followed by the ancillary
+// chunks, and returns the encoder of the rows
+func newPNGStreamEncoder(w io.Writer, width, height int, alpha bool, opts EncodeOptions, chunks [][]byte) (*pngStreamEncoder, error) {
+	colorType, bpp := byte(2), 3
+	if alpha {
+		colorType, bpp = 6, 4
+	}
+
+	ihdr := binary.BigEndian.AppendUint32(nil, uint32(width))
+	ihdr = binary.BigEndian.AppendUint32(ihdr, uint32(height))
+	ihdr = append(ihdr, 8, colorType, 0, 0, 0)
+
+	header := append([]byte(pngSignature), pngChunk("IHDR", ihdr)...)
+	for _, chunk := range chunks {
+		header = append(header, chunk...)
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	e := &pngStreamEncoder{
+		w:    w,
+		bpp:  bpp,
+		row:  make([]byte, width*bpp),
+		prev: make([]byte, width*bpp),
+	}
+	e.idat = bufio.NewWriterSize(idatWriter{w}, pngIDATSize)
+
+	zw, err := zlib.NewWriterLevel(e.idat, zlibLevel(opts.PNGCompression))
+	if err != nil {
+		return nil, err
+	}
+	e.zw = zw
+
+	return e, nil
+}
+
+// writeRows writes the rows of band
+func (e *pngStreamEncoder) writeRows(band *image.NRGBA) error {
+	width := band.Rect.Dx()
+	for y := 0; y < band.Rect.Dy(); y++ {
+		pix := band.Pix[y*band.Stride : y*band.Stride+width*4]
+		if e.bpp == 4 {
+			copy(e.row, pix)
+		} else {
+			for x := 0; x < width; x++ {
+				copy(e.row[x*3:x*3+3], pix[x*4:])
+			}
+		}
+
+		if _, err := e.zw.Write(filterRow(e.row, e.prev, e.bpp)); err != nil {
+			return err
+		}
+		e.prev, e.row = e.row, e.prev
+	}
+
+	return nil
+}
+
+// close ends the compressed data and the stream
+func (e *pngStreamEncoder) close() error {
+	if err := e.zw.Close(); err != nil {
+		return err
+	}
+	if err := e.idat.Flush(); err != nil {
+		return err
+	}
+
+	_, err := e.w.Write(pngChunk("IEND", nil))
+	return err
+}
+
+// idatWriter writes each call as an IDAT chunk
+type idatWriter struct {
+	w io.Writer
+}
+
+func (d idatWriter) Write(p []byte) (int, error) {
+	if _, err := d.w.Write(pngChunk("IDAT", p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// pngICCWriter inserts an iCCP chunk holding profile after the IHDR chunk of
+// the PNG stream written through it, only the signature and IHDR chunk are
+// buffered
+type pngICCWriter struct {
+	w       io.Writer
+	profile []byte
+	head    []byte
+	done    bool
+}
+
+func (p *pngICCWriter) Write(b []byte) (int, error) {
+	if p.done {
+		return p.w.Write(b)
+	}
+
+	take := min(pngIHDREnd-len(p.head), len(b))
+	p.head = append(p.head, b[:take]...)
+	if len(p.head) < pngIHDREnd {
+		return len(b), nil
+	}
+
+	p.done = true
+	if _, err := p.w.Write(insertPNGICC(p.head, p.profile)); err != nil {
+		return 0, err
+	}
+	if _, err := p.w.Write(b[take:]); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
@@ -0,0 +1,97 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStreamResize(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+	imgr.ICCProfile = []byte("profile")
+
+	buf := bytes.NewBuffer(nil)
+	if err := imgr.StreamResize(buf, 70, 0, 8); err != nil {
+		t.Fatalf("StreamResize returned an error: %v", err)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("StreamResize wrote an invalid PNG: %v", err)
+	}
+	if icc := pngICC(buf.Bytes()); string(icc) != "profile" {
+		t.Fatalf("StreamResize did not embed the ICC profile: %q", icc)
+	}
+
+	tiled, _ := NewImager(createGradientImage())
+	tiled.ResizeTiled(70, 0, 8)
+	if decoded.Bounds() != tiled.Image.Bounds() {
+		t.Fatalf("StreamResize wrote %v, want %v", decoded.Bounds(), tiled.Image.Bounds())
+	}
+	for y := 0; y < 70; y++ {
+		for x := 0; x < 70; x++ {
+			if got, want := pixel(decoded, x, y), pixel(tiled.Image, x, y); got != want {
+				t.Fatalf("StreamResize wrote %v at %d,%d, want %v", got, x, y, want)
+			}
+		}
+	}
+	if imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("StreamResize changed the image")
+	}
+
+	// Transparent images keep their alpha
+	transparent, _ := NewImager(image.NewNRGBA(image.Rect(0, 0, 10, 10)))
+	buf.Reset()
+	if err := transparent.StreamResize(buf, 5, 5, 0); err != nil {
+		t.Fatalf("StreamResize returned an error: %v", err)
+	}
+	if decoded, err := png.Decode(buf); err != nil || pixel(decoded, 2, 2) != (color.NRGBA{}) {
+		t.Fatalf("StreamResize did not keep the alpha: %v", err)
+	}
+
+	if err := imgr.StreamResize(buf, 0, 0, 8); err == nil {
+		t.Fatalf("StreamResize did not return an error for a zero size")
+	}
+	if err := imgr.StreamResize(buf, -10, 5, 8); err == nil || !strings.Contains(err.Error(), "-10x5") {
+		t.Fatalf("StreamResize did not report the requested size: %v", err)
+	}
+
+	// Nothing is written after a failed operation
+	failed, _ := NewImager(createGradientImage())
+	failed.Crop(500, 500, 0, 0)
+	buf.Reset()
+	if err := failed.StreamResize(buf, 50, 0, 8); !errors.Is(err, ErrOutOfBounds) || buf.Len() != 0 {
+		t.Fatalf("StreamResize wrote %d bytes after a failed operation: %v", buf.Len(), err)
+	}
+}
+
+func TestSaveLeavesNoTemporaryFile(t *testing.T) {
+	dir := t.TempDir()
+	imgr, _ := NewImager(createTestImage())
+	imgr.ICCProfile = []byte("profile")
+
+	location := filepath.Join(dir, "out.png")
+	if err := imgr.Save(location); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	data, err := os.ReadFile(location)
+	if err != nil {
+		t.Fatalf("Save did not write the file: %v", err)
+	}
+	if icc := pngICC(data); string(icc) != "profile" {
+		t.Fatalf("Save did not embed the ICC profile: %q", icc)
+	}
+
+	if err := imgr.Save(filepath.Join(dir, "out.unknown")); err == nil {
+		t.Fatalf("Save did not return an error for an unknown format")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("Save left %d files behind", len(entries))
+	}
+}
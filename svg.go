@@ -0,0 +1,916 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/vector"
+)
+
+// NewImagerFromSVG rasterizes the SVG document in data to width x height
+// pixels. With a single dimension the aspect ratio of the document is kept,
+// with none its own size is used. Shapes, paths, groups and transforms are
+// drawn with solid fills and strokes, gradients, text and filters are skipped.
+// The ImageType is PNG, which keeps the transparency
+// i.e :
+// imgr, err := imager.NewImagerFromSVG(logo, 512, 0)
+// imgr, err := imager.NewImagerFromSVG(icon, 64, 64, imager.WithDecodeLimits(imager.DecodeLimits{MaxPixels: 4_000_000}))
+func NewImagerFromSVG(data []byte, width, height int, opts ...LoadOption) (*Imager, error) {
+	config := newLoadConfig(opts)
+	if err := config.limits.checkSize(int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root, err := svgRoot(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	docW, docH, viewBox := svgSize(root)
+	switch {
+	case width <= 0 && height <= 0:
+		width, height = int(math.Ceil(docW)), int(math.Ceil(docH))
+	case width <= 0:
+		width = max(1, int(float64(height)*docW/docH+0.5))
+	case height <= 0:
+		height = max(1, int(float64(width)*docH/docW+0.5))
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%w: empty SVG document", ErrInvalidArgument)
+	}
+	if err := config.limits.checkConfig(image.Config{Width: width, Height: height}); err != nil {
+		return nil, err
+	}
+
+	// The viewBox is centered within the image keeping its aspect ratio
+	var transform svgMatrix
+	if viewBox != nil {
+		scale := math.Min(float64(width)/viewBox[2], float64(height)/viewBox[3])
+		tx := (float64(width)-viewBox[2]*scale)/2 - viewBox[0]*scale
+		ty := (float64(height)-viewBox[3]*scale)/2 - viewBox[1]*scale
+		transform = svgMatrix{scale, 0, 0, scale, tx, ty}
+	} else {
+		transform = svgMatrix{float64(width) / docW, 0, 0, float64(height) / docH, 0, 0}
+	}
+
+	r := &svgRenderer{canvas: image.NewRGBA(image.Rect(0, 0, width, height))}
+	style := defaultSVGStyle
+	style.transform = transform
+	if err := r.render(decoder, root, style.inherit(root.Attr)); err != nil {
+		return nil, err
+	}
+
+	imgr := &Imager{Image: r.canvas, ImageType: IMPNG}
+	imgr.snapshot()
+	return imgr.applyLoadOptions(config), nil
+}
+
+// svgRoot returns the root svg element of the document read by decoder
+func svgRoot(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, fmt.Errorf("%w: not an SVG document: %v", ErrUnknownFormat, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "svg" {
+				return xml.StartElement{}, fmt.Errorf("%w: not an SVG document", ErrUnknownFormat)
+			}
+			return start, nil
+		}
+	}
+}
+
+// svgSize returns the size of the document from the attributes of its root
+// element, along with its viewBox if any
+func svgSize(root xml.StartElement) (float64, float64, []float64) {
+	viewBox := svgNumbers(svgAttr(root.Attr, "viewBox"))
+	if len(viewBox) != 4 || viewBox[2] <= 0 || viewBox[3] <= 0 {
+		viewBox = nil
+	}
+
+	width, height := svgLength(svgAttr(root.Attr, "width")), svgLength(svgAttr(root.Attr, "height"))
+	switch {
+	case width > 0 && height > 0:
+	case viewBox != nil && width > 0:
+		height = width * viewBox[3] / viewBox[2]
+	case viewBox != nil && height > 0:
+		width = height * viewBox[2] / viewBox[3]
+	case viewBox != nil:
+		width, height = viewBox[2], viewBox[3]
+	default:
+		width, height = 300, 150
+	}
+
+	return width, height, viewBox
+}
+
+// svgSkipped are the elements whose content is not drawn
+var svgSkipped = map[string]bool{
+	"defs": true, "clipPath": true, "mask": true, "symbol": true, "style": true,
+	"title": true, "desc": true, "metadata": true, "linearGradient": true,
+	"radialGradient": true, "pattern": true, "marker": true, "text": true,
+}
+
+// svgRenderer draws the elements of an SVG document on canvas
+type svgRenderer struct {
+	canvas *image.RGBA
+	raster vector.Rasterizer
+}
+
+// render draws the children of parent, up to its end element
+func (r *svgRenderer) render(decoder *xml.Decoder, parent xml.StartElement, style svgStyle) error {
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: invalid SVG document: %v", ErrUnknownFormat, err)
+		}
+
+		switch token := token.(type) {
+		case xml.EndElement:
+			if token.Name.Local == parent.Name.Local {
+				return nil
+			}
+		case xml.StartElement:
+			if svgSkipped[token.Name.Local] || svgAttr(token.Attr, "display") == "none" {
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			childStyle := style.inherit(token.Attr)
+			if subpaths := svgShape(token); subpaths != nil {
+				r.draw(subpaths, childStyle)
+			}
+			if err := r.render(decoder, token, childStyle); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// draw fills then strokes the subpaths, given in user space
+func (r *svgRenderer) draw(subpaths []svgSubpath, style svgStyle) {
+	for idx := range subpaths {
+		for p := range subpaths[idx].points {
+			subpaths[idx].points[p] = style.transform.apply(subpaths[idx].points[p])
+		}
+	}
+
+	if style.fill != nil {
+		r.begin()
+		for _, subpath := range subpaths {
+			r.polygon(subpath.points)
+		}
+		r.paint(style.fill, style.opacity*style.fillOpacity)
+	}
+
+	width := style.strokeWidth * math.Sqrt(math.Abs(style.transform.det()))
+	if style.stroke != nil && width > 0 {
+		r.begin()
+		for _, subpath := range subpaths {
+			r.stroke(subpath, width/2)
+		}
+		r.paint(style.stroke, style.opacity*style.strokeOpacity)
+	}
+}
+
+// begin clears the rasterizer
+func (r *svgRenderer) begin() {
+	b := r.canvas.Bounds()
+	r.raster.Reset(b.Dx(), b.Dy())
+}
+
+// paint composites the rasterized area over the canvas with c
+func (r *svgRenderer) paint(c color.Color, opacity float64) {
+	cr, cg, cb, ca := c.RGBA()
+	alpha := math.Max(0, math.Min(1, opacity)) * float64(ca) / 0xffff
+	src := color.NRGBA64{uint16(cr), uint16(cg), uint16(cb), uint16(alpha * 0xffff)}
+	if ca > 0 {
+		// Un-premultiply, c.RGBA() is alpha premultiplied
+		src.R, src.G, src.B = uint16(cr*0xffff/ca), uint16(cg*0xffff/ca), uint16(cb*0xffff/ca)
+	}
+
+	r.raster.Draw(r.canvas, r.canvas.Bounds(), image.NewUniform(src), image.Point{})
+}
+
+// polygon adds the closed polygon points to the rasterizer
+func (r *svgRenderer) polygon(points []svgPoint) {
+	if len(points) < 3 {
+		return
+	}
+
+	r.raster.MoveTo(float32(points[0].x), float32(points[0].y))
+	for _, p := range points[1:] {
+		r.raster.LineTo(float32(p.x), float32(p.y))
+	}
+	r.raster.ClosePath()
+}
+
+// stroke adds the outline of subpath, half width wide on each side, with
+// butt caps and miter joins. The pieces all wind the same way so that their
+// overlaps add up instead of cancelling out
+func (r *svgRenderer) stroke(subpath svgSubpath, half float64) {
+	points := subpath.points
+	if subpath.closed && len(points) > 1 && points[0] != points[len(points)-1] {
+		points = append(points, points[0])
+	}
+
+	type segment struct{ a, b, dir, normal svgPoint }
+	var segments []segment
+	for idx := 1; idx < len(points); idx++ {
+		a, b := points[idx-1], points[idx]
+		length := math.Hypot(b.x-a.x, b.y-a.y)
+		if length == 0 {
+			continue
+		}
+		dir := svgPoint{(b.x - a.x) / length, (b.y - a.y) / length}
+		segments = append(segments, segment{a, b, dir, svgPoint{-dir.y, dir.x}})
+	}
+
+	for _, s := range segments {
+		n := s.normal.scale(half)
+		r.polygon(clockwise([]svgPoint{s.a.add(n), s.b.add(n), s.b.sub(n), s.a.sub(n)}))
+	}
+
+	joins := len(segments) - 1
+	if subpath.closed {
+		joins = len(segments)
+	}
+	for idx := 0; idx < joins; idx++ {
+		in, out := segments[idx], segments[(idx+1)%len(segments)]
+		turn := in.normal.x*out.dir.x + in.normal.y*out.dir.y
+		if turn == 0 {
+			continue
+		}
+
+		// The join is on the outer side of the turn
+		side := -math.Copysign(1, turn)
+		v := out.a
+		p1, p2 := v.add(in.normal.scale(side*half)), v.add(out.normal.scale(side*half))
+		bisector := in.normal.add(out.normal)
+		length := bisector.x*bisector.x + bisector.y*bisector.y
+		if length > 0.25 {
+			// Within the default miter limit of 4
+			miter := v.add(bisector.scale(side * 2 * half / length))
+			r.polygon(clockwise([]svgPoint{v, p1, miter, p2}))
+		} else {
+			r.polygon(clockwise([]svgPoint{v, p1, p2}))
+		}
+	}
+}
+
+// clockwise returns points ordered clockwise, on screen
+func clockwise(points []svgPoint) []svgPoint {
+	area := 0.0
+	for idx, p := range points {
+		q := points[(idx+1)%len(points)]
+		area += p.x*q.y - q.x*p.y
+	}
+	if area < 0 {
+		for a, b := 0, len(points)-1; a < b; a, b = a+1, b-1 {
+			points[a], points[b] = points[b], points[a]
+		}
+	}
+
+	return points
+}
+
+// svgShape returns the subpaths of the shape element start, in user space,
+// or nil when start is not a shape
+func svgShape(start xml.StartElement) []svgSubpath {
+	attr := func(name string) float64 {
+		return svgLength(svgAttr(start.Attr, name))
+	}
+
+	p := &svgPath{}
+	switch start.Name.Local {
+	case "path":
+		p.parse(svgAttr(start.Attr, "d"))
+	case "rect":
+		x, y, w, h := attr("x"), attr("y"), attr("width"), attr("height")
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+
+		rx, ry := attr("rx"), attr("ry")
+		if rx <= 0 {
+			rx = ry
+		}
+		if ry <= 0 {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+
+		if rx <= 0 {
+			p.moveTo(svgPoint{x, y})
+			p.lineTo(svgPoint{x + w, y})
+			p.lineTo(svgPoint{x + w, y + h})
+			p.lineTo(svgPoint{x, y + h})
+		} else {
+			p.moveTo(svgPoint{x + rx, y})
+			p.ellipse(svgPoint{x + w - rx, y + ry}, rx, ry, -math.Pi/2, 0)
+			p.ellipse(svgPoint{x + w - rx, y + h - ry}, rx, ry, 0, math.Pi/2)
+			p.ellipse(svgPoint{x + rx, y + h - ry}, rx, ry, math.Pi/2, math.Pi)
+			p.ellipse(svgPoint{x + rx, y + ry}, rx, ry, math.Pi, 3*math.Pi/2)
+		}
+		p.close()
+	case "circle", "ellipse":
+		rx, ry := attr("rx"), attr("ry")
+		if start.Name.Local == "circle" {
+			rx, ry = attr("r"), attr("r")
+		}
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+
+		center := svgPoint{attr("cx"), attr("cy")}
+		p.moveTo(center.add(svgPoint{rx, 0}))
+		p.ellipse(center, rx, ry, 0, 2*math.Pi)
+		p.close()
+	case "line":
+		p.moveTo(svgPoint{attr("x1"), attr("y1")})
+		p.lineTo(svgPoint{attr("x2"), attr("y2")})
+	case "polyline", "polygon":
+		coords := svgNumbers(svgAttr(start.Attr, "points"))
+		for idx := 0; idx+1 < len(coords); idx += 2 {
+			if idx == 0 {
+				p.moveTo(svgPoint{coords[0], coords[1]})
+			} else {
+				p.lineTo(svgPoint{coords[idx], coords[idx+1]})
+			}
+		}
+		if start.Name.Local == "polygon" {
+			p.close()
+		}
+	default:
+		return nil
+	}
+
+	return p.subpaths
+}
+
+// svgPoint is a point of a path
+type svgPoint struct{ x, y float64 }
+
+func (p svgPoint) add(q svgPoint) svgPoint     { return svgPoint{p.x + q.x, p.y + q.y} }
+func (p svgPoint) sub(q svgPoint) svgPoint     { return svgPoint{p.x - q.x, p.y - q.y} }
+func (p svgPoint) scale(s float64) svgPoint    { return svgPoint{p.x * s, p.y * s} }
+func (p svgPoint) reflect(c svgPoint) svgPoint { return p.add(p.sub(c)) }
+func (p svgPoint) lerp(q svgPoint, t float64) svgPoint {
+	return svgPoint{p.x + (q.x-p.x)*t, p.y + (q.y-p.y)*t}
+}
+
+// svgSubpath is a polyline, curves are flattened into line segments
+type svgSubpath struct {
+	points []svgPoint
+	closed bool
+}
+
+// svgCurveSteps is the number of line segments a curve is flattened into
+const svgCurveSteps = 16
+
+// svgPath builds the subpaths of a path
+type svgPath struct {
+	subpaths   []svgSubpath
+	cur, start svgPoint
+
+	// ctrl is the last control point, reflected by the S and T commands
+	ctrl svgPoint
+}
+
+func (p *svgPath) moveTo(pt svgPoint) {
+	p.subpaths = append(p.subpaths, svgSubpath{points: []svgPoint{pt}})
+	p.cur, p.start, p.ctrl = pt, pt, pt
+}
+
+func (p *svgPath) lineTo(pt svgPoint) {
+	if len(p.subpaths) == 0 || p.subpaths[len(p.subpaths)-1].closed {
+		// Drawing after a close starts a new subpath at the same point
+		cur := p.cur
+		p.moveTo(cur)
+	}
+
+	last := &p.subpaths[len(p.subpaths)-1]
+	last.points = append(last.points, pt)
+	p.cur, p.ctrl = pt, pt
+}
+
+func (p *svgPath) close() {
+	if len(p.subpaths) > 0 {
+		p.subpaths[len(p.subpaths)-1].closed = true
+	}
+	p.cur, p.ctrl = p.start, p.start
+}
+
+// cubicTo flattens the cubic Bézier curve from the current point
+func (p *svgPath) cubicTo(c1, c2, end svgPoint) {
+	start := p.cur
+	for step := 1; step <= svgCurveSteps; step++ {
+		t := float64(step) / svgCurveSteps
+		a, b, c := start.lerp(c1, t), c1.lerp(c2, t), c2.lerp(end, t)
+		p.lineTo(a.lerp(b, t).lerp(b.lerp(c, t), t))
+	}
+	p.ctrl = c2
+}
+
+// quadTo flattens the quadratic Bézier curve from the current point
+func (p *svgPath) quadTo(c, end svgPoint) {
+	start := p.cur
+	for step := 1; step <= svgCurveSteps; step++ {
+		t := float64(step) / svgCurveSteps
+		p.lineTo(start.lerp(c, t).lerp(c.lerp(end, t), t))
+	}
+	p.ctrl = c
+}
+
+// ellipse flattens the arc of the axis aligned ellipse from angle from to to
+func (p *svgPath) ellipse(center svgPoint, rx, ry, from, to float64) {
+	p.arcPoints(center, rx, ry, 0, from, to-from)
+}
+
+// arcPoints flattens an elliptical arc rotated by phi, starting at angle
+// theta and spanning delta radians
+func (p *svgPath) arcPoints(center svgPoint, rx, ry, phi, theta, delta float64) {
+	steps := max(1, int(math.Ceil(math.Abs(delta)/(math.Pi/16))))
+	sinPhi, cosPhi := math.Sincos(phi)
+	for step := 1; step <= steps; step++ {
+		sin, cos := math.Sincos(theta + delta*float64(step)/float64(steps))
+		p.lineTo(svgPoint{
+			center.x + rx*cos*cosPhi - ry*sin*sinPhi,
+			center.y + rx*cos*sinPhi + ry*sin*cosPhi,
+		})
+	}
+}
+
+// arcTo flattens the SVG elliptical arc from the current point to end, as
+// described by the A path command
+func (p *svgPath) arcTo(rx, ry, rotation float64, large, sweep bool, end svgPoint) {
+	start := p.cur
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.lineTo(end)
+		return
+	}
+	if start == end {
+		return
+	}
+
+	// Conversion from the endpoint to the center parameterization
+	phi := rotation * math.Pi / 180
+	sinPhi, cosPhi := math.Sincos(phi)
+	dx, dy := (start.x-end.x)/2, (start.y-end.y)/2
+	x1, y1 := cosPhi*dx+sinPhi*dy, -sinPhi*dx+cosPhi*dy
+
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx, ry = rx*math.Sqrt(lambda), ry*math.Sqrt(lambda)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cx, cy := coef*rx*y1/ry, -coef*ry*x1/rx
+
+	center := svgPoint{
+		cosPhi*cx - sinPhi*cy + (start.x+end.x)/2,
+		sinPhi*cx + cosPhi*cy + (start.y+end.y)/2,
+	}
+	theta := math.Atan2((y1-cy)/ry, (x1-cx)/rx)
+	delta := math.Atan2((-y1-cy)/ry, (-x1-cx)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	p.arcPoints(center, rx, ry, phi, theta, delta)
+}
+
+// parse adds the subpaths described by the path data d. Parsing stops at
+// the first error, keeping what was drawn before as SVG renderers do
+func (p *svgPath) parse(d string) {
+	s := &svgScanner{s: d}
+	var cmd byte
+	for {
+		s.skipSeparators()
+		if s.done() {
+			return
+		}
+		if c := s.s[s.pos]; c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+			cmd = c
+			s.pos++
+		} else if cmd == 0 {
+			return
+		}
+
+		var origin svgPoint
+		if cmd >= 'a' {
+			origin = p.cur
+		}
+		point := func() (svgPoint, bool) {
+			x, okX := s.number()
+			y, okY := s.number()
+			return origin.add(svgPoint{x, y}), okX && okY
+		}
+
+		switch cmd | 0x20 {
+		case 'z':
+			p.close()
+			cmd = 0
+		case 'm':
+			pt, ok := point()
+			if !ok {
+				return
+			}
+			p.moveTo(pt)
+			// Following pairs are implicit line commands
+			cmd -= 'm' - 'l'
+		case 'l':
+			pt, ok := point()
+			if !ok {
+				return
+			}
+			p.lineTo(pt)
+		case 'h':
+			x, ok := s.number()
+			if !ok {
+				return
+			}
+			p.lineTo(svgPoint{origin.x + x, p.cur.y})
+		case 'v':
+			y, ok := s.number()
+			if !ok {
+				return
+			}
+			p.lineTo(svgPoint{p.cur.x, origin.y + y})
+		case 'c':
+			c1, ok1 := point()
+			c2, ok2 := point()
+			end, ok3 := point()
+			if !ok1 || !ok2 || !ok3 {
+				return
+			}
+			p.cubicTo(c1, c2, end)
+		case 's':
+			c1 := p.cur.reflect(p.ctrl)
+			c2, ok1 := point()
+			end, ok2 := point()
+			if !ok1 || !ok2 {
+				return
+			}
+			p.cubicTo(c1, c2, end)
+		case 'q':
+			c, ok1 := point()
+			end, ok2 := point()
+			if !ok1 || !ok2 {
+				return
+			}
+			p.quadTo(c, end)
+		case 't':
+			c := p.cur.reflect(p.ctrl)
+			end, ok := point()
+			if !ok {
+				return
+			}
+			p.quadTo(c, end)
+		case 'a':
+			rx, ok1 := s.number()
+			ry, ok2 := s.number()
+			rotation, ok3 := s.number()
+			large, ok4 := s.flag()
+			sweep, ok5 := s.flag()
+			end, ok6 := point()
+			if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok6 {
+				return
+			}
+			p.arcTo(rx, ry, rotation, large, sweep, end)
+		default:
+			return
+		}
+
+		// The control point is only reflected after a curve of the same kind
+		if c := cmd | 0x20; c != 'c' && c != 's' && c != 'q' && c != 't' {
+			p.ctrl = p.cur
+		}
+	}
+}
+
+// svgScanner reads the numbers of SVG attributes
+type svgScanner struct {
+	s   string
+	pos int
+}
+
+func (s *svgScanner) done() bool {
+	return s.pos >= len(s.s)
+}
+
+func (s *svgScanner) skipSeparators() {
+	for !s.done() && strings.IndexByte(" \t\r\n,", s.s[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+// number reads the next number, such as 12, -.5 or 1e-3
+func (s *svgScanner) number() (float64, bool) {
+	s.skipSeparators()
+	start := s.pos
+	digits := func() {
+		for !s.done() && s.s[s.pos] >= '0' && s.s[s.pos] <= '9' {
+			s.pos++
+		}
+	}
+
+	if !s.done() && (s.s[s.pos] == '+' || s.s[s.pos] == '-') {
+		s.pos++
+	}
+	digits()
+	if !s.done() && s.s[s.pos] == '.' {
+		s.pos++
+		digits()
+	}
+	if !s.done() && (s.s[s.pos] == 'e' || s.s[s.pos] == 'E') {
+		s.pos++
+		if !s.done() && (s.s[s.pos] == '+' || s.s[s.pos] == '-') {
+			s.pos++
+		}
+		digits()
+	}
+
+	value, err := strconv.ParseFloat(s.s[start:s.pos], 64)
+	if err != nil {
+		s.pos = start
+		return 0, false
+	}
+
+	return value, true
+}
+
+// flag reads an arc flag, which may not be separated from the next number
+func (s *svgScanner) flag() (bool, bool) {
+	s.skipSeparators()
+	if s.done() || (s.s[s.pos] != '0' && s.s[s.pos] != '1') {
+		return false, false
+	}
+	s.pos++
+
+	return s.s[s.pos-1] == '1', true
+}
+
+// svgNumbers returns the list of numbers in value, such as a viewBox
+func svgNumbers(value string) []float64 {
+	s := &svgScanner{s: value}
+	var numbers []float64
+	for {
+		n, ok := s.number()
+		if !ok {
+			return numbers
+		}
+		numbers = append(numbers, n)
+	}
+}
+
+// svgLength returns the length in value, in pixels. Percentages are not
+// supported and return zero
+func svgLength(value string) float64 {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// svgAttr returns the value of the attribute name, or an empty string
+func svgAttr(attrs []xml.Attr, name string) string {
+	for _, attr := range attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// svgMatrix is an affine transform, mapping x, y to
+// a*x + c*y + e, b*x + d*y + f
+type svgMatrix [6]float64
+
+// svgIdentity is the identity transform
+var svgIdentity = svgMatrix{1, 0, 0, 1, 0, 0}
+
+func (m svgMatrix) apply(p svgPoint) svgPoint {
+	return svgPoint{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+// mul returns the transform applying n then m
+func (m svgMatrix) mul(n svgMatrix) svgMatrix {
+	return svgMatrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m svgMatrix) det() float64 {
+	return m[0]*m[3] - m[1]*m[2]
+}
+
+// svgTransform returns the transform described by the transform attribute
+// value, such as "translate(10 20) rotate(45)"
+func svgTransform(value string) svgMatrix {
+	m := svgIdentity
+	for value = strings.TrimSpace(value); value != ""; value = strings.TrimLeft(value, " \t\r\n,") {
+		open, end := strings.IndexByte(value, '('), strings.IndexByte(value, ')')
+		if open < 0 || end < open {
+			return m
+		}
+		name, args := strings.TrimSpace(value[:open]), svgNumbers(value[open+1:end])
+		value = value[end+1:]
+
+		arg := func(idx int, fallback float64) float64 {
+			if idx < len(args) {
+				return args[idx]
+			}
+			return fallback
+		}
+
+		var t svgMatrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				continue
+			}
+			copy(t[:], args)
+		case "translate":
+			t = svgMatrix{1, 0, 0, 1, arg(0, 0), arg(1, 0)}
+		case "scale":
+			t = svgMatrix{arg(0, 1), 0, 0, arg(1, arg(0, 1)), 0, 0}
+		case "rotate":
+			sin, cos := math.Sincos(arg(0, 0) * math.Pi / 180)
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = svgMatrix{1, 0, 0, 1, cx, cy}.mul(svgMatrix{cos, sin, -sin, cos, 0, 0}).mul(svgMatrix{1, 0, 0, 1, -cx, -cy})
+		case "skewX":
+			t = svgMatrix{1, 0, math.Tan(arg(0, 0) * math.Pi / 180), 1, 0, 0}
+		case "skewY":
+			t = svgMatrix{1, math.Tan(arg(0, 0) * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		m = m.mul(t)
+	}
+
+	return m
+}
+
+// svgStyle holds the presentation attributes of an element, inherited from
+// its ancestors
+type svgStyle struct {
+	// fill and stroke are nil for none
+	fill, stroke color.Color
+
+	strokeWidth                         float64
+	opacity, fillOpacity, strokeOpacity float64
+	transform                           svgMatrix
+}
+
+// defaultSVGStyle is the style of the root element, shapes are filled black
+var defaultSVGStyle = svgStyle{
+	fill:          color.Black,
+	strokeWidth:   1,
+	opacity:       1,
+	fillOpacity:   1,
+	strokeOpacity: 1,
+	transform:     svgIdentity,
+}
+
+// inherit returns the style of an element with attrs, a child of an element
+// styled s. The style attribute overrides the presentation attributes
+func (s svgStyle) inherit(attrs []xml.Attr) svgStyle {
+	properties := map[string]string{}
+	for _, attr := range attrs {
+		properties[attr.Name.Local] = attr.Value
+	}
+	for _, declaration := range strings.Split(properties["style"], ";") {
+		if name, value, ok := strings.Cut(declaration, ":"); ok {
+			properties[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	if value, ok := properties["fill"]; ok {
+		s.fill = svgColor(value, s.fill)
+	}
+	if value, ok := properties["stroke"]; ok {
+		s.stroke = svgColor(value, s.stroke)
+	}
+	if value, ok := properties["stroke-width"]; ok {
+		s.strokeWidth = svgLength(value)
+	}
+	opacity := func(name string, current float64) float64 {
+		if value, ok := properties[name]; ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				return n
+			}
+		}
+		return current
+	}
+	s.fillOpacity = opacity("fill-opacity", s.fillOpacity)
+	s.strokeOpacity = opacity("stroke-opacity", s.strokeOpacity)
+	// Group opacity is approximated by applying it to each shape
+	s.opacity *= opacity("opacity", 1)
+
+	if value, ok := properties["transform"]; ok {
+		s.transform = s.transform.mul(svgTransform(value))
+	}
+
+	return s
+}
+
+// svgNamedColors are the most common of the SVG color keywords
+var svgNamedColors = map[string]color.Color{
+	"black":   color.Black,
+	"white":   color.White,
+	"red":     color.RGBA{255, 0, 0, 255},
+	"lime":    color.RGBA{0, 255, 0, 255},
+	"green":   color.RGBA{0, 128, 0, 255},
+	"blue":    color.RGBA{0, 0, 255, 255},
+	"yellow":  color.RGBA{255, 255, 0, 255},
+	"cyan":    color.RGBA{0, 255, 255, 255},
+	"magenta": color.RGBA{255, 0, 255, 255},
+	"orange":  color.RGBA{255, 165, 0, 255},
+	"purple":  color.RGBA{128, 0, 128, 255},
+	"gray":    color.RGBA{128, 128, 128, 255},
+	"grey":    color.RGBA{128, 128, 128, 255},
+	"silver":  color.RGBA{192, 192, 192, 255},
+	"navy":    color.RGBA{0, 0, 128, 255},
+	"teal":    color.RGBA{0, 128, 128, 255},
+	"maroon":  color.RGBA{128, 0, 0, 255},
+	"olive":   color.RGBA{128, 128, 0, 255},
+}
+
+// svgColor parses a paint value, such as #f80, #ff8800, rgb(255, 136, 0) or
+// orange. None returns nil, invalid values and unsupported paint servers
+// such as gradients keep current
+func svgColor(value string, current color.Color) color.Color {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "none" || value == "transparent":
+		return nil
+	case value == "currentcolor":
+		return color.Black
+	case strings.HasPrefix(value, "#"):
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 6 || err != nil {
+			return current
+		}
+		return color.RGBA{uint8(n >> 16), uint8(n >> 8), uint8(n), 255}
+	case strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")"):
+		var channels [3]uint8
+		parts := strings.Split(value[4:len(value)-1], ",")
+		if len(parts) != 3 {
+			return current
+		}
+		for idx, part := range parts {
+			part = strings.TrimSpace(part)
+			scale := 1.0
+			if strings.HasSuffix(part, "%") {
+				part, scale = strings.TrimSuffix(part, "%"), 2.55
+			}
+			n, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return current
+			}
+			channels[idx] = uint8(math.Max(0, math.Min(255, n*scale+0.5)))
+		}
+		return color.RGBA{channels[0], channels[1], channels[2], 255}
+	}
+
+	if c, ok := svgNamedColors[value]; ok {
+		return c
+	}
+
+	return current
+}
@@ -0,0 +1,66 @@
+package imager
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+)
+
+const testSVG = `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">
+  <title>logo</title>
+  <rect x="0" y="0" width="50" height="50" fill="#ff0000"/>
+  <g transform="translate(50 0)" style="fill: blue">
+    <circle cx="25" cy="25" r="20"/>
+    <path d="M20,20 h10 v10 h-10 z" fill="rgb(0, 255, 0)"/>
+  </g>
+  <path d="M5 45 A 40 40 0 0 1 45 5" fill="none" stroke="white" stroke-width="4"/>
+  <defs><rect width="100" height="50" fill="black"/></defs>
+</svg>`
+
+func TestNewImagerFromSVG(t *testing.T) {
+	imgr, err := NewImagerFromSVG([]byte(testSVG), 200, 0)
+	if err != nil {
+		t.Fatalf("NewImagerFromSVG returned an error: %v", err)
+	}
+	if b := imgr.Image.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Fatalf("NewImagerFromSVG kept the wrong aspect ratio: %v", b)
+	}
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.NRGBA
+	}{
+		{"rect", 20, 80, color.NRGBA{255, 0, 0, 255}},
+		{"circle", 120, 50, color.NRGBA{0, 0, 255, 255}},
+		{"path", 150, 50, color.NRGBA{0, 255, 0, 255}},
+		{"arc stroke", 33, 33, color.NRGBA{255, 255, 255, 255}},
+		{"background", 102, 2, color.NRGBA{}},
+	}
+	for _, tt := range tests {
+		got := color.NRGBAModel.Convert(imgr.Image.At(tt.x, tt.y)).(color.NRGBA)
+		if got != tt.want {
+			t.Errorf("%s: pixel at %d,%d is %v, expected %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	if imgr.ImageType != IMPNG {
+		t.Fatalf("NewImagerFromSVG set the image type to %q", imgr.ImageType)
+	}
+
+	imgr, err = NewImagerFromSVG([]byte(testSVG), 0, 0)
+	if err != nil {
+		t.Fatalf("NewImagerFromSVG returned an error: %v", err)
+	}
+	if b := imgr.Image.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Fatalf("NewImagerFromSVG did not use the document size: %v", b)
+	}
+
+	if _, err := NewImagerFromSVG([]byte("<html></html>"), 10, 10); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("NewImagerFromSVG returned %v for an HTML document", err)
+	}
+	if _, err := NewImagerFromSVG([]byte(testSVG), 2000, 0, WithDecodeLimits(DecodeLimits{MaxPixels: 1000})); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("NewImagerFromSVG returned %v above the limits", err)
+	}
+}
@@ -0,0 +1,315 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// TextAlign is the alignment of the lines of a text
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+)
+
+// defaultFontSize is the size of the fonts loaded from FontFile or FontData
+// when Size is zero
+const defaultFontSize = 16
+
+// TextOptions holds the options used by DrawText and Annotate
+type TextOptions struct {
+	// Color of the text, defaults to black
+	Color color.Color
+
+	// Face used to render the text, defaults to basicfont.Face7x13
+	Face font.Face
+
+	// FontFile is the path of a TrueType or OpenType font, used instead of Face
+	FontFile string
+
+	// FontData is a TrueType or OpenType font, used instead of Face
+	FontData []byte
+
+	// Size is the height of the text in pixels. The rendered glyphs are scaled
+	// when it differs from the height of Face, zero keeps the face size.
+	// With FontFile or FontData it is the font size, 16 by default
+	Size float64
+
+	// Anchor is the position of the text within the image, used by Annotate
+	Anchor Anchor
+
+	// Margin is the distance in pixels between the text and the image edges,
+	// used by Annotate
+	Margin int
+
+	// MaxWidth wraps the lines longer than MaxWidth pixels, zero only breaks
+	// lines on new lines
+	MaxWidth int
+
+	// Align is the alignment of the lines
+	Align TextAlign
+
+	// LineSpacing multiplies the height of the lines, zero means 1
+	LineSpacing float64
+
+	// ShadowColor draws a drop shadow behind the text when set
+	ShadowColor color.Color
+
+	// ShadowOffset is the offset of the shadow, defaults to (2, 2)
+	ShadowOffset image.Point
+
+	// OutlineColor draws an outline around the text when set
+	OutlineColor color.Color
+
+	// OutlineWidth is the width of the outline in pixels, defaults to 1
+	OutlineWidth int
+}
+
+// DrawText draws text onto the image, pos is the left end of the baseline
+// of the first line
+// i.e :
+// imgr.DrawText("hello", image.Pt(10, 20), imager.TextOptions{})
+// imgr.DrawText("hello", image.Pt(10, 40), imager.TextOptions{Color: color.White, Size: 26})
+// imgr.DrawText("hello", image.Pt(10, 40), imager.TextOptions{FontFile: "font.ttf", OutlineColor: color.Black})
+func (i *Imager) DrawText(text string, pos image.Point, opts TextOptions) *Imager {
+	mask, ascent, err := renderText(text, opts)
+	if err != nil {
+		i.setErr(err)
+		return i
+	}
+	if mask == nil {
+		return i
+	}
+
+	return i.drawTextMask("DrawText", mask, image.Pt(pos.X, pos.Y-ascent), opts)
+}
+
+// Annotate draws text onto the image at the position given by opts.Anchor,
+// to stamp captions, timestamps or copyright notices
+// i.e :
+// imgr.Annotate("© ACME", imager.TextOptions{Anchor: imager.AnchorBottomRight, Margin: 10})
+// imgr.Annotate("caption", imager.TextOptions{Anchor: imager.AnchorBottom, MaxWidth: 300, Align: imager.AlignCenter})
+func (i *Imager) Annotate(text string, opts TextOptions) *Imager {
+	mask, _, err := renderText(text, opts)
+	if err != nil {
+		i.setErr(err)
+		return i
+	}
+	if mask == nil {
+		return i
+	}
+
+	origin := opts.Anchor.point(i.Image.Bounds(), mask.Bounds().Size(), opts.Margin)
+	return i.drawTextMask("Annotate", mask, origin, opts)
+}
+
+// drawTextMask draws the text mask at origin with its shadow and outline,
+// name being the operation, see apply
+func (i *Imager) drawTextMask(name string, mask image.Image, origin image.Point, opts TextOptions) *Imager {
+	col := opts.Color
+	if col == nil {
+		col = color.Black
+	}
+
+	// The outline is the text mask grown by its width
+	var outline image.Image
+	var outlineOrigin image.Point
+	if opts.OutlineColor != nil {
+		width := opts.OutlineWidth
+		if width <= 0 {
+			width = 1
+		}
+		outline = dilateMask(mask, width)
+		outlineOrigin = origin.Sub(image.Pt(width, width))
+	}
+
+	return i.apply(name, func(img image.Image) image.Image {
+		dst := imaging.Clone(img)
+		drawMask := func(mask image.Image, origin image.Point, col color.Color) {
+			draw.DrawMask(dst, mask.Bounds().Add(origin), image.NewUniform(col), image.Point{}, mask, image.Point{}, draw.Over)
+		}
+
+		if opts.ShadowColor != nil {
+			offset := opts.ShadowOffset
+			if offset == (image.Point{}) {
+				offset = image.Pt(2, 2)
+			}
+			if outline != nil {
+				drawMask(outline, outlineOrigin.Add(offset), opts.ShadowColor)
+			} else {
+				drawMask(mask, origin.Add(offset), opts.ShadowColor)
+			}
+		}
+		if outline != nil {
+			drawMask(outline, outlineOrigin, opts.OutlineColor)
+		}
+		drawMask(mask, origin, col)
+
+		return dst
+	})
+}
+
+// renderText renders the lines of text into an alpha mask and returns it
+// along with the ascent of the first line. The mask is nil for empty text
+func renderText(text string, opts TextOptions) (image.Image, int, error) {
+	face, scaled, err := textFace(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	metrics := face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	glyphHeight := ascent + metrics.Descent.Ceil()
+	if glyphHeight <= 0 {
+		return nil, 0, nil
+	}
+
+	// Scaled faces are rendered at their own size, so is the wrapping width
+	scale := 1.0
+	if scaled && opts.Size > 0 && int(opts.Size+0.5) != glyphHeight {
+		scale = opts.Size / float64(glyphHeight)
+	}
+
+	lines := wrapText(face, text, fixed.I(int(float64(opts.MaxWidth)/scale)))
+	widths := make([]int, len(lines))
+	width := 0
+	for k, line := range lines {
+		widths[k] = font.MeasureString(face, line).Ceil()
+		width = max(width, widths[k])
+	}
+	if width <= 0 {
+		return nil, 0, nil
+	}
+
+	lineHeight := metrics.Height.Ceil()
+	if opts.LineSpacing > 0 {
+		lineHeight = int(float64(lineHeight)*opts.LineSpacing + 0.5)
+	}
+	height := glyphHeight + (len(lines)-1)*lineHeight
+
+	// Render the glyphs into a mask first so it can be scaled to opts.Size
+	alpha := image.NewAlpha(image.Rect(0, 0, width, height))
+	drawer := &font.Drawer{Dst: alpha, Src: image.Opaque, Face: face}
+	for k, line := range lines {
+		x := 0
+		switch opts.Align {
+		case AlignCenter:
+			x = (width - widths[k]) / 2
+		case AlignRight:
+			x = width - widths[k]
+		}
+		drawer.Dot = fixed.P(x, ascent+k*lineHeight)
+		drawer.DrawString(line)
+	}
+
+	if scale == 1 {
+		return alpha, ascent, nil
+	}
+
+	mask := imaging.Resize(alpha, int(float64(width)*scale+0.5), int(float64(height)*scale+0.5), imaging.Linear)
+	return mask, int(float64(ascent)*scale + 0.5), nil
+}
+
+// textFace returns the face used to render text, scaled reports whether its
+// glyphs must be scaled to opts.Size
+func textFace(opts TextOptions) (font.Face, bool, error) {
+	data := opts.FontData
+	if data == nil && opts.FontFile != "" {
+		var err error
+		if data, err = os.ReadFile(opts.FontFile); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if data == nil {
+		if opts.Face != nil {
+			return opts.Face, true, nil
+		}
+		return basicfont.Face7x13, true, nil
+	}
+
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	size := opts.Size
+	if size <= 0 {
+		size = defaultFontSize
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	return face, false, err
+}
+
+// wrapText splits text into lines on new lines and, when maxWidth is
+// positive, between words so the lines fit within maxWidth. Words longer
+// than maxWidth get a line of their own
+func wrapText(face font.Face, text string, maxWidth fixed.Int26_6) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		if maxWidth <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := words[0]
+		for _, word := range words[1:] {
+			if font.MeasureString(face, line+" "+word) <= maxWidth {
+				line += " " + word
+				continue
+			}
+			lines = append(lines, line)
+			line = word
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// dilateMask returns mask grown by radius pixels on each side, each pixel
+// taking the highest alpha within a disc of radius
+func dilateMask(mask image.Image, radius int) *image.Alpha {
+	src := image.NewAlpha(mask.Bounds())
+	draw.Draw(src, src.Bounds(), mask, mask.Bounds().Min, draw.Src)
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewAlpha(image.Rect(0, 0, w+2*radius, h+2*radius))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a := src.Pix[y*src.Stride+x]
+			if a == 0 {
+				continue
+			}
+
+			for dy := -radius; dy <= radius; dy++ {
+				for dx := -radius; dx <= radius; dx++ {
+					if dx*dx+dy*dy > radius*radius {
+						continue
+					}
+					pos := (y+dy+radius)*dst.Stride + x + dx + radius
+					dst.Pix[pos] = max(dst.Pix[pos], a)
+				}
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,112 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestDrawText(t *testing.T) {
+	imgr, err := NewImager(createTestImage())
+	if err != nil {
+		t.Fatalf("NewImager returned an error: %v", err)
+	}
+
+	imgr.DrawText("Hello", image.Pt(10, 50), TextOptions{Color: color.Black})
+
+	changed := 0
+	for y := 39; y < 52; y++ {
+		for x := 10; x < 45; x++ {
+			r, g, b, _ := imgr.Image.At(x, y).RGBA()
+			if r>>8 != 255 || g>>8 != 0 || b>>8 != 0 {
+				changed++
+			}
+		}
+	}
+
+	if changed == 0 {
+		t.Fatalf("DrawText did not change any pixel within the text bounding box")
+	}
+
+	if imgr.Image.Bounds() != image.Rect(0, 0, 100, 100) {
+		t.Fatalf("DrawText changed the image bounds: got %v", imgr.Image.Bounds())
+	}
+}
+
+func TestDrawTextSize(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.DrawText("Hi", image.Pt(5, 80), TextOptions{Size: 26})
+
+	// Scaled text reaches above the native 13px glyph height
+	changed := false
+	for x := 5; x < 40 && !changed; x++ {
+		r, _, _, _ := imgr.Image.At(x, 62).RGBA()
+		changed = r>>8 != 255
+	}
+
+	if !changed {
+		t.Fatalf("DrawText did not scale the text to the requested size")
+	}
+}
+
+func TestDrawTextTrueType(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.DrawText("Hi", image.Pt(5, 80), TextOptions{FontData: goregular.TTF, Size: 40, Color: color.White})
+	if err := imgr.Err(); err != nil {
+		t.Fatalf("DrawText returned an error: %v", err)
+	}
+
+	// A 40px font reaches well above the baseline
+	changed := false
+	for x := 5; x < 40 && !changed; x++ {
+		_, g, _, _ := imgr.Image.At(x, 55).RGBA()
+		changed = g>>8 > 128
+	}
+	if !changed {
+		t.Fatalf("DrawText did not render the TrueType font")
+	}
+
+	imgr.DrawText("Hi", image.Pt(5, 80), TextOptions{FontData: []byte("not a font")})
+	if imgr.Err() == nil {
+		t.Fatalf("DrawText accepted invalid font data")
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.Annotate("Hello", TextOptions{Anchor: AnchorBottomRight, Margin: 2, OutlineColor: color.White})
+
+	// The text ends 2px away from the bottom right corner, the outline too
+	mask, _, _ := renderText("Hello", TextOptions{})
+	size := mask.Bounds().Size()
+	changed, outlined := false, false
+	for y := 98 - size.Y; y < 98; y++ {
+		for x := 98 - size.X; x < 98; x++ {
+			r, g, _, _ := imgr.Image.At(x, y).RGBA()
+			changed = changed || r>>8 < 128
+			outlined = outlined || g>>8 > 200
+		}
+	}
+	if !changed || !outlined {
+		t.Fatalf("Annotate did not draw the outlined text at the bottom right corner")
+	}
+	if r, _, _, _ := imgr.Image.At(5, 5).RGBA(); r>>8 != 255 {
+		t.Fatalf("Annotate drew outside of the anchored area")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText(basicfont.Face7x13, "one two three\nfour", 7*9<<6)
+	if len(lines) != 3 || lines[0] != "one two" || lines[1] != "three" || lines[2] != "four" {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+
+	single, _, _ := renderText("one two three", TextOptions{})
+	wrapped, _, _ := renderText("one two three", TextOptions{MaxWidth: 50})
+	if wrapped.Bounds().Dy() <= single.Bounds().Dy() || wrapped.Bounds().Dx() > 50 {
+		t.Fatalf("MaxWidth did not wrap the text: got %v", wrapped.Bounds())
+	}
+}
@@ -0,0 +1,179 @@
+package imager
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// ResizeTiled resizes the image like MD_SCALE but processes it in horizontal
+// bands of tileSize output rows. Only the source rows needed by a band are
+// copied at a time, which keeps the peak memory bounded for very large
+// images. The result is the same as a plain Resize, bands don't leave seams.
+// Invalid sizes record ErrInvalidArgument, see ResizeWithFilter
+// i.e :
+// imgr.ResizeTiled(1000, 1000, 256)
+func (i *Imager) ResizeTiled(width, height int, tileSize int) *Imager {
+	if !i.checkResizeSize(width, height, MD_SCALE) {
+		return i
+	}
+
+	return i.apply("ResizeTiled", func(img image.Image) image.Image {
+		return resizeTiled(img, width, height, tileSize)
+	})
+}
+
+// resizeTiled resizes src with the Lanczos filter, tileSize rows at a time
+func resizeTiled(src image.Image, width, height int, tileSize int) *image.NRGBA {
+	width, height, ok := tiledSize(src, width, height)
+	if !ok {
+		return &image.NRGBA{}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	resizeBands(src, width, height, tileSize, func(band *image.NRGBA) error {
+		copy(dst.Pix[band.Rect.Min.Y*dst.Stride:], band.Pix)
+		return nil
+	})
+
+	return dst
+}
+
+// tiledSize returns the output size of a tiled resize of src, ok is false
+// when there is none
+func tiledSize(src image.Image, width, height int) (int, int, bool) {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	if width < 0 || height < 0 || (width == 0 && height == 0) || srcW <= 0 || srcH <= 0 {
+		return 0, 0, false
+	}
+
+	// Same aspect ratio rules as imaging.Resize
+	if width == 0 {
+		width = int(math.Max(1.0, math.Floor(float64(height)*float64(srcW)/float64(srcH)+0.5)))
+	}
+	if height == 0 {
+		height = int(math.Max(1.0, math.Floor(float64(width)*float64(srcH)/float64(srcW)+0.5)))
+	}
+
+	return width, height, true
+}
+
+// resizeBands resizes src to width x height with the Lanczos filter and
+// passes the result to emit in bands of tileSize rows, from the top. The
+// bands have their actual position as bounds and are reused between calls
+func resizeBands(src image.Image, width, height int, tileSize int, emit func(band *image.NRGBA) error) error {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	if tileSize <= 0 || tileSize > height {
+		tileSize = height
+	}
+
+	filter := imaging.Lanczos
+	weights := resizeWeights(height, srcH, filter)
+	out := image.NewNRGBA(image.Rect(0, 0, width, tileSize))
+
+	for top := 0; top < height; top += tileSize {
+		bottom := min(top+tileSize, height)
+		dst := &image.NRGBA{Pix: out.Pix[:(bottom-top)*out.Stride], Stride: out.Stride, Rect: image.Rect(0, top, width, bottom)}
+		clear(dst.Pix)
+
+		// Source rows contributing to the output rows of this band
+		first, last := srcH, 0
+		for y := top; y < bottom; y++ {
+			if len(weights[y]) == 0 {
+				continue
+			}
+			first = min(first, weights[y][0].index)
+			last = max(last, weights[y][len(weights[y])-1].index)
+		}
+		if first <= last {
+			rect := image.Rect(0, first, srcW, last+1).Add(src.Bounds().Min)
+			band := imaging.Crop(src, rect)
+			if srcW != width {
+				band = imaging.Resize(band, width, band.Bounds().Dy(), filter)
+			}
+
+			for y := top; y < bottom; y++ {
+				resizeRow(dst, y-top, band, first, weights[y])
+			}
+		}
+
+		if err := emit(dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexWeight is the weight of a source row in an output row
+type indexWeight struct {
+	index  int
+	weight float64
+}
+
+// resizeWeights computes the source rows and weights of each output row, it
+// follows imaging so that tiled and plain resizes produce the same pixels
+func resizeWeights(dstSize, srcSize int, filter imaging.ResampleFilter) [][]indexWeight {
+	du := float64(srcSize) / float64(dstSize)
+	scale := math.Max(du, 1.0)
+	ru := math.Ceil(scale * filter.Support)
+
+	out := make([][]indexWeight, dstSize)
+	for v := 0; v < dstSize; v++ {
+		fu := (float64(v)+0.5)*du - 0.5
+		begin := max(int(math.Ceil(fu-ru)), 0)
+		end := min(int(math.Floor(fu+ru)), srcSize-1)
+
+		var sum float64
+		for u := begin; u <= end; u++ {
+			w := filter.Kernel((float64(u) - fu) / scale)
+			if w != 0 {
+				sum += w
+				out[v] = append(out[v], indexWeight{index: u, weight: w})
+			}
+		}
+		if sum != 0 {
+			for k := range out[v] {
+				out[v][k].weight /= sum
+			}
+		}
+	}
+
+	return out
+}
+
+// resizeRow computes the row y of dst, counted from its top, from band
+// whose first row is the source row offset
+func resizeRow(dst *image.NRGBA, y int, band *image.NRGBA, offset int, weights []indexWeight) {
+	for x := 0; x < dst.Bounds().Dx(); x++ {
+		var r, g, b, a float64
+		for _, w := range weights {
+			s := band.Pix[(w.index-offset)*band.Stride+x*4:]
+			aw := float64(s[3]) * w.weight
+			r += float64(s[0]) * aw
+			g += float64(s[1]) * aw
+			b += float64(s[2]) * aw
+			a += aw
+		}
+		if a != 0 {
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0] = clampUint8(r / a)
+			d[1] = clampUint8(g / a)
+			d[2] = clampUint8(b / a)
+			d[3] = clampUint8(a)
+		}
+	}
+}
+
+// clampUint8 rounds and clamps v to the 0-255 range
+func clampUint8(v float64) uint8 {
+	v = v + 0.5
+	if v > 255 {
+		return 255
+	}
+	if v > 0 {
+		return uint8(v)
+	}
+	return 0
+}
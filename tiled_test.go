@@ -0,0 +1,48 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResizeTiled(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x), uint8(y), uint8(x * y), 255})
+		}
+	}
+
+	tiled, _ := NewImager(img)
+	tiled.ResizeTiled(150, 113, 7)
+
+	plain, _ := NewImager(img)
+	plain.Resize(150, 113, MD_SCALE)
+
+	if tiled.Image.Bounds() != plain.Image.Bounds() {
+		t.Fatalf("ResizeTiled returned %v, want %v", tiled.Image.Bounds(), plain.Image.Bounds())
+	}
+
+	psnr, err := tiled.CompareTo(plain)
+	if err != nil {
+		t.Fatalf("CompareTo returned an error: %v", err)
+	}
+	if psnr < 50 {
+		t.Fatalf("ResizeTiled differs from Resize: PSNR %v dB", psnr)
+	}
+}
+
+func TestResizeTiledInvalidSize(t *testing.T) {
+	for _, size := range [][2]int{{-10, 10}, {0, 0}} {
+		imgr, _ := NewImager(createGradientImage())
+		imgr.ResizeTiled(size[0], size[1], 1)
+		if !errors.Is(imgr.Err(), ErrInvalidArgument) {
+			t.Errorf("ResizeTiled(%d, %d): expected ErrInvalidArgument, got %v", size[0], size[1], imgr.Err())
+		}
+		if imgr.Image.Bounds().Dx() != 100 {
+			t.Errorf("ResizeTiled(%d, %d) changed the image", size[0], size[1])
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package imager
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+)
+
+// defaultTileSize is the size of the tiles when Tiler.TileSize is zero
+const defaultTileSize = 256
+
+// TileSink stores the tiles generated by a Tiler
+type TileSink interface {
+	WriteTile(z, x, y int, data []byte) error
+}
+
+// TileSinkFunc adapts a function to TileSink
+type TileSinkFunc func(z, x, y int, data []byte) error
+
+// WriteTile calls f
+func (f TileSinkFunc) WriteTile(z, x, y int, data []byte) error {
+	return f(z, x, y, data)
+}
+
+// DirTileSink returns a TileSink writing the tiles below root as z/x/y.ext,
+// the layout expected by Leaflet and OpenSeadragon
+// i.e :
+// sink := imager.DirTileSink("tiles", "jpg")
+func DirTileSink(root, ext string) TileSink {
+	return TileSinkFunc(func(z, x, y int, data []byte) error {
+		dir := filepath.Join(root, strconv.Itoa(z), strconv.Itoa(x))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, strconv.Itoa(y)+"."+ext), data, 0o644)
+	})
+}
+
+// Tiler slices an image into a pyramid of tiles. Zoom level 0 fits the whole
+// image into a single tile and each level doubles the resolution, up to the
+// full resolution. The tiles on the right and bottom edges are cut to the
+// image size
+type Tiler struct {
+	// Sink stores the tiles
+	Sink TileSink
+
+	// TileSize is the width and height of the tiles, zero means 256
+	TileSize int
+
+	// Format is the format of the tiles, one of the IM* constants, empty
+	// means JPEG
+	Format string
+
+	// Options are the encoding options of the tiles
+	Options EncodeOptions
+}
+
+// NewTiler returns a Tiler writing 256 pixels JPEG tiles to sink
+// i.e :
+// err := imager.NewTiler(imager.DirTileSink("tiles", "jpg")).Tile(imgr)
+func NewTiler(sink TileSink) *Tiler {
+	return &Tiler{Sink: sink, TileSize: defaultTileSize, Format: IMJPEG}
+}
+
+// Levels returns the number of zoom levels of an image of the given size
+// i.e :
+// levels := tiler.Levels(imgr.Image.Bounds().Size())
+func (t *Tiler) Levels(size image.Point) int {
+	levels, tileSize := 1, t.tileSize()
+	for longest := max(size.X, size.Y); longest > tileSize; longest = (longest + 1) / 2 {
+		levels++
+	}
+
+	return levels
+}
+
+// Tile writes the tiles of every zoom level of the image to the sink, from
+// the full resolution level down to level 0
+// i.e :
+// err := tiler.Tile(imgr)
+func (t *Tiler) Tile(imgr *Imager) error {
+	if t.Sink == nil {
+		return fmt.Errorf("%w: tiler without sink", ErrInvalidArgument)
+	}
+
+	format := t.Format
+	if format == "" {
+		format = IMJPEG
+	}
+	tileSize := t.tileSize()
+
+	// Each level is downscaled from the previous one
+	var level image.Image = imaging.Clone(imgr.Image)
+	for z := t.Levels(level.Bounds().Size()) - 1; z >= 0; z-- {
+		bounds := level.Bounds()
+		for y := 0; y*tileSize < bounds.Dy(); y++ {
+			for x := 0; x*tileSize < bounds.Dx(); x++ {
+				rect := image.Rect(x*tileSize, y*tileSize, (x+1)*tileSize, (y+1)*tileSize).Intersect(bounds)
+				tile := &Imager{Image: imaging.Crop(level, rect), Background: imgr.Background}
+
+				buf := bytes.NewBuffer(nil)
+				if err := tile.Encode(buf, format, t.Options); err != nil {
+					return err
+				}
+				if err := t.Sink.WriteTile(z, x, y, buf.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+
+		if z > 0 {
+			level = imaging.Resize(level, (bounds.Dx()+1)/2, (bounds.Dy()+1)/2, imaging.Linear)
+		}
+	}
+
+	return nil
+}
+
+// tileSize returns the configured tile size or the default one
+func (t *Tiler) tileSize() int {
+	if t.TileSize <= 0 {
+		return defaultTileSize
+	}
+
+	return t.TileSize
+}
@@ -0,0 +1,65 @@
+package imager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTiler(t *testing.T) {
+	imgr, _ := NewImager(createPatternImage(300, 200))
+
+	tiles := map[string]image.Point{}
+	tiler := &Tiler{TileSize: 100, Format: IMPNG, Sink: TileSinkFunc(func(z, x, y int, data []byte) error {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		tiles[fmt.Sprintf("%d/%d/%d", z, x, y)] = img.Bounds().Size()
+		return nil
+	})}
+
+	if levels := tiler.Levels(image.Pt(300, 200)); levels != 3 {
+		t.Fatalf("Levels returned %d, want 3", levels)
+	}
+	if err := tiler.Tile(imgr); err != nil {
+		t.Fatalf("Tile returned an error: %v", err)
+	}
+
+	// Level 2 is 300x200, level 1 is 150x100 and level 0 is 75x50
+	want := map[string]image.Point{
+		"2/0/0": {100, 100}, "2/1/0": {100, 100}, "2/2/0": {100, 100},
+		"2/0/1": {100, 100}, "2/1/1": {100, 100}, "2/2/1": {100, 100},
+		"1/0/0": {100, 100}, "1/1/0": {50, 100},
+		"0/0/0": {75, 50},
+	}
+	if len(tiles) != len(want) {
+		t.Fatalf("Tile wrote %d tiles, want %d: %v", len(tiles), len(want), tiles)
+	}
+	for name, size := range want {
+		if tiles[name] != size {
+			t.Fatalf("Tile wrote %s of size %v, want %v", name, tiles[name], size)
+		}
+	}
+}
+
+func TestDirTileSink(t *testing.T) {
+	root := t.TempDir()
+	imgr, _ := NewImager(createTestImage())
+
+	if err := NewTiler(DirTileSink(root, "jpg")).Tile(imgr); err != nil {
+		t.Fatalf("Tile returned an error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "0", "0", "0.jpg")); err != nil {
+		t.Fatalf("DirTileSink did not write the tile: %v", err)
+	}
+
+	if err := (&Tiler{}).Tile(imgr); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Tile returned %v without sink, want %v", err, ErrInvalidArgument)
+	}
+}
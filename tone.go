@@ -0,0 +1,82 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/disintegration/imaging"
+)
+
+// Grayscale converts the image to shades of gray, keeping its alpha
+// i.e :
+// imgr.Grayscale()
+func (i *Imager) Grayscale() *Imager {
+	return i.transform("Grayscale", imaging.Grayscale)
+}
+
+// Sepia gives the image a brown, aged tone. strength ranges from 0 (no
+// change) to 1 (full sepia)
+// i.e :
+// imgr.Sepia(0.8)
+func (i *Imager) Sepia(strength float64) *Imager {
+	if !i.checkRange("sepia strength", strength, 0, 1) || strength == 0 {
+		return i
+	}
+
+	return i.apply("Sepia", func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			r, g, b := float64(c.R), float64(c.G), float64(c.B)
+			sr := 0.393*r + 0.769*g + 0.189*b
+			sg := 0.349*r + 0.686*g + 0.168*b
+			sb := 0.272*r + 0.534*g + 0.131*b
+
+			return color.NRGBA{
+				clampUint8(r + (sr-r)*strength),
+				clampUint8(g + (sg-g)*strength),
+				clampUint8(b + (sb-b)*strength),
+				c.A,
+			}
+		})
+	})
+}
+
+// Invert inverts the colors of the image, keeping its alpha
+// i.e :
+// imgr.Invert()
+func (i *Imager) Invert() *Imager {
+	return i.transform("Invert", imaging.Invert)
+}
+
+// Duotone maps the luminance of the image to a gradient from dark, for the
+// shadows, to light, for the highlights. A nil dark is black and a nil
+// light is white
+// i.e :
+// imgr.Duotone(color.RGBA{20, 20, 80, 255}, color.RGBA{255, 200, 120, 255})
+// imgr.Duotone(color.RGBA{20, 20, 80, 255}, nil)
+func (i *Imager) Duotone(dark, light color.Color) *Imager {
+	if dark == nil {
+		dark = color.Black
+	}
+	if light == nil {
+		light = color.White
+	}
+	d := color.NRGBAModel.Convert(dark).(color.NRGBA)
+	l := color.NRGBAModel.Convert(light).(color.NRGBA)
+
+	var table [256][3]uint8
+	for v := range table {
+		t := float64(v) / 255
+		table[v] = [3]uint8{
+			clampUint8(float64(d.R) + (float64(l.R)-float64(d.R))*t),
+			clampUint8(float64(d.G) + (float64(l.G)-float64(d.G))*t),
+			clampUint8(float64(d.B) + (float64(l.B)-float64(d.B))*t),
+		}
+	}
+
+	return i.apply("Duotone", func(img image.Image) image.Image {
+		return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+			tone := table[luma(c)]
+			return color.NRGBA{tone[0], tone[1], tone[2], c.A}
+		})
+	})
+}
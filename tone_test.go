@@ -0,0 +1,51 @@
+package imager
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestGrayscaleAndInvert(t *testing.T) {
+	imgr, _ := NewImager(createColorImage(color.NRGBA{200, 50, 50, 128}))
+	if c := pixel(imgr.Grayscale().Image, 5, 5); c.R != c.G || c.G != c.B || c.A != 128 {
+		t.Fatalf("Grayscale did not produce gray: %v", c)
+	}
+
+	imgr, _ = NewImager(createColorImage(color.NRGBA{200, 50, 0, 255}))
+	if c := pixel(imgr.Invert().Image, 5, 5); c != (color.NRGBA{55, 205, 255, 255}) {
+		t.Fatalf("Invert returned %v", c)
+	}
+}
+
+func TestSepia(t *testing.T) {
+	imgr, _ := NewImager(createColorImage(color.NRGBA{100, 100, 100, 255}))
+	if c := pixel(imgr.Sepia(1).Image, 5, 5); !(c.R > c.G && c.G > c.B) {
+		t.Fatalf("Sepia did not produce a brown tone: %v", c)
+	}
+
+	imgr.Sepia(2)
+	if !errors.Is(imgr.Err(), ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", imgr.Err())
+	}
+}
+
+func TestDuotone(t *testing.T) {
+	dark, light := color.NRGBA{0, 0, 128, 255}, color.NRGBA{255, 255, 0, 255}
+
+	imgr, _ := NewImager(createColorImage(color.Black))
+	if c := pixel(imgr.Duotone(dark, light).Image, 5, 5); c != dark {
+		t.Fatalf("Duotone did not map black to the dark color: %v", c)
+	}
+
+	imgr, _ = NewImager(createColorImage(color.White))
+	if c := pixel(imgr.Duotone(dark, light).Image, 5, 5); c != light {
+		t.Fatalf("Duotone did not map white to the light color: %v", c)
+	}
+
+	// Nil colors default to black and white
+	imgr, _ = NewImager(createColorImage(color.White))
+	if c := pixel(imgr.Duotone(nil, nil).Image, 5, 5); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("Duotone(nil, nil) did not map white to white: %v", c)
+	}
+}
@@ -0,0 +1,129 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// FlipH flips the image horizontally, from left to right
+// i.e :
+// imgr.FlipH()
+func (i *Imager) FlipH() *Imager {
+	i.moveFocal(flipHPoint)
+	return i.transform("FlipH", imaging.FlipH)
+}
+
+// FlipV flips the image vertically, from top to bottom
+// i.e :
+// imgr.FlipV()
+func (i *Imager) FlipV() *Imager {
+	i.moveFocal(flipVPoint)
+	return i.transform("FlipV", imaging.FlipV)
+}
+
+// Transpose flips the image horizontally and rotates it 90 degrees
+// counter-clockwise, mirroring it along the top left to bottom right diagonal
+// i.e :
+// imgr.Transpose()
+func (i *Imager) Transpose() *Imager {
+	i.moveFocal(transposePoint)
+	return i.transform("Transpose", imaging.Transpose)
+}
+
+// Transverse flips the image vertically and rotates it 90 degrees
+// counter-clockwise, mirroring it along the top right to bottom left diagonal
+// i.e :
+// imgr.Transverse()
+func (i *Imager) Transverse() *Imager {
+	i.moveFocal(transversePoint)
+	return i.transform("Transverse", imaging.Transverse)
+}
+
+// Rotate90 rotates the image 90 degrees counter-clockwise, without
+// resampling
+// i.e :
+// imgr.Rotate90()
+func (i *Imager) Rotate90() *Imager {
+	i.moveFocal(rotate90Point)
+	return i.transform("Rotate90", imaging.Rotate90)
+}
+
+// Rotate180 rotates the image 180 degrees, without resampling
+// i.e :
+// imgr.Rotate180()
+func (i *Imager) Rotate180() *Imager {
+	i.moveFocal(rotate180Point)
+	return i.transform("Rotate180", imaging.Rotate180)
+}
+
+// Rotate270 rotates the image 270 degrees counter-clockwise, so 90 degrees
+// clockwise, without resampling
+// i.e :
+// imgr.Rotate270()
+func (i *Imager) Rotate270() *Imager {
+	i.moveFocal(rotate270Point)
+	return i.transform("Rotate270", imaging.Rotate270)
+}
+
+// transform applies an imaging transformation to the image, name being the
+// operation, see apply
+func (i *Imager) transform(name string, op func(image.Image) *image.NRGBA) *Imager {
+	return i.apply(name, func(img image.Image) image.Image {
+		return op(img)
+	})
+}
+
+// RotateOptions holds the options used by RotateWith
+type RotateOptions struct {
+	// Background fills the corners uncovered by the rotation, transparent
+	// when nil
+	Background color.Color
+
+	// Clip keeps the dimensions of the image, cutting the rotated corners,
+	// instead of expanding the canvas to fit the whole rotated image
+	Clip bool
+}
+
+// RotateWith rotates the image counter-clockwise by degrees, which may be
+// fractional. Multiples of 90 degrees take a fast path without resampling
+// i.e :
+// imgr.RotateWith(12.5, imager.RotateOptions{Background: color.White})
+// imgr.RotateWith(-3, imager.RotateOptions{Clip: true})
+func (i *Imager) RotateWith(degrees float64, opts RotateOptions) *Imager {
+	if math.IsNaN(degrees) || math.IsInf(degrees, 0) {
+		i.setErr(fmt.Errorf("%w: rotation of %v degrees", ErrInvalidArgument, degrees))
+		return i
+	}
+	bg := opts.Background
+	if bg == nil {
+		bg = color.Transparent
+	}
+
+	bounds := i.Image.Bounds()
+	defer func() { i.rotateFocal(degrees, bounds.Size(), i.Image.Bounds().Size()) }()
+	return i.apply("RotateWith", func(img image.Image) image.Image {
+		var rotated image.Image
+		switch math.Mod(math.Mod(degrees, 360)+360, 360) {
+		case 0:
+			rotated = img
+		case 90:
+			rotated = imaging.Rotate90(img)
+		case 180:
+			rotated = imaging.Rotate180(img)
+		case 270:
+			rotated = imaging.Rotate270(img)
+		default:
+			rotated = imaging.Rotate(img, degrees, bg)
+		}
+
+		if !opts.Clip || rotated.Bounds().Size() == bounds.Size() {
+			return rotated
+		}
+
+		return imaging.PasteCenter(imaging.New(bounds.Dx(), bounds.Dy(), bg), rotated)
+	})
+}
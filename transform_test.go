@@ -0,0 +1,89 @@
+package imager
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// createMarkedImage returns a 4x2 white image with a black top left pixel
+func createMarkedImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for k := range img.Pix {
+		img.Pix[k] = 255
+	}
+	img.Set(0, 0, color.Black)
+
+	return img
+}
+
+func TestFlipAndRotate(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     func(*Imager) *Imager
+		size   image.Point
+		marked image.Point
+	}{
+		{"FlipH", (*Imager).FlipH, image.Pt(4, 2), image.Pt(3, 0)},
+		{"FlipV", (*Imager).FlipV, image.Pt(4, 2), image.Pt(0, 1)},
+		{"Transpose", (*Imager).Transpose, image.Pt(2, 4), image.Pt(0, 0)},
+		{"Transverse", (*Imager).Transverse, image.Pt(2, 4), image.Pt(1, 3)},
+		{"Rotate90", (*Imager).Rotate90, image.Pt(2, 4), image.Pt(0, 3)},
+		{"Rotate180", (*Imager).Rotate180, image.Pt(4, 2), image.Pt(3, 1)},
+		{"Rotate270", (*Imager).Rotate270, image.Pt(2, 4), image.Pt(1, 0)},
+		{"Rotate(-90)", func(i *Imager) *Imager { return i.Rotate(-90) }, image.Pt(2, 4), image.Pt(1, 0)},
+		{"Rotate(450)", func(i *Imager) *Imager { return i.Rotate(450) }, image.Pt(2, 4), image.Pt(0, 3)},
+	}
+
+	for _, tt := range tests {
+		imgr, _ := NewImager(createMarkedImage())
+		tt.op(imgr)
+
+		if imgr.Image.Bounds().Size() != tt.size {
+			t.Fatalf("%s: expected size %v, got %v", tt.name, tt.size, imgr.Image.Bounds().Size())
+		}
+		if r, _, _, _ := imgr.Image.At(tt.marked.X, tt.marked.Y).RGBA(); r != 0 {
+			t.Fatalf("%s: expected the marked pixel at %v", tt.name, tt.marked)
+		}
+	}
+}
+
+func TestRotateWith(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+
+	imgr, _ := NewImager(img)
+	imgr.RotateWith(30, RotateOptions{Background: color.White})
+	if b := imgr.Image.Bounds(); b.Dx() <= 100 || b.Dy() <= 50 {
+		t.Fatalf("RotateWith did not expand the canvas: %v", b)
+	}
+	if got := color.NRGBAModel.Convert(imgr.Image.At(0, 0)); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("RotateWith filled the corners with %v", got)
+	}
+
+	clipped, _ := NewImager(img)
+	clipped.RotateWith(30, RotateOptions{Clip: true})
+	if b := clipped.Image.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Fatalf("RotateWith with Clip returned %v", b)
+	}
+	if _, _, _, a := clipped.Image.At(0, 0).RGBA(); a != 0 {
+		t.Fatalf("RotateWith did not leave the corners transparent by default")
+	}
+	if got := color.NRGBAModel.Convert(clipped.Image.At(50, 25)); got != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("RotateWith with Clip lost the center: %v", got)
+	}
+
+	// Right angles are not resampled, clipping pads the sides
+	quarter, _ := NewImager(img)
+	quarter.RotateWith(90, RotateOptions{Clip: true, Background: color.Black})
+	if b := quarter.Image.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Fatalf("RotateWith(90) with Clip returned %v", b)
+	}
+	if got := color.NRGBAModel.Convert(quarter.Image.At(0, 25)); got != (color.NRGBA{0, 0, 0, 255}) {
+		t.Fatalf("RotateWith(90) with Clip did not pad with the background: %v", got)
+	}
+	if got := color.NRGBAModel.Convert(quarter.Image.At(50, 25)); got != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("RotateWith(90) with Clip lost the center: %v", got)
+	}
+}
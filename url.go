@@ -0,0 +1,141 @@
+package imager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Defaults of URLOptions
+const (
+	defaultURLTimeout   = 30 * time.Second
+	defaultURLMaxBytes  = 32 << 20
+	defaultURLRedirects = 10
+)
+
+// URLOptions holds the options used by NewImagerFromURL
+type URLOptions struct {
+	// Client sends the request, http.DefaultClient when nil. Its redirect
+	// policy is replaced by MaxRedirects
+	Client *http.Client
+
+	// Timeout bounds the whole download, zero means 30 seconds
+	Timeout time.Duration
+
+	// MaxBytes is the largest accepted image, zero means 32 MiB
+	MaxBytes int64
+
+	// ContentTypes are the accepted media types, such as image/jpeg. A type
+	// ending with a slash, such as image/, accepts all its subtypes. Empty
+	// accepts any image
+	ContentTypes []string
+
+	// MaxRedirects is the number of redirects followed, zero means 10 and a
+	// negative value follows none. Only http and https redirects are followed
+	MaxRedirects int
+}
+
+// NewImagerFromURL downloads the image at rawURL, an http or https URL, and
+// creates a new Imager from it. The download fails with ErrTooLarge past
+// opts.MaxBytes and with ErrUnknownFormat when the Content-Type is not
+// accepted
+// i.e :
+// imgr, err := imager.NewImagerFromURL(ctx, "https://example.com/photo.jpg", imager.URLOptions{})
+// imgr, err := imager.NewImagerFromURL(ctx, src, imager.URLOptions{Timeout: 5 * time.Second, MaxBytes: 10 << 20}, imager.WithAutoOrient())
+func NewImagerFromURL(ctx context.Context, rawURL string, opts URLOptions, load ...LoadOption) (*Imager, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: unsupported URL scheme %q", ErrInvalidArgument, u.Scheme)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultURLTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := opts.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("imager: GET %s: %s", rawURL, resp.Status)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !opts.accepts(contentType) {
+		return nil, fmt.Errorf("%w: content type %q", ErrUnknownFormat, contentType)
+	}
+
+	limits := DecodeLimits{MaxBytes: opts.MaxBytes}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = defaultURLMaxBytes
+	}
+	if err := limits.checkSize(resp.ContentLength); err != nil {
+		return nil, err
+	}
+
+	return NewImagerFromReader(&limitedReader{r: resp.Body, remaining: limits.MaxBytes, limits: limits}, load...)
+}
+
+// client returns a copy of the client of the options with their redirect
+// policy
+func (o URLOptions) client() *http.Client {
+	client := http.DefaultClient
+	if o.Client != nil {
+		client = o.Client
+	}
+
+	maxRedirects := o.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultURLRedirects
+	}
+
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("imager: stopped after %d redirects", max(0, maxRedirects))
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return errors.New("imager: redirect to an unsupported URL scheme")
+		}
+		return nil
+	}
+
+	return &c
+}
+
+// accepts reports whether the Content-Type header value is accepted
+func (o URLOptions) accepts(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	accepted := o.ContentTypes
+	if len(accepted) == 0 {
+		accepted = []string{"image/"}
+	}
+	for _, t := range accepted {
+		if mediaType == t || strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package imager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewImagerFromURL(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, createTestImage()); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/red.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/slow.png", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	mux.Handle("/moved.png", http.RedirectHandler("/red.png", http.StatusFound))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	imgr, err := NewImagerFromURL(ctx, server.URL+"/red.png", URLOptions{})
+	if err != nil {
+		t.Fatalf("NewImagerFromURL returned an error: %v", err)
+	}
+	if imgr.ImageType != IMPNG || imgr.Image.Bounds().Dx() != 100 {
+		t.Fatalf("NewImagerFromURL loaded a %s of %v", imgr.ImageType, imgr.Image.Bounds())
+	}
+
+	if _, err := NewImagerFromURL(ctx, server.URL+"/moved.png", URLOptions{}); err != nil {
+		t.Fatalf("NewImagerFromURL did not follow the redirect: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		opts URLOptions
+		want error
+	}{
+		{"content type", server.URL + "/page.html", URLOptions{}, ErrUnknownFormat},
+		{"allowed types", server.URL + "/red.png", URLOptions{ContentTypes: []string{"image/jpeg"}}, ErrUnknownFormat},
+		{"max bytes", server.URL + "/red.png", URLOptions{MaxBytes: 64}, ErrTooLarge},
+		{"timeout", server.URL + "/slow.png", URLOptions{Timeout: 50 * time.Millisecond}, context.DeadlineExceeded},
+		{"scheme", "file:///etc/passwd", URLOptions{}, ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		if _, err := NewImagerFromURL(ctx, tt.url, tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("%s: NewImagerFromURL returned %v, expected %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := NewImagerFromURL(ctx, server.URL+"/moved.png", URLOptions{MaxRedirects: -1}); err == nil {
+		t.Fatalf("NewImagerFromURL followed a redirect with MaxRedirects -1")
+	}
+}
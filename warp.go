@@ -0,0 +1,262 @@
+package imager
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// Interpolation is how Transform and Perspective sample the source pixels
+type Interpolation int
+
+const (
+	InterpolationBilinear Interpolation = iota
+	InterpolationNearest
+	InterpolationBicubic
+)
+
+// Matrix is an affine transform mapping x, y to a*x + b*y + c, d*x + e*y + f,
+// stored as {a, b, c, d, e, f} like f64.Aff3
+type Matrix [6]float64
+
+// TransformOptions holds the options used by Transform and Perspective
+type TransformOptions struct {
+	// Interpolation samples the source pixels, bilinear by default
+	Interpolation Interpolation
+
+	// Background fills the areas outside of the source image, transparent
+	// when nil
+	Background color.Color
+}
+
+// Transform applies the affine transform m to the image, such as a shear or
+// a scale. The canvas is fitted to the transformed image, so translations
+// have no visible effect. Singular matrices record ErrInvalidArgument
+// i.e :
+// imgr.Transform(imager.Matrix{1, 0.3, 0, 0, 1, 0}, imager.TransformOptions{})
+// imgr.Transform(imager.Matrix{2, 0, 0, 0, 0.5, 0}, imager.TransformOptions{Interpolation: imager.InterpolationBicubic})
+func (i *Imager) Transform(m Matrix, opts TransformOptions) *Imager {
+	det := m[0]*m[4] - m[1]*m[3]
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		i.setErr(fmt.Errorf("%w: singular matrix %v", ErrInvalidArgument, m))
+		return i
+	}
+
+	// The bounding box of the transformed corners is the new canvas
+	size := i.Image.Bounds().Size()
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, corner := range [][2]float64{{0, 0}, {float64(size.X), 0}, {0, float64(size.Y)}, {float64(size.X), float64(size.Y)}} {
+		x := m[0]*corner[0] + m[1]*corner[1] + m[2]
+		y := m[3]*corner[0] + m[4]*corner[1] + m[5]
+		minX, minY, maxX, maxY = math.Min(minX, x), math.Min(minY, y), math.Max(maxX, x), math.Max(maxY, y)
+	}
+	minX, minY = math.Floor(minX+1e-9), math.Floor(minY+1e-9)
+	width, height := int(math.Ceil(maxX-1e-9)-minX), int(math.Ceil(maxY-1e-9)-minY)
+
+	// The inverse maps the canvas back to the source, shifted by the box
+	a, b, d, e := m[4]/det, -m[1]/det, -m[3]/det, m[0]/det
+	cx, cy := minX-m[2], minY-m[5]
+	h := homography{a, b, a*cx + b*cy, d, e, d*cx + e*cy, 0, 0}
+
+	return i.apply("Transform", func(img image.Image) image.Image {
+		return warp(img, width, height, h, opts)
+	})
+}
+
+// Perspective maps the quadrilateral src of the image to dst, the corners
+// being in the same order, such as straightening the photo of a document.
+// The result spans from the origin to the bottom right of dst. Degenerate
+// quadrilaterals record ErrInvalidArgument
+// i.e :
+// corners := [4]image.Point{{112, 80}, {910, 140}, {880, 1300}, {60, 1250}}
+// imgr.Perspective(corners, [4]image.Point{{0, 0}, {800, 0}, {800, 1200}, {0, 1200}}, imager.TransformOptions{})
+func (i *Imager) Perspective(src, dst [4]image.Point, opts TransformOptions) *Imager {
+	width, height := 0, 0
+	for _, pt := range dst {
+		width, height = max(width, pt.X), max(height, pt.Y)
+	}
+
+	h, ok := solveHomography(dst, src)
+	if !ok || width <= 0 || height <= 0 {
+		i.setErr(fmt.Errorf("%w: degenerate quadrilateral %v -> %v", ErrInvalidArgument, src, dst))
+		return i
+	}
+
+	return i.apply("Perspective", func(img image.Image) image.Image {
+		return warp(img, width, height, h, opts)
+	})
+}
+
+// homography is a projective transform mapping x, y to
+// (h0*x + h1*y + h2) / w, (h3*x + h4*y + h5) / w with w = h6*x + h7*y + 1
+type homography [8]float64
+
+func (h homography) apply(x, y float64) (float64, float64) {
+	w := h[6]*x + h[7]*y + 1
+	return (h[0]*x + h[1]*y + h[2]) / w, (h[3]*x + h[4]*y + h[5]) / w
+}
+
+// solveHomography returns the homography mapping the from points to the to
+// points, ok is false when the points are degenerate
+func solveHomography(from, to [4]image.Point) (homography, bool) {
+	// Two equations per point pair, solved by Gaussian elimination
+	var a [8][9]float64
+	for k := 0; k < 4; k++ {
+		x, y := float64(from[k].X), float64(from[k].Y)
+		u, v := float64(to[k].X), float64(to[k].Y)
+		a[2*k] = [9]float64{x, y, 1, 0, 0, 0, -u * x, -u * y, u}
+		a[2*k+1] = [9]float64{0, 0, 0, x, y, 1, -v * x, -v * y, v}
+	}
+
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-10 {
+			return homography{}, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			f := a[row][col] / a[col][col]
+			for k := col; k < 9; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+		}
+	}
+
+	var h homography
+	for k := range h {
+		h[k] = a[k][8] / a[k][k]
+	}
+
+	return h, true
+}
+
+// warp returns a width x height image whose pixels are sampled from img at
+// the points given by h, from the canvas to the source coordinates
+func warp(img image.Image, width, height int, h homography, opts TransformOptions) *image.NRGBA {
+	src := imaging.Clone(img)
+	bg := color.NRGBA{}
+	if opts.Background != nil {
+		bg = color.NRGBAModel.Convert(opts.Background).(color.NRGBA)
+	}
+	s := sampler{src: src, bg: bg}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Sampling at the pixel centers
+			sx, sy := h.apply(float64(x)+0.5, float64(y)+0.5)
+
+			var c color.NRGBA
+			switch {
+			case sx < 0 || sy < 0 || sx > float64(src.Rect.Dx()) || sy > float64(src.Rect.Dy()) || math.IsNaN(sx) || math.IsNaN(sy):
+				c = bg
+			case opts.Interpolation == InterpolationNearest:
+				c = s.at(int(sx), int(sy))
+			case opts.Interpolation == InterpolationBicubic:
+				c = s.bicubic(sx-0.5, sy-0.5)
+			default:
+				c = s.bilinear(sx-0.5, sy-0.5)
+			}
+
+			off := y*dst.Stride + x*4
+			dst.Pix[off], dst.Pix[off+1], dst.Pix[off+2], dst.Pix[off+3] = c.R, c.G, c.B, c.A
+		}
+	}
+
+	return dst
+}
+
+// sampler interpolates the pixels of src, the pixels outside of src are bg
+type sampler struct {
+	src *image.NRGBA
+	bg  color.NRGBA
+}
+
+func (s sampler) at(x, y int) color.NRGBA {
+	if x < 0 || y < 0 || x >= s.src.Rect.Dx() || y >= s.src.Rect.Dy() {
+		return s.bg
+	}
+
+	off := y*s.src.Stride + x*4
+	return color.NRGBA{s.src.Pix[off], s.src.Pix[off+1], s.src.Pix[off+2], s.src.Pix[off+3]}
+}
+
+func (s sampler) bilinear(x, y float64) color.NRGBA {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	tx, ty := x-x0, y-y0
+
+	var acc premultiplied
+	for dy := 0; dy < 2; dy++ {
+		for dx := 0; dx < 2; dx++ {
+			w := (1 - math.Abs(float64(dx)-tx)) * (1 - math.Abs(float64(dy)-ty))
+			acc.add(s.at(int(x0)+dx, int(y0)+dy), w)
+		}
+	}
+
+	return acc.color()
+}
+
+func (s sampler) bicubic(x, y float64) color.NRGBA {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	tx, ty := x-x0, y-y0
+
+	var acc premultiplied
+	for dy := -1; dy <= 2; dy++ {
+		wy := catmullRom(float64(dy) - ty)
+		for dx := -1; dx <= 2; dx++ {
+			acc.add(s.at(int(x0)+dx, int(y0)+dy), wy*catmullRom(float64(dx)-tx))
+		}
+	}
+
+	return acc.color()
+}
+
+// catmullRom is the Catmull-Rom cubic kernel
+func catmullRom(t float64) float64 {
+	t = math.Abs(t)
+	switch {
+	case t < 1:
+		return 1.5*t*t*t - 2.5*t*t + 1
+	case t < 2:
+		return -0.5*t*t*t + 2.5*t*t - 4*t + 2
+	}
+
+	return 0
+}
+
+// premultiplied accumulates weighted colors with premultiplied alpha, so
+// transparent pixels don't bleed their color
+type premultiplied struct {
+	r, g, b, a float64
+}
+
+func (p *premultiplied) add(c color.NRGBA, w float64) {
+	alpha := float64(c.A) * w
+	p.r += float64(c.R) * alpha
+	p.g += float64(c.G) * alpha
+	p.b += float64(c.B) * alpha
+	p.a += alpha
+}
+
+func (p premultiplied) color() color.NRGBA {
+	if p.a <= 0 {
+		return color.NRGBA{}
+	}
+
+	channel := func(v float64) uint8 {
+		return uint8(math.Max(0, math.Min(255, v/p.a+0.5)))
+	}
+	return color.NRGBA{channel(p.r), channel(p.g), channel(p.b), uint8(math.Min(255, p.a+0.5))}
+}
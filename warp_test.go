@@ -0,0 +1,68 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	imgr, _ := NewImager(createGradientImage())
+	identity, _ := NewImager(createGradientImage())
+	identity.Transform(Matrix{1, 0, 0, 0, 1, 0}, TransformOptions{})
+	if c, _ := imgr.Compare(identity); c.MAE != 0 {
+		t.Fatalf("the identity transform changed the image, MAE %v", c.MAE)
+	}
+
+	scaled, _ := NewImager(createGradientImage())
+	scaled.Transform(Matrix{2, 0, 0, 0, 0.5, 0}, TransformOptions{Interpolation: InterpolationBicubic})
+	if b := scaled.Image.Bounds(); b.Dx() != 200 || b.Dy() != 50 {
+		t.Fatalf("Transform scaled the image to %v", b)
+	}
+
+	sheared, _ := NewImager(createGradientImage())
+	sheared.Transform(Matrix{1, 0.5, 0, 0, 1, 0}, TransformOptions{Background: color.White})
+	if b := sheared.Image.Bounds(); b.Dx() != 150 || b.Dy() != 100 {
+		t.Fatalf("Transform sheared the image to %v", b)
+	}
+	if got := color.NRGBAModel.Convert(sheared.Image.At(149, 0)); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("Transform did not fill the uncovered area with the background: %v", got)
+	}
+
+	if sheared.Transform(Matrix{1, 2, 0, 2, 4, 0}, TransformOptions{}); !errors.Is(sheared.Err(), ErrInvalidArgument) {
+		t.Fatalf("Transform recorded %v for a singular matrix", sheared.Err())
+	}
+}
+
+func TestPerspective(t *testing.T) {
+	// Mapping an inner rectangle to the origin is a crop
+	imgr, _ := NewImager(createGradientImage())
+	src := [4]image.Point{{10, 20}, {60, 20}, {60, 50}, {10, 50}}
+	dst := [4]image.Point{{0, 0}, {50, 0}, {50, 30}, {0, 30}}
+	imgr.Perspective(src, dst, TransformOptions{Interpolation: InterpolationNearest})
+
+	cropped, _ := NewImager(createGradientImage())
+	cropped.Crop(50, 30, 10, 20)
+	if c, err := imgr.Compare(cropped); err != nil || c.MAE != 0 {
+		t.Fatalf("Perspective did not match the crop: %v %v", c, err)
+	}
+
+	// A mirrored quadrilateral flips the image
+	flipped, _ := NewImager(createGradientImage())
+	flipped.Perspective(
+		[4]image.Point{{0, 0}, {100, 0}, {100, 100}, {0, 100}},
+		[4]image.Point{{100, 0}, {0, 0}, {0, 100}, {100, 100}},
+		TransformOptions{Interpolation: InterpolationNearest},
+	)
+	mirror, _ := NewImager(createGradientImage())
+	mirror.FlipH()
+	if c, _ := flipped.Compare(mirror); c.MAE != 0 {
+		t.Fatalf("Perspective did not flip the image, MAE %v", c.MAE)
+	}
+
+	degenerate := [4]image.Point{{0, 0}, {10, 10}, {20, 20}, {30, 30}}
+	if imgr.Perspective(degenerate, dst, TransformOptions{}); !errors.Is(imgr.Err(), ErrInvalidArgument) {
+		t.Fatalf("Perspective recorded %v for a degenerate quadrilateral", imgr.Err())
+	}
+}
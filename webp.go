@@ -0,0 +1,648 @@
+package imager
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+// The WebP encoder below writes lossless (VP8L) images. It applies the
+// subtract green and predictor transforms and entropy codes the residuals
+// with one set of canonical prefix codes, which keeps it small while still
+// compressing photos and flat graphics reasonably well.
+
+// webpMaxSize is the largest width or height allowed by the VP8L header
+const webpMaxSize = 1 << 14
+
+// webpPredictorBits is the log2 of the predictor tile size
+const webpPredictorBits = 4
+
+// webpPredictors are the predictor modes tried for each tile
+var webpPredictors = []uint8{1, 2, 7, 11, 12}
+
+// codeLengthCodeOrder is the order in which the code length code lengths are written
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// encodeWebP writes img to w as a lossless WebP image
+func encodeWebP(w io.Writer, img image.Image) error {
+	data, err := encodeVP8L(img)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(riffContainer(riffChunk("VP8L", data)))
+	return err
+}
+
+// encodeWebPAnimation writes anim to w as an animated lossless WebP image.
+// Every frame after the first only holds the area differing from the frame
+// before, laid over it, so the still parts of the animation cost nothing
+func encodeWebPAnimation(w io.Writer, anim *Animation) error {
+	bounds := anim.Frames[0].Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > webpMaxSize || height > webpMaxSize {
+		return fmt.Errorf("%w: webp dimensions %dx%d", ErrInvalidArgument, width, height)
+	}
+
+	var alpha bool
+	var previous *image.NRGBA
+	chunks := [][]byte{nil, riffChunk("ANIM", webpAnimHeader(anim.LoopCount))}
+	for idx, img := range anim.Frames {
+		frame := imaging.Clone(img)
+		alpha = alpha || !frame.Opaque()
+
+		rect := frame.Rect
+		if previous != nil {
+			rect = changedRect(previous, frame)
+		}
+		previous = frame
+
+		data, err := encodeVP8L(frame.SubImage(rect))
+		if err != nil {
+			return err
+		}
+
+		delay := 0
+		if idx < len(anim.Delays) {
+			delay = anim.Delays[idx]
+		}
+
+		// Offsets are stored halved, the frame doesn't blend with the
+		// canvas and is kept for the next one
+		anmf := appendUint24(nil, rect.Min.X/2)
+		anmf = appendUint24(anmf, rect.Min.Y/2)
+		anmf = appendUint24(anmf, rect.Dx()-1)
+		anmf = appendUint24(anmf, rect.Dy()-1)
+		anmf = appendUint24(anmf, min(delay*10, 1<<24-1))
+		anmf = append(anmf, 0x02)
+		chunks = append(chunks, riffChunk("ANMF", append(anmf, riffChunk("VP8L", data)...)))
+	}
+
+	// VP8X flags the animation and the alpha
+	vp8x := []byte{0x02, 0, 0, 0}
+	if alpha {
+		vp8x[0] |= 0x10
+	}
+	vp8x = appendUint24(vp8x, width-1)
+	vp8x = appendUint24(vp8x, height-1)
+	chunks[0] = riffChunk("VP8X", vp8x)
+
+	_, err := w.Write(riffContainer(chunks...))
+	return err
+}
+
+// webpAnimHeader returns the ANIM chunk data: a transparent background and
+// the number of loops, converted from the LoopCount of gif.GIF
+func webpAnimHeader(loopCount int) []byte {
+	header := binary.LittleEndian.AppendUint32(nil, 0)
+	return binary.LittleEndian.AppendUint16(header, uint16(loopPlays(loopCount)))
+}
+
+// changedRect returns the smallest rectangle holding the pixels of frame
+// differing from previous, its corner at even coordinates as the ANMF
+// offsets require. Identical frames give a single pixel
+func changedRect(previous, frame *image.NRGBA) image.Rectangle {
+	w, h := frame.Rect.Dx(), frame.Rect.Dy()
+	minX, minY, maxX, maxY := w, h, -1, -1
+	for y := 0; y < h; y++ {
+		row := y * frame.Stride
+		for x := 0; x < w; x++ {
+			if !bytes.Equal(previous.Pix[row+x*4:row+x*4+4], frame.Pix[row+x*4:row+x*4+4]) {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
+	}
+	if maxX < 0 {
+		return image.Rect(0, 0, 1, 1)
+	}
+
+	return image.Rect(minX&^1, minY&^1, maxX+1, maxY+1)
+}
+
+// appendUint24 appends v to b as a 24 bit little endian integer
+func appendUint24(b []byte, v int) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16))
+}
+
+// riffChunk returns a RIFF chunk, padded to an even size
+func riffChunk(fourCC string, data []byte) []byte {
+	chunk := make([]byte, 0, len(data)+9)
+	chunk = append(chunk, fourCC...)
+	chunk = binary.LittleEndian.AppendUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+
+	return chunk
+}
+
+// riffContainer wraps WebP chunks into a RIFF file
+func riffContainer(chunks ...[]byte) []byte {
+	size := 4
+	for _, chunk := range chunks {
+		size += len(chunk)
+	}
+
+	out := make([]byte, 0, size+8)
+	out = append(out, "RIFF"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(size))
+	out = append(out, "WEBP"...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+
+	return out
+}
+
+// encodeVP8L returns the VP8L bitstream of img
+func encodeVP8L(img image.Image) ([]byte, error) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width <= 0 || height <= 0 || width > webpMaxSize || height > webpMaxSize {
+		return nil, fmt.Errorf("%w: webp dimensions %dx%d", ErrInvalidArgument, width, height)
+	}
+
+	// NRGBA pixels, compact since imaging.Clone allocates a new image
+	pix := imaging.Clone(img).Pix
+
+	hasAlpha := false
+	for p := 0; p < len(pix); p += 4 {
+		// Subtract green transform
+		pix[p+0] -= pix[p+1]
+		pix[p+2] -= pix[p+1]
+		hasAlpha = hasAlpha || pix[p+3] != 0xff
+	}
+
+	modes, tilesW := choosePredictors(pix, width, height)
+	residuals := predictResiduals(pix, width, height, modes, tilesW)
+
+	w := &bitWriter{}
+	w.write(0x2f, 8)
+	w.write(uint32(width-1), 14)
+	w.write(uint32(height-1), 14)
+	if hasAlpha {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+	w.write(0, 3)
+
+	// Subtract green transform
+	w.write(1, 1)
+	w.write(2, 2)
+
+	// Predictor transform, the modes are stored in the green channel of a sub image
+	w.write(1, 1)
+	w.write(0, 2)
+	w.write(webpPredictorBits-2, 3)
+	sub := make([]byte, len(modes)*4)
+	for idx, mode := range modes {
+		sub[idx*4+1] = mode
+	}
+	writeImageData(w, sub, false)
+
+	// No more transforms
+	w.write(0, 1)
+	writeImageData(w, residuals, true)
+
+	return w.bytes(), nil
+}
+
+// choosePredictors picks for each tile the predictor with the smallest residuals
+func choosePredictors(pix []byte, width, height int) ([]uint8, int) {
+	size := 1 << webpPredictorBits
+	tilesW, tilesH := (width+size-1)/size, (height+size-1)/size
+	modes := make([]uint8, tilesW*tilesH)
+
+	for ty := 0; ty < tilesH; ty++ {
+		for tx := 0; tx < tilesW; tx++ {
+			best, bestCost := webpPredictors[0], -1
+			for _, mode := range webpPredictors {
+				cost := 0
+				for y := ty * size; y < min((ty+1)*size, height); y++ {
+					for x := tx * size; x < min((tx+1)*size, width); x++ {
+						pred := predict(pix, width, x, y, mode)
+						p := (y*width + x) * 4
+						for c := 0; c < 4; c++ {
+							cost += absResidual(pix[p+c] - pred[c])
+						}
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[ty*tilesW+tx] = best
+		}
+	}
+
+	return modes, tilesW
+}
+
+// predictResiduals returns the difference between pix and its prediction
+func predictResiduals(pix []byte, width, height int, modes []uint8, tilesW int) []byte {
+	residuals := make([]byte, len(pix))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mode := modes[(y>>webpPredictorBits)*tilesW+(x>>webpPredictorBits)]
+			pred := predict(pix, width, x, y, mode)
+			p := (y*width + x) * 4
+			for c := 0; c < 4; c++ {
+				residuals[p+c] = pix[p+c] - pred[c]
+			}
+		}
+	}
+
+	return residuals
+}
+
+// predict returns the predicted NRGBA value of the pixel at x, y
+func predict(pix []byte, width, x, y int, mode uint8) [4]uint8 {
+	at := func(px, py int) [4]uint8 {
+		p := (py*width + px) * 4
+		return [4]uint8{pix[p], pix[p+1], pix[p+2], pix[p+3]}
+	}
+
+	switch {
+	case x == 0 && y == 0:
+		return [4]uint8{0, 0, 0, 0xff}
+	case y == 0:
+		return at(x-1, y)
+	case x == 0:
+		return at(x, y-1)
+	}
+
+	l, t, tl := at(x-1, y), at(x, y-1), at(x-1, y-1)
+	var out [4]uint8
+	switch mode {
+	case 1:
+		out = l
+	case 2:
+		out = t
+	case 7:
+		for c := range out {
+			out[c] = uint8((uint16(l[c]) + uint16(t[c])) / 2)
+		}
+	case 11:
+		// Select(L, T, TL)
+		pl, pt := 0, 0
+		for c := range out {
+			pl += absInt(int(tl[c]) - int(t[c]))
+			pt += absInt(int(tl[c]) - int(l[c]))
+		}
+		if pl < pt {
+			out = l
+		} else {
+			out = t
+		}
+	case 12:
+		// ClampAddSubtractFull(L, T, TL)
+		for c := range out {
+			v := int(l[c]) + int(t[c]) - int(tl[c])
+			out[c] = uint8(max(0, min(255, v)))
+		}
+	}
+
+	return out
+}
+
+// absResidual returns the magnitude of a residual seen as a signed byte
+func absResidual(v uint8) int {
+	return absInt(int(int8(v)))
+}
+
+// absInt returns the absolute value of v
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// writeImageData writes the entropy coded pixels, as literals only
+func writeImageData(w *bitWriter, pix []byte, topLevel bool) {
+	// No color cache
+	w.write(0, 1)
+	if topLevel {
+		// No meta prefix codes
+		w.write(0, 1)
+	}
+
+	green := make([]int, 256+24)
+	red, blue, alpha := make([]int, 256), make([]int, 256), make([]int, 256)
+	for p := 0; p < len(pix); p += 4 {
+		red[pix[p]]++
+		green[pix[p+1]]++
+		blue[pix[p+2]]++
+		alpha[pix[p+3]]++
+	}
+
+	codes := [4]prefixCode{
+		writePrefixCode(w, green),
+		writePrefixCode(w, red),
+		writePrefixCode(w, blue),
+		writePrefixCode(w, alpha),
+	}
+	// Distance code, never used
+	writePrefixCode(w, make([]int, 40))
+
+	for p := 0; p < len(pix); p += 4 {
+		codes[0].write(w, int(pix[p+1]))
+		codes[1].write(w, int(pix[p]))
+		codes[2].write(w, int(pix[p+2]))
+		codes[3].write(w, int(pix[p+3]))
+	}
+}
+
+// bitWriter packs values into bytes, least significant bit first
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+// write writes the n low bits of v
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+// bytes flushes the pending bits and returns the written data
+func (w *bitWriter) bytes() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+
+	return w.buf
+}
+
+// prefixCode is a canonical prefix code, codes are stored bit reversed
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+// write writes symbol s
+func (c prefixCode) write(w *bitWriter, s int) {
+	if c.lengths[s] > 0 {
+		w.write(c.codes[s], uint(c.lengths[s]))
+	}
+}
+
+// writePrefixCode builds the prefix code for hist and writes its definition
+func writePrefixCode(w *bitWriter, hist []int) prefixCode {
+	var symbols []int
+	for s, count := range hist {
+		if count > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 {
+		symbols = []int{0}
+	}
+
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < 256 {
+		// Simple code, a single symbol takes no bits at all
+		w.write(1, 1)
+		w.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			w.write(0, 1)
+			w.write(uint32(symbols[0]), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(symbols[0]), 8)
+		}
+
+		lengths := make([]uint8, len(hist))
+		if len(symbols) == 2 {
+			w.write(uint32(symbols[1]), 8)
+			lengths[symbols[0]], lengths[symbols[1]] = 1, 1
+		}
+		return canonicalCode(lengths)
+	}
+
+	lengths := huffmanLengths(hist, 15)
+	w.write(0, 1)
+	writeCodeLengths(w, lengths)
+
+	return canonicalCode(lengths)
+}
+
+// writeCodeLengths writes the code lengths of a normal prefix code
+func writeCodeLengths(w *bitWriter, lengths []uint8) {
+	type token struct {
+		symbol int
+		extra  uint32
+		nbits  uint
+	}
+
+	// Run length encode the code lengths
+	var tokens []token
+	for idx := 0; idx < len(lengths); {
+		value := lengths[idx]
+		run := 1
+		for idx+run < len(lengths) && lengths[idx+run] == value {
+			run++
+		}
+		idx += run
+
+		if value == 0 {
+			for run >= 11 {
+				n := min(run, 138)
+				tokens = append(tokens, token{18, uint32(n - 11), 7})
+				run -= n
+			}
+			if run >= 3 {
+				tokens = append(tokens, token{17, uint32(run - 3), 3})
+				run = 0
+			}
+		} else {
+			tokens = append(tokens, token{symbol: int(value)})
+			run--
+			for run >= 3 {
+				n := min(run, 6)
+				tokens = append(tokens, token{16, uint32(n - 3), 2})
+				run -= n
+			}
+		}
+		for ; run > 0; run-- {
+			tokens = append(tokens, token{symbol: int(value)})
+		}
+	}
+
+	hist := make([]int, 19)
+	used := 0
+	for _, t := range tokens {
+		if hist[t.symbol] == 0 {
+			used++
+		}
+		hist[t.symbol]++
+	}
+	if used == 1 {
+		// Keep a proper two leaf tree for the decoders
+		if hist[0] == 0 {
+			hist[0] = 1
+		} else {
+			hist[1] = 1
+		}
+	}
+
+	code := canonicalCode(huffmanLengths(hist, 7))
+
+	count := 4
+	for idx, s := range codeLengthCodeOrder {
+		if code.lengths[s] > 0 {
+			count = max(count, idx+1)
+		}
+	}
+	w.write(uint32(count-4), 4)
+	for _, s := range codeLengthCodeOrder[:count] {
+		w.write(uint32(code.lengths[s]), 3)
+	}
+
+	// All the code lengths are written, no max symbol
+	w.write(0, 1)
+	for _, t := range tokens {
+		code.write(w, t.symbol)
+		if t.nbits > 0 {
+			w.write(t.extra, t.nbits)
+		}
+	}
+}
+
+// canonicalCode assigns canonical codes to the code lengths
+func canonicalCode(lengths []uint8) prefixCode {
+	var count [16]uint32
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+
+	var next [16]uint32
+	code := uint32(0)
+	for bits := 1; bits < 16; bits++ {
+		code = (code + count[bits-1]) << 1
+		next[bits] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[s] = reverseBits(next[l], uint(l))
+		next[l]++
+	}
+
+	return prefixCode{lengths: lengths, codes: codes}
+}
+
+// reverseBits reverses the n low bits of v
+func reverseBits(v uint32, n uint) uint32 {
+	var out uint32
+	for k := uint(0); k < n; k++ {
+		out = out<<1 | (v>>k)&1
+	}
+
+	return out
+}
+
+// huffmanLengths returns the Huffman code lengths of hist, no longer than
+// maxLength. The counts are flattened until the tree fits
+func huffmanLengths(hist []int, maxLength int) []uint8 {
+	counts := append([]int(nil), hist...)
+	for {
+		lengths, deepest := huffmanTree(counts)
+		if deepest <= maxLength {
+			return lengths
+		}
+		for s, c := range counts {
+			if c > 0 {
+				counts[s] = max(1, c/2)
+			}
+		}
+	}
+}
+
+// huffmanNode is a node of the tree built by huffmanTree
+type huffmanNode struct {
+	count  int
+	symbol int
+	left   *huffmanNode
+	right  *huffmanNode
+}
+
+// huffmanQueue is a min heap of nodes ordered by count
+type huffmanQueue []*huffmanNode
+
+func (q huffmanQueue) Len() int { return len(q) }
+func (q huffmanQueue) Less(a, b int) bool {
+	if q[a].count == q[b].count {
+		return q[a].symbol < q[b].symbol
+	}
+	return q[a].count < q[b].count
+}
+func (q huffmanQueue) Swap(a, b int) { q[a], q[b] = q[b], q[a] }
+func (q *huffmanQueue) Push(x any)   { *q = append(*q, x.(*huffmanNode)) }
+func (q *huffmanQueue) Pop() any {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+// huffmanTree returns the unconstrained Huffman code lengths of counts and
+// the length of the longest code
+func huffmanTree(counts []int) ([]uint8, int) {
+	lengths := make([]uint8, len(counts))
+
+	q := huffmanQueue{}
+	for s, c := range counts {
+		if c > 0 {
+			q = append(q, &huffmanNode{count: c, symbol: s})
+		}
+	}
+	switch len(q) {
+	case 0:
+		return lengths, 0
+	case 1:
+		lengths[q[0].symbol] = 1
+		return lengths, 1
+	}
+
+	sort.Sort(q)
+	heap.Init(&q)
+	for q.Len() > 1 {
+		a := heap.Pop(&q).(*huffmanNode)
+		b := heap.Pop(&q).(*huffmanNode)
+		heap.Push(&q, &huffmanNode{count: a.count + b.count, symbol: min(a.symbol, b.symbol), left: a, right: b})
+	}
+
+	deepest := 0
+	var walk func(n *huffmanNode, depth int)
+	walk = func(n *huffmanNode, depth int) {
+		if n.left == nil {
+			lengths[n.symbol] = uint8(min(depth, 255))
+			deepest = max(deepest, depth)
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk(q[0], 0)
+
+	return lengths, deepest
+}
@@ -0,0 +1,138 @@
+package imager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// assertSamePixels fails when a and b differ on any pixel
+func assertSamePixels(t *testing.T, a, b image.Image) {
+	t.Helper()
+
+	if a.Bounds().Dx() != b.Bounds().Dx() || a.Bounds().Dy() != b.Bounds().Dy() {
+		t.Fatalf("image bounds differ: %v and %v", a.Bounds(), b.Bounds())
+	}
+
+	for y := 0; y < a.Bounds().Dy(); y++ {
+		for x := 0; x < a.Bounds().Dx(); x++ {
+			ca := color.NRGBAModel.Convert(a.At(a.Bounds().Min.X+x, a.Bounds().Min.Y+y))
+			cb := color.NRGBAModel.Convert(b.At(b.Bounds().Min.X+x, b.Bounds().Min.Y+y))
+			if ca != cb {
+				t.Fatalf("pixel %d,%d differs: %v and %v", x, y, ca, cb)
+			}
+		}
+	}
+}
+
+func TestWebPRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 67, 45))
+	noise := image.NewNRGBA(image.Rect(0, 0, 33, 17))
+	for y := 0; y < 45; y++ {
+		for x := 0; x < 67; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x * 3), uint8(y * 5), uint8(x * y), uint8(255 - x)})
+			if x < 33 && y < 17 {
+				noise.SetNRGBA(x, y, color.NRGBA{uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), 255})
+			}
+		}
+	}
+
+	for name, img := range map[string]image.Image{
+		"solid":    createTestImage(),
+		"gradient": gradient,
+		"noise":    noise,
+		"pixel":    image.NewNRGBA(image.Rect(0, 0, 1, 1)),
+	} {
+		imgr, _ := NewImager(img)
+		imgr.ImageType = IMWEBP
+
+		data, err := imgr.Bytes()
+		if err != nil {
+			t.Fatalf("Bytes returned an error for %s: %v", name, err)
+		}
+
+		decoded, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode %s webp: %v", name, err)
+		}
+
+		assertSamePixels(t, img, decoded)
+	}
+}
+
+func TestWebPSaveAndLoad(t *testing.T) {
+	imgr, _ := NewImager(createTestImage())
+	imgr.ImageType = IMPNG
+
+	location := filepath.Join(t.TempDir(), "image.webp")
+	if err := imgr.Save(location); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	loaded, err := NewImagerFromFile(location)
+	if err != nil {
+		t.Fatalf("NewImagerFromFile returned an error: %v", err)
+	}
+
+	if loaded.ImageType != IMWEBP {
+		t.Fatalf("NewImagerFromFile returned incorrect image type: %v", loaded.ImageType)
+	}
+
+	assertSamePixels(t, imgr.Image, loaded.Image)
+}
+
+func TestAnimatedWebP(t *testing.T) {
+	imgr, _ := NewImagerFromBytes(createTestGIF(t))
+	anim := imgr.Animation
+
+	data, err := imgr.ConvertTo(IMWEBP).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if string(data[12:16]) != "VP8X" || data[20]&0x02 == 0 {
+		t.Fatalf("Bytes did not write an animated webp header")
+	}
+
+	// Walk the chunks, laying each frame over the canvas
+	canvas := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	var frames int
+	for p := 12; p+8 <= len(data); {
+		fourCC, size := string(data[p:p+4]), int(binary.LittleEndian.Uint32(data[p+4:]))
+		chunk := data[p+8 : p+8+size]
+		p += 8 + size + size%2
+
+		switch fourCC {
+		case "ANIM":
+			if loops := binary.LittleEndian.Uint16(chunk[4:]); loops != 4 {
+				t.Fatalf("ANIM has %d loops, want 4", loops)
+			}
+		case "ANMF":
+			u24 := func(b []byte) int { return int(b[0]) | int(b[1])<<8 | int(b[2])<<16 }
+			x, y := u24(chunk)*2, u24(chunk[3:])*2
+			if delay := u24(chunk[12:]); delay != anim.Delays[frames]*10 {
+				t.Fatalf("frame %d has a delay of %dms, want %d", frames, delay, anim.Delays[frames]*10)
+			}
+
+			img, err := webp.Decode(bytes.NewReader(riffContainer(chunk[16:])))
+			if err != nil {
+				t.Fatalf("failed to decode frame %d: %v", frames, err)
+			}
+			draw.Draw(canvas, img.Bounds().Add(image.Pt(x, y)), img, img.Bounds().Min, draw.Src)
+			assertSamePixels(t, canvas, anim.Frames[frames])
+			frames++
+		}
+	}
+
+	if frames != 3 {
+		t.Fatalf("Bytes wrote %d frames, want 3", frames)
+	}
+}
@@ -0,0 +1,112 @@
+package imager
+
+import (
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// neutralKelvin is the color temperature Temperature shifts from, daylight
+const neutralKelvin = 6500
+
+// Temperature shifts the color temperature of the image by kelvinShift,
+// from -5000 to 5000, as if it had been shot under a light that much warmer.
+// Positive values warm the image toward orange and negative values cool it
+// toward blue
+// i.e :
+// imgr.Temperature(-1500)
+func (i *Imager) Temperature(kelvinShift float64) *Imager {
+	if !i.checkRange("temperature shift", kelvinShift, -5000, 5000) {
+		return i
+	}
+
+	light, neutral := kelvinToRGB(neutralKelvin-kelvinShift), kelvinToRGB(neutralKelvin)
+	return i.applyGains("Temperature", light[0]/neutral[0], light[1]/neutral[1], light[2]/neutral[2])
+}
+
+// Tint shifts the colors of the image along the green-magenta axis, which
+// Temperature leaves alone, by amount from -100 (green) to 100 (magenta)
+// i.e :
+// imgr.Temperature(-800).Tint(10)
+func (i *Imager) Tint(amount float64) *Imager {
+	if !i.checkRange("tint", amount, -100, 100) {
+		return i
+	}
+
+	t := amount / 100
+	return i.applyGains("Tint", 1+0.15*t, 1-0.3*t, 1+0.15*t)
+}
+
+// AutoWhiteBalance removes the color cast of the light the image was shot
+// under, assuming its colors average to gray: each channel is scaled so its
+// mean matches the mean luminance. Transparent pixels are ignored and the
+// correction is limited to a factor of 2 per channel
+// i.e :
+// imgr.AutoWhiteBalance()
+func (i *Imager) AutoWhiteBalance() *Imager {
+	if i.err != nil {
+		return i
+	}
+
+	src := imaging.Clone(i.Image)
+	var sums [3]float64
+	var weight float64
+	for p := 0; p < len(src.Pix); p += 4 {
+		a := float64(src.Pix[p+3]) / 255
+		for ch := 0; ch < 3; ch++ {
+			sums[ch] += float64(src.Pix[p+ch]) * a
+		}
+		weight += a
+	}
+	if weight == 0 || sums[0] == 0 || sums[1] == 0 || sums[2] == 0 {
+		return i
+	}
+
+	gray := 0.299*sums[0] + 0.587*sums[1] + 0.114*sums[2]
+	var gains [3]float64
+	for ch := range gains {
+		gains[ch] = math.Min(2, math.Max(0.5, gray/sums[ch]))
+	}
+
+	return i.applyGains("AutoWhiteBalance", gains[0], gains[1], gains[2])
+}
+
+// applyGains scales the red, green and blue channels of the image, see
+// applyTables
+func (i *Imager) applyGains(name string, red, green, blue float64) *Imager {
+	var tables [3][256]uint8
+	for ch, gain := range []float64{red, green, blue} {
+		for v := range tables[ch] {
+			tables[ch][v] = clampUint8(float64(v) * gain)
+		}
+	}
+
+	return i.applyTables(name, tables[0], tables[1], tables[2])
+}
+
+// kelvinToRGB returns the color of a black body at the temperature kelvin,
+// from 1000 to 40000, with Tanner Helland's approximation
+func kelvinToRGB(kelvin float64) [3]float64 {
+	t := math.Min(400, math.Max(10, kelvin/100))
+
+	var rgb [3]float64
+	if t <= 66 {
+		rgb[0] = 255
+		rgb[1] = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		rgb[0] = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		rgb[1] = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+	switch {
+	case t >= 66:
+		rgb[2] = 255
+	case t > 19:
+		rgb[2] = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+
+	for ch := range rgb {
+		rgb[ch] = math.Min(255, math.Max(1, rgb[ch]))
+	}
+
+	return rgb
+}
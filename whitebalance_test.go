@@ -0,0 +1,74 @@
+package imager
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// meanChannels returns the mean of the red, green and blue channels of img
+func meanChannels(img image.Image) [3]float64 {
+	var means [3]float64
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			means[0] += float64(c.R)
+			means[1] += float64(c.G)
+			means[2] += float64(c.B)
+		}
+	}
+	for ch := range means {
+		means[ch] /= float64(bounds.Dx() * bounds.Dy())
+	}
+
+	return means
+}
+
+func TestTemperature(t *testing.T) {
+	gray := color.NRGBA{128, 128, 128, 255}
+	warm, _ := NewImager(createColorImage(gray))
+	warm.Temperature(2000)
+	if m := meanChannels(warm.Image); m[0] <= m[2] {
+		t.Fatalf("a positive shift did not warm the image: %v", m)
+	}
+
+	cool, _ := NewImager(createColorImage(gray))
+	cool.Temperature(-2000)
+	if m := meanChannels(cool.Image); m[2] <= m[0] {
+		t.Fatalf("a negative shift did not cool the image: %v", m)
+	}
+
+	if err := cool.Temperature(9000).Err(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestTint(t *testing.T) {
+	imgr, _ := NewImager(createColorImage(color.NRGBA{128, 128, 128, 255}))
+	imgr.Tint(50)
+	if m := meanChannels(imgr.Image); m[1] >= m[0] || m[1] >= m[2] {
+		t.Fatalf("a positive tint did not shift to magenta: %v", m)
+	}
+}
+
+func TestAutoWhiteBalance(t *testing.T) {
+	// A gray pattern under a yellow light
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			v := float64(40 + x*8)
+			img.SetNRGBA(x, y, color.NRGBA{clampUint8(v * 1.2), clampUint8(v * 1.1), clampUint8(v * 0.7), 255})
+		}
+	}
+
+	imgr, _ := NewImager(img)
+	if err := imgr.AutoWhiteBalance().Err(); err != nil {
+		t.Fatalf("AutoWhiteBalance returned an error: %v", err)
+	}
+	m := meanChannels(imgr.Image)
+	if m[0]-m[2] > 3 || m[2]-m[0] > 3 || m[1]-m[2] > 3 || m[2]-m[1] > 3 {
+		t.Fatalf("the color cast remains: %v", m)
+	}
+}